@@ -19,3 +19,10 @@ type Action interface {
 	Resume() (interface{}, error)
 	Cancel() error
 }
+
+// ProgressReporter is implemented by asynchronous actions
+// that report their progress while they are running
+type ProgressReporter interface {
+	// Progress is converted to JSON and included in running task state
+	Progress() interface{}
+}
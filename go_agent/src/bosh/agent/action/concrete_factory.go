@@ -5,8 +5,10 @@ import (
 	boshas "bosh/agent/applier/applyspec"
 	boshcomp "bosh/agent/compiler"
 	boshdrain "bosh/agent/drain"
+	boshsnapshot "bosh/agent/snapshot"
 	boshtask "bosh/agent/task"
 	boshblob "bosh/blobstore"
+	boshboot "bosh/bootstrap"
 	bosherr "bosh/errors"
 	boshjobsuper "bosh/jobsupervisor"
 	boshlog "bosh/logger"
@@ -31,6 +33,8 @@ func NewFactory(
 	jobSupervisor boshjobsuper.JobSupervisor,
 	specService boshas.V1Service,
 	drainScriptProvider boshdrain.DrainScriptProvider,
+	snapshotHookProvider boshsnapshot.SnapshotHookProvider,
+	bootstrapJournal boshboot.Journal,
 	logger boshlog.Logger,
 ) (factory Factory) {
 	compressor := platform.GetCompressor()
@@ -47,14 +51,16 @@ func NewFactory(
 			"cancel_task": NewCancelTask(taskService),
 
 			// VM admin
-			"ssh":        NewSsh(settingsService, platform, dirProvider),
-			"fetch_logs": NewFetchLogs(compressor, copier, blobstore, dirProvider),
+			"ssh":                  NewSsh(settingsService, platform, dirProvider),
+			"fetch_logs":           NewFetchLogs(compressor, copier, blobstore, dirProvider),
+			"get_bootstrap_status": NewGetBootstrapStatus(bootstrapJournal),
 
 			// Job management
 			"prepare":    NewPrepare(applier),
 			"apply":      NewApply(applier, specService, settingsService),
-			"start":      NewStart(jobSupervisor),
-			"stop":       NewStop(jobSupervisor),
+			"start":      NewStart(jobSupervisor, specService),
+			"stop":       NewStop(jobSupervisor, specService),
+			"restart":    NewRestart(jobSupervisor, specService),
 			"drain":      NewDrain(notifier, specService, drainScriptProvider, jobSupervisor),
 			"get_state":  NewGetState(settingsService, specService, jobSupervisor, vitalsService, ntpService),
 			"run_errand": NewRunErrand(specService, dirProvider.JobsDir(), platform.GetRunner(), logger),
@@ -69,10 +75,15 @@ func NewFactory(
 			"mount_disk":   NewMountDisk(settingsService, platform, platform, dirProvider),
 			"unmount_disk": NewUnmountDisk(settingsService, platform),
 
+			// Disk snapshots
+			"snapshot_disk":   NewSnapshotDisk(settingsService, specService, snapshotHookProvider, platform, logger),
+			"list_snapshots":  NewListSnapshots(settingsService, platform),
+			"delete_snapshot": NewDeleteSnapshot(settingsService, platform),
+
 			// Networking
 			"prepare_network_change":     NewPrepareNetworkChange(platform.GetFs(), settingsService),
 			"prepare_configure_networks": NewPrepareConfigureNetworks(platform, settingsService),
-			"configure_networks":         NewConfigureNetworks(),
+			"configure_networks":         NewConfigureNetworks(platform),
 		},
 	}
 	return
@@ -9,8 +9,10 @@ import (
 	fakeappl "bosh/agent/applier/fakes"
 	fakecomp "bosh/agent/compiler/fakes"
 	boshdrain "bosh/agent/drain"
+	boshsnapshot "bosh/agent/snapshot"
 	faketask "bosh/agent/task/fakes"
 	fakeblobstore "bosh/blobstore/fakes"
+	fakeboot "bosh/bootstrap/fakes"
 	fakejobsuper "bosh/jobsupervisor/fakes"
 	boshlog "bosh/logger"
 	fakenotif "bosh/notification/fakes"
@@ -21,18 +23,20 @@ import (
 
 var _ = Describe("concreteFactory", func() {
 	var (
-		settingsService     *fakesettings.FakeSettingsService
-		platform            *fakeplatform.FakePlatform
-		blobstore           *fakeblobstore.FakeBlobstore
-		taskService         *faketask.FakeService
-		notifier            *fakenotif.FakeNotifier
-		applier             *fakeappl.FakeApplier
-		compiler            *fakecomp.FakeCompiler
-		jobSupervisor       *fakejobsuper.FakeJobSupervisor
-		specService         *fakeas.FakeV1Service
-		drainScriptProvider boshdrain.DrainScriptProvider
-		factory             Factory
-		logger              boshlog.Logger
+		settingsService      *fakesettings.FakeSettingsService
+		platform             *fakeplatform.FakePlatform
+		blobstore            *fakeblobstore.FakeBlobstore
+		taskService          *faketask.FakeService
+		notifier             *fakenotif.FakeNotifier
+		applier              *fakeappl.FakeApplier
+		compiler             *fakecomp.FakeCompiler
+		jobSupervisor        *fakejobsuper.FakeJobSupervisor
+		specService          *fakeas.FakeV1Service
+		drainScriptProvider  boshdrain.DrainScriptProvider
+		snapshotHookProvider boshsnapshot.SnapshotHookProvider
+		bootstrapJournal     *fakeboot.FakeJournal
+		factory              Factory
+		logger               boshlog.Logger
 	)
 
 	BeforeEach(func() {
@@ -46,6 +50,8 @@ var _ = Describe("concreteFactory", func() {
 		jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
 		specService = fakeas.NewFakeV1Service()
 		drainScriptProvider = boshdrain.NewConcreteDrainScriptProvider(nil, nil, platform.GetDirProvider())
+		snapshotHookProvider = boshsnapshot.NewConcreteSnapshotHookProvider(nil, nil, platform.GetDirProvider())
+		bootstrapJournal = &fakeboot.FakeJournal{}
 		logger = boshlog.NewLogger(boshlog.LevelNone)
 
 		factory = NewFactory(
@@ -59,6 +65,8 @@ var _ = Describe("concreteFactory", func() {
 			jobSupervisor,
 			specService,
 			drainScriptProvider,
+			snapshotHookProvider,
+			bootstrapJournal,
 			logger,
 		)
 	})
@@ -87,6 +95,12 @@ var _ = Describe("concreteFactory", func() {
 		Expect(action).To(Equal(NewFetchLogs(platform.GetCompressor(), platform.GetCopier(), blobstore, platform.GetDirProvider())))
 	})
 
+	It("get_bootstrap_status", func() {
+		action, err := factory.Create("get_bootstrap_status")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewGetBootstrapStatus(bootstrapJournal)))
+	})
+
 	It("get_task", func() {
 		action, err := factory.Create("get_task")
 		Expect(err).ToNot(HaveOccurred())
@@ -115,7 +129,9 @@ var _ = Describe("concreteFactory", func() {
 	It("migrate_disk", func() {
 		action, err := factory.Create("migrate_disk")
 		Expect(err).ToNot(HaveOccurred())
-		Expect(action).To(Equal(NewMigrateDisk(platform, platform.GetDirProvider())))
+
+		// Cannot do equality check since channel is used in initializer
+		Expect(action).To(BeAssignableToTypeOf(MigrateDiskAction{}))
 	})
 
 	It("mount_disk", func() {
@@ -145,7 +161,7 @@ var _ = Describe("concreteFactory", func() {
 	It("configure_networks", func() {
 		action, err := factory.Create("configure_networks")
 		Expect(err).ToNot(HaveOccurred())
-		Expect(action).To(Equal(NewConfigureNetworks()))
+		Expect(action).To(Equal(NewConfigureNetworks(platform)))
 	})
 
 	It("ssh", func() {
@@ -157,13 +173,19 @@ var _ = Describe("concreteFactory", func() {
 	It("start", func() {
 		action, err := factory.Create("start")
 		Expect(err).ToNot(HaveOccurred())
-		Expect(action).To(Equal(NewStart(jobSupervisor)))
+		Expect(action).To(Equal(NewStart(jobSupervisor, specService)))
 	})
 
 	It("stop", func() {
 		action, err := factory.Create("start")
 		Expect(err).ToNot(HaveOccurred())
-		Expect(action).To(Equal(NewStart(jobSupervisor)))
+		Expect(action).To(Equal(NewStart(jobSupervisor, specService)))
+	})
+
+	It("restart", func() {
+		action, err := factory.Create("restart")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewRestart(jobSupervisor, specService)))
 	})
 
 	It("unmount_disk", func() {
@@ -172,6 +194,24 @@ var _ = Describe("concreteFactory", func() {
 		Expect(action).To(Equal(NewUnmountDisk(settingsService, platform)))
 	})
 
+	It("snapshot_disk", func() {
+		action, err := factory.Create("snapshot_disk")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewSnapshotDisk(settingsService, specService, snapshotHookProvider, platform, logger)))
+	})
+
+	It("list_snapshots", func() {
+		action, err := factory.Create("list_snapshots")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewListSnapshots(settingsService, platform)))
+	})
+
+	It("delete_snapshot", func() {
+		action, err := factory.Create("delete_snapshot")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(action).To(Equal(NewDeleteSnapshot(settingsService, platform)))
+	})
+
 	It("compile_package", func() {
 		action, err := factory.Create("compile_package")
 		Expect(err).ToNot(HaveOccurred())
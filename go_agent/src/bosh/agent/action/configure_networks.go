@@ -4,13 +4,18 @@ import (
 	"errors"
 	"os"
 	"time"
+
+	bosherr "bosh/errors"
+	boshplatform "bosh/platform"
 )
 
 type ConfigureNetworksAction struct {
+	platform                boshplatform.Platform
 	waitToKillAgentInterval time.Duration
 }
 
-func NewConfigureNetworks() (prepareAction ConfigureNetworksAction) {
+func NewConfigureNetworks(platform boshplatform.Platform) (prepareAction ConfigureNetworksAction) {
+	prepareAction.platform = platform
 	prepareAction.waitToKillAgentInterval = 1 * time.Second
 	return
 }
@@ -41,6 +46,16 @@ func (a ConfigureNetworksAction) Run() (interface{}, error) {
 }
 
 func (a ConfigureNetworksAction) Resume() (interface{}, error) {
+	// Networking is verified and rolled back if necessary while agent is bootstrapped
+	failure, err := a.platform.GetNetworkingFailure()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Getting networking failure")
+	}
+
+	if failure != "" {
+		return nil, bosherr.New("Networking configuration was rolled back: %s", failure)
+	}
+
 	return "ok", nil
 }
 
@@ -1,20 +1,25 @@
 package action_test
 
 import (
+	"errors"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	. "bosh/agent/action"
+	fakeplatform "bosh/platform/fakes"
 )
 
 func init() {
 	Describe("configureNetworks", func() {
 		var (
-			action ConfigureNetworksAction
+			platform *fakeplatform.FakePlatform
+			action   ConfigureNetworksAction
 		)
 
 		BeforeEach(func() {
-			action = NewConfigureNetworks()
+			platform = fakeplatform.NewFakePlatform()
+			action = NewConfigureNetworks(platform)
 		})
 
 		It("is asynchronous", func() {
@@ -35,6 +40,24 @@ func init() {
 				Expect(value).To(Equal("ok"))
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			It("returns error when networking configuration failed verification and was rolled back", func() {
+				platform.GetNetworkingFailureFailure = "fake-verification-failure"
+
+				value, err := action.Resume()
+				Expect(value).To(BeNil())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-verification-failure"))
+			})
+
+			It("returns error when getting networking failure fails", func() {
+				platform.GetNetworkingFailureErr = errors.New("fake-get-failure-err")
+
+				value, err := action.Resume()
+				Expect(value).To(BeNil())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-get-failure-err"))
+			})
 		})
 	})
 }
@@ -0,0 +1,53 @@
+package action
+
+import (
+	"errors"
+
+	bosherr "bosh/errors"
+	boshplatform "bosh/platform"
+	boshsettings "bosh/settings"
+)
+
+type DeleteSnapshotAction struct {
+	settingsService boshsettings.Service
+	platform        boshplatform.Platform
+}
+
+func NewDeleteSnapshot(
+	settingsService boshsettings.Service,
+	platform boshplatform.Platform,
+) (action DeleteSnapshotAction) {
+	action.settingsService = settingsService
+	action.platform = platform
+	return
+}
+
+func (a DeleteSnapshotAction) IsAsynchronous() bool {
+	return true
+}
+
+func (a DeleteSnapshotAction) IsPersistent() bool {
+	return false
+}
+
+func (a DeleteSnapshotAction) Run(name string) (string, error) {
+	volumeID, devicePath := a.settingsService.GetSettings().Disks.PersistentDisk()
+	if len(devicePath) == 0 {
+		return "", bosherr.New("Persistent disk is not attached")
+	}
+
+	err := a.platform.DeletePersistentDiskSnapshot(volumeID, devicePath, name)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Deleting persistent disk snapshot")
+	}
+
+	return "deleted", nil
+}
+
+func (a DeleteSnapshotAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a DeleteSnapshotAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,68 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/agent/action"
+	fakeplatform "bosh/platform/fakes"
+	boshsettings "bosh/settings"
+	fakesettings "bosh/settings/fakes"
+)
+
+var _ = Describe("DeleteSnapshotAction", func() {
+	var (
+		settingsService *fakesettings.FakeSettingsService
+		platform        *fakeplatform.FakePlatform
+		action          DeleteSnapshotAction
+	)
+
+	BeforeEach(func() {
+		settingsService = &fakesettings.FakeSettingsService{
+			Settings: boshsettings.Settings{
+				Disks: boshsettings.Disks{
+					Persistent: map[string]string{"vol-123": "/dev/sdf"},
+				},
+			},
+		}
+
+		platform = fakeplatform.NewFakePlatform()
+		action = NewDeleteSnapshot(settingsService, platform)
+	})
+
+	It("is asynchronous", func() {
+		Expect(action.IsAsynchronous()).To(BeTrue())
+	})
+
+	It("is not persistent", func() {
+		Expect(action.IsPersistent()).To(BeFalse())
+	})
+
+	It("deletes snapshot of persistent disk", func() {
+		value, err := action.Run("fake-snapshot")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal("deleted"))
+
+		Expect(platform.DeletePersistentDiskSnapshotVolumeID).To(Equal("vol-123"))
+		Expect(platform.DeletePersistentDiskSnapshotDevicePath).To(Equal("/dev/sdf"))
+		Expect(platform.DeletePersistentDiskSnapshotName).To(Equal("fake-snapshot"))
+	})
+
+	It("returns error when deleting snapshot fails", func() {
+		platform.DeletePersistentDiskSnapshotErr = errors.New("fake-delete-err")
+
+		_, err := action.Run("fake-snapshot")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-delete-err"))
+	})
+
+	It("returns error when persistent disk is not attached", func() {
+		settingsService.Settings.Disks.Persistent = nil
+
+		_, err := action.Run("fake-snapshot")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Persistent disk is not attached"))
+	})
+})
@@ -0,0 +1,50 @@
+package action
+
+import (
+	"errors"
+
+	boshboot "bosh/bootstrap"
+	bosherr "bosh/errors"
+)
+
+type GetBootstrapStatusAction struct {
+	journal boshboot.Journal
+}
+
+func NewGetBootstrapStatus(journal boshboot.Journal) (action GetBootstrapStatusAction) {
+	action.journal = journal
+	return
+}
+
+func (a GetBootstrapStatusAction) IsAsynchronous() bool {
+	return false
+}
+
+func (a GetBootstrapStatusAction) IsPersistent() bool {
+	return false
+}
+
+type BootstrapStatusValue struct {
+	Stages []boshboot.StageRecord `json:"stages"`
+}
+
+func (a GetBootstrapStatusAction) Run() (BootstrapStatusValue, error) {
+	records, err := a.journal.Records()
+	if err != nil {
+		return BootstrapStatusValue{}, bosherr.WrapError(err, "Getting bootstrap journal records")
+	}
+
+	if records == nil {
+		records = []boshboot.StageRecord{}
+	}
+
+	return BootstrapStatusValue{Stages: records}, nil
+}
+
+func (a GetBootstrapStatusAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a GetBootstrapStatusAction) Cancel() error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,57 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/agent/action"
+	boshboot "bosh/bootstrap"
+	fakeboot "bosh/bootstrap/fakes"
+)
+
+var _ = Describe("GetBootstrapStatus", func() {
+	var (
+		journal *fakeboot.FakeJournal
+		action  GetBootstrapStatusAction
+	)
+
+	BeforeEach(func() {
+		journal = &fakeboot.FakeJournal{}
+		action = NewGetBootstrapStatus(journal)
+	})
+
+	It("is synchronous", func() {
+		Expect(action.IsAsynchronous()).To(BeFalse())
+	})
+
+	It("is not persistent", func() {
+		Expect(action.IsPersistent()).To(BeFalse())
+	})
+
+	It("returns stage records from bootstrap journal", func() {
+		journal.StageRecords = []boshboot.StageRecord{
+			{Name: "fake-stage-1", State: boshboot.StageStateSkipped},
+			{Name: "fake-stage-2", State: boshboot.StageStateFailed, Error: "fake-err"},
+		}
+
+		status, err := action.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status).To(Equal(BootstrapStatusValue{Stages: journal.StageRecords}))
+	})
+
+	It("returns empty stages when bootstrap has not run", func() {
+		status, err := action.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.Stages).To(Equal([]boshboot.StageRecord{}))
+	})
+
+	It("returns error when journal cannot be read", func() {
+		journal.RecordsErr = errors.New("fake-records-err")
+
+		_, err := action.Run()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-records-err"))
+	})
+})
@@ -45,12 +45,13 @@ func (a GetStateAction) IsPersistent() bool {
 type GetStateV1ApplySpec struct {
 	boshas.V1ApplySpec
 
-	AgentID      string             `json:"agent_id"`
-	BoshProtocol string             `json:"bosh_protocol"`
-	JobState     string             `json:"job_state"`
-	Vitals       *boshvitals.Vitals `json:"vitals,omitempty"`
-	VM           boshsettings.VM    `json:"vm"`
-	Ntp          boshntp.NTPInfo    `json:"ntp"`
+	AgentID      string                 `json:"agent_id"`
+	BoshProtocol string                 `json:"bosh_protocol"`
+	JobState     string                 `json:"job_state"`
+	Processes    []boshjobsuper.Process `json:"processes,omitempty"`
+	Vitals       *boshvitals.Vitals     `json:"vitals,omitempty"`
+	VM           boshsettings.VM        `json:"vm"`
+	Ntp          boshntp.NTPInfo        `json:"ntp"`
 }
 
 func (a GetStateAction) Run(filters ...string) (GetStateV1ApplySpec, error) {
@@ -59,11 +60,18 @@ func (a GetStateAction) Run(filters ...string) (GetStateV1ApplySpec, error) {
 		return GetStateV1ApplySpec{}, bosherr.WrapError(err, "Getting current spec")
 	}
 
+	// Does not fail get_state when monit cannot be reached;
+	// job state already reports it as unknown
+	processes, err := a.jobSupervisor.Processes()
+	if err != nil {
+		processes = nil
+	}
+
 	var vitals boshvitals.Vitals
 	var vitalsReference *boshvitals.Vitals
 
 	if len(filters) > 0 && filters[0] == "full" {
-		vitals, err = a.vitalsService.Get()
+		vitals, err = a.vitalsService.GetExtended(processPids(processes))
 		if err != nil {
 			return GetStateV1ApplySpec{}, bosherr.WrapError(err, "Building full vitals")
 		}
@@ -77,6 +85,7 @@ func (a GetStateAction) Run(filters ...string) (GetStateV1ApplySpec, error) {
 		settings.AgentID,
 		"1",
 		a.jobSupervisor.Status(),
+		processes,
 		vitalsReference,
 		settings.VM,
 		a.ntpService.GetInfo(),
@@ -95,6 +104,16 @@ func (a GetStateAction) Run(filters ...string) (GetStateV1ApplySpec, error) {
 	return value, nil
 }
 
+func processPids(processes []boshjobsuper.Process) map[string]int {
+	pids := map[string]int{}
+
+	for _, process := range processes {
+		pids[process.Name] = process.Pid
+	}
+
+	return pids
+}
+
 func (a GetStateAction) Resume() (interface{}, error) {
 	return nil, errors.New("not supported")
 }
@@ -10,6 +10,7 @@ import (
 	boshas "bosh/agent/applier/applyspec"
 	fakeas "bosh/agent/applier/applyspec/fakes"
 	boshassert "bosh/assert"
+	boshjobsuper "bosh/jobsupervisor"
 	fakejobsuper "bosh/jobsupervisor/fakes"
 	boshntp "bosh/platform/ntp"
 	fakentp "bosh/platform/ntp/fakes"
@@ -104,7 +105,7 @@ var _ = Describe("GetState", func() {
 					expectedVitals := boshvitals.Vitals{
 						Load: []string{"foo", "bar", "baz"},
 					}
-					vitalsService.GetVitals = expectedVitals
+					vitalsService.GetExtendedVitals = expectedVitals
 					expectedVM := map[string]interface{}{"name": "vm-abc-def"}
 
 					state, err := action.Run("full")
@@ -117,6 +118,58 @@ var _ = Describe("GetState", func() {
 					boshassert.MatchesJSONMap(GinkgoT(), state.VM, expectedVM)
 				})
 
+				It("includes vitals of job processes", func() {
+					jobSupervisor.ProcessesProcesses = []boshjobsuper.Process{
+						boshjobsuper.Process{Name: "fake-process-1", Pid: 123},
+						boshjobsuper.Process{Name: "fake-process-2"},
+					}
+
+					_, err := action.Run("full")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(vitalsService.GetExtendedProcessPids).To(Equal(map[string]int{
+						"fake-process-1": 123,
+						"fake-process-2": 0,
+					}))
+				})
+
+				It("returns status of each job process", func() {
+					processes := []boshjobsuper.Process{
+						boshjobsuper.Process{
+							Name:      "fake-process",
+							Monitored: true,
+							Status:    "running",
+							Pid:       123,
+							Uptime:    3600,
+							Restarts:  1,
+							Memory:    boshjobsuper.ProcessMemory{Kb: 30720, Percent: 1.5},
+							CPU:       boshjobsuper.ProcessCPU{Total: 2.5},
+						},
+					}
+					jobSupervisor.ProcessesProcesses = processes
+
+					state, err := action.Run()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(state.Processes).To(Equal(processes))
+
+					boshassert.MatchesJSONString(GinkgoT(), state.Processes, `[{"name":"fake-process","monitored":true,"status":"running","pid":123,"uptime":3600,"restarts":1,"mem":{"kb":30720,"percent":1.5},"cpu":{"total":2.5}}]`)
+				})
+
+				It("does not include status of job processes when they cannot be retrieved", func() {
+					jobSupervisor.ProcessesErr = errors.New("fake-processes-err")
+
+					state, err := action.Run()
+					Expect(err).ToNot(HaveOccurred())
+					boshassert.LacksJSONKey(GinkgoT(), state, "processes")
+				})
+
+				It("does not include vitals of job processes when they cannot be retrieved", func() {
+					jobSupervisor.ProcessesErr = errors.New("fake-processes-err")
+
+					_, err := action.Run("full")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(vitalsService.GetExtendedProcessPids).To(Equal(map[string]int{}))
+				})
+
 				Describe("non-populated field formatting", func() {
 					It("returns network as empty hash if not set", func() {
 						specService.Spec = boshas.V1ApplySpec{NetworkSpecs: nil}
@@ -167,7 +220,7 @@ var _ = Describe("GetState", func() {
 
 			Context("when vitals cannot be retrieved", func() {
 				It("returns error", func() {
-					vitalsService.GetErr = errors.New("fake-vitals-get-error")
+					vitalsService.GetExtendedErr = errors.New("fake-vitals-get-error")
 
 					_, err := action.Run("full")
 					Expect(err).To(HaveOccurred())
@@ -31,10 +31,16 @@ func (a GetTaskAction) Run(taskID string) (interface{}, error) {
 	}
 
 	if task.State == boshtask.TaskStateRunning {
-		return boshtask.TaskStateValue{
+		value := boshtask.TaskStateValue{
 			AgentTaskID: task.ID,
 			State:       task.State,
-		}, nil
+		}
+
+		if task.ProgressFunc != nil {
+			value.Progress = task.ProgressFunc()
+		}
+
+		return value, nil
 	}
 
 	if task.Error != nil {
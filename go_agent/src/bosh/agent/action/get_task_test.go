@@ -45,6 +45,20 @@ var _ = Describe("GetTask", func() {
 			`{"agent_task_id":"fake-task-id","state":"running"}`)
 	})
 
+	It("returns progress of a running task", func() {
+		taskService.StartedTasks["fake-task-id"] = boshtask.Task{
+			ID:           "fake-task-id",
+			State:        boshtask.TaskStateRunning,
+			ProgressFunc: func() interface{} { return map[string]int{"copied_bytes": 10} },
+		}
+
+		taskValue, err := action.Run("fake-task-id")
+		Expect(err).ToNot(HaveOccurred())
+
+		boshassert.MatchesJSONString(GinkgoT(), taskValue,
+			`{"agent_task_id":"fake-task-id","state":"running","progress":{"copied_bytes":10}}`)
+	})
+
 	It("returns a failed task", func() {
 		taskService.StartedTasks["fake-task-id"] = boshtask.Task{
 			ID:    "fake-task-id",
@@ -0,0 +1,66 @@
+package action
+
+import (
+	boshas "bosh/agent/applier/applyspec"
+	bosherr "bosh/errors"
+	boshjobsuper "bosh/jobsupervisor"
+)
+
+// resolveJobProcessNames expands job names into names of their processes.
+// Only jobs of current apply spec and their processes can be given.
+func resolveJobProcessNames(
+	specService boshas.V1Service,
+	jobSupervisor boshjobsuper.JobSupervisor,
+	names []string,
+) ([]string, error) {
+	spec, err := specService.Get()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Getting current spec")
+	}
+
+	processNamesByJob := map[string][]string{}
+	jobNamesByProcess := map[string]string{}
+
+	for _, jobTemplateSpec := range spec.JobSpec.JobTemplateSpecs {
+		jobName := jobTemplateSpec.Name
+
+		processNames, err := jobSupervisor.JobProcessNames(jobName)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Getting processes of job %s", jobName)
+		}
+
+		processNamesByJob[jobName] = processNames
+
+		for _, processName := range processNames {
+			jobNamesByProcess[processName] = jobName
+		}
+	}
+
+	var resolvedNames []string
+	seenNames := map[string]bool{}
+
+	addName := func(name string) {
+		if !seenNames[name] {
+			seenNames[name] = true
+			resolvedNames = append(resolvedNames, name)
+		}
+	}
+
+	for _, name := range names {
+		if processNames, found := processNamesByJob[name]; found {
+			for _, processName := range processNames {
+				addName(processName)
+			}
+			continue
+		}
+
+		if _, found := jobNamesByProcess[name]; found {
+			addName(name)
+			continue
+		}
+
+		return nil, bosherr.New("Unknown job or process %s", name)
+	}
+
+	return resolvedNames, nil
+}
@@ -41,9 +41,9 @@ func (a ListDiskAction) Run() (value interface{}, err error) {
 	for volumeID, devicePath := range settings.Disks.Persistent {
 		var isMounted bool
 
-		isMounted, err = a.platform.IsPersistentDiskMounted(devicePath)
+		isMounted, err = a.platform.IsPersistentDiskMounted(volumeID, devicePath)
 		if err != nil {
-			bosherr.WrapError(err, "Checking whether device %s is mounted", devicePath)
+			err = bosherr.WrapError(err, "Checking whether device %s is mounted", devicePath)
 			return
 		}
 
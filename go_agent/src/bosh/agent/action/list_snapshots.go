@@ -0,0 +1,53 @@
+package action
+
+import (
+	"errors"
+
+	bosherr "bosh/errors"
+	boshplatform "bosh/platform"
+	boshsettings "bosh/settings"
+)
+
+type ListSnapshotsAction struct {
+	settingsService boshsettings.Service
+	platform        boshplatform.Platform
+}
+
+func NewListSnapshots(
+	settingsService boshsettings.Service,
+	platform boshplatform.Platform,
+) (action ListSnapshotsAction) {
+	action.settingsService = settingsService
+	action.platform = platform
+	return
+}
+
+func (a ListSnapshotsAction) IsAsynchronous() bool {
+	return false
+}
+
+func (a ListSnapshotsAction) IsPersistent() bool {
+	return false
+}
+
+func (a ListSnapshotsAction) Run() ([]boshplatform.PersistentDiskSnapshot, error) {
+	volumeID, devicePath := a.settingsService.GetSettings().Disks.PersistentDisk()
+	if len(devicePath) == 0 {
+		return nil, bosherr.New("Persistent disk is not attached")
+	}
+
+	snapshots, err := a.platform.ListPersistentDiskSnapshots(volumeID, devicePath)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Listing persistent disk snapshots")
+	}
+
+	return snapshots, nil
+}
+
+func (a ListSnapshotsAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a ListSnapshotsAction) Cancel() error {
+	return errors.New("not supported")
+}
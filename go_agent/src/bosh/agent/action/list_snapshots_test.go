@@ -0,0 +1,69 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/agent/action"
+	boshplatform "bosh/platform"
+	fakeplatform "bosh/platform/fakes"
+	boshsettings "bosh/settings"
+	fakesettings "bosh/settings/fakes"
+)
+
+var _ = Describe("ListSnapshotsAction", func() {
+	var (
+		settingsService *fakesettings.FakeSettingsService
+		platform        *fakeplatform.FakePlatform
+		action          ListSnapshotsAction
+	)
+
+	BeforeEach(func() {
+		settingsService = &fakesettings.FakeSettingsService{
+			Settings: boshsettings.Settings{
+				Disks: boshsettings.Disks{
+					Persistent: map[string]string{"vol-123": "/dev/sdf"},
+				},
+			},
+		}
+
+		platform = fakeplatform.NewFakePlatform()
+		action = NewListSnapshots(settingsService, platform)
+	})
+
+	It("is synchronous", func() {
+		Expect(action.IsAsynchronous()).To(BeFalse())
+	})
+
+	It("is not persistent", func() {
+		Expect(action.IsPersistent()).To(BeFalse())
+	})
+
+	It("returns snapshots of persistent disk", func() {
+		platform.ListPersistentDiskSnapshotsSnapshots = []boshplatform.PersistentDiskSnapshot{{Name: "fake-snapshot"}}
+
+		snapshots, err := action.Run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(snapshots).To(Equal([]boshplatform.PersistentDiskSnapshot{{Name: "fake-snapshot"}}))
+		Expect(platform.ListPersistentDiskSnapshotsVolumeID).To(Equal("vol-123"))
+		Expect(platform.ListPersistentDiskSnapshotsDevicePath).To(Equal("/dev/sdf"))
+	})
+
+	It("returns error when listing snapshots fails", func() {
+		platform.ListPersistentDiskSnapshotsErr = errors.New("fake-list-err")
+
+		_, err := action.Run()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-list-err"))
+	})
+
+	It("returns error when persistent disk is not attached", func() {
+		settingsService.Settings.Disks.Persistent = nil
+
+		_, err := action.Run()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Persistent disk is not attached"))
+	})
+})
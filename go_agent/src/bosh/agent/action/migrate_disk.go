@@ -2,15 +2,30 @@ package action
 
 import (
 	"errors"
+	"sync"
 
 	bosherr "bosh/errors"
 	boshplatform "bosh/platform"
+	boshdiskcopier "bosh/platform/diskcopier"
 	boshdirs "bosh/settings/directories"
 )
 
 type MigrateDiskAction struct {
 	platform    boshplatform.Platform
 	dirProvider boshdirs.DirectoriesProvider
+
+	// Shared by all runs since action is created once;
+	// retried migration starts reporting progress from the beginning
+	progress *boshdiskcopier.Progress
+	run      *migrateDiskRun
+}
+
+// migrateDiskRun holds cancel channel of migration in progress.
+// Each run gets its own channel so that cancelling one migration
+// never aborts the next one.
+type migrateDiskRun struct {
+	lock     sync.Mutex
+	cancelCh chan struct{}
 }
 
 func NewMigrateDisk(
@@ -19,6 +34,8 @@ func NewMigrateDisk(
 ) (action MigrateDiskAction) {
 	action.platform = platform
 	action.dirProvider = dirProvider
+	action.progress = boshdiskcopier.NewProgress()
+	action.run = &migrateDiskRun{}
 	return
 }
 
@@ -31,7 +48,10 @@ func (a MigrateDiskAction) IsPersistent() bool {
 }
 
 func (a MigrateDiskAction) Run() (value interface{}, err error) {
-	err = a.platform.MigratePersistentDisk(a.dirProvider.StoreDir(), a.dirProvider.StoreMigrationDir())
+	cancelCh := a.run.start()
+	defer a.run.finish(cancelCh)
+
+	err = a.platform.MigratePersistentDisk(a.dirProvider.StoreDir(), a.dirProvider.StoreMigrationDir(), a.progress, cancelCh)
 	if err != nil {
 		err = bosherr.WrapError(err, "Migrating persistent disk")
 		return
@@ -41,10 +61,45 @@ func (a MigrateDiskAction) Run() (value interface{}, err error) {
 	return
 }
 
+func (a MigrateDiskAction) Progress() interface{} {
+	return a.progress.Value()
+}
+
 func (a MigrateDiskAction) Resume() (interface{}, error) {
 	return nil, errors.New("not supported")
 }
 
+// Cancel follows the same rules as cancelling errands;
+// migration can be retried later and continues where it stopped
 func (a MigrateDiskAction) Cancel() error {
-	return errors.New("not supported")
+	a.run.cancel()
+	return nil
+}
+
+func (r *migrateDiskRun) start() chan struct{} {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.cancelCh = make(chan struct{})
+	return r.cancelCh
+}
+
+func (r *migrateDiskRun) finish(cancelCh chan struct{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.cancelCh == cancelCh {
+		r.cancelCh = nil
+	}
+}
+
+// cancel does nothing when no migration is in progress
+func (r *migrateDiskRun) cancel() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.cancelCh != nil {
+		close(r.cancelCh)
+		r.cancelCh = nil
+	}
 }
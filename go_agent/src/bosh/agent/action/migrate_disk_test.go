@@ -1,6 +1,8 @@
 package action_test
 
 import (
+	"errors"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -39,5 +41,77 @@ func init() {
 			Expect(platform.MigratePersistentDiskFromMountPoint).To(Equal("/foo/store"))
 			Expect(platform.MigratePersistentDiskToMountPoint).To(Equal("/foo/store_migration_target"))
 		})
+
+		It("migrate disk action run returns error when migration fails", func() {
+			platform, action := buildMigrateDiskAction()
+			platform.MigratePersistentDiskErr = errors.New("fake-migrate-err")
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-migrate-err"))
+		})
+
+		It("migrate disk action reports migration progress", func() {
+			platform, action := buildMigrateDiskAction()
+
+			_, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(action.Progress()).To(Equal(platform.MigratePersistentDiskProgress.Value()))
+			boshassert.MatchesJSONString(GinkgoT(), action.Progress(),
+				`{"phase":"","total_files":0,"copied_files":0,"total_bytes":0,"copied_bytes":0,"verified_bytes":0}`)
+		})
+
+		It("migrate disk action cancel cancels migration in progress", func() {
+			platform, action := buildMigrateDiskAction()
+			platform.MigratePersistentDiskStarted = make(chan struct{})
+			platform.MigratePersistentDiskErr = errors.New("fake-cancelled-err")
+
+			errCh := make(chan error)
+			go func() {
+				_, err := action.Run()
+				errCh <- err
+			}()
+
+			<-platform.MigratePersistentDiskStarted
+
+			err := action.Cancel()
+			Expect(err).ToNot(HaveOccurred())
+
+			// Second cancel does not block or panic
+			err = action.Cancel()
+			Expect(err).ToNot(HaveOccurred())
+
+			err = <-errCh
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-cancelled-err"))
+		})
+
+		It("migrate disk action cancel does not affect next migration", func() {
+			platform, action := buildMigrateDiskAction()
+
+			err := action.Cancel()
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = action.Run()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(platform.MigratePersistentDiskCancelCh).ToNot(Receive())
+		})
+
+		It("migrate disk action cancel after migration finished does not affect next migration", func() {
+			platform, action := buildMigrateDiskAction()
+
+			_, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+
+			err = action.Cancel()
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = action.Run()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(platform.MigratePersistentDiskCancelCh).ToNot(Receive())
+		})
 	})
 }
@@ -4,12 +4,13 @@ import (
 	"errors"
 
 	bosherr "bosh/errors"
+	boshplatform "bosh/platform"
 	boshsettings "bosh/settings"
 	boshdirs "bosh/settings/directories"
 )
 
 type diskMounter interface {
-	MountPersistentDisk(volumeID, mountPoint string) error
+	MountPersistentDisk(volumeID, devicePath, mountPoint string) (boshplatform.PersistentDiskMountResult, error)
 }
 
 type mountPoints interface {
@@ -67,12 +68,13 @@ func (a MountDiskAction) Run(diskCid string) (interface{}, error) {
 		mountPoint = a.dirProvider.StoreMigrationDir()
 	}
 
-	err = a.diskMounter.MountPersistentDisk(devicePath, mountPoint)
+	// Result includes file system check output so that director can show it
+	result, err := a.diskMounter.MountPersistentDisk(diskCid, devicePath, mountPoint)
 	if err != nil {
 		return nil, bosherr.WrapError(err, "Mounting persistent disk")
 	}
 
-	return map[string]string{}, nil
+	return result, nil
 }
 
 func (a MountDiskAction) Resume() (interface{}, error) {
@@ -2,14 +2,28 @@ package action_test
 
 import (
 	"errors"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	. "bosh/agent/action"
+	boshdpresolv "bosh/infrastructure/devicepathresolver"
+	boshlog "bosh/logger"
+	boshplatform "bosh/platform"
+	fakecd "bosh/platform/cdutil/fakes"
+	boshcmd "bosh/platform/commands"
+	boshdisk "bosh/platform/disk"
+	fakedisk "bosh/platform/disk/fakes"
+	fakediskcopier "bosh/platform/diskcopier/fakes"
 	fakeplatform "bosh/platform/fakes"
+	fakedns "bosh/platform/net/dns/fakes"
+	fakenet "bosh/platform/net/fakes"
+	fakestats "bosh/platform/stats/fakes"
+	boshvitals "bosh/platform/vitals"
 	boshdirs "bosh/settings/directories"
 	fakesettings "bosh/settings/fakes"
+	fakesys "bosh/system/fakes"
 )
 
 var _ = Describe("MountDiskAction", func() {
@@ -43,6 +57,13 @@ var _ = Describe("MountDiskAction", func() {
 					}
 				})
 
+				It("passes disk cid along with device path so that disk can be found by volume ID", func() {
+					_, err := action.Run("fake-disk-cid")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(platform.MountPersistentDiskVolumeID).To(Equal("fake-disk-cid"))
+					Expect(platform.MountPersistentDiskDevicePath).To(Equal("fake-device-path"))
+				})
+
 				It("checks if store directory is already mounted", func() {
 					_, err := action.Run("fake-disk-cid")
 					Expect(err).NotTo(HaveOccurred())
@@ -58,11 +79,23 @@ var _ = Describe("MountDiskAction", func() {
 						It("returns without an error after mounting store directory", func() {
 							result, err := action.Run("fake-disk-cid")
 							Expect(err).NotTo(HaveOccurred())
-							Expect(result).To(Equal(map[string]string{}))
+							Expect(result).To(Equal(boshplatform.PersistentDiskMountResult{}))
 
 							Expect(platform.MountPersistentDiskDevicePath).To(Equal("fake-device-path"))
 							Expect(platform.MountPersistentDiskMountPoint).To(Equal("/fake-base-dir/store"))
 						})
+
+						It("returns file system check and resize results", func() {
+							platform.MountPersistentDiskResult = boshplatform.PersistentDiskMountResult{
+								FileSystem: boshdisk.FileSystemExt4,
+								Resized:    true,
+								Check:      &boshdisk.CheckResult{FileSystem: boshdisk.FileSystemExt4, ExitStatus: 1, Repaired: true},
+							}
+
+							result, err := action.Run("fake-disk-cid")
+							Expect(err).NotTo(HaveOccurred())
+							Expect(result).To(Equal(platform.MountPersistentDiskResult))
+						})
 					})
 
 					Context("when mounting fails", func() {
@@ -85,7 +118,7 @@ var _ = Describe("MountDiskAction", func() {
 						It("returns without an error after mounting store migration directory", func() {
 							result, err := action.Run("fake-disk-cid")
 							Expect(err).NotTo(HaveOccurred())
-							Expect(result).To(Equal(map[string]string{}))
+							Expect(result).To(Equal(boshplatform.PersistentDiskMountResult{}))
 
 							Expect(platform.MountPersistentDiskDevicePath).To(Equal("fake-device-path"))
 							Expect(platform.MountPersistentDiskMountPoint).To(Equal("/fake-base-dir/store_migration_target"))
@@ -147,4 +180,79 @@ var _ = Describe("MountDiskAction", func() {
 			})
 		})
 	})
+
+	Context("when linux platform resolves disks with infrastructure device path resolver chain", func() {
+		var (
+			fs          *fakesys.FakeFileSystem
+			diskManager *fakedisk.FakeDiskManager
+		)
+
+		BeforeEach(func() {
+			logger := boshlog.NewLogger(boshlog.LevelNone)
+
+			fs = fakesys.NewFakeFileSystem()
+			cmdRunner := fakesys.NewFakeCmdRunner()
+			diskManager = fakedisk.NewFakeDiskManager()
+			dirProvider := boshdirs.NewDirectoriesProvider("/fake-base-dir")
+			collector := &fakestats.FakeStatsCollector{}
+
+			linuxPlatform := boshplatform.NewLinuxPlatform(
+				fs,
+				cmdRunner,
+				collector,
+				boshcmd.NewTarballCompressor(cmdRunner, fs),
+				boshcmd.NewCpCopier(cmdRunner, fs, logger),
+				&fakediskcopier.FakeCopier{},
+				dirProvider,
+				boshvitals.NewService(collector, dirProvider, nil),
+				fakecd.NewFakeCdUtil(),
+				diskManager,
+				&fakenet.FakeNetManager{},
+				&fakenet.FakeNetworkVerifier{},
+				&fakedns.FakeManager{},
+				time.Millisecond,
+				boshplatform.LinuxOptions{},
+				logger,
+			)
+
+			// Same chain as used for openstack infrastructure
+			linuxPlatform.SetDevicePathResolver(boshdpresolv.NewChainDevicePathResolver(
+				boshdpresolv.NewIDDevicePathResolver(time.Millisecond, cmdRunner, fs),
+				boshdpresolv.NewMappedDevicePathResolver(time.Millisecond, fs),
+			))
+
+			action = NewMountDisk(settingsService, linuxPlatform, linuxPlatform, dirProvider)
+
+			// Device path guessed by director points to another attached disk
+			settingsService.Settings.Disks.Persistent = map[string]string{
+				"aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee": "/dev/sdc",
+			}
+			fs.WriteFileString("/dev/vdc", "")
+			fs.WriteFileString("/dev/vdd", "")
+		})
+
+		It("mounts disk found by disk cid", func() {
+			fs.SetGlob("/dev/disk/by-id/*", []string{
+				"/dev/disk/by-id/virtio-11111111-2222-3333-4",
+				"/dev/disk/by-id/virtio-aaaaaaaa-bbbb-cccc-d",
+			})
+			fs.Symlink("../../vdc", "/dev/disk/by-id/virtio-11111111-2222-3333-4")
+			fs.Symlink("../../vdd", "/dev/disk/by-id/virtio-aaaaaaaa-bbbb-cccc-d")
+
+			_, err := action.Run("aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(diskManager.FakePartitioner.PartitionDevicePath).To(Equal("/dev/vdd"))
+			Expect(diskManager.FakeMounter.MountPartitionPaths).To(Equal([]string{"/dev/vdd1"}))
+			Expect(diskManager.FakeMounter.MountMountPoints).To(Equal([]string{"/fake-base-dir/store"}))
+		})
+
+		It("falls back to device path from settings when disk cannot be found by disk cid", func() {
+			_, err := action.Run("aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(diskManager.FakePartitioner.PartitionDevicePath).To(Equal("/dev/vdc"))
+			Expect(diskManager.FakeMounter.MountPartitionPaths).To(Equal([]string{"/dev/vdc1"}))
+		})
+	})
 })
@@ -0,0 +1,80 @@
+package action
+
+import (
+	"errors"
+
+	boshas "bosh/agent/applier/applyspec"
+	bosherr "bosh/errors"
+	boshjobsuper "bosh/jobsupervisor"
+)
+
+type RestartAction struct {
+	jobSupervisor boshjobsuper.JobSupervisor
+	specService   boshas.V1Service
+}
+
+func NewRestart(jobSupervisor boshjobsuper.JobSupervisor, specService boshas.V1Service) (restart RestartAction) {
+	restart = RestartAction{
+		jobSupervisor: jobSupervisor,
+		specService:   specService,
+	}
+	return
+}
+
+func (a RestartAction) IsAsynchronous() bool {
+	return true
+}
+
+func (a RestartAction) IsPersistent() bool {
+	return false
+}
+
+// Run restarts processes of all jobs unless job or process names are given
+func (a RestartAction) Run(names ...string) (value string, err error) {
+	var processNames []string
+
+	if len(names) > 0 {
+		processNames, err = resolveJobProcessNames(a.specService, a.jobSupervisor, names)
+		if err != nil {
+			err = bosherr.WrapError(err, "Resolving processes to restart")
+			return
+		}
+	} else {
+		processNames, err = a.allProcessNames()
+		if err != nil {
+			return
+		}
+	}
+
+	err = a.jobSupervisor.RestartProcesses(processNames)
+	if err != nil {
+		err = bosherr.WrapError(err, "Restarting processes")
+		return
+	}
+
+	value = "restarted"
+	return
+}
+
+func (a RestartAction) allProcessNames() ([]string, error) {
+	processes, err := a.jobSupervisor.Processes()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Getting job processes")
+	}
+
+	var names []string
+
+	for _, process := range processes {
+		names = append(names, process.Name)
+	}
+
+	return names, nil
+}
+
+func (a RestartAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a RestartAction) Cancel() error {
+	return errors.New("not supported")
+}
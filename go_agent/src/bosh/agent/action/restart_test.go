@@ -0,0 +1,101 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/agent/action"
+	boshas "bosh/agent/applier/applyspec"
+	fakeas "bosh/agent/applier/applyspec/fakes"
+	boshjobsuper "bosh/jobsupervisor"
+	fakejobsuper "bosh/jobsupervisor/fakes"
+)
+
+func init() {
+	Describe("Restart", func() {
+		var (
+			jobSupervisor *fakejobsuper.FakeJobSupervisor
+			specService   *fakeas.FakeV1Service
+			action        RestartAction
+		)
+
+		BeforeEach(func() {
+			jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
+			specService = fakeas.NewFakeV1Service()
+			action = NewRestart(jobSupervisor, specService)
+		})
+
+		It("is asynchronous", func() {
+			Expect(action.IsAsynchronous()).To(BeTrue())
+		})
+
+		It("is not persistent", func() {
+			Expect(action.IsPersistent()).To(BeFalse())
+		})
+
+		It("restarts all job processes", func() {
+			jobSupervisor.ProcessesProcesses = []boshjobsuper.Process{
+				boshjobsuper.Process{Name: "fake-process-1"},
+				boshjobsuper.Process{Name: "fake-process-2"},
+			}
+
+			restarted, err := action.Run()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restarted).To(Equal("restarted"))
+
+			Expect(jobSupervisor.RestartProcessesNames).To(Equal([]string{"fake-process-1", "fake-process-2"}))
+		})
+
+		It("returns error when job processes cannot be retrieved", func() {
+			jobSupervisor.ProcessesErr = errors.New("fake-processes-err")
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-processes-err"))
+		})
+
+		Context("when job or process names are given", func() {
+			BeforeEach(func() {
+				specService.Spec = boshas.V1ApplySpec{
+					JobSpec: boshas.JobSpec{
+						JobTemplateSpecs: []boshas.JobTemplateSpec{
+							boshas.JobTemplateSpec{Name: "fake-job-1"},
+							boshas.JobTemplateSpec{Name: "fake-job-2"},
+						},
+					},
+				}
+
+				jobSupervisor.JobProcessNamesNames = map[string][]string{
+					"fake-job-1": []string{"fake-process-1"},
+					"fake-job-2": []string{"fake-process-2"},
+				}
+			})
+
+			It("restarts only processes of given jobs", func() {
+				restarted, err := action.Run("fake-job-2")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(restarted).To(Equal("restarted"))
+
+				Expect(jobSupervisor.RestartProcessesNames).To(Equal([]string{"fake-process-2"}))
+			})
+
+			It("returns error when name is not a job or process of current apply spec", func() {
+				_, err := action.Run("fake-unknown-job")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Unknown job or process fake-unknown-job"))
+
+				Expect(jobSupervisor.RestartProcessesNames).To(BeNil())
+			})
+		})
+
+		It("returns error when processes fail to restart", func() {
+			jobSupervisor.RestartProcessesErr = errors.New("fake-restart-processes-err")
+
+			_, err := action.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-restart-processes-err"))
+		})
+	})
+}
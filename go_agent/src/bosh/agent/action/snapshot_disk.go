@@ -0,0 +1,125 @@
+package action
+
+import (
+	"errors"
+
+	boshas "bosh/agent/applier/applyspec"
+	models "bosh/agent/applier/models"
+	boshsnapshot "bosh/agent/snapshot"
+	bosherr "bosh/errors"
+	boshlog "bosh/logger"
+	boshplatform "bosh/platform"
+	boshsettings "bosh/settings"
+)
+
+const snapshotDiskActionLogTag = "snapshotDiskAction"
+
+type SnapshotDiskAction struct {
+	settingsService      boshsettings.Service
+	specService          boshas.V1Service
+	snapshotHookProvider boshsnapshot.SnapshotHookProvider
+	platform             boshplatform.Platform
+	logger               boshlog.Logger
+}
+
+func NewSnapshotDisk(
+	settingsService boshsettings.Service,
+	specService boshas.V1Service,
+	snapshotHookProvider boshsnapshot.SnapshotHookProvider,
+	platform boshplatform.Platform,
+	logger boshlog.Logger,
+) (action SnapshotDiskAction) {
+	action.settingsService = settingsService
+	action.specService = specService
+	action.snapshotHookProvider = snapshotHookProvider
+	action.platform = platform
+	action.logger = logger
+	return
+}
+
+func (a SnapshotDiskAction) IsAsynchronous() bool {
+	return true
+}
+
+func (a SnapshotDiskAction) IsPersistent() bool {
+	return false
+}
+
+func (a SnapshotDiskAction) Run(name string) (boshplatform.PersistentDiskSnapshot, error) {
+	var snapshot boshplatform.PersistentDiskSnapshot
+
+	volumeID, devicePath := a.settingsService.GetSettings().Disks.PersistentDisk()
+	if len(devicePath) == 0 {
+		return snapshot, bosherr.New("Persistent disk is not attached")
+	}
+
+	// Jobs are not quiesced for a snapshot that cannot be taken
+	err := a.platform.CheckPersistentDiskSnapshot(volumeID, devicePath, name)
+	if err != nil {
+		return snapshot, bosherr.WrapError(err, "Checking persistent disk snapshot")
+	}
+
+	currentSpec, err := a.specService.Get()
+	if err != nil {
+		return snapshot, bosherr.WrapError(err, "Getting current spec")
+	}
+
+	jobs := currentSpec.Jobs()
+
+	for i, job := range jobs {
+		err = a.runHook(a.snapshotHookProvider.NewPreSnapshotHook(job.Name), name)
+		if err != nil {
+			// Jobs that were already quiesced are resumed without taking the snapshot
+			a.runPostSnapshotHooks(jobs[:i], name)
+			return snapshot, bosherr.WrapError(err, "Running pre-snapshot hook of %s", job.Name)
+		}
+	}
+
+	snapshot, err = a.platform.SnapshotPersistentDisk(volumeID, devicePath, name)
+
+	postErr := a.runPostSnapshotHooks(jobs, name)
+
+	if err != nil {
+		return snapshot, bosherr.WrapError(err, "Snapshotting persistent disk")
+	}
+
+	if postErr != nil {
+		return snapshot, postErr
+	}
+
+	return snapshot, nil
+}
+
+// runPostSnapshotHooks runs all hooks even if some of them fail so that every job is resumed
+func (a SnapshotDiskAction) runPostSnapshotHooks(jobs []models.Job, name string) error {
+	var firstErr error
+
+	for _, job := range jobs {
+		err := a.runHook(a.snapshotHookProvider.NewPostSnapshotHook(job.Name), name)
+		if err != nil {
+			a.logger.Error(snapshotDiskActionLogTag, "Running post-snapshot hook of %s: %s", job.Name, err.Error())
+
+			if firstErr == nil {
+				firstErr = bosherr.WrapError(err, "Running post-snapshot hook of %s", job.Name)
+			}
+		}
+	}
+
+	return firstErr
+}
+
+func (a SnapshotDiskAction) runHook(hook boshsnapshot.SnapshotHook, name string) error {
+	if !hook.Exists() {
+		return nil
+	}
+
+	return hook.Run(name)
+}
+
+func (a SnapshotDiskAction) Resume() (interface{}, error) {
+	return nil, errors.New("not supported")
+}
+
+func (a SnapshotDiskAction) Cancel() error {
+	return errors.New("not supported")
+}
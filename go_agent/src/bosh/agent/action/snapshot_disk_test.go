@@ -0,0 +1,150 @@
+package action_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/agent/action"
+	boshas "bosh/agent/applier/applyspec"
+	fakeas "bosh/agent/applier/applyspec/fakes"
+	fakesnapshot "bosh/agent/snapshot/fakes"
+	boshlog "bosh/logger"
+	boshplatform "bosh/platform"
+	fakeplatform "bosh/platform/fakes"
+	boshsettings "bosh/settings"
+	fakesettings "bosh/settings/fakes"
+)
+
+var _ = Describe("SnapshotDiskAction", func() {
+	var (
+		settingsService      *fakesettings.FakeSettingsService
+		specService          *fakeas.FakeV1Service
+		snapshotHookProvider *fakesnapshot.FakeSnapshotHookProvider
+		platform             *fakeplatform.FakePlatform
+		action               SnapshotDiskAction
+	)
+
+	BeforeEach(func() {
+		settingsService = &fakesettings.FakeSettingsService{
+			Settings: boshsettings.Settings{
+				Disks: boshsettings.Disks{
+					Persistent: map[string]string{"vol-123": "/dev/sdf"},
+				},
+			},
+		}
+
+		specService = fakeas.NewFakeV1Service()
+		specService.Spec.JobSpec.JobTemplateSpecs = []boshas.JobTemplateSpec{
+			{Name: "fake-job-1"},
+			{Name: "fake-job-2"},
+		}
+
+		snapshotHookProvider = fakesnapshot.NewFakeSnapshotHookProvider()
+		snapshotHookProvider.Hook("fake-job-1/pre-snapshot").ExistsBool = true
+		snapshotHookProvider.Hook("fake-job-1/post-snapshot").ExistsBool = true
+		snapshotHookProvider.Hook("fake-job-2/post-snapshot").ExistsBool = true
+
+		platform = fakeplatform.NewFakePlatform()
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		action = NewSnapshotDisk(settingsService, specService, snapshotHookProvider, platform, logger)
+	})
+
+	It("is asynchronous", func() {
+		Expect(action.IsAsynchronous()).To(BeTrue())
+	})
+
+	It("is not persistent", func() {
+		Expect(action.IsPersistent()).To(BeFalse())
+	})
+
+	It("runs existing pre-snapshot hooks, snapshots persistent disk and runs post-snapshot hooks", func() {
+		platform.SnapshotPersistentDiskSnapshot = boshplatform.PersistentDiskSnapshot{Name: "fake-snapshot"}
+
+		snapshot, err := action.Run("fake-snapshot")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(snapshot).To(Equal(boshplatform.PersistentDiskSnapshot{Name: "fake-snapshot"}))
+
+		Expect(platform.SnapshotPersistentDiskVolumeID).To(Equal("vol-123"))
+		Expect(platform.SnapshotPersistentDiskDevicePath).To(Equal("/dev/sdf"))
+		Expect(platform.SnapshotPersistentDiskName).To(Equal("fake-snapshot"))
+
+		Expect(snapshotHookProvider.RunPaths).To(Equal([]string{
+			"fake-job-1/pre-snapshot",
+			"fake-job-1/post-snapshot",
+			"fake-job-2/post-snapshot",
+		}))
+		Expect(snapshotHookProvider.Hook("fake-job-1/pre-snapshot").RunSnapshotName).To(Equal("fake-snapshot"))
+	})
+
+	It("resumes already quiesced jobs without taking snapshot when pre-snapshot hook fails", func() {
+		snapshotHookProvider.Hook("fake-job-2/pre-snapshot").ExistsBool = true
+		snapshotHookProvider.Hook("fake-job-2/pre-snapshot").RunErr = errors.New("fake-hook-err")
+
+		_, err := action.Run("fake-snapshot")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Running pre-snapshot hook of fake-job-2"))
+		Expect(err.Error()).To(ContainSubstring("fake-hook-err"))
+
+		Expect(platform.SnapshotPersistentDiskName).To(BeEmpty())
+		Expect(snapshotHookProvider.RunPaths).To(Equal([]string{
+			"fake-job-1/pre-snapshot",
+			"fake-job-2/pre-snapshot",
+			"fake-job-1/post-snapshot",
+		}))
+	})
+
+	It("runs post-snapshot hooks when snapshotting fails", func() {
+		platform.SnapshotPersistentDiskErr = errors.New("fake-snapshot-err")
+
+		_, err := action.Run("fake-snapshot")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-snapshot-err"))
+
+		Expect(snapshotHookProvider.RunPaths).To(ContainElement("fake-job-2/post-snapshot"))
+	})
+
+	It("runs all post-snapshot hooks and returns error when one of them fails", func() {
+		snapshotHookProvider.Hook("fake-job-1/post-snapshot").RunErr = errors.New("fake-hook-err")
+
+		_, err := action.Run("fake-snapshot")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Running post-snapshot hook of fake-job-1"))
+
+		Expect(snapshotHookProvider.RunPaths).To(ContainElement("fake-job-2/post-snapshot"))
+	})
+
+	It("returns error when getting current spec fails", func() {
+		specService.GetErr = errors.New("fake-get-spec-err")
+
+		_, err := action.Run("fake-snapshot")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-get-spec-err"))
+		Expect(platform.SnapshotPersistentDiskName).To(BeEmpty())
+	})
+
+	It("returns error without running hooks when snapshot cannot be taken", func() {
+		platform.CheckPersistentDiskSnapshotErr = errors.New("fake-check-snapshot-err")
+
+		_, err := action.Run("fake-snapshot")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-check-snapshot-err"))
+
+		Expect(platform.CheckPersistentDiskSnapshotVolumeID).To(Equal("vol-123"))
+		Expect(platform.CheckPersistentDiskSnapshotDevicePath).To(Equal("/dev/sdf"))
+		Expect(platform.CheckPersistentDiskSnapshotName).To(Equal("fake-snapshot"))
+
+		Expect(platform.SnapshotPersistentDiskName).To(BeEmpty())
+		Expect(snapshotHookProvider.RunPaths).To(BeEmpty())
+	})
+
+	It("returns error when persistent disk is not attached", func() {
+		settingsService.Settings.Disks.Persistent = nil
+
+		_, err := action.Run("fake-snapshot")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Persistent disk is not attached"))
+		Expect(snapshotHookProvider.RunPaths).To(BeEmpty())
+	})
+})
@@ -46,6 +46,7 @@ type SshResult struct {
 	Command string `json:"command"`
 	Status  string `json:"status"`
 	IP      string `json:"ip,omitempty"`
+	IPv6    string `json:"ipv6,omitempty"`
 }
 
 func (a SshAction) Run(cmd string, params SshParams) (SshResult, error) {
@@ -81,8 +82,9 @@ func (a SshAction) setupSsh(params SshParams) (SshResult, error) {
 
 	settings := a.settingsService.GetSettings()
 
-	defaultIP, found := settings.Networks.DefaultIP()
-	if !found {
+	defaultIP, foundIP := settings.Networks.DefaultIP()
+	defaultIPv6, foundIPv6 := settings.Networks.DefaultIPv6()
+	if !foundIP && !foundIPv6 {
 		return result, errors.New("No default ip could be found")
 	}
 
@@ -90,6 +92,7 @@ func (a SshAction) setupSsh(params SshParams) (SshResult, error) {
 		Command: "setup",
 		Status:  "success",
 		IP:      defaultIP,
+		IPv6:    defaultIPv6,
 	}
 
 	return result, nil
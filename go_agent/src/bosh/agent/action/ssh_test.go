@@ -93,6 +93,41 @@ var _ = Describe("SshAction", func() {
 			testSshSetupWithGivenPassword("")
 		})
 
+		It("ssh setup includes default IPv6 address", func() {
+			settingsService := &fakesettings.FakeSettingsService{}
+			settingsService.Settings.Networks = boshsettings.Networks{
+				"fake-net": boshsettings.Network{IP: "ww.xx.yy.zz", IPv6: "2001:db8::10"},
+			}
+
+			_, action := buildSshAction(settingsService)
+
+			response, err := action.Run("setup", SshParams{User: "fake-user"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(SshResult{
+				Command: "setup",
+				Status:  "success",
+				IP:      "ww.xx.yy.zz",
+				IPv6:    "2001:db8::10",
+			}))
+		})
+
+		It("ssh setup on IPv6 only network", func() {
+			settingsService := &fakesettings.FakeSettingsService{}
+			settingsService.Settings.Networks = boshsettings.Networks{
+				"fake-net": boshsettings.Network{IPv6: "2001:db8::10"},
+			}
+
+			_, action := buildSshAction(settingsService)
+
+			response, err := action.Run("setup", SshParams{User: "fake-user"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response).To(Equal(SshResult{
+				Command: "setup",
+				Status:  "success",
+				IPv6:    "2001:db8::10",
+			}))
+		})
+
 		It("ssh run cleanup deletes ephemeral user", func() {
 			response, err := action.Run("cleanup", SshParams{UserRegex: "^foobar.*"})
 			Expect(err).ToNot(HaveOccurred())
@@ -1,32 +1,112 @@
 package action
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	boshas "bosh/agent/applier/applyspec"
 	bosherr "bosh/errors"
 	boshjobsuper "bosh/jobsupervisor"
 )
 
+const (
+	defaultStartTimeout      = 60 * time.Second
+	defaultStartPollInterval = 1 * time.Second
+)
+
 type StartAction struct {
 	jobSupervisor boshjobsuper.JobSupervisor
+	specService   boshas.V1Service
 }
 
-func NewStart(jobSupervisor boshjobsuper.JobSupervisor) (start StartAction) {
+func NewStart(jobSupervisor boshjobsuper.JobSupervisor, specService boshas.V1Service) (start StartAction) {
 	start = StartAction{
 		jobSupervisor: jobSupervisor,
+		specService:   specService,
 	}
 	return
 }
 
+// StartOptions can be given as an argument of start, e.g. {"wait": true, "timeout": 120}
+type StartOptions struct {
+	// Wait makes start wait for all processes to be running
+	// and report state of each process
+	Wait bool `json:"wait"`
+
+	// Seconds to wait for processes to be running and
+	// seconds between checks; defaults are used when not set
+	Timeout      int `json:"timeout"`
+	PollInterval int `json:"poll_interval"`
+}
+
+// StartArgument is either a job or process name or start options
+type StartArgument struct {
+	Name    string
+	Options *StartOptions
+}
+
+func (a *StartArgument) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '{' {
+		var options StartOptions
+
+		err := json.Unmarshal(data, &options)
+		if err != nil {
+			return bosherr.WrapError(err, "Unmarshalling start options")
+		}
+
+		a.Options = &options
+		return nil
+	}
+
+	return json.Unmarshal(data, &a.Name)
+}
+
+// StartResult is returned when start waits for processes to be running
+type StartResult struct {
+	State     string                      `json:"state"`
+	Processes []boshjobsuper.ProcessState `json:"processes"`
+}
+
+// IsAsynchronous is true since starting processes waits
+// for them to be running which can take longer than a message response timeout
 func (a StartAction) IsAsynchronous() bool {
-	return false
+	return true
 }
 
 func (a StartAction) IsPersistent() bool {
 	return false
 }
 
-func (a StartAction) Run() (value string, err error) {
+// Run starts all jobs unless job or process names are given
+func (a StartAction) Run(args ...StartArgument) (value interface{}, err error) {
+	var names []string
+	var options StartOptions
+
+	for _, arg := range args {
+		if arg.Options != nil {
+			options = *arg.Options
+		} else {
+			names = append(names, arg.Name)
+		}
+	}
+
+	if len(names) > 0 {
+		err = a.startProcesses(names)
+		if err != nil {
+			return
+		}
+
+		value = "started"
+		return
+	}
+
+	if options.Wait {
+		return a.startAndWait(options)
+	}
+
 	err = a.jobSupervisor.Start()
 	if err != nil {
 		err = bosherr.WrapError(err, "Starting Monitored Services")
@@ -37,6 +117,60 @@ func (a StartAction) Run() (value string, err error) {
 	return
 }
 
+// startAndWait fails when some processes are not running before timeout
+// listing state of each such process
+func (a StartAction) startAndWait(options StartOptions) (StartResult, error) {
+	timeout := defaultStartTimeout
+	if options.Timeout > 0 {
+		timeout = time.Duration(options.Timeout) * time.Second
+	}
+
+	pollInterval := defaultStartPollInterval
+	if options.PollInterval > 0 {
+		pollInterval = time.Duration(options.PollInterval) * time.Second
+	}
+
+	states, err := a.jobSupervisor.StartAndWait(pollInterval, timeout)
+	if err != nil {
+		return StartResult{}, bosherr.WrapError(err, "Starting Monitored Services")
+	}
+
+	var notRunning []string
+
+	for _, state := range states {
+		if state.Status == "running" {
+			continue
+		}
+
+		description := fmt.Sprintf("%s (%s)", state.Name, state.Status)
+		if state.Error != "" {
+			description = fmt.Sprintf("%s (%s: %s)", state.Name, state.Status, state.Error)
+		}
+
+		notRunning = append(notRunning, description)
+	}
+
+	if len(notRunning) > 0 {
+		return StartResult{}, bosherr.New("Processes are not running after %s: %s", timeout, strings.Join(notRunning, ", "))
+	}
+
+	return StartResult{State: "started", Processes: states}, nil
+}
+
+func (a StartAction) startProcesses(names []string) error {
+	processNames, err := resolveJobProcessNames(a.specService, a.jobSupervisor, names)
+	if err != nil {
+		return bosherr.WrapError(err, "Resolving processes to start")
+	}
+
+	err = a.jobSupervisor.StartProcesses(processNames)
+	if err != nil {
+		return bosherr.WrapError(err, "Starting processes")
+	}
+
+	return nil
+}
+
 func (a StartAction) Resume() (interface{}, error) {
 	return nil, errors.New("not supported")
 }
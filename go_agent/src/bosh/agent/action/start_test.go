@@ -1,10 +1,17 @@
 package action_test
 
 import (
+	"encoding/json"
+	"errors"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	. "bosh/agent/action"
+	boshas "bosh/agent/applier/applyspec"
+	fakeas "bosh/agent/applier/applyspec/fakes"
+	boshjobsuper "bosh/jobsupervisor"
 	fakejobsuper "bosh/jobsupervisor/fakes"
 )
 
@@ -12,16 +19,18 @@ func init() {
 	Describe("Start", func() {
 		var (
 			jobSupervisor *fakejobsuper.FakeJobSupervisor
+			specService   *fakeas.FakeV1Service
 			action        StartAction
 		)
 
 		BeforeEach(func() {
 			jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
-			action = NewStart(jobSupervisor)
+			specService = fakeas.NewFakeV1Service()
+			action = NewStart(jobSupervisor, specService)
 		})
 
-		It("is synchronous", func() {
-			Expect(action.IsAsynchronous()).To(BeFalse())
+		It("is asynchronous", func() {
+			Expect(action.IsAsynchronous()).To(BeTrue())
 		})
 
 		It("is not persistent", func() {
@@ -39,5 +48,144 @@ func init() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(jobSupervisor.Started).To(BeTrue())
 		})
+
+		It("unmarshals job or process names and start options from arguments", func() {
+			var args []StartArgument
+
+			err := json.Unmarshal([]byte(`["fake-job", {"wait": true, "timeout": 120, "poll_interval": 2}]`), &args)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(args).To(Equal([]StartArgument{
+				StartArgument{Name: "fake-job"},
+				StartArgument{Options: &StartOptions{Wait: true, Timeout: 120, PollInterval: 2}},
+			}))
+		})
+
+		Context("when start is asked to wait for processes to be running", func() {
+			waitArg := StartArgument{Options: &StartOptions{Wait: true}}
+
+			It("returns started with state of each process when all processes are running", func() {
+				states := []boshjobsuper.ProcessState{
+					boshjobsuper.ProcessState{Name: "fake-process-1", Status: "running"},
+					boshjobsuper.ProcessState{Name: "fake-process-2", Status: "running"},
+				}
+				jobSupervisor.StartAndWaitStates = states
+
+				value, err := action.Run(waitArg)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(value).To(Equal(StartResult{State: "started", Processes: states}))
+
+				Expect(jobSupervisor.Started).To(BeFalse())
+			})
+
+			It("returns error listing state of each process that is not running after timeout", func() {
+				jobSupervisor.StartAndWaitStates = []boshjobsuper.ProcessState{
+					boshjobsuper.ProcessState{Name: "fake-process-1", Status: "running"},
+					boshjobsuper.ProcessState{Name: "fake-process-2", Status: "failing", Error: "fake-monit-error"},
+					boshjobsuper.ProcessState{Name: "fake-process-3", Status: "initializing"},
+				}
+
+				_, err := action.Run(waitArg)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Processes are not running after 1m0s: " +
+					"fake-process-2 (failing: fake-monit-error), fake-process-3 (initializing)"))
+			})
+
+			It("waits with default poll interval and timeout", func() {
+				_, err := action.Run(waitArg)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(jobSupervisor.StartAndWaitPollInterval).To(Equal(1 * time.Second))
+				Expect(jobSupervisor.StartAndWaitTimeout).To(Equal(60 * time.Second))
+			})
+
+			It("waits with poll interval and timeout given in start options", func() {
+				_, err := action.Run(StartArgument{Options: &StartOptions{Wait: true, PollInterval: 5, Timeout: 300}})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(jobSupervisor.StartAndWaitPollInterval).To(Equal(5 * time.Second))
+				Expect(jobSupervisor.StartAndWaitTimeout).To(Equal(300 * time.Second))
+			})
+
+			It("returns error when processes cannot be started", func() {
+				jobSupervisor.StartAndWaitErr = errors.New("fake-start-and-wait-err")
+
+				_, err := action.Run(waitArg)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-start-and-wait-err"))
+			})
+		})
+
+		It("does not wait for processes to be running when options do not ask to wait", func() {
+			value, err := action.Run(StartArgument{Options: &StartOptions{Timeout: 300}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal("started"))
+
+			Expect(jobSupervisor.Started).To(BeTrue())
+			Expect(jobSupervisor.StartAndWaitTimeout).To(Equal(time.Duration(0)))
+		})
+
+		Context("when job or process names are given", func() {
+			BeforeEach(func() {
+				specService.Spec = boshas.V1ApplySpec{
+					JobSpec: boshas.JobSpec{
+						JobTemplateSpecs: []boshas.JobTemplateSpec{
+							boshas.JobTemplateSpec{Name: "fake-job-1"},
+							boshas.JobTemplateSpec{Name: "fake-job-2"},
+						},
+					},
+				}
+
+				jobSupervisor.JobProcessNamesNames = map[string][]string{
+					"fake-job-1": []string{"fake-process-1a", "fake-process-1b"},
+					"fake-job-2": []string{"fake-process-2"},
+				}
+			})
+
+			It("starts only processes of given jobs and given processes", func() {
+				started, err := action.Run(StartArgument{Name: "fake-job-1"}, StartArgument{Name: "fake-process-2"}, StartArgument{Name: "fake-process-1a"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(started).To(Equal("started"))
+
+				Expect(jobSupervisor.Started).To(BeFalse())
+				Expect(jobSupervisor.StartProcessesNames).To(Equal([]string{
+					"fake-process-1a",
+					"fake-process-1b",
+					"fake-process-2",
+				}))
+			})
+
+			It("returns error when name is not a job or process of current apply spec", func() {
+				_, err := action.Run(StartArgument{Name: "fake-unknown-job"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Unknown job or process fake-unknown-job"))
+
+				Expect(jobSupervisor.StartProcessesNames).To(BeNil())
+			})
+
+			It("returns error when current apply spec cannot be retrieved", func() {
+				specService.GetErr = errors.New("fake-spec-get-err")
+
+				_, err := action.Run(StartArgument{Name: "fake-job-1"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-spec-get-err"))
+			})
+
+			It("returns error when processes of job cannot be found", func() {
+				jobSupervisor.JobProcessNamesErr = errors.New("fake-job-process-names-err")
+
+				_, err := action.Run(StartArgument{Name: "fake-job-1"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-job-process-names-err"))
+			})
+
+			It("returns error when processes fail to start", func() {
+				jobSupervisor.StartProcessesErr = errors.New("fake-start-processes-err")
+
+				_, err := action.Run(StartArgument{Name: "fake-job-1"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-start-processes-err"))
+			})
+		})
 	})
 }
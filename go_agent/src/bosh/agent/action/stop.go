@@ -3,17 +3,20 @@ package action
 import (
 	"errors"
 
+	boshas "bosh/agent/applier/applyspec"
 	bosherr "bosh/errors"
 	boshjobsuper "bosh/jobsupervisor"
 )
 
 type StopAction struct {
 	jobSupervisor boshjobsuper.JobSupervisor
+	specService   boshas.V1Service
 }
 
-func NewStop(jobSupervisor boshjobsuper.JobSupervisor) (stop StopAction) {
+func NewStop(jobSupervisor boshjobsuper.JobSupervisor, specService boshas.V1Service) (stop StopAction) {
 	stop = StopAction{
 		jobSupervisor: jobSupervisor,
+		specService:   specService,
 	}
 	return
 }
@@ -26,7 +29,18 @@ func (a StopAction) IsPersistent() bool {
 	return false
 }
 
-func (a StopAction) Run() (value string, err error) {
+// Run stops all jobs unless job or process names are given
+func (a StopAction) Run(names ...string) (value string, err error) {
+	if len(names) > 0 {
+		err = a.stopProcesses(names)
+		if err != nil {
+			return
+		}
+
+		value = "stopped"
+		return
+	}
+
 	err = a.jobSupervisor.Stop()
 	if err != nil {
 		err = bosherr.WrapError(err, "Stopping Monitored Services")
@@ -37,6 +51,20 @@ func (a StopAction) Run() (value string, err error) {
 	return
 }
 
+func (a StopAction) stopProcesses(names []string) error {
+	processNames, err := resolveJobProcessNames(a.specService, a.jobSupervisor, names)
+	if err != nil {
+		return bosherr.WrapError(err, "Resolving processes to stop")
+	}
+
+	err = a.jobSupervisor.StopProcesses(processNames)
+	if err != nil {
+		return bosherr.WrapError(err, "Stopping processes")
+	}
+
+	return nil
+}
+
 func (a StopAction) Resume() (interface{}, error) {
 	return nil, errors.New("not supported")
 }
@@ -1,10 +1,14 @@
 package action_test
 
 import (
+	"errors"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	. "bosh/agent/action"
+	boshas "bosh/agent/applier/applyspec"
+	fakeas "bosh/agent/applier/applyspec/fakes"
 	fakejobsuper "bosh/jobsupervisor/fakes"
 )
 
@@ -12,12 +16,14 @@ func init() {
 	Describe("Stop", func() {
 		var (
 			jobSupervisor *fakejobsuper.FakeJobSupervisor
+			specService   *fakeas.FakeV1Service
 			action        StopAction
 		)
 
 		BeforeEach(func() {
 			jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
-			action = NewStop(jobSupervisor)
+			specService = fakeas.NewFakeV1Service()
+			action = NewStop(jobSupervisor, specService)
 		})
 
 		It("is asynchronous", func() {
@@ -39,5 +45,44 @@ func init() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(jobSupervisor.Stopped).To(BeTrue())
 		})
+
+		Context("when job or process names are given", func() {
+			BeforeEach(func() {
+				specService.Spec = boshas.V1ApplySpec{
+					JobSpec: boshas.JobSpec{
+						JobTemplateSpecs: []boshas.JobTemplateSpec{
+							boshas.JobTemplateSpec{Name: "fake-job"},
+						},
+					},
+				}
+
+				jobSupervisor.JobProcessNamesNames = map[string][]string{
+					"fake-job": []string{"fake-process-1", "fake-process-2"},
+				}
+			})
+
+			It("stops only given processes", func() {
+				stopped, err := action.Run("fake-process-2")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stopped).To(Equal("stopped"))
+
+				Expect(jobSupervisor.Stopped).To(BeFalse())
+				Expect(jobSupervisor.StopProcessesNames).To(Equal([]string{"fake-process-2"}))
+			})
+
+			It("returns error when name is not a job or process of current apply spec", func() {
+				_, err := action.Run("fake-unknown-process")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Unknown job or process fake-unknown-process"))
+			})
+
+			It("returns error when processes fail to stop", func() {
+				jobSupervisor.StopProcessesErr = errors.New("fake-stop-processes-err")
+
+				_, err := action.Run("fake-job")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-stop-processes-err"))
+			})
+		})
 	})
 }
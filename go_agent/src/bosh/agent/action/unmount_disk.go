@@ -40,7 +40,7 @@ func (a UnmountDiskAction) Run(volumeID string) (value interface{}, err error) {
 		return
 	}
 
-	didUnmount, err := a.platform.UnmountPersistentDisk(devicePath)
+	didUnmount, err := a.platform.UnmountPersistentDisk(volumeID, devicePath)
 	if err != nil {
 		err = bosherr.WrapError(err, "Unmounting persistent disk")
 		return
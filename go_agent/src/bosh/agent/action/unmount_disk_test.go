@@ -46,6 +46,7 @@ func init() {
 			Expect(err).ToNot(HaveOccurred())
 			boshassert.MatchesJSONString(GinkgoT(), result, `{"message":"Unmounted partition of /dev/sdf"}`)
 
+			Expect(platform.UnmountPersistentDiskVolumeID).To(Equal("vol-123"))
 			Expect(platform.UnmountPersistentDiskDevicePath).To(Equal("/dev/sdf"))
 		})
 
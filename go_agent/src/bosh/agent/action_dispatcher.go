@@ -1,11 +1,14 @@
 package agent
 
 import (
+	"time"
+
 	boshaction "bosh/agent/action"
 	boshtask "bosh/agent/task"
 	bosherr "bosh/errors"
 	boshhandler "bosh/handler"
 	boshlog "bosh/logger"
+	boshmetrics "bosh/metrics"
 )
 
 const actionDispatcherLogTag = "Action Dispatcher"
@@ -21,6 +24,7 @@ type concreteActionDispatcher struct {
 	taskManager   boshtask.Manager
 	actionFactory boshaction.Factory
 	actionRunner  boshaction.Runner
+	metrics       boshmetrics.Registry
 }
 
 func NewActionDispatcher(
@@ -29,6 +33,7 @@ func NewActionDispatcher(
 	taskManager boshtask.Manager,
 	actionFactory boshaction.Factory,
 	actionRunner boshaction.Runner,
+	metrics boshmetrics.Registry,
 ) (dispatcher ActionDispatcher) {
 	return concreteActionDispatcher{
 		logger:        logger,
@@ -36,6 +41,7 @@ func NewActionDispatcher(
 		taskManager:   taskManager,
 		actionFactory: actionFactory,
 		actionRunner:  actionRunner,
+		metrics:       metrics,
 	}
 }
 
@@ -59,15 +65,21 @@ func (dispatcher concreteActionDispatcher) ResumePreviouslyDispatchedTasks() {
 		}
 
 		taskID := taskInfo.TaskID
+		method := taskInfo.Method
 		payload := taskInfo.Payload
 
 		task := dispatcher.taskService.CreateTaskWithID(
 			taskID,
-			func() (interface{}, error) { return dispatcher.actionRunner.Resume(action, payload) },
+			func() (interface{}, error) {
+				defer dispatcher.observeActionDuration(method, time.Now())
+				return dispatcher.actionRunner.Resume(action, payload)
+			},
 			func(_ boshtask.Task) error { return action.Cancel() },
 			dispatcher.removeTaskInfo,
 		)
 
+		task.ProgressFunc = taskProgressFunc(action)
+
 		dispatcher.taskService.StartTask(task)
 	}
 }
@@ -96,6 +108,7 @@ func (dispatcher concreteActionDispatcher) dispatchAsynchronousAction(
 	var err error
 
 	runTask := func() (interface{}, error) {
+		defer dispatcher.observeActionDuration(req.Method, time.Now())
 		return dispatcher.actionRunner.Run(action, req.GetPayload())
 	}
 
@@ -134,6 +147,8 @@ func (dispatcher concreteActionDispatcher) dispatchAsynchronousAction(
 		}
 	}
 
+	task.ProgressFunc = taskProgressFunc(action)
+
 	dispatcher.taskService.StartTask(task)
 
 	return boshhandler.NewValueResponse(boshtask.TaskStateValue{
@@ -142,13 +157,28 @@ func (dispatcher concreteActionDispatcher) dispatchAsynchronousAction(
 	})
 }
 
+// taskProgressFunc returns nil for actions that do not report progress
+func taskProgressFunc(action boshaction.Action) boshtask.TaskProgressFunc {
+	reporter, ok := action.(boshaction.ProgressReporter)
+	if !ok {
+		return nil
+	}
+
+	return reporter.Progress
+}
+
 func (dispatcher concreteActionDispatcher) dispatchSynchronousAction(
 	action boshaction.Action,
 	req boshhandler.Request,
 ) boshhandler.Response {
 	dispatcher.logger.Info(actionDispatcherLogTag, "Running sync action %s", req.Method)
 
+	startedAt := time.Now()
+
 	value, err := dispatcher.actionRunner.Run(action, req.GetPayload())
+
+	dispatcher.observeActionDuration(req.Method, startedAt)
+
 	if err != nil {
 		err = bosherr.WrapError(err, "Action Failed %s", req.Method)
 		dispatcher.logger.Error(actionDispatcherLogTag, err.Error())
@@ -158,6 +188,11 @@ func (dispatcher concreteActionDispatcher) dispatchSynchronousAction(
 	return boshhandler.NewValueResponse(value)
 }
 
+func (dispatcher concreteActionDispatcher) observeActionDuration(method string, startedAt time.Time) {
+	labels := boshmetrics.Labels{"action": method}
+	dispatcher.metrics.ObserveDuration(boshmetrics.ActionDurationSeconds, labels, time.Since(startedAt))
+}
+
 func (dispatcher concreteActionDispatcher) removeTaskInfo(task boshtask.Task) {
 	err := dispatcher.taskManager.RemoveTaskInfo(task.ID)
 	if err != nil {
@@ -15,6 +15,8 @@ import (
 	boshassert "bosh/assert"
 	boshhandler "bosh/handler"
 	boshlog "bosh/logger"
+	boshmetrics "bosh/metrics"
+	fakemetrics "bosh/metrics/fakes"
 )
 
 func init() {
@@ -25,6 +27,7 @@ func init() {
 			taskManager   *faketask.FakeManager
 			actionFactory *fakeaction.FakeFactory
 			actionRunner  *fakeaction.FakeRunner
+			metrics       *fakemetrics.FakeRegistry
 			dispatcher    ActionDispatcher
 		)
 
@@ -34,7 +37,8 @@ func init() {
 			taskManager = faketask.NewFakeManager()
 			actionFactory = fakeaction.NewFakeFactory()
 			actionRunner = &fakeaction.FakeRunner{}
-			dispatcher = NewActionDispatcher(logger, taskService, taskManager, actionFactory, actionRunner)
+			metrics = fakemetrics.NewFakeRegistry()
+			dispatcher = NewActionDispatcher(logger, taskService, taskManager, actionFactory, actionRunner, metrics)
 		})
 
 		It("responds with exception when the method is unknown", func() {
@@ -70,6 +74,13 @@ func init() {
 				expectedJSON := fmt.Sprintf("{\"exception\":{\"message\":\"Action Failed %s: fake-run-error\"}}", req.Method)
 				boshassert.MatchesJSONString(GinkgoT(), resp, expectedJSON)
 			})
+
+			It("records action duration", func() {
+				dispatcher.Dispatch(req)
+
+				key := fakemetrics.FakeRegistryKey(boshmetrics.ActionDurationSeconds, boshmetrics.Labels{"action": "fake-action"})
+				Expect(len(metrics.Durations[key])).To(Equal(1))
+			})
 		})
 
 		Context("when action is asynchronous", func() {
@@ -104,6 +115,22 @@ func init() {
 				})
 			}
 
+			It("does not report progress of task if action does not report progress", func() {
+				dispatcher.Dispatch(req)
+				Expect(taskService.StartedTasks["fake-generated-task-id"].ProgressFunc).To(BeNil())
+			})
+
+			It("reports progress of task if action reports progress", func() {
+				progressAction := &fakeaction.TestProgressAction{
+					TestAction:    fakeaction.TestAction{Asynchronous: true},
+					ProgressValue: "fake-progress",
+				}
+				actionFactory.RegisterAction("fake-progress-action", progressAction)
+
+				dispatcher.Dispatch(boshhandler.NewRequest("fake-reply", "fake-progress-action", []byte("fake-payload")))
+				Expect(taskService.StartedTasks["fake-generated-task-id"].ProgressFunc()).To(Equal("fake-progress"))
+			})
+
 			Context("when action is not persistent", func() {
 				BeforeEach(func() {
 					action.Persistent = false
@@ -141,6 +168,16 @@ func init() {
 					Expect(string(actionRunner.RunPayload)).To(Equal("fake-payload"))
 				})
 
+				It("records action duration when task runs", func() {
+					dispatcher.Dispatch(req)
+
+					key := fakemetrics.FakeRegistryKey(boshmetrics.ActionDurationSeconds, boshmetrics.Labels{"action": "fake-action"})
+					Expect(metrics.Durations[key]).To(BeEmpty())
+
+					taskService.StartedTasks["fake-generated-task-id"].TaskFunc()
+					Expect(len(metrics.Durations[key])).To(Equal(1))
+				})
+
 				It("returns run error to the task", func() {
 					actionRunner.RunErr = errors.New("fake-run-error")
 					dispatcher.Dispatch(req)
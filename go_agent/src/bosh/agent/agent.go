@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"math/rand"
 	"time"
 
 	boshalert "bosh/agent/alert"
@@ -11,19 +12,29 @@ import (
 	boshlog "bosh/logger"
 	boshmbus "bosh/mbus"
 	boshplatform "bosh/platform"
+	boshvitals "bosh/platform/vitals"
+	boshsettings "bosh/settings"
 	boshsyslog "bosh/syslog"
 )
 
+const agentLogTag = "Agent"
+
+// Heartbeat jitter defaults to a tenth of heartbeat interval when it is not configured
+const defaultHeartbeatJitterDivisor = 10
+
 type Agent struct {
-	logger            boshlog.Logger
-	mbusHandler       boshhandler.Handler
-	platform          boshplatform.Platform
-	actionDispatcher  ActionDispatcher
-	heartbeatInterval time.Duration
-	alertSender       AlertSender
-	jobSupervisor     boshjobsuper.JobSupervisor
-	specService       boshas.V1Service
-	syslogServer      boshsyslog.Server
+	logger                 boshlog.Logger
+	mbusHandler            boshhandler.Handler
+	platform               boshplatform.Platform
+	actionDispatcher       ActionDispatcher
+	heartbeatInterval      time.Duration
+	jobStatusCheckInterval time.Duration
+	random                 *rand.Rand
+	alertSender            AlertSender
+	jobSupervisor          boshjobsuper.JobSupervisor
+	specService            boshas.V1Service
+	syslogServer           boshsyslog.Server
+	settingsService        boshsettings.Service
 }
 
 func New(
@@ -35,17 +46,22 @@ func New(
 	jobSupervisor boshjobsuper.JobSupervisor,
 	specService boshas.V1Service,
 	syslogServer boshsyslog.Server,
+	settingsService boshsettings.Service,
 	heartbeatInterval time.Duration,
+	jobStatusCheckInterval time.Duration,
 ) (a Agent) {
 	a.logger = logger
 	a.mbusHandler = mbusHandler
 	a.platform = platform
 	a.actionDispatcher = actionDispatcher
 	a.heartbeatInterval = heartbeatInterval
+	a.jobStatusCheckInterval = jobStatusCheckInterval
+	a.random = rand.New(rand.NewSource(time.Now().UnixNano()))
 	a.alertSender = alertSender
 	a.jobSupervisor = jobSupervisor
 	a.specService = specService
 	a.syslogServer = syslogServer
+	a.settingsService = settingsService
 	return
 }
 
@@ -61,7 +77,7 @@ func (a Agent) Run() error {
 
 	go a.subscribeActionDispatcher(errCh)
 
-	go a.generateHeartbeats(errCh)
+	go a.generateHeartbeats()
 
 	go a.jobSupervisor.MonitorJobFailures(a.handleJobFailure(errCh))
 
@@ -84,41 +100,93 @@ func (a Agent) subscribeActionDispatcher(errCh chan error) {
 	errCh <- err
 }
 
-func (a Agent) generateHeartbeats(errCh chan error) {
+func (a Agent) generateHeartbeats() {
 	defer a.logger.HandlePanic("Agent Generate Heartbeats")
 
 	// Send initial heartbeat
-	a.sendHeartbeat(errCh)
+	jobState := a.jobSupervisor.Status()
+	sent := a.sendHeartbeat(jobState)
 
-	tickChan := time.Tick(a.heartbeatInterval)
+	heartbeatTimer := time.After(a.nextHeartbeatInterval())
+	jobStatusTickChan := time.Tick(a.jobStatusCheckInterval)
 
 	for {
 		select {
-		case <-tickChan:
-			a.sendHeartbeat(errCh)
+		case <-heartbeatTimer:
+			jobState = a.jobSupervisor.Status()
+			sent = a.sendHeartbeat(jobState)
+			heartbeatTimer = time.After(a.nextHeartbeatInterval())
+
+		case <-jobStatusTickChan:
+			// Job state changes are reported without waiting for next periodic heartbeat;
+			// heartbeat that failed to be sent is retried so that job state change is not lost
+			newJobState := a.jobSupervisor.Status()
+			if newJobState != jobState || !sent {
+				jobState = newJobState
+				sent = a.sendHeartbeat(jobState)
+			}
 		}
 	}
 }
 
-func (a Agent) sendHeartbeat(errCh chan error) {
-	heartbeat, err := a.getHeartbeat()
+// nextHeartbeatInterval adds random jitter so that VMs
+// booted at the same time do not send heartbeats in lockstep
+func (a Agent) nextHeartbeatInterval() time.Duration {
+	boshEnv := a.settingsService.GetSettings().Env.Bosh
+
+	interval := a.heartbeatInterval
+	if boshEnv.HeartbeatInterval > 0 {
+		interval = time.Duration(boshEnv.HeartbeatInterval) * time.Second
+	}
+
+	maxJitter := interval / defaultHeartbeatJitterDivisor
+	if boshEnv.HeartbeatJitter > 0 {
+		maxJitter = time.Duration(boshEnv.HeartbeatJitter) * time.Second
+	}
+
+	if maxJitter > 0 {
+		interval += time.Duration(a.random.Int63n(int64(maxJitter)))
+	}
+
+	return interval
+}
+
+// sendHeartbeat returns whether heartbeat was sent;
+// agent keeps on running when it fails since next heartbeat might succeed
+func (a Agent) sendHeartbeat(jobState string) bool {
+	heartbeat, err := a.getHeartbeat(jobState)
 	if err != nil {
-		err = bosherr.WrapError(err, "Building heartbeat")
-		errCh <- err
-		return
+		a.logger.Error(agentLogTag, "Building heartbeat: %s", err.Error())
+		return false
 	}
 
 	err = a.mbusHandler.SendToHealthManager("heartbeat", heartbeat)
 	if err != nil {
-		err = bosherr.WrapError(err, "Sending heartbeat")
-		errCh <- err
+		a.logger.Error(agentLogTag, "Sending heartbeat: %s", err.Error())
+		return false
 	}
+
+	return true
 }
 
-func (a Agent) getHeartbeat() (boshmbus.Heartbeat, error) {
+func (a Agent) getHeartbeat(jobState string) (boshmbus.Heartbeat, error) {
 	vitalsService := a.platform.GetVitalsService()
+	boshEnv := a.settingsService.GetSettings().Env.Bosh
+
+	var processes []boshjobsuper.Process
+	var vitals boshvitals.Vitals
+	var err error
+
+	if boshEnv.ExtendedVitalsInHeartbeats || boshEnv.ProcessesInHeartbeats {
+		processes = a.processes()
+	}
 
-	vitals, err := vitalsService.Get()
+	// Extended vitals are opt-in to keep heartbeat size stable
+	if boshEnv.ExtendedVitalsInHeartbeats {
+		vitals, err = vitalsService.GetExtended(processPids(processes))
+	} else {
+		vitals, err = vitalsService.Get()
+	}
 	if err != nil {
 		return boshmbus.Heartbeat{}, bosherr.WrapError(err, "Getting job vitals")
 	}
@@ -131,12 +199,39 @@ func (a Agent) getHeartbeat() (boshmbus.Heartbeat, error) {
 	hb := boshmbus.Heartbeat{
 		Job:      spec.JobSpec.Name,
 		Index:    spec.Index,
-		JobState: a.jobSupervisor.Status(),
+		JobState: jobState,
 		Vitals:   vitals,
 	}
+
+	if boshEnv.ProcessesInHeartbeats {
+		hb.Processes = processes
+	}
+
 	return hb, nil
 }
 
+// processes does not fail heartbeat when monit cannot be reached;
+// job state already reports it as unknown
+func (a Agent) processes() []boshjobsuper.Process {
+	processes, err := a.jobSupervisor.Processes()
+	if err != nil {
+		a.logger.Error(agentLogTag, "Getting job processes: %s", err.Error())
+		return nil
+	}
+
+	return processes
+}
+
+func processPids(processes []boshjobsuper.Process) map[string]int {
+	pids := map[string]int{}
+
+	for _, process := range processes {
+		pids[process.Name] = process.Pid
+	}
+
+	return pids
+}
+
 func (a Agent) handleJobFailure(errCh chan error) boshjobsuper.JobFailureHandler {
 	return func(monitAlert boshalert.MonitAlert) error {
 		err := a.alertSender.SendAlert(monitAlert)
@@ -2,6 +2,7 @@ package agent_test
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -14,12 +15,14 @@ import (
 	fakeas "bosh/agent/applier/applyspec/fakes"
 	fakeagent "bosh/agent/fakes"
 	boshhandler "bosh/handler"
+	boshjobsuper "bosh/jobsupervisor"
 	fakejobsuper "bosh/jobsupervisor/fakes"
 	boshlog "bosh/logger"
 	boshmbus "bosh/mbus"
 	fakembus "bosh/mbus/fakes"
 	fakeplatform "bosh/platform/fakes"
 	boshvitals "bosh/platform/vitals"
+	fakesettings "bosh/settings/fakes"
 	boshsyslog "bosh/syslog"
 	fakesyslog "bosh/syslog/fakes"
 )
@@ -36,6 +39,7 @@ func init() {
 			jobSupervisor    *fakejobsuper.FakeJobSupervisor
 			specService      *fakeas.FakeV1Service
 			syslogServer     *fakesyslog.FakeServer
+			settingsService  *fakesettings.FakeSettingsService
 			agent            Agent
 		)
 
@@ -49,6 +53,7 @@ func init() {
 			jobSupervisor = fakejobsuper.NewFakeJobSupervisor()
 			specService = fakeas.NewFakeV1Service()
 			syslogServer = &fakesyslog.FakeServer{}
+			settingsService = &fakesettings.FakeSettingsService{}
 			agent = New(
 				logger,
 				handler,
@@ -58,11 +63,30 @@ func init() {
 				jobSupervisor,
 				specService,
 				syslogServer,
+				settingsService,
+				5*time.Millisecond,
 				5*time.Millisecond,
 			)
 		})
 
 		Describe("Run", func() {
+			// stopAfterHeartbeats makes Run return once given number of heartbeats was attempted
+			stopAfterHeartbeats := func(count int, callback func(int)) {
+				stop := make(chan struct{})
+				handler.RunCallBack = func() { <-stop }
+
+				sentRequests := 0
+				handler.SendToHealthManagerCallBack = func(_ fakembus.HMRequest) {
+					sentRequests++
+					if callback != nil {
+						callback(sentRequests)
+					}
+					if sentRequests == count {
+						close(stop)
+					}
+				}
+			}
+
 			It("lets dispatcher handle requests arriving via handler", func() {
 				err := agent.Run()
 				Expect(err).ToNot(HaveOccurred())
@@ -129,15 +153,16 @@ func init() {
 						jobSupervisor,
 						specService,
 						syslogServer,
+						settingsService,
+						5*time.Hour,
 						5*time.Hour,
 					)
 
 					// Immediately exit after sending initial heartbeat
-					handler.SendToHealthManagerErr = errors.New("stop")
+					stopAfterHeartbeats(1, nil)
 
 					err := agent.Run()
-					Expect(err).To(HaveOccurred())
-					Expect(err.Error()).To(ContainSubstring("stop"))
+					Expect(err).ToNot(HaveOccurred())
 
 					Expect(handler.HMRequests()).To(Equal([]fakembus.HMRequest{
 						fakembus.HMRequest{Topic: "heartbeat", Payload: expectedHb},
@@ -145,24 +170,225 @@ func init() {
 				})
 
 				It("sends periodic heartbeats", func() {
-					sentRequests := 0
-					handler.SendToHealthManagerCallBack = func(_ fakembus.HMRequest) {
-						sentRequests++
-						if sentRequests == 3 {
-							handler.SendToHealthManagerErr = errors.New("stop")
-						}
-					}
+					stopAfterHeartbeats(3, nil)
 
 					err := agent.Run()
-					Expect(err).To(HaveOccurred())
-					Expect(err.Error()).To(ContainSubstring("stop"))
+					Expect(err).ToNot(HaveOccurred())
 
-					Expect(handler.HMRequests()).To(Equal([]fakembus.HMRequest{
+					Expect(handler.HMRequests()[:3]).To(Equal([]fakembus.HMRequest{
 						fakembus.HMRequest{Topic: "heartbeat", Payload: expectedHb},
 						fakembus.HMRequest{Topic: "heartbeat", Payload: expectedHb},
 						fakembus.HMRequest{Topic: "heartbeat", Payload: expectedHb},
 					}))
 				})
+
+				It("sends heartbeat as soon as job state changes", func() {
+					// Configure periodic heartbeat every 5 hours
+					// so that only job state change triggers another heartbeat
+					agent = New(
+						logger,
+						handler,
+						platform,
+						actionDispatcher,
+						alertSender,
+						jobSupervisor,
+						specService,
+						syslogServer,
+						settingsService,
+						5*time.Hour,
+						5*time.Millisecond,
+					)
+
+					stopAfterHeartbeats(2, func(sentRequests int) {
+						if sentRequests == 1 {
+							jobSupervisor.StatusStatus = "failing"
+						}
+					})
+
+					err := agent.Run()
+					Expect(err).ToNot(HaveOccurred())
+
+					requests := handler.HMRequests()
+					Expect(len(requests)).To(Equal(2))
+					Expect(requests[0].Payload.(boshmbus.Heartbeat).JobState).To(Equal("fake-state"))
+					Expect(requests[1].Payload.(boshmbus.Heartbeat).JobState).To(Equal("failing"))
+				})
+
+				It("sends periodic heartbeats at interval configured in settings", func() {
+					settingsService.Settings.Env.Bosh.HeartbeatInterval = 1
+
+					agent = New(
+						logger,
+						handler,
+						platform,
+						actionDispatcher,
+						alertSender,
+						jobSupervisor,
+						specService,
+						syslogServer,
+						settingsService,
+						5*time.Hour,
+						5*time.Hour,
+					)
+
+					stopAfterHeartbeats(2, nil)
+
+					err := agent.Run()
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(handler.HMRequests()[:2]).To(Equal([]fakembus.HMRequest{
+						fakembus.HMRequest{Topic: "heartbeat", Payload: expectedHb},
+						fakembus.HMRequest{Topic: "heartbeat", Payload: expectedHb},
+					}))
+				})
+
+				It("keeps on sending heartbeats after sending heartbeat fails", func() {
+					handler.SendToHealthManagerErr = errors.New("fake-send-err")
+
+					stopAfterHeartbeats(3, func(sentRequests int) {
+						if sentRequests == 2 {
+							handler.SendToHealthManagerErr = nil
+						}
+					})
+
+					err := agent.Run()
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(len(handler.HMRequests())).To(BeNumerically(">=", 3))
+				})
+
+				It("retries sending heartbeat with changed job state on next job status check when sending it fails", func() {
+					// Configure periodic heartbeat every 5 hours
+					// so that only job status checks trigger another heartbeat
+					agent = New(
+						logger,
+						handler,
+						platform,
+						actionDispatcher,
+						alertSender,
+						jobSupervisor,
+						specService,
+						syslogServer,
+						settingsService,
+						5*time.Hour,
+						5*time.Millisecond,
+					)
+
+					stopAfterHeartbeats(3, func(sentRequests int) {
+						switch sentRequests {
+						case 1:
+							jobSupervisor.StatusStatus = "failing"
+						case 2:
+							handler.SendToHealthManagerErr = errors.New("fake-send-err")
+						case 3:
+							handler.SendToHealthManagerErr = nil
+						}
+					})
+
+					err := agent.Run()
+					Expect(err).ToNot(HaveOccurred())
+
+					requests := handler.HMRequests()
+					Expect(len(requests)).To(Equal(3))
+					Expect(requests[1].Payload.(boshmbus.Heartbeat).JobState).To(Equal("failing"))
+					Expect(requests[2].Payload.(boshmbus.Heartbeat).JobState).To(Equal("failing"))
+				})
+			})
+
+			Context("when extended vitals are enabled for heartbeats", func() {
+				BeforeEach(func() {
+					settingsService.Settings.Env.Bosh.ExtendedVitalsInHeartbeats = true
+					handler.KeepOnRunning()
+
+					// Configure periodic heartbeat every 5 hours
+					// so that only initial heartbeat is sent
+					agent = New(
+						logger,
+						handler,
+						platform,
+						actionDispatcher,
+						alertSender,
+						jobSupervisor,
+						specService,
+						syslogServer,
+						settingsService,
+						5*time.Hour,
+						5*time.Hour,
+					)
+
+					jobSupervisor.ProcessesProcesses = []boshjobsuper.Process{
+						boshjobsuper.Process{Name: "fake-process", Pid: 123},
+					}
+
+					platform.FakeVitalsService.GetExtendedVitals = boshvitals.Vitals{
+						Load: []string{"d", "e", "f"},
+					}
+				})
+
+				It("sends heartbeat with extended vitals of job processes", func() {
+					stopAfterHeartbeats(1, nil)
+
+					err := agent.Run()
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(platform.FakeVitalsService.GetExtendedProcessPids).To(Equal(map[string]int{"fake-process": 123}))
+
+					requests := handler.HMRequests()
+					Expect(requests).ToNot(BeEmpty())
+					Expect(requests[0].Payload.(boshmbus.Heartbeat).Vitals.Load).To(Equal([]string{"d", "e", "f"}))
+				})
+			})
+
+			Context("when processes are enabled for heartbeats", func() {
+				BeforeEach(func() {
+					settingsService.Settings.Env.Bosh.ProcessesInHeartbeats = true
+					handler.KeepOnRunning()
+
+					// Configure periodic heartbeat every 5 hours
+					// so that only initial heartbeat is sent
+					agent = New(
+						logger,
+						handler,
+						platform,
+						actionDispatcher,
+						alertSender,
+						jobSupervisor,
+						specService,
+						syslogServer,
+						settingsService,
+						5*time.Hour,
+						5*time.Hour,
+					)
+				})
+
+				It("sends heartbeat with status of each job process", func() {
+					processes := []boshjobsuper.Process{
+						boshjobsuper.Process{Name: "fake-process", Status: "running", Pid: 123},
+					}
+					jobSupervisor.ProcessesProcesses = processes
+
+					stopAfterHeartbeats(1, nil)
+
+					err := agent.Run()
+					Expect(err).ToNot(HaveOccurred())
+
+					requests := handler.HMRequests()
+					Expect(requests).ToNot(BeEmpty())
+					Expect(requests[0].Payload.(boshmbus.Heartbeat).Processes).To(Equal(processes))
+				})
+
+				It("sends heartbeat without processes when they cannot be retrieved", func() {
+					jobSupervisor.ProcessesErr = errors.New("fake-processes-err")
+
+					stopAfterHeartbeats(1, nil)
+
+					err := agent.Run()
+					Expect(err).ToNot(HaveOccurred())
+
+					requests := handler.HMRequests()
+					Expect(requests).ToNot(BeEmpty())
+					Expect(requests[0].Payload.(boshmbus.Heartbeat).Processes).To(BeNil())
+				})
 			})
 
 			Context("when the agent fails to get job spec for a heartbeat", func() {
@@ -171,10 +397,23 @@ func init() {
 					handler.KeepOnRunning()
 				})
 
-				It("returns the error", func() {
-					err := agent.Run()
-					Expect(err).To(HaveOccurred())
-					Expect(err.Error()).To(ContainSubstring("fake-spec-service-error"))
+				It("keeps on running without sending heartbeats", func() {
+					// Agent keeps on running after this test so it must not read shared variables
+					runningAgent := agent
+
+					errCh := make(chan error, 1)
+					go func() { errCh <- runningAgent.Run() }()
+
+					// Wait for several heartbeats to be attempted
+					time.Sleep(50 * time.Millisecond)
+
+					select {
+					case err := <-errCh:
+						Fail(fmt.Sprintf("Expected agent to keep on running but it returned %#v", err))
+					default:
+					}
+
+					Expect(handler.HMRequests()).To(BeEmpty())
 				})
 			})
 
@@ -184,10 +423,23 @@ func init() {
 					handler.KeepOnRunning()
 				})
 
-				It("returns the error", func() {
-					err := agent.Run()
-					Expect(err).To(HaveOccurred())
-					Expect(err.Error()).To(ContainSubstring("fake-vitals-service-error"))
+				It("keeps on running without sending heartbeats", func() {
+					// Agent keeps on running after this test so it must not read shared variables
+					runningAgent := agent
+
+					errCh := make(chan error, 1)
+					go func() { errCh <- runningAgent.Run() }()
+
+					// Wait for several heartbeats to be attempted
+					time.Sleep(50 * time.Millisecond)
+
+					select {
+					case err := <-errCh:
+						Fail(fmt.Sprintf("Expected agent to keep on running but it returned %#v", err))
+					default:
+					}
+
+					Expect(handler.HMRequests()).To(BeEmpty())
 				})
 			})
 
@@ -54,6 +54,15 @@ func (s concreteV1Service) Set(spec V1ApplySpec) error {
 func (s concreteV1Service) PopulateDynamicNetworks(spec V1ApplySpec, settings boshsettings.Settings) (V1ApplySpec, error) {
 	for networkName, networkSpec := range spec.NetworkSpecs {
 		if !networkSpec.IsDynamic() {
+			// IPv6 address assigned via SLAAC or DHCPv6 is only known after resolving it
+			network, ok := settings.Networks[networkName]
+			if ok && network.IsIPv6Auto() && network.IPv6 != "" {
+				spec.NetworkSpecs[networkName] = networkSpec.PopulateIPv6Info(
+					network.IPv6,
+					network.IPv6Prefix,
+					network.IPv6Gateway,
+				)
+			}
 			continue
 		}
 
@@ -62,11 +71,21 @@ func (s concreteV1Service) PopulateDynamicNetworks(spec V1ApplySpec, settings bo
 			return V1ApplySpec{}, bosherr.New("Network %s is not found in settings", networkName)
 		}
 
-		spec.NetworkSpecs[networkName] = networkSpec.PopulateIPInfo(
+		networkSpec = networkSpec.PopulateIPInfo(
 			network.IP,
 			network.Netmask,
 			network.Gateway,
 		)
+
+		if network.IPv6 != "" {
+			networkSpec = networkSpec.PopulateIPv6Info(
+				network.IPv6,
+				network.IPv6Prefix,
+				network.IPv6Gateway,
+			)
+		}
+
+		spec.NetworkSpecs[networkName] = networkSpec
 	}
 
 	return spec, nil
@@ -102,6 +102,45 @@ func init() {
 				})
 			})
 
+			Context("when there are networks with IPv6 assigned via SLAAC", func() {
+				unresolvedSpec := V1ApplySpec{
+					NetworkSpecs: map[string]NetworkSpec{
+						"fake-net": NetworkSpec{
+							Fields: map[string]interface{}{"ip": "fake-net-ip"},
+						},
+					},
+				}
+
+				settings := boshsettings.Settings{
+					Networks: boshsettings.Networks{
+						"fake-net": boshsettings.Network{
+							IP:          "fake-net-ip",
+							IPv6Mode:    boshsettings.IPv6ModeSLAAC,
+							IPv6:        "fake-resolved-ipv6",
+							IPv6Prefix:  64,
+							IPv6Gateway: "fake-resolved-ipv6-gateway",
+						},
+					},
+				}
+
+				It("returns spec with resolved IPv6 information", func() {
+					spec, err := service.PopulateDynamicNetworks(unresolvedSpec, settings)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(spec).To(Equal(V1ApplySpec{
+						NetworkSpecs: map[string]NetworkSpec{
+							"fake-net": NetworkSpec{
+								Fields: map[string]interface{}{
+									"ip":           "fake-net-ip",
+									"ipv6":         "fake-resolved-ipv6",
+									"ipv6_prefix":  64,
+									"ipv6_gateway": "fake-resolved-ipv6-gateway",
+								},
+							},
+						},
+					}))
+				})
+			})
+
 			Context("when there are dynamic networks", func() {
 				unresolvedSpec := V1ApplySpec{
 					Deployment: "fake-deployment",
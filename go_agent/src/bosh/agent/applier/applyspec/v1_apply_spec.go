@@ -90,6 +90,16 @@ func (s NetworkSpec) PopulateIPInfo(ip, netmask, gateway string) NetworkSpec {
 	return s
 }
 
+func (s NetworkSpec) PopulateIPv6Info(ipv6 string, prefix int, gateway string) NetworkSpec {
+	if s.Fields == nil {
+		s.Fields = map[string]interface{}{}
+	}
+	s.Fields["ipv6"] = ipv6
+	s.Fields["ipv6_prefix"] = prefix
+	s.Fields["ipv6_gateway"] = gateway
+	return s
+}
+
 func (s *NetworkSpec) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &s.Fields)
 }
@@ -319,4 +319,23 @@ var _ = Describe("NetworkSpec", func() {
 			}))
 		})
 	})
+
+	Describe("PopulateIPv6Info", func() {
+		It("populates network spec with ipv6, prefix and ipv6 gateway addresses keeping IPv4 info", func() {
+			networkSpec := NetworkSpec{
+				Fields: map[string]interface{}{"ip": "fake-ip"},
+			}
+
+			networkSpec = networkSpec.PopulateIPv6Info("fake-ipv6", 64, "fake-ipv6-gateway")
+
+			Expect(networkSpec).To(Equal(NetworkSpec{
+				Fields: map[string]interface{}{
+					"ip":           "fake-ip",
+					"ipv6":         "fake-ipv6",
+					"ipv6_prefix":  64,
+					"ipv6_gateway": "fake-ipv6-gateway",
+				},
+			}))
+		})
+	})
 })
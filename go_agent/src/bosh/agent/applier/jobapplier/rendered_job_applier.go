@@ -1,7 +1,6 @@
 package jobapplier
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -170,7 +169,7 @@ func (s *renderedJobApplier) Configure(job models.Job, jobIndex int) (err error)
 
 	monitFilePath := filepath.Join(jobDir, "monit")
 	if fs.FileExists(monitFilePath) {
-		err = s.jobSupervisor.AddJob(job.Name, jobIndex, monitFilePath)
+		err = s.jobSupervisor.AddJob(job.Name, jobIndex, "", monitFilePath)
 		if err != nil {
 			err = bosherr.WrapError(err, "Adding monit configuration")
 			return
@@ -185,9 +184,8 @@ func (s *renderedJobApplier) Configure(job models.Job, jobIndex int) (err error)
 
 	for _, monitFilePath := range monitFilePaths {
 		label := strings.Replace(filepath.Base(monitFilePath), ".monit", "", 1)
-		subJobName := fmt.Sprintf("%s_%s", job.Name, label)
 
-		err = s.jobSupervisor.AddJob(subJobName, jobIndex, monitFilePath)
+		err = s.jobSupervisor.AddJob(job.Name, jobIndex, label, monitFilePath)
 		if err != nil {
 			err = bosherr.WrapError(err, "Adding additional monit configuration %s", label)
 			return
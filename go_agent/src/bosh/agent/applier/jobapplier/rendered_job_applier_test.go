@@ -431,8 +431,9 @@ func init() {
 				}))
 
 				Expect(jobSupervisor.AddJobArgs[1]).To(Equal(fakejobsuper.AddJobArgs{
-					Name:       job.Name + "_subjob",
+					Name:       job.Name,
 					Index:      0,
+					Label:      "subjob",
 					ConfigPath: "/path/to/job/subjob.monit",
 				}))
 			})
@@ -0,0 +1,50 @@
+package snapshot
+
+import (
+	bosherr "bosh/errors"
+	boshsys "bosh/system"
+)
+
+type ConcreteSnapshotHook struct {
+	fs       boshsys.FileSystem
+	runner   boshsys.CmdRunner
+	hookPath string
+}
+
+func NewConcreteSnapshotHook(
+	fs boshsys.FileSystem,
+	runner boshsys.CmdRunner,
+	hookPath string,
+) ConcreteSnapshotHook {
+	return ConcreteSnapshotHook{
+		fs:       fs,
+		runner:   runner,
+		hookPath: hookPath,
+	}
+}
+
+func (h ConcreteSnapshotHook) Exists() bool {
+	return h.fs.FileExists(h.hookPath)
+}
+
+func (h ConcreteSnapshotHook) Path() string {
+	return h.hookPath
+}
+
+// Run fails when hook exits with non-zero status
+func (h ConcreteSnapshotHook) Run(snapshotName string) error {
+	command := boshsys.Command{
+		Name: h.hookPath,
+		Env: map[string]string{
+			"PATH":               "/usr/sbin:/usr/bin:/sbin:/bin",
+			"BOSH_SNAPSHOT_NAME": snapshotName,
+		},
+	}
+
+	_, _, _, err := h.runner.RunComplexCommand(command)
+	if err != nil {
+		return bosherr.WrapError(err, "Running snapshot hook %s", h.hookPath)
+	}
+
+	return nil
+}
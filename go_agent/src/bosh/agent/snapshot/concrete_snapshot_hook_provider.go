@@ -0,0 +1,39 @@
+package snapshot
+
+import (
+	"path/filepath"
+
+	boshdirs "bosh/settings/directories"
+	boshsys "bosh/system"
+)
+
+type ConcreteSnapshotHookProvider struct {
+	cmdRunner   boshsys.CmdRunner
+	fs          boshsys.FileSystem
+	dirProvider boshdirs.DirectoriesProvider
+}
+
+func NewConcreteSnapshotHookProvider(
+	cmdRunner boshsys.CmdRunner,
+	fs boshsys.FileSystem,
+	dirProvider boshdirs.DirectoriesProvider,
+) ConcreteSnapshotHookProvider {
+	return ConcreteSnapshotHookProvider{
+		cmdRunner:   cmdRunner,
+		fs:          fs,
+		dirProvider: dirProvider,
+	}
+}
+
+func (p ConcreteSnapshotHookProvider) NewPreSnapshotHook(jobName string) SnapshotHook {
+	return p.newHook(jobName, "pre-snapshot")
+}
+
+func (p ConcreteSnapshotHookProvider) NewPostSnapshotHook(jobName string) SnapshotHook {
+	return p.newHook(jobName, "post-snapshot")
+}
+
+func (p ConcreteSnapshotHookProvider) newHook(jobName, hookName string) SnapshotHook {
+	hookPath := filepath.Join(p.dirProvider.JobsDir(), jobName, "bin", hookName)
+	return NewConcreteSnapshotHook(p.fs, p.cmdRunner, hookPath)
+}
@@ -0,0 +1,31 @@
+package snapshot_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/agent/snapshot"
+	boshdir "bosh/settings/directories"
+	fakesys "bosh/system/fakes"
+)
+
+var _ = Describe("ConcreteSnapshotHookProvider", func() {
+	var provider ConcreteSnapshotHookProvider
+
+	BeforeEach(func() {
+		runner := fakesys.NewFakeCmdRunner()
+		fs := fakesys.NewFakeFileSystem()
+		dirProvider := boshdir.NewDirectoriesProvider("/var/vcap")
+		provider = NewConcreteSnapshotHookProvider(runner, fs, dirProvider)
+	})
+
+	It("returns pre-snapshot hook of the job", func() {
+		hook := provider.NewPreSnapshotHook("foo")
+		Expect(hook.Path()).To(Equal("/var/vcap/jobs/foo/bin/pre-snapshot"))
+	})
+
+	It("returns post-snapshot hook of the job", func() {
+		hook := provider.NewPostSnapshotHook("foo")
+		Expect(hook.Path()).To(Equal("/var/vcap/jobs/foo/bin/post-snapshot"))
+	})
+})
@@ -0,0 +1,62 @@
+package snapshot_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/agent/snapshot"
+	boshsys "bosh/system"
+	fakesys "bosh/system/fakes"
+)
+
+var _ = Describe("ConcreteSnapshotHook", func() {
+	var (
+		runner *fakesys.FakeCmdRunner
+		fs     *fakesys.FakeFileSystem
+		hook   ConcreteSnapshotHook
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		runner = fakesys.NewFakeCmdRunner()
+		hook = NewConcreteSnapshotHook(fs, runner, "/fake/hook")
+	})
+
+	Describe("Exists", func() {
+		It("returns true when hook exists", func() {
+			fs.WriteFileString("/fake/hook", "")
+			Expect(hook.Exists()).To(BeTrue())
+		})
+
+		It("returns false when hook does not exist", func() {
+			Expect(hook.Exists()).To(BeFalse())
+		})
+	})
+
+	Describe("Run", func() {
+		It("runs hook with snapshot name", func() {
+			err := hook.Run("fake-snapshot")
+			Expect(err).ToNot(HaveOccurred())
+
+			expectedCmd := boshsys.Command{
+				Name: "/fake/hook",
+				Env: map[string]string{
+					"PATH":               "/usr/sbin:/usr/bin:/sbin:/bin",
+					"BOSH_SNAPSHOT_NAME": "fake-snapshot",
+				},
+			}
+
+			Expect(runner.RunComplexCommands).To(Equal([]boshsys.Command{expectedCmd}))
+		})
+
+		It("returns error when hook fails", func() {
+			runner.AddCmdResult("/fake/hook", fakesys.FakeCmdResult{ExitStatus: 1, Error: errors.New("fake-hook-err")})
+
+			err := hook.Run("fake-snapshot")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-hook-err"))
+		})
+	})
+})
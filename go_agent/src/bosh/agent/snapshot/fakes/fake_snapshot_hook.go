@@ -0,0 +1,26 @@
+package fakes
+
+type FakeSnapshotHook struct {
+	HookPath   string
+	ExistsBool bool
+
+	// Shared between hooks to record order in which they ran
+	RunPaths *[]string
+
+	RunSnapshotName string
+	RunErr          error
+}
+
+func (h *FakeSnapshotHook) Exists() bool {
+	return h.ExistsBool
+}
+
+func (h *FakeSnapshotHook) Path() string {
+	return h.HookPath
+}
+
+func (h *FakeSnapshotHook) Run(snapshotName string) error {
+	h.RunSnapshotName = snapshotName
+	*h.RunPaths = append(*h.RunPaths, h.HookPath)
+	return h.RunErr
+}
@@ -0,0 +1,34 @@
+package fakes
+
+import (
+	boshsnapshot "bosh/agent/snapshot"
+)
+
+type FakeSnapshotHookProvider struct {
+	// Hooks are created on demand and keyed by their path, e.g. "fake-job/pre-snapshot"
+	Hooks map[string]*FakeSnapshotHook
+
+	RunPaths []string
+}
+
+func NewFakeSnapshotHookProvider() *FakeSnapshotHookProvider {
+	return &FakeSnapshotHookProvider{Hooks: map[string]*FakeSnapshotHook{}}
+}
+
+func (p *FakeSnapshotHookProvider) NewPreSnapshotHook(jobName string) boshsnapshot.SnapshotHook {
+	return p.Hook(jobName + "/pre-snapshot")
+}
+
+func (p *FakeSnapshotHookProvider) NewPostSnapshotHook(jobName string) boshsnapshot.SnapshotHook {
+	return p.Hook(jobName + "/post-snapshot")
+}
+
+func (p *FakeSnapshotHookProvider) Hook(path string) *FakeSnapshotHook {
+	hook, found := p.Hooks[path]
+	if !found {
+		hook = &FakeSnapshotHook{HookPath: path, RunPaths: &p.RunPaths}
+		p.Hooks[path] = hook
+	}
+
+	return hook
+}
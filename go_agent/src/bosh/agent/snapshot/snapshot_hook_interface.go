@@ -0,0 +1,7 @@
+package snapshot
+
+type SnapshotHook interface {
+	Exists() bool
+	Run(snapshotName string) error
+	Path() string
+}
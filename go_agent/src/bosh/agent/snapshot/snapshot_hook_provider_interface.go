@@ -0,0 +1,9 @@
+package snapshot
+
+type SnapshotHookProvider interface {
+	// NewPreSnapshotHook returns hook that quiesces job's data before snapshot is taken
+	NewPreSnapshotHook(jobName string) SnapshotHook
+
+	// NewPostSnapshotHook returns hook that resumes job once snapshot is taken
+	NewPostSnapshotHook(jobName string) SnapshotHook
+}
@@ -85,6 +85,20 @@ func (service asyncTaskService) FindTaskWithID(id string) (Task, bool) {
 	return <-taskChan, <-foundChan
 }
 
+func (service asyncTaskService) CountTasksByState() map[TaskState]int {
+	countsChan := make(chan map[TaskState]int)
+
+	service.taskSem <- func() {
+		counts := map[TaskState]int{}
+		for _, task := range service.currentTasks {
+			counts[task.State]++
+		}
+		countsChan <- counts
+	}
+
+	return <-countsChan
+}
+
 func (service asyncTaskService) processSemFuncs() {
 	defer service.logger.HandlePanic("Task Service Process Sem Funcs")
 
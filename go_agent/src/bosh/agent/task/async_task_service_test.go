@@ -60,6 +60,21 @@ func init() {
 				Expect(task.Error).To(Equal(err))
 			})
 
+			It("counts finished tasks by state", func() {
+				for i, taskErr := range []error{nil, nil, errors.New("fake-error")} {
+					taskErr := taskErr
+					runFunc := func() (interface{}, error) { return nil, taskErr }
+
+					task := service.CreateTaskWithID(fmt.Sprintf("fake-task-id-%d", i), runFunc, nil, nil)
+					startAndWaitForTaskCompletion(task)
+				}
+
+				Expect(service.CountTasksByState()).To(Equal(map[TaskState]int{
+					TaskStateDone:   2,
+					TaskStateFailed: 1,
+				}))
+			})
+
 			Describe("CreateTask", func() {
 				It("can run task created with CreateTask which does not have end func", func() {
 					ranFunc := false
@@ -46,6 +46,14 @@ func (s *FakeService) StartTask(task boshtask.Task) {
 	s.StartedTasks[task.ID] = task
 }
 
+func (s *FakeService) CountTasksByState() map[boshtask.TaskState]int {
+	counts := map[boshtask.TaskState]int{}
+	for _, task := range s.StartedTasks {
+		counts[task.State]++
+	}
+	return counts
+}
+
 func (s *FakeService) FindTaskWithID(id string) (boshtask.Task, bool) {
 	task, found := s.StartedTasks[id]
 	return task, found
@@ -8,4 +8,7 @@ type Service interface {
 	// Records that task to run later
 	StartTask(Task)
 	FindTaskWithID(string) (Task, bool)
+
+	// Counts all recorded tasks including finished ones
+	CountTasksByState() map[TaskState]int
 }
@@ -6,6 +6,8 @@ type TaskCancelFunc func(task Task) error
 
 type TaskEndFunc func(task Task)
 
+type TaskProgressFunc func() interface{}
+
 type TaskState string
 
 const (
@@ -23,6 +25,9 @@ type Task struct {
 	TaskFunc    TaskFunc
 	CancelFunc  TaskCancelFunc
 	TaskEndFunc TaskEndFunc
+
+	// Optional; reports progress of running task
+	ProgressFunc TaskProgressFunc
 }
 
 func (t Task) Cancel() error {
@@ -33,6 +38,7 @@ func (t Task) Cancel() error {
 }
 
 type TaskStateValue struct {
-	AgentTaskID string    `json:"agent_task_id"`
-	State       TaskState `json:"state"`
+	AgentTaskID string      `json:"agent_task_id"`
+	State       TaskState   `json:"state"`
+	Progress    interface{} `json:"progress,omitempty"`
 }
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"net/url"
 	"path/filepath"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	boshpa "bosh/agent/applier/packageapplier"
 	boshcomp "bosh/agent/compiler"
 	boshdrain "bosh/agent/drain"
+	boshsnapshot "bosh/agent/snapshot"
 	boshtask "bosh/agent/task"
 	boshblob "bosh/blobstore"
 	boshboot "bosh/bootstrap"
@@ -23,8 +25,10 @@ import (
 	boshmonit "bosh/jobsupervisor/monit"
 	boshlog "bosh/logger"
 	boshmbus "bosh/mbus"
+	boshmetrics "bosh/metrics"
 	boshnotif "bosh/notification"
 	boshplatform "bosh/platform"
+	boshntp "bosh/platform/ntp"
 	boshsettings "bosh/settings"
 	boshdirs "bosh/settings/directories"
 	boshsyslog "bosh/syslog"
@@ -38,6 +42,9 @@ type app struct {
 	agent          boshagent.Agent
 	platform       boshplatform.Platform
 	infrastructure boshinf.Infrastructure
+
+	// Only set when metrics are enabled in config
+	metricsServer boshmetrics.Server
 }
 
 func New(logger boshlog.Logger) app {
@@ -64,22 +71,27 @@ func (app *app) Setup(args []string) error {
 		return bosherr.WrapError(err, "Getting platform")
 	}
 
-	infProvider := boshinf.NewProvider(app.logger, app.platform)
+	infProvider := boshinf.NewProvider(app.logger, app.platform, config.Infrastructure)
 	app.infrastructure, err = infProvider.Get(opts.InfrastructureName)
-
-	app.platform.SetDevicePathResolver(app.infrastructure.GetDevicePathResolver())
-
 	if err != nil {
 		return bosherr.WrapError(err, "Getting infrastructure")
 	}
 
+	app.platform.SetDevicePathResolver(app.infrastructure.GetDevicePathResolver())
+
 	settingsServiceProvider := boshsettings.NewServiceProvider()
 
+	bootstrapJournal := boshboot.NewFileJournal(
+		app.platform.GetFs(),
+		filepath.Join(dirProvider.BoshDir(), "bootstrap_journal.json"),
+	)
+
 	boot := boshboot.New(
 		app.infrastructure,
 		app.platform,
 		dirProvider,
 		settingsServiceProvider,
+		bootstrapJournal,
 		app.logger,
 	)
 
@@ -88,14 +100,16 @@ func (app *app) Setup(args []string) error {
 		return bosherr.WrapError(err, "Running bootstrap")
 	}
 
-	mbusHandlerProvider := boshmbus.NewHandlerProvider(settingsService, app.logger)
+	metrics := boshmetrics.NewRegistry()
+
+	mbusHandlerProvider := boshmbus.NewHandlerProvider(settingsService, metrics, app.logger)
 
 	mbusHandler, err := mbusHandlerProvider.Get(app.platform, dirProvider)
 	if err != nil {
 		return bosherr.WrapError(err, "Getting mbus handler")
 	}
 
-	blobstoreProvider := boshblob.NewProvider(app.platform, dirProvider, app.logger)
+	blobstoreProvider := boshblob.NewProvider(app.platform, dirProvider, metrics, app.logger)
 
 	blobstore, err := blobstoreProvider.Get(settingsService.GetSettings().Blobstore)
 	if err != nil {
@@ -150,6 +164,12 @@ func (app *app) Setup(args []string) error {
 		dirProvider,
 	)
 
+	snapshotHookProvider := boshsnapshot.NewConcreteSnapshotHookProvider(
+		app.platform.GetRunner(),
+		app.platform.GetFs(),
+		dirProvider,
+	)
+
 	actionFactory := boshaction.NewFactory(
 		settingsService,
 		app.platform,
@@ -161,6 +181,8 @@ func (app *app) Setup(args []string) error {
 		jobSupervisor,
 		specService,
 		drainScriptProvider,
+		snapshotHookProvider,
+		bootstrapJournal,
 		app.logger,
 	)
 
@@ -172,6 +194,7 @@ func (app *app) Setup(args []string) error {
 		taskManager,
 		actionFactory,
 		actionRunner,
+		metrics,
 	)
 
 	alertBuilder := boshalert.NewBuilder(settingsService, app.logger)
@@ -194,13 +217,40 @@ func (app *app) Setup(args []string) error {
 		jobSupervisor,
 		specService,
 		syslogServer,
+		settingsService,
 		time.Minute,
+		10*time.Second,
 	)
 
+	if config.Metrics.Port > 0 {
+		// Metrics served on other than loopback address require the same credentials as mbus
+		mbusURL, err := url.Parse(settingsService.GetSettings().Mbus)
+		if err != nil {
+			return bosherr.WrapError(err, "Parsing mbus URL")
+		}
+
+		app.metricsServer = boshmetrics.NewServer(
+			config.Metrics,
+			[]boshmetrics.Collector{
+				metrics,
+				boshmetrics.NewTaskCollector(taskService),
+				boshmetrics.NewVitalsCollector(app.platform.GetVitalsService()),
+				boshmetrics.NewMonitCollector(monitClient),
+				boshmetrics.NewNTPCollector(boshntp.NewConcreteService(app.platform.GetFs(), dirProvider)),
+			},
+			mbusURL.User,
+			app.logger,
+		)
+	}
+
 	return nil
 }
 
 func (app *app) Run() error {
+	if app.metricsServer != nil {
+		go app.runMetricsServer()
+	}
+
 	err := app.agent.Run()
 	if err != nil {
 		return bosherr.WrapError(err, "Running agent")
@@ -208,6 +258,16 @@ func (app *app) Run() error {
 	return nil
 }
 
+// runMetricsServer does not stop the agent since metrics are not essential
+func (app *app) runMetricsServer() {
+	defer app.logger.HandlePanic("Metrics Server")
+
+	err := app.metricsServer.Start()
+	if err != nil {
+		app.logger.Error("App", "Running metrics server: %s", err.Error())
+	}
+}
+
 func (app *app) GetPlatform() boshplatform.Platform {
 	return app.platform
 }
@@ -4,12 +4,16 @@ import (
 	"encoding/json"
 
 	bosherr "bosh/errors"
+	boshinf "bosh/infrastructure"
+	boshmetrics "bosh/metrics"
 	boshplatform "bosh/platform"
 	boshsys "bosh/system"
 )
 
 type Config struct {
-	Platform boshplatform.ProviderOptions
+	Platform       boshplatform.ProviderOptions
+	Infrastructure boshinf.ProviderOptions
+	Metrics        boshmetrics.Options
 }
 
 func LoadConfigFromPath(fs boshsys.FileSystem, path string) (Config, error) {
@@ -6,6 +6,8 @@ import (
 
 	. "bosh/app"
 
+	boshinf "bosh/infrastructure"
+	boshmetrics "bosh/metrics"
 	boshplatform "bosh/platform"
 	fakesys "bosh/system/fakes"
 )
@@ -25,8 +27,27 @@ var _ = Describe("LoadConfigFromPath", func() {
 				"Linux": {
 					"UseDefaultTmpDir": true,
 					"UsePreformattedPersistentDisk": true,
-					"BindMountPersistentDisk": true
+					"BindMountPersistentDisk": true,
+					"EphemeralDisk": {
+						"SwapSize": "10%",
+						"FileSystem": "xfs",
+						"Partitions": [{"MountPoint": "sys/log", "Size": "2048"}],
+						"AllowRepartition": true
+					}
 				}
+			},
+			"Infrastructure": {
+				"OpenStack": {
+					"MetadataURL": "http://fake-metadata-host",
+					"IDStrategy": "instance-id",
+					"DiskWaitTimeoutMs": 5000,
+					"SettingsSources": ["http"],
+					"SSHKeySource": "config-drive"
+				}
+			},
+			"Metrics": {
+				"Port": 9100,
+				"Interface": "eth0"
 			}
 		}`)
 
@@ -38,8 +59,29 @@ var _ = Describe("LoadConfigFromPath", func() {
 					UseDefaultTmpDir:              true,
 					UsePreformattedPersistentDisk: true,
 					BindMountPersistentDisk:       true,
+					EphemeralDisk: boshplatform.EphemeralDiskOptions{
+						SwapSize:   "10%",
+						FileSystem: "xfs",
+						Partitions: []boshplatform.EphemeralPartitionOptions{
+							{MountPoint: "sys/log", Size: "2048"},
+						},
+						AllowRepartition: true,
+					},
 				},
 			},
+			Infrastructure: boshinf.ProviderOptions{
+				OpenStack: boshinf.Options{
+					MetadataURL:       "http://fake-metadata-host",
+					IDStrategy:        "instance-id",
+					DiskWaitTimeoutMs: 5000,
+					SettingsSources:   []string{"http"},
+					SSHKeySource:      "config-drive",
+				},
+			},
+			Metrics: boshmetrics.Options{
+				Port:      9100,
+				Interface: "eth0",
+			},
 		}))
 	})
 
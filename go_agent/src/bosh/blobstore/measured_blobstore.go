@@ -0,0 +1,61 @@
+package blobstore
+
+import (
+	"os"
+
+	boshmetrics "bosh/metrics"
+)
+
+// measuredBlobstore counts bytes of successfully transferred blobs
+type measuredBlobstore struct {
+	blobstore Blobstore
+	metrics   boshmetrics.Registry
+}
+
+func NewMeasuredBlobstore(blobstore Blobstore, metrics boshmetrics.Registry) Blobstore {
+	return measuredBlobstore{
+		blobstore: blobstore,
+		metrics:   metrics,
+	}
+}
+
+func (b measuredBlobstore) Get(blobID, fingerprint string) (string, error) {
+	fileName, err := b.blobstore.Get(blobID, fingerprint)
+	if err != nil {
+		return "", err
+	}
+
+	b.addTransferredBytes("download", fileName)
+
+	return fileName, nil
+}
+
+func (b measuredBlobstore) CleanUp(fileName string) error {
+	return b.blobstore.CleanUp(fileName)
+}
+
+func (b measuredBlobstore) Create(fileName string) (string, string, error) {
+	blobID, fingerprint, err := b.blobstore.Create(fileName)
+	if err != nil {
+		return "", "", err
+	}
+
+	b.addTransferredBytes("upload", fileName)
+
+	return blobID, fingerprint, nil
+}
+
+func (b measuredBlobstore) Validate() error {
+	return b.blobstore.Validate()
+}
+
+func (b measuredBlobstore) addTransferredBytes(direction, fileName string) {
+	// Missing file only means that transfer is not accounted for
+	fileInfo, err := os.Stat(fileName)
+	if err != nil {
+		return
+	}
+
+	labels := boshmetrics.Labels{"direction": direction}
+	b.metrics.AddToCounter(boshmetrics.BlobstoreTransferredBytesTotal, labels, float64(fileInfo.Size()))
+}
@@ -0,0 +1,93 @@
+package blobstore_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshblob "bosh/blobstore"
+	fakeblob "bosh/blobstore/fakes"
+	boshmetrics "bosh/metrics"
+	fakemetrics "bosh/metrics/fakes"
+)
+
+var _ = Describe("measuredBlobstore", func() {
+	var (
+		innerBlobstore    *fakeblob.FakeBlobstore
+		metrics           *fakemetrics.FakeRegistry
+		measuredBlobstore boshblob.Blobstore
+		filePath          string
+	)
+
+	BeforeEach(func() {
+		innerBlobstore = &fakeblob.FakeBlobstore{}
+		metrics = fakemetrics.NewFakeRegistry()
+		measuredBlobstore = boshblob.NewMeasuredBlobstore(innerBlobstore, metrics)
+
+		file, err := ioutil.TempFile("", "measured-blobstore")
+		Expect(err).ToNot(HaveOccurred())
+
+		defer file.Close()
+
+		_, err = file.Write([]byte("fake-contents"))
+		Expect(err).ToNot(HaveOccurred())
+
+		filePath = file.Name()
+	})
+
+	AfterEach(func() {
+		os.Remove(filePath)
+	})
+
+	downloadedKey := fakemetrics.FakeRegistryKey(boshmetrics.BlobstoreTransferredBytesTotal, boshmetrics.Labels{"direction": "download"})
+	uploadedKey := fakemetrics.FakeRegistryKey(boshmetrics.BlobstoreTransferredBytesTotal, boshmetrics.Labels{"direction": "upload"})
+
+	Describe("Get", func() {
+		It("counts downloaded bytes", func() {
+			innerBlobstore.GetFileName = filePath
+
+			fileName, err := measuredBlobstore.Get("fake-blob-id", "fake-fingerprint")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fileName).To(Equal(filePath))
+
+			Expect(metrics.Counters[downloadedKey]).To(Equal(float64(len("fake-contents"))))
+		})
+
+		It("does not count bytes when inner blobstore fails", func() {
+			innerBlobstore.GetError = errors.New("fake-get-err")
+
+			_, err := measuredBlobstore.Get("fake-blob-id", "fake-fingerprint")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-get-err"))
+
+			Expect(metrics.Counters).To(BeEmpty())
+		})
+	})
+
+	Describe("Create", func() {
+		It("counts uploaded bytes", func() {
+			innerBlobstore.CreateBlobID = "fake-blob-id"
+			innerBlobstore.CreateFingerprint = "fake-fingerprint"
+
+			blobID, fingerprint, err := measuredBlobstore.Create(filePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(blobID).To(Equal("fake-blob-id"))
+			Expect(fingerprint).To(Equal("fake-fingerprint"))
+
+			Expect(metrics.Counters[uploadedKey]).To(Equal(float64(len("fake-contents"))))
+		})
+
+		It("does not count bytes when inner blobstore fails", func() {
+			innerBlobstore.CreateErr = errors.New("fake-create-err")
+
+			_, _, err := measuredBlobstore.Create(filePath)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-create-err"))
+
+			Expect(metrics.Counters).To(BeEmpty())
+		})
+	})
+})
@@ -6,6 +6,7 @@ import (
 
 	bosherr "bosh/errors"
 	boshlog "bosh/logger"
+	boshmetrics "bosh/metrics"
 	boshplatform "bosh/platform"
 	boshsettings "bosh/settings"
 	boshdir "bosh/settings/directories"
@@ -16,17 +17,20 @@ type Provider struct {
 	platform    boshplatform.Platform
 	dirProvider boshdir.DirectoriesProvider
 	uuidGen     boshuuid.Generator
+	metrics     boshmetrics.Registry
 	logger      boshlog.Logger
 }
 
 func NewProvider(
 	platform boshplatform.Platform,
 	dirProvider boshdir.DirectoriesProvider,
+	metrics boshmetrics.Registry,
 	logger boshlog.Logger,
 ) (p Provider) {
 	p.uuidGen = boshuuid.NewGenerator()
 	p.platform = platform
 	p.dirProvider = dirProvider
+	p.metrics = metrics
 	p.logger = logger
 	return
 }
@@ -59,7 +63,9 @@ func (p Provider) Get(settings boshsettings.Blobstore) (blobstore Blobstore, err
 
 	blobstore = NewSHA1VerifiableBlobstore(blobstore)
 
-	blobstore = NewRetryableBlobstore(blobstore, 3, p.logger)
+	blobstore = NewRetryableBlobstore(blobstore, 3, p.metrics, p.logger)
+
+	blobstore = NewMeasuredBlobstore(blobstore, p.metrics)
 
 	err = blobstore.Validate()
 	if err != nil {
@@ -6,6 +6,7 @@ import (
 
 	. "bosh/blobstore"
 	boshlog "bosh/logger"
+	fakemetrics "bosh/metrics/fakes"
 	fakeplatform "bosh/platform/fakes"
 	boshsettings "bosh/settings"
 	boshdir "bosh/settings/directories"
@@ -15,6 +16,7 @@ import (
 var _ = Describe("Provider", func() {
 	var (
 		platform *fakeplatform.FakePlatform
+		metrics  *fakemetrics.FakeRegistry
 		logger   boshlog.Logger
 		provider Provider
 	)
@@ -22,8 +24,9 @@ var _ = Describe("Provider", func() {
 	BeforeEach(func() {
 		platform = fakeplatform.NewFakePlatform()
 		dirProvider := boshdir.NewDirectoriesProvider("/var/vcap")
+		metrics = fakemetrics.NewFakeRegistry()
 		logger = boshlog.NewLogger(boshlog.LevelNone)
-		provider = NewProvider(platform, dirProvider, logger)
+		provider = NewProvider(platform, dirProvider, metrics, logger)
 	})
 
 	Describe("Get", func() {
@@ -55,7 +58,8 @@ var _ = Describe("Provider", func() {
 				"/var/vcap/bosh/etc/blobstore-fake-external-type.json",
 			)
 			expectedBlobstore = NewSHA1VerifiableBlobstore(expectedBlobstore)
-			expectedBlobstore = NewRetryableBlobstore(expectedBlobstore, 3, logger)
+			expectedBlobstore = NewRetryableBlobstore(expectedBlobstore, 3, metrics, logger)
+			expectedBlobstore = NewMeasuredBlobstore(expectedBlobstore, metrics)
 			Expect(blobstore).To(Equal(expectedBlobstore))
 
 			err = expectedBlobstore.Validate()
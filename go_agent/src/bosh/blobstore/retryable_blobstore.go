@@ -3,6 +3,7 @@ package blobstore
 import (
 	bosherr "bosh/errors"
 	boshlog "bosh/logger"
+	boshmetrics "bosh/metrics"
 )
 
 const retryableBlobstoreLogTag = "retryableBlobstore"
@@ -10,13 +11,15 @@ const retryableBlobstoreLogTag = "retryableBlobstore"
 type retryableBlobstore struct {
 	blobstore Blobstore
 	maxTries  int
+	metrics   boshmetrics.Registry
 	logger    boshlog.Logger
 }
 
-func NewRetryableBlobstore(blobstore Blobstore, maxTries int, logger boshlog.Logger) Blobstore {
+func NewRetryableBlobstore(blobstore Blobstore, maxTries int, metrics boshmetrics.Registry, logger boshlog.Logger) Blobstore {
 	return retryableBlobstore{
 		blobstore: blobstore,
 		maxTries:  maxTries,
+		metrics:   metrics,
 		logger:    logger,
 	}
 }
@@ -33,6 +36,10 @@ func (b retryableBlobstore) Get(blobID, fingerprint string) (string, error) {
 
 		b.logger.Info(retryableBlobstoreLogTag,
 			"Failed to get blob with error %s, attempt %d", lastErr.Error(), i)
+
+		if i < b.maxTries-1 {
+			b.metrics.AddToCounter(boshmetrics.BlobstoreRetriesTotal, boshmetrics.Labels{"operation": "get"}, 1)
+		}
 	}
 
 	return "", bosherr.WrapError(lastErr, "Getting blob from inner blobstore")
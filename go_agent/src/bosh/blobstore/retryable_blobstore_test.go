@@ -10,19 +10,23 @@ import (
 	fakeblob "bosh/blobstore/fakes"
 	bosherr "bosh/errors"
 	boshlog "bosh/logger"
+	boshmetrics "bosh/metrics"
+	fakemetrics "bosh/metrics/fakes"
 )
 
 var _ = Describe("retryableBlobstore", func() {
 	var (
 		innerBlobstore     *fakeblob.FakeBlobstore
+		metrics            *fakemetrics.FakeRegistry
 		logger             boshlog.Logger
 		retryableBlobstore boshblob.Blobstore
 	)
 
 	BeforeEach(func() {
 		innerBlobstore = &fakeblob.FakeBlobstore{}
+		metrics = fakemetrics.NewFakeRegistry()
 		logger = boshlog.NewLogger(boshlog.LevelNone)
-		retryableBlobstore = boshblob.NewRetryableBlobstore(innerBlobstore, 3, logger)
+		retryableBlobstore = boshblob.NewRetryableBlobstore(innerBlobstore, 3, metrics, logger)
 	})
 
 	Describe("Get", func() {
@@ -60,6 +64,21 @@ var _ = Describe("retryableBlobstore", func() {
 					[]string{"fake-fingerprint", "fake-fingerprint", "fake-fingerprint"},
 				))
 			})
+
+			It("counts retries", func() {
+				innerBlobstore.GetFileNames = []string{"", "", "fake-last-path"}
+				innerBlobstore.GetErrs = []error{
+					errors.New("fake-get-err-1"),
+					errors.New("fake-get-err-2"),
+					nil,
+				}
+
+				_, err := retryableBlobstore.Get("fake-blob-id", "fake-fingerprint")
+				Expect(err).ToNot(HaveOccurred())
+
+				key := fakemetrics.FakeRegistryKey(boshmetrics.BlobstoreRetriesTotal, boshmetrics.Labels{"operation": "get"})
+				Expect(metrics.Counters[key]).To(Equal(float64(2)))
+			})
 		})
 
 		Context("when inner blobstore does not succeed before maximum number of get tries", func() {
@@ -127,11 +146,11 @@ var _ = Describe("retryableBlobstore", func() {
 
 	Describe("Validate", func() {
 		It("returns error if max tries is < 1", func() {
-			err := boshblob.NewRetryableBlobstore(innerBlobstore, -1, logger).Validate()
+			err := boshblob.NewRetryableBlobstore(innerBlobstore, -1, metrics, logger).Validate()
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("Max tries must be > 0"))
 
-			err = boshblob.NewRetryableBlobstore(innerBlobstore, 0, logger).Validate()
+			err = boshblob.NewRetryableBlobstore(innerBlobstore, 0, metrics, logger).Validate()
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("Max tries must be > 0"))
 		})
@@ -12,12 +12,15 @@ import (
 	boshsys "bosh/system"
 )
 
+const bootstrapLogTag = "bootstrap"
+
 type bootstrap struct {
 	fs                      boshsys.FileSystem
 	infrastructure          boshinf.Infrastructure
 	platform                boshplatform.Platform
 	dirProvider             boshdir.DirectoriesProvider
 	settingsServiceProvider boshsettings.ServiceProvider
+	journal                 Journal
 	logger                  boshlog.Logger
 }
 
@@ -26,6 +29,7 @@ func New(
 	platform boshplatform.Platform,
 	dirProvider boshdir.DirectoriesProvider,
 	settingsServiceProvider boshsettings.ServiceProvider,
+	journal Journal,
 	logger boshlog.Logger,
 ) (b bootstrap) {
 	b.fs = platform.GetFs()
@@ -33,18 +37,24 @@ func New(
 	b.platform = platform
 	b.dirProvider = dirProvider
 	b.settingsServiceProvider = settingsServiceProvider
+	b.journal = journal
 	b.logger = logger
 	return
 }
 
 func (boot bootstrap) Run() (settingsService boshsettings.Service, err error) {
-	err = boot.platform.SetupRuntimeConfiguration()
+	stages := newStageRunner(boot.journal, boot.fs, boot.logger)
+
+	err = stages.Run(stage{name: "runtime_configuration", perBoot: true}, boot.platform.SetupRuntimeConfiguration)
 	if err != nil {
 		err = bosherr.WrapError(err, "Setting up runtime configuration")
 		return
 	}
 
-	err = boot.infrastructure.SetupSsh(boshsettings.VCAPUsername)
+	// Public key is only known once it is fetched
+	err = stages.Run(stage{name: "ssh", alwaysRun: true}, func() error {
+		return boot.infrastructure.SetupSsh(boshsettings.VCAPUsername)
+	})
 	if err != nil {
 		err = bosherr.WrapError(err, "Setting up ssh")
 		return
@@ -58,7 +68,7 @@ func (boot bootstrap) Run() (settingsService boshsettings.Service, err error) {
 		boot.logger,
 	)
 
-	err = settingsService.LoadSettings()
+	err = stages.Run(stage{name: "settings", alwaysRun: true}, settingsService.LoadSettings)
 	if err != nil {
 		err = bosherr.WrapError(err, "Fetching settings")
 		return
@@ -66,25 +76,61 @@ func (boot bootstrap) Run() (settingsService boshsettings.Service, err error) {
 
 	settings := settingsService.GetSettings()
 
-	err = boot.setUserPasswords(settings.Env)
+	err = stages.Run(stage{name: "user_passwords", inputs: settings.Env.GetPassword()}, func() error {
+		return boot.setUserPasswords(settings.Env)
+	})
 	if err != nil {
 		err = bosherr.WrapError(err, "Settings user password")
 		return
 	}
 
-	err = boot.platform.SetupHostname(settings.AgentID)
+	err = stages.Run(stage{name: "hostname", inputs: settings.AgentID}, func() error {
+		return boot.platform.SetupHostname(settings.AgentID)
+	})
 	if err != nil {
 		err = bosherr.WrapError(err, "Setting up hostname")
 		return
 	}
 
-	err = boot.infrastructure.SetupNetworking(settings.Networks)
+	var networkingSetUp bool
+
+	err = stages.Run(stage{name: "networking", inputs: settings.Networks}, func() error {
+		networkingSetUp = true
+		return boot.infrastructure.SetupNetworking(settings.Networks)
+	})
 	if err != nil {
 		err = bosherr.WrapError(err, "Setting up networking")
 		return
 	}
 
-	err = boot.platform.SetTimeWithNtpServers(settings.Ntp)
+	// DNS cache runs inside the agent so it is gone after agent restart or reboot
+	err = stages.Run(stage{name: "dns_cache", alwaysRun: true}, func() error {
+		return boot.platform.StartDNSCache(settings.Networks)
+	})
+	if err != nil {
+		err = bosherr.WrapError(err, "Starting DNS cache")
+		return
+	}
+
+	// Networking that was verified before is not verified again
+	// so that recorded verification failure is kept
+	if boot.needsNetworkingVerification(networkingSetUp) {
+		err = stages.Run(stage{name: "networking_verification", alwaysRun: true}, func() error {
+			return boot.platform.VerifyNetworking(settings.Networks, boot.networkVerificationEndpoints())
+		})
+		if err != nil {
+			// Agent continues to run with restored networking
+			// so that failure can be reported to the director
+			boot.logger.Error(bootstrapLogTag, "Failed to verify networking: %s", err.Error())
+
+			// Desired networking is applied again on the next bootstrap
+			stages.Fail("networking", err)
+		}
+	}
+
+	err = stages.Run(stage{name: "ntp", inputs: settings.Ntp, perBoot: true}, func() error {
+		return boot.platform.SetTimeWithNtpServers(settings.Ntp)
+	})
 	if err != nil {
 		err = bosherr.WrapError(err, "Setting up NTP servers")
 		return
@@ -96,19 +142,21 @@ func (boot bootstrap) Run() (settingsService boshsettings.Service, err error) {
 		return
 	}
 
-	err = boot.platform.SetupEphemeralDiskWithPath(ephemeralDiskPath)
+	err = stages.Run(stage{name: "ephemeral_disk", inputs: ephemeralDiskPath, perBoot: true}, func() error {
+		return boot.platform.SetupEphemeralDiskWithPath(ephemeralDiskPath)
+	})
 	if err != nil {
 		err = bosherr.WrapError(err, "Setting up ephemeral disk")
 		return
 	}
 
-	err = boot.platform.SetupDataDir()
+	err = stages.Run(stage{name: "data_dir", perBoot: true}, boot.platform.SetupDataDir)
 	if err != nil {
 		err = bosherr.WrapError(err, "Setting up data dir")
 		return
 	}
 
-	err = boot.platform.SetupTmpDir()
+	err = stages.Run(stage{name: "tmp_dir", perBoot: true}, boot.platform.SetupTmpDir)
 	if err != nil {
 		err = bosherr.WrapError(err, "Setting up tmp dir")
 		return
@@ -119,21 +167,28 @@ func (boot bootstrap) Run() (settingsService boshsettings.Service, err error) {
 		return
 	}
 
-	for _, devicePath := range settings.Disks.Persistent {
-		err = boot.platform.MountPersistentDisk(devicePath, boot.dirProvider.StoreDir())
-		if err != nil {
-			err = bosherr.WrapError(err, "Mounting persistent disk")
-			return
+	err = stages.Run(stage{name: "persistent_disk", inputs: settings.Disks.Persistent, perBoot: true}, func() error {
+		for volumeID, devicePath := range settings.Disks.Persistent {
+			_, err := boot.platform.MountPersistentDisk(volumeID, devicePath, boot.dirProvider.StoreDir())
+			if err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		err = bosherr.WrapError(err, "Mounting persistent disk")
+		return
 	}
 
-	err = boot.platform.SetupMonitUser()
+	err = stages.Run(stage{name: "monit_user", perBoot: true}, boot.platform.SetupMonitUser)
 	if err != nil {
 		err = bosherr.WrapError(err, "Setting up monit user")
 		return
 	}
 
-	err = boot.platform.StartMonit()
+	// Monit might have been stopped since the last run
+	err = stages.Run(stage{name: "monit", alwaysRun: true}, boot.platform.StartMonit)
 	if err != nil {
 		err = bosherr.WrapError(err, "Starting monit")
 		return
@@ -160,3 +215,31 @@ func (boot bootstrap) setUserPasswords(env boshsettings.Env) error {
 
 	return nil
 }
+
+// needsNetworkingVerification is true when networking was set up in this bootstrap
+// or set up by previous bootstrap that was interrupted before verifying it
+func (boot bootstrap) needsNetworkingVerification(networkingSetUp bool) bool {
+	if networkingSetUp {
+		return true
+	}
+
+	unverified, err := boot.platform.HasUnverifiedNetworking()
+	if err != nil {
+		boot.logger.Error(bootstrapLogTag, "Failed to check for unverified networking: %s", err.Error())
+		return true
+	}
+
+	return unverified
+}
+
+// networkVerificationEndpoints returns endpoints that are only reachable
+// when networking works. Mbus is not included since it may be down
+// independently of VM networking (or agent itself listens on it).
+func (boot bootstrap) networkVerificationEndpoints() []string {
+	registryEndpoint, found := boot.infrastructure.GetRegistryEndpoint()
+	if !found {
+		return []string{}
+	}
+
+	return []string{registryEndpoint}
+}
@@ -0,0 +1,26 @@
+package fakes
+
+import (
+	boshboot "bosh/bootstrap"
+)
+
+type FakeJournal struct {
+	StageRecords []boshboot.StageRecord
+	RecordsErr   error
+
+	SaveRecordsErr error
+}
+
+func (j *FakeJournal) Records() ([]boshboot.StageRecord, error) {
+	return j.StageRecords, j.RecordsErr
+}
+
+func (j *FakeJournal) SaveRecords(records []boshboot.StageRecord) error {
+	if j.SaveRecordsErr != nil {
+		return j.SaveRecordsErr
+	}
+
+	j.StageRecords = append([]boshboot.StageRecord{}, records...)
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"time"
+
+	bosherr "bosh/errors"
+	boshsys "bosh/system"
+)
+
+const (
+	StageStateCompleted = "completed"
+	StageStateSkipped   = "skipped"
+	StageStateFailed    = "failed"
+)
+
+type StageRecord struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+
+	// Digest of inputs stage was last run with; empty for stages that always run
+	InputsDigest string `json:"inputs_digest,omitempty"`
+
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	DurationMs int64     `json:"duration_ms"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// Done is true when stage effects are in place
+func (r StageRecord) Done() bool {
+	return r.State == StageStateCompleted || r.State == StageStateSkipped
+}
+
+type Journal interface {
+	// Records returns records of the last bootstrap in stage order
+	Records() ([]StageRecord, error)
+	SaveRecords(records []StageRecord) error
+}
+
+type fileJournal struct {
+	fs   boshsys.FileSystem
+	path string
+}
+
+func NewFileJournal(fs boshsys.FileSystem, path string) fileJournal {
+	return fileJournal{fs: fs, path: path}
+}
+
+func (j fileJournal) Records() ([]StageRecord, error) {
+	var records []StageRecord
+
+	if !j.fs.FileExists(j.path) {
+		return records, nil
+	}
+
+	bytes, err := j.fs.ReadFile(j.path)
+	if err != nil {
+		return records, bosherr.WrapError(err, "Reading bootstrap journal")
+	}
+
+	err = json.Unmarshal(bytes, &records)
+	if err != nil {
+		return records, bosherr.WrapError(err, "Unmarshalling bootstrap journal")
+	}
+
+	return records, nil
+}
+
+func (j fileJournal) SaveRecords(records []StageRecord) error {
+	bytes, err := json.Marshal(records)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling bootstrap journal")
+	}
+
+	err = j.fs.WriteFile(j.path, bytes)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing bootstrap journal")
+	}
+
+	return nil
+}
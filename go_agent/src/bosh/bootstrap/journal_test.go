@@ -0,0 +1,77 @@
+package bootstrap_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/bootstrap"
+	fakesys "bosh/system/fakes"
+)
+
+var _ = Describe("fileJournal", func() {
+	var (
+		fs      *fakesys.FakeFileSystem
+		journal Journal
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		journal = NewFileJournal(fs, "/var/vcap/bosh/bootstrap_journal.json")
+	})
+
+	It("returns no records when journal does not exist", func() {
+		records, err := journal.Records()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(records).To(BeEmpty())
+	})
+
+	It("returns saved records", func() {
+		startedAt := time.Date(2014, time.June, 1, 10, 0, 0, 0, time.UTC)
+
+		savedRecords := []StageRecord{
+			{
+				Name:         "fake-stage-1",
+				State:        StageStateCompleted,
+				InputsDigest: "fake-digest",
+				StartedAt:    startedAt,
+				FinishedAt:   startedAt.Add(time.Second),
+				DurationMs:   1000,
+			},
+			{
+				Name:  "fake-stage-2",
+				State: StageStateFailed,
+				Error: "fake-err",
+			},
+		}
+
+		err := journal.SaveRecords(savedRecords)
+		Expect(err).ToNot(HaveOccurred())
+
+		records, err := journal.Records()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Name).To(Equal("fake-stage-1"))
+		Expect(records[0].StartedAt.Equal(startedAt)).To(BeTrue())
+		Expect(records[0].DurationMs).To(Equal(int64(1000)))
+		Expect(records[1].Error).To(Equal("fake-err"))
+	})
+
+	It("returns error when journal cannot be parsed", func() {
+		fs.WriteFileString("/var/vcap/bosh/bootstrap_journal.json", "fake-invalid-json")
+
+		_, err := journal.Records()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unmarshalling bootstrap journal"))
+	})
+
+	It("returns error when journal cannot be written", func() {
+		fs.WriteToFileError = errors.New("fake-write-err")
+
+		err := journal.SaveRecords([]StageRecord{{Name: "fake-stage"}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-write-err"))
+	})
+})
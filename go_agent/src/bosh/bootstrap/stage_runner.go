@@ -0,0 +1,166 @@
+package bootstrap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	boshlog "bosh/logger"
+	boshsys "bosh/system"
+)
+
+const bootIDPath = "/proc/sys/kernel/random/boot_id"
+
+type stage struct {
+	name string
+
+	// Stage is skipped when it completed before with the same inputs
+	inputs interface{}
+
+	// Effects of per boot stages (mounts, started processes) do not survive reboot
+	perBoot bool
+
+	alwaysRun bool
+}
+
+type stageRunner struct {
+	journal Journal
+	bootID  string
+	logger  boshlog.Logger
+
+	records []StageRecord
+}
+
+func newStageRunner(journal Journal, fs boshsys.FileSystem, logger boshlog.Logger) *stageRunner {
+	records, err := journal.Records()
+	if err != nil {
+		logger.Error(bootstrapLogTag, "Failed to load bootstrap journal, running all stages: %s", err.Error())
+		records = nil
+	}
+
+	// Without boot id per boot stages cannot be safely skipped
+	bootID, err := fs.ReadFileString(bootIDPath)
+	if err != nil {
+		bootID = ""
+	}
+
+	return &stageRunner{
+		journal: journal,
+		bootID:  strings.TrimSpace(bootID),
+		logger:  logger,
+		records: records,
+	}
+}
+
+func (r *stageRunner) Run(s stage, stageFunc func() error) error {
+	digest, skippable := r.inputsDigest(s)
+
+	record := StageRecord{
+		Name:         s.name,
+		InputsDigest: digest,
+		StartedAt:    time.Now().UTC(),
+	}
+
+	previous, found := r.findRecord(s.name)
+
+	var err error
+
+	if skippable && found && previous.Done() && previous.InputsDigest == digest {
+		r.logger.Info(bootstrapLogTag, "Skipping stage %s since its inputs have not changed", s.name)
+		record.State = StageStateSkipped
+	} else {
+		r.logger.Info(bootstrapLogTag, "Running stage %s", s.name)
+
+		err = stageFunc()
+		if err != nil {
+			record.State = StageStateFailed
+			record.Error = err.Error()
+		} else {
+			record.State = StageStateCompleted
+		}
+	}
+
+	record.FinishedAt = time.Now().UTC()
+	record.DurationMs = int64(record.FinishedAt.Sub(record.StartedAt) / time.Millisecond)
+
+	r.saveRecord(record)
+
+	return err
+}
+
+// Fail records that effects of already run stage were undone
+// so that stage is run again on the next bootstrap
+func (r *stageRunner) Fail(name string, err error) {
+	record, found := r.findRecord(name)
+	if !found {
+		return
+	}
+
+	record.State = StageStateFailed
+	record.Error = err.Error()
+
+	r.saveRecord(record)
+}
+
+func (r *stageRunner) inputsDigest(s stage) (string, bool) {
+	if s.alwaysRun {
+		return "", false
+	}
+
+	inputs := struct {
+		Inputs interface{}
+		BootID string
+	}{Inputs: s.inputs}
+
+	if s.perBoot {
+		if len(r.bootID) == 0 {
+			return "", false
+		}
+
+		inputs.BootID = r.bootID
+	}
+
+	bytes, err := json.Marshal(inputs)
+	if err != nil {
+		r.logger.Error(bootstrapLogTag, "Failed to marshal inputs of stage %s: %s", s.name, err.Error())
+		return "", false
+	}
+
+	sum := sha256.Sum256(bytes)
+
+	return hex.EncodeToString(sum[:]), true
+}
+
+func (r *stageRunner) findRecord(name string) (StageRecord, bool) {
+	for _, record := range r.records {
+		if record.Name == name {
+			return record, true
+		}
+	}
+
+	return StageRecord{}, false
+}
+
+// saveRecord replaces record of the previous run so that
+// records of not yet run stages are kept for the next run
+func (r *stageRunner) saveRecord(record StageRecord) {
+	replaced := false
+
+	for i, existing := range r.records {
+		if existing.Name == record.Name {
+			r.records[i] = record
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		r.records = append(r.records, record)
+	}
+
+	err := r.journal.SaveRecords(r.records)
+	if err != nil {
+		r.logger.Error(bootstrapLogTag, "Failed to save bootstrap journal after stage %s: %s", record.Name, err.Error())
+	}
+}
@@ -55,6 +55,16 @@ func (inf awsInfrastructure) GetSettings() (boshsettings.Settings, error) {
 	return settings, nil
 }
 
+func (inf awsInfrastructure) GetRegistryEndpoint() (string, bool) {
+	registryConfig, err := inf.metadataService.GetRegistryConfig()
+	if err != nil {
+		inf.logger.Error(awsInfrastructureLogTag, "Failed to get registry config: %s", err.Error())
+		return "", false
+	}
+
+	return registryConfig.Endpoint, registryConfig.Endpoint != ""
+}
+
 func (inf awsInfrastructure) SetupNetworking(networks boshsettings.Networks) (err error) {
 	return inf.platform.SetupDhcp(networks)
 }
@@ -85,6 +85,24 @@ func init() {
 			})
 		})
 
+		Describe("GetRegistryEndpoint", func() {
+			It("returns registry endpoint from metadata service", func() {
+				metadataService.RegistryConfig = RegistryConfig{Endpoint: "http://fake-registry.com"}
+
+				endpoint, found := aws.GetRegistryEndpoint()
+				Expect(found).To(BeTrue())
+				Expect(endpoint).To(Equal("http://fake-registry.com"))
+			})
+
+			It("returns not found if registry config cannot be retrieved", func() {
+				metadataService.GetRegistryConfigErr = errors.New("fake-get-registry-config-err")
+
+				endpoint, found := aws.GetRegistryEndpoint()
+				Expect(found).To(BeFalse())
+				Expect(endpoint).To(Equal(""))
+			})
+		})
+
 		Describe("SetupNetworking", func() {
 			It("sets up DHCP on the platform", func() {
 				networks := boshsettings.Networks{"bosh": boshsettings.Network{}}
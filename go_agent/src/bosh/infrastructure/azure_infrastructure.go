@@ -0,0 +1,79 @@
+package infrastructure
+
+import (
+	bosherr "bosh/errors"
+	boshdpresolv "bosh/infrastructure/devicepathresolver"
+	boshlog "bosh/logger"
+	boshplatform "bosh/platform"
+	boshsettings "bosh/settings"
+)
+
+const azureInfrastructureLogTag = "azureInfrastructure"
+
+type azureInfrastructure struct {
+	metadataService    MetadataService
+	registry           Registry
+	platform           boshplatform.Platform
+	devicePathResolver boshdpresolv.DevicePathResolver
+	logger             boshlog.Logger
+}
+
+func NewAzureInfrastructure(
+	metadataService MetadataService,
+	registry Registry,
+	platform boshplatform.Platform,
+	devicePathResolver boshdpresolv.DevicePathResolver,
+	logger boshlog.Logger,
+) (inf azureInfrastructure) {
+	inf.metadataService = metadataService
+	inf.registry = registry
+	inf.platform = platform
+	inf.devicePathResolver = devicePathResolver
+	inf.logger = logger
+	return
+}
+
+func (inf azureInfrastructure) GetDevicePathResolver() boshdpresolv.DevicePathResolver {
+	return inf.devicePathResolver
+}
+
+func (inf azureInfrastructure) SetupSsh(username string) error {
+	publicKey, err := inf.metadataService.GetPublicKey()
+	if err != nil {
+		return bosherr.WrapError(err, "Error getting public key")
+	}
+
+	return inf.platform.SetupSsh(publicKey, username)
+}
+
+func (inf azureInfrastructure) GetSettings() (boshsettings.Settings, error) {
+	settings, err := inf.registry.GetSettings()
+	if err != nil {
+		return settings, bosherr.WrapError(err, "Getting settings from registry")
+	}
+
+	return settings, nil
+}
+
+func (inf azureInfrastructure) GetRegistryEndpoint() (string, bool) {
+	registryConfig, err := inf.metadataService.GetRegistryConfig()
+	if err != nil {
+		inf.logger.Error(azureInfrastructureLogTag, "Failed to get registry config: %s", err.Error())
+		return "", false
+	}
+
+	return registryConfig.Endpoint, registryConfig.Endpoint != ""
+}
+
+func (inf azureInfrastructure) SetupNetworking(networks boshsettings.Networks) (err error) {
+	return inf.platform.SetupDhcp(networks)
+}
+
+func (inf azureInfrastructure) GetEphemeralDiskPath(devicePath string) (realPath string, found bool) {
+	if devicePath == "" {
+		inf.logger.Info(azureInfrastructureLogTag, "Ephemeral disk path is empty")
+		return "", false
+	}
+
+	return inf.platform.NormalizeDiskPath(devicePath)
+}
@@ -0,0 +1,123 @@
+package infrastructure_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/infrastructure"
+	boshdpresolv "bosh/infrastructure/devicepathresolver"
+	fakeinf "bosh/infrastructure/fakes"
+	boshlog "bosh/logger"
+	fakeplatform "bosh/platform/fakes"
+	boshsettings "bosh/settings"
+)
+
+var _ = Describe("azureInfrastructure", func() {
+	var (
+		ts       *httptest.Server
+		platform *fakeplatform.FakePlatform
+		azure    Infrastructure
+	)
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+
+		mux.HandleFunc("/metadata/instance/", func(w http.ResponseWriter, r *http.Request) {
+			GinkgoRecover()
+
+			Expect(r.Header.Get("Metadata")).To(Equal("true"))
+			Expect(r.URL.Query().Get("api-version")).ToNot(BeEmpty())
+
+			switch r.URL.Path {
+			case "/metadata/instance/compute":
+				w.Write([]byte(`{"name":"fake-vm-name","publicKeys":[{"keyData":"fake-public-key"}]}`))
+			case "/metadata/instance/compute/userData":
+				userData := fmt.Sprintf(`{"registry":{"endpoint":"%s"}}`, ts.URL)
+				w.Write([]byte(base64.StdEncoding.EncodeToString([]byte(userData))))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+
+		mux.HandleFunc("/instances/fake-vm-name/settings", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"settings": "{\"agent_id\":\"fake-agent-id\"}"}`))
+		})
+
+		ts = httptest.NewServer(mux)
+
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		httpClient := NewHTTPClient(DefaultHTTPClientOptions, logger)
+
+		metadataService := NewAzureMetadataService(ts.URL, &fakeinf.FakeDNSResolver{}, httpClient)
+		registry := NewConcreteRegistry(metadataService, httpClient, true)
+
+		platform = fakeplatform.NewFakePlatform()
+
+		devicePathResolver := boshdpresolv.NewSymlinkDevicePathResolver(
+			time.Millisecond,
+			boshdpresolv.AzureDiskSymlinkFormat,
+			platform.GetFs(),
+		)
+
+		azure = NewAzureInfrastructure(metadataService, registry, platform, devicePathResolver, logger)
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	Describe("SetupSsh", func() {
+		It("sets up ssh with public key from compute metadata", func() {
+			err := azure.SetupSsh("vcap")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(platform.SetupSshPublicKey).To(Equal("fake-public-key"))
+			Expect(platform.SetupSshUsername).To(Equal("vcap"))
+		})
+	})
+
+	Describe("GetSettings", func() {
+		It("gets settings from registry by vm name", func() {
+			settings, err := azure.GetSettings()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(settings).To(Equal(boshsettings.Settings{AgentID: "fake-agent-id"}))
+		})
+	})
+
+	Describe("SetupNetworking", func() {
+		It("sets up DHCP on the platform", func() {
+			networks := boshsettings.Networks{"bosh": boshsettings.Network{}}
+
+			err := azure.SetupNetworking(networks)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(platform.SetupDhcpNetworks).To(Equal(networks))
+		})
+	})
+
+	Describe("GetDevicePathResolver", func() {
+		It("resolves data disk LUN to device path", func() {
+			platform.GetFs().Symlink("../../../sdc", "/dev/disk/azure/scsi1/lun0")
+
+			realPath, err := azure.GetDevicePathResolver().GetRealDevicePath("0")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(realPath).To(Equal("/dev/sdc"))
+		})
+	})
+
+	Describe("GetEphemeralDiskPath", func() {
+		It("returns false if device path is empty", func() {
+			realPath, found := azure.GetEphemeralDiskPath("")
+			Expect(found).To(BeFalse())
+			Expect(realPath).To(BeEmpty())
+
+			Expect(platform.NormalizeDiskPathCalled).To(BeFalse())
+		})
+	})
+})
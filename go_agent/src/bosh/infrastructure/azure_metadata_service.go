@@ -0,0 +1,136 @@
+package infrastructure
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	bosherr "bosh/errors"
+)
+
+const (
+	azureMetadataPath = "/metadata/instance"
+
+	// Oldest api version that exposes user data
+	azureMetadataAPIVersion = "2021-01-01"
+
+	// Requests without this header are rejected by Azure IMDS
+	azureMetadataHeader = "Metadata"
+)
+
+type azureMetadataService struct {
+	metadataHost string
+	resolver     dnsResolver
+	client       HTTPClient
+}
+
+type azureComputeType struct {
+	Name       string `json:"name"`
+	VMID       string `json:"vmId"`
+	PublicKeys []struct {
+		KeyData string `json:"keyData"`
+	} `json:"publicKeys"`
+}
+
+func NewAzureMetadataService(
+	metadataHost string,
+	resolver dnsResolver,
+	client HTTPClient,
+) azureMetadataService {
+	return azureMetadataService{
+		metadataHost: metadataHost,
+		resolver:     resolver,
+		client:       client,
+	}
+}
+
+func (ms azureMetadataService) GetPublicKey() (string, error) {
+	compute, err := ms.getCompute()
+	if err != nil {
+		return "", bosherr.WrapError(err, "Getting compute metadata")
+	}
+
+	for _, publicKey := range compute.PublicKeys {
+		if len(publicKey.KeyData) > 0 {
+			return publicKey.KeyData, nil
+		}
+	}
+
+	return "", bosherr.New("No public keys found in compute metadata")
+}
+
+func (ms azureMetadataService) GetInstanceID() (string, error) {
+	compute, err := ms.getCompute()
+	if err != nil {
+		return "", bosherr.WrapError(err, "Getting compute metadata")
+	}
+
+	return compute.VMID, nil
+}
+
+func (ms azureMetadataService) GetServerName() (string, error) {
+	compute, err := ms.getCompute()
+	if err != nil {
+		return "", bosherr.WrapError(err, "Getting compute metadata")
+	}
+
+	if len(compute.Name) == 0 {
+		return "", bosherr.New("Empty server name")
+	}
+
+	return compute.Name, nil
+}
+
+func (ms azureMetadataService) IsAvailable() bool {
+	return true
+}
+
+func (ms azureMetadataService) GetRegistryConfig() (RegistryConfig, error) {
+	var userData userDataType
+
+	encodedUserData, err := ms.get("compute/userData", url.Values{"format": []string{"text"}})
+	if err != nil {
+		return RegistryConfig{}, bosherr.WrapError(err, "Getting user data")
+	}
+
+	userDataBytes, err := base64.StdEncoding.DecodeString(string(encodedUserData))
+	if err != nil {
+		return RegistryConfig{}, bosherr.WrapError(err, "Decoding user data")
+	}
+
+	err = json.Unmarshal(userDataBytes, &userData)
+	if err != nil {
+		return RegistryConfig{}, bosherr.WrapError(err, "Unmarshalling user data")
+	}
+
+	return newRegistryConfig(ms.resolver, userData)
+}
+
+func (ms azureMetadataService) getCompute() (azureComputeType, error) {
+	var compute azureComputeType
+
+	computeBytes, err := ms.get("compute", url.Values{})
+	if err != nil {
+		return compute, err
+	}
+
+	err = json.Unmarshal(computeBytes, &compute)
+	if err != nil {
+		return compute, bosherr.WrapError(err, "Unmarshalling compute metadata")
+	}
+
+	return compute, nil
+}
+
+func (ms azureMetadataService) get(path string, query url.Values) ([]byte, error) {
+	query.Set("api-version", azureMetadataAPIVersion)
+
+	metadataURL := fmt.Sprintf("%s%s/%s?%s", ms.metadataHost, azureMetadataPath, path, query.Encode())
+
+	headers := http.Header{}
+	headers.Set(azureMetadataHeader, "true")
+
+	return ms.client.Get(metadataURL, headers)
+}
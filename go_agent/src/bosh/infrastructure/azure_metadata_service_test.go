@@ -0,0 +1,139 @@
+package infrastructure_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/infrastructure"
+	fakeinf "bosh/infrastructure/fakes"
+	boshlog "bosh/logger"
+)
+
+var _ = Describe("azureMetadataService", func() {
+	var (
+		ts              *httptest.Server
+		compute         string
+		userData        string
+		dnsResolver     *fakeinf.FakeDNSResolver
+		metadataService MetadataService
+	)
+
+	BeforeEach(func() {
+		compute = `{
+			"name": "fake-vm-name",
+			"vmId": "fake-vm-id",
+			"publicKeys": [{"keyData": "fake-public-key", "path": "/home/vcap/.ssh/authorized_keys"}]
+		}`
+		userData = ""
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			GinkgoRecover()
+
+			Expect(r.Method).To(Equal("GET"))
+
+			if r.Header.Get("Metadata") != "true" || r.URL.Query().Get("api-version") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			switch r.URL.Path {
+			case "/metadata/instance/compute":
+				w.Write([]byte(compute))
+			case "/metadata/instance/compute/userData":
+				Expect(r.URL.Query().Get("format")).To(Equal("text"))
+				w.Write([]byte(base64.StdEncoding.EncodeToString([]byte(userData))))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+
+		ts = httptest.NewServer(handler)
+
+		dnsResolver = &fakeinf.FakeDNSResolver{}
+		httpClient := NewHTTPClient(DefaultHTTPClientOptions, boshlog.NewLogger(boshlog.LevelNone))
+		metadataService = NewAzureMetadataService(ts.URL, dnsResolver, httpClient)
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	Describe("GetPublicKey", func() {
+		It("returns first public key from compute metadata", func() {
+			publicKey, err := metadataService.GetPublicKey()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(publicKey).To(Equal("fake-public-key"))
+		})
+
+		It("returns error if compute metadata does not contain public keys", func() {
+			compute = `{"name": "fake-vm-name"}`
+
+			_, err := metadataService.GetPublicKey()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("No public keys found"))
+		})
+
+		It("returns error if compute metadata cannot be parsed", func() {
+			compute = "fake-invalid-json"
+
+			_, err := metadataService.GetPublicKey()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unmarshalling compute metadata"))
+		})
+	})
+
+	Describe("GetInstanceID", func() {
+		It("returns vm id", func() {
+			instanceID, err := metadataService.GetInstanceID()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instanceID).To(Equal("fake-vm-id"))
+		})
+	})
+
+	Describe("GetServerName", func() {
+		It("returns vm name", func() {
+			serverName, err := metadataService.GetServerName()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(serverName).To(Equal("fake-vm-name"))
+		})
+
+		It("returns error if vm name is empty", func() {
+			compute = `{}`
+
+			_, err := metadataService.GetServerName()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Empty server name"))
+		})
+	})
+
+	Describe("GetRegistryConfig", func() {
+		It("returns registry endpoint from base64 encoded user data", func() {
+			userData = `{"registry":{"endpoint":"http://fake-registry.com:8877"}}`
+
+			registryConfig, err := metadataService.GetRegistryConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(registryConfig.Endpoint).To(Equal("http://fake-registry.com:8877"))
+		})
+
+		It("resolves registry endpoint when user data contains dns servers", func() {
+			userData = `{
+				"registry":{"endpoint":"http://fake-registry.com"},
+				"dns":{"nameserver":["fake-dns-server-ip"]}
+			}`
+
+			dnsResolver.RegisterRecord(fakeinf.FakeDNSRecord{
+				DNSServers: []string{"fake-dns-server-ip"},
+				Host:       "fake-registry.com",
+				IP:         "fake-registry-ip",
+			})
+
+			registryConfig, err := metadataService.GetRegistryConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(registryConfig.Endpoint).To(Equal("http://fake-registry-ip"))
+		})
+	})
+})
@@ -3,10 +3,6 @@ package infrastructure
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
-	"strings"
 
 	bosherr "bosh/errors"
 )
@@ -14,11 +10,17 @@ import (
 type concreteMetadataService struct {
 	metadataHost string
 	resolver     dnsResolver
+	client       HTTPClient
 }
 
 type userDataType struct {
 	Registry struct {
 		Endpoint string
+		Username string
+		Password string
+
+		CACert                 string   `json:"ca_cert"`
+		PinnedCertFingerprints []string `json:"pinned_cert_fingerprints"`
 	}
 	Server struct {
 		Name string // Name given by CPI e.g. vm-384sd4-r7re9e...
@@ -31,44 +33,32 @@ type userDataType struct {
 func NewConcreteMetadataService(
 	metadataHost string,
 	resolver dnsResolver,
+	client HTTPClient,
 ) concreteMetadataService {
 	return concreteMetadataService{
 		metadataHost: metadataHost,
 		resolver:     resolver,
+		client:       client,
 	}
 }
 
 func (ms concreteMetadataService) GetPublicKey() (string, error) {
 	url := fmt.Sprintf("%s/latest/meta-data/public-keys/0/openssh-key", ms.metadataHost)
-	resp, err := http.Get(url)
+	bytes, err := ms.client.Get(url, nil)
 	if err != nil {
 		return "", bosherr.WrapError(err, "Getting open ssh key")
 	}
 
-	defer resp.Body.Close()
-
-	bytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", bosherr.WrapError(err, "Reading ssh key response body")
-	}
-
 	return string(bytes), nil
 }
 
 func (ms concreteMetadataService) GetInstanceID() (string, error) {
 	url := fmt.Sprintf("%s/latest/meta-data/instance-id", ms.metadataHost)
-	resp, err := http.Get(url)
+	bytes, err := ms.client.Get(url, nil)
 	if err != nil {
 		return "", bosherr.WrapError(err, "Getting instance id from url")
 	}
 
-	defer resp.Body.Close()
-
-	bytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", bosherr.WrapError(err, "Reading instance id response body")
-	}
-
 	return string(bytes), nil
 }
 
@@ -87,23 +77,19 @@ func (ms concreteMetadataService) GetServerName() (string, error) {
 	return serverName, nil
 }
 
-func (ms concreteMetadataService) GetRegistryEndpoint() (string, error) {
+// HTTP metadata service is assumed to be present;
+// its failures are reported by individual calls
+func (ms concreteMetadataService) IsAvailable() bool {
+	return true
+}
+
+func (ms concreteMetadataService) GetRegistryConfig() (RegistryConfig, error) {
 	userData, err := ms.getUserData()
 	if err != nil {
-		return "", bosherr.WrapError(err, "Getting user data")
-	}
-
-	endpoint := userData.Registry.Endpoint
-	nameServers := userData.DNS.Nameserver
-
-	if len(nameServers) > 0 {
-		endpoint, err = ms.resolveRegistryEndpoint(endpoint, nameServers)
-		if err != nil {
-			return "", bosherr.WrapError(err, "Resolving registry endpoint")
-		}
+		return RegistryConfig{}, bosherr.WrapError(err, "Getting user data")
 	}
 
-	return endpoint, nil
+	return newRegistryConfig(ms.resolver, userData)
 }
 
 func (ms concreteMetadataService) getUserData() (userDataType, error) {
@@ -111,18 +97,11 @@ func (ms concreteMetadataService) getUserData() (userDataType, error) {
 
 	userDataURL := fmt.Sprintf("%s/latest/user-data", ms.metadataHost)
 
-	userDataResp, err := http.Get(userDataURL)
+	userDataBytes, err := ms.client.Get(userDataURL, nil)
 	if err != nil {
 		return userData, bosherr.WrapError(err, "Getting user data from url")
 	}
 
-	defer userDataResp.Body.Close()
-
-	userDataBytes, err := ioutil.ReadAll(userDataResp.Body)
-	if err != nil {
-		return userData, bosherr.WrapError(err, "Reading user data response body")
-	}
-
 	err = json.Unmarshal(userDataBytes, &userData)
 	if err != nil {
 		return userData, bosherr.WrapError(err, "Unmarshalling user data")
@@ -130,24 +109,3 @@ func (ms concreteMetadataService) getUserData() (userDataType, error) {
 
 	return userData, nil
 }
-
-func (ms concreteMetadataService) resolveRegistryEndpoint(namedEndpoint string, nameServers []string) (string, error) {
-	registryURL, err := url.Parse(namedEndpoint)
-	if err != nil {
-		return "", bosherr.WrapError(err, "Parsing registry named endpoint")
-	}
-
-	registryHostAndPort := strings.Split(registryURL.Host, ":")
-	registryIP, err := ms.resolver.LookupHost(nameServers, registryHostAndPort[0])
-	if err != nil {
-		return "", bosherr.WrapError(err, "Looking up registry")
-	}
-
-	if len(registryHostAndPort) == 2 {
-		registryURL.Host = fmt.Sprintf("%s:%s", registryIP, registryHostAndPort[1])
-	} else {
-		registryURL.Host = registryIP
-	}
-
-	return registryURL.String(), nil
-}
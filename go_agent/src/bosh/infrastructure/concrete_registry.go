@@ -1,10 +1,11 @@
 package infrastructure
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"net/url"
 
 	bosherr "bosh/errors"
 	boshsettings "bosh/settings"
@@ -12,19 +13,32 @@ import (
 
 type concreteRegistry struct {
 	metadataService   MetadataService
+	client            HTTPClient
 	useServerNameAsID bool
 }
 
 func NewConcreteRegistry(
 	metadataService MetadataService,
+	client HTTPClient,
 	useServerNameAsID bool,
 ) concreteRegistry {
 	return concreteRegistry{
 		metadataService:   metadataService,
+		client:            client,
 		useServerNameAsID: useServerNameAsID,
 	}
 }
 
+// RegistryAuthenticationError is returned when registry rejects agent credentials
+type RegistryAuthenticationError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e RegistryAuthenticationError) Error() string {
+	return fmt.Sprintf("Registry authentication failed for %s with status code %d", e.URL, e.StatusCode)
+}
+
 type settingsWrapperType struct {
 	Settings string
 }
@@ -47,22 +61,36 @@ func (r concreteRegistry) GetSettings() (boshsettings.Settings, error) {
 		}
 	}
 
-	registryEndpoint, err := r.metadataService.GetRegistryEndpoint()
+	registryConfig, err := r.metadataService.GetRegistryConfig()
 	if err != nil {
-		return settings, bosherr.WrapError(err, "Getting registry endpoint")
+		return settings, bosherr.WrapError(err, "Getting registry config")
 	}
 
-	settingsURL := fmt.Sprintf("%s/instances/%s/settings", registryEndpoint, identifier)
-	wrapperResponse, err := http.Get(settingsURL)
+	endpointURL, username, password, err := r.endpointWithoutCredentials(registryConfig)
 	if err != nil {
-		return settings, bosherr.WrapError(err, "Getting settings from url")
+		return settings, bosherr.WrapError(err, "Parsing registry endpoint")
 	}
 
-	defer wrapperResponse.Body.Close()
+	client, err := r.clientFor(registryConfig)
+	if err != nil {
+		return settings, bosherr.WrapError(err, "Configuring registry client")
+	}
 
-	wrapperBytes, err := ioutil.ReadAll(wrapperResponse.Body)
+	headers := http.Header{}
+	if len(username) > 0 {
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		headers.Set("Authorization", "Basic "+credentials)
+	}
+
+	settingsURL := fmt.Sprintf("%s/instances/%s/settings", endpointURL, identifier)
+	wrapperBytes, err := client.Get(settingsURL, headers)
 	if err != nil {
-		return settings, bosherr.WrapError(err, "Reading settings response body")
+		respErr, isRespErr := err.(HTTPResponseError)
+		if isRespErr && (respErr.StatusCode == http.StatusUnauthorized || respErr.StatusCode == http.StatusForbidden) {
+			return settings, RegistryAuthenticationError{URL: settingsURL, StatusCode: respErr.StatusCode}
+		}
+
+		return settings, bosherr.WrapError(err, "Getting settings from url")
 	}
 
 	var wrapper settingsWrapperType
@@ -79,3 +107,41 @@ func (r concreteRegistry) GetSettings() (boshsettings.Settings, error) {
 
 	return settings, nil
 }
+
+// endpointWithoutCredentials strips credentials from endpoint URL
+// so that they never show up in errors and logs
+func (r concreteRegistry) endpointWithoutCredentials(config RegistryConfig) (string, string, string, error) {
+	endpointURL, err := url.Parse(config.Endpoint)
+	if err != nil {
+		// Parse error includes the URL and hence credentials
+		return "", "", "", bosherr.New("Invalid registry endpoint URL")
+	}
+
+	var username, password string
+
+	if endpointURL.User != nil {
+		username = endpointURL.User.Username()
+		password, _ = endpointURL.User.Password()
+		endpointURL.User = nil
+	}
+
+	// Explicitly configured credentials take precedence
+	if len(config.Username) > 0 {
+		username = config.Username
+		password = config.Password
+	}
+
+	return endpointURL.String(), username, password, nil
+}
+
+func (r concreteRegistry) clientFor(config RegistryConfig) (HTTPClient, error) {
+	if len(config.CACert) == 0 && len(config.ServerName) == 0 && len(config.PinnedCertFingerprints) == 0 {
+		return r.client, nil
+	}
+
+	return r.client.WithTLS(TLSOptions{
+		CACert:                 config.CACert,
+		ServerName:             config.ServerName,
+		PinnedCertFingerprints: config.PinnedCertFingerprints,
+	})
+}
@@ -0,0 +1,157 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"sort"
+
+	bosherr "bosh/errors"
+	boshlog "bosh/logger"
+	boshplatform "bosh/platform"
+)
+
+const configDriveMetadataServiceLogTag = "configDriveMetadataService"
+
+type configDriveMetadataService struct {
+	resolver         dnsResolver
+	platform         boshplatform.Platform
+	diskPaths        []string
+	metaDataFilePath string
+	userDataFilePath string
+	logger           boshlog.Logger
+}
+
+type metaDataType struct {
+	UUID       string            `json:"uuid"`
+	PublicKeys map[string]string `json:"public_keys"`
+}
+
+// NewConfigDriveMetadataService returns metadata service that reads
+// OpenStack-style meta data and user data from the first present config drive
+func NewConfigDriveMetadataService(
+	resolver dnsResolver,
+	platform boshplatform.Platform,
+	diskPaths []string,
+	metaDataFilePath string,
+	userDataFilePath string,
+	logger boshlog.Logger,
+) configDriveMetadataService {
+	return configDriveMetadataService{
+		resolver:         resolver,
+		platform:         platform,
+		diskPaths:        diskPaths,
+		metaDataFilePath: metaDataFilePath,
+		userDataFilePath: userDataFilePath,
+		logger:           logger,
+	}
+}
+
+func (ms configDriveMetadataService) GetPublicKey() (string, error) {
+	metaData, _, err := ms.load()
+	if err != nil {
+		return "", err
+	}
+
+	// Public keys are keyed by their names so choose deterministically
+	var keyNames []string
+	for keyName := range metaData.PublicKeys {
+		keyNames = append(keyNames, keyName)
+	}
+
+	sort.Strings(keyNames)
+
+	for _, keyName := range keyNames {
+		if len(metaData.PublicKeys[keyName]) > 0 {
+			return metaData.PublicKeys[keyName], nil
+		}
+	}
+
+	return "", bosherr.New("No public keys found in config drive meta data")
+}
+
+func (ms configDriveMetadataService) GetInstanceID() (string, error) {
+	metaData, _, err := ms.load()
+	if err != nil {
+		return "", err
+	}
+
+	if len(metaData.UUID) == 0 {
+		return "", bosherr.New("Empty instance id")
+	}
+
+	return metaData.UUID, nil
+}
+
+func (ms configDriveMetadataService) GetServerName() (string, error) {
+	_, userData, err := ms.load()
+	if err != nil {
+		return "", err
+	}
+
+	serverName := userData.Server.Name
+
+	if len(serverName) == 0 {
+		return "", bosherr.New("Empty server name")
+	}
+
+	return serverName, nil
+}
+
+func (ms configDriveMetadataService) GetRegistryConfig() (RegistryConfig, error) {
+	_, userData, err := ms.load()
+	if err != nil {
+		return RegistryConfig{}, err
+	}
+
+	return newRegistryConfig(ms.resolver, userData)
+}
+
+func (ms configDriveMetadataService) IsAvailable() bool {
+	_, found := ms.findDiskPath()
+	return found
+}
+
+func (ms configDriveMetadataService) findDiskPath() (string, bool) {
+	for _, diskPath := range ms.diskPaths {
+		if ms.platform.GetFs().FileExists(diskPath) {
+			return diskPath, true
+		}
+	}
+
+	return "", false
+}
+
+func (ms configDriveMetadataService) load() (metaDataType, userDataType, error) {
+	var metaData metaDataType
+	var userData userDataType
+
+	diskPath, found := ms.findDiskPath()
+	if !found {
+		return metaData, userData, bosherr.New("Config drive is not present")
+	}
+
+	ms.logger.Debug(configDriveMetadataServiceLogTag, "Loading config drive %s", diskPath)
+
+	contents, err := ms.platform.GetFilesContentsFromDisk(
+		diskPath,
+		[]string{ms.metaDataFilePath, ms.userDataFilePath},
+	)
+	if err != nil {
+		return metaData, userData, bosherr.WrapError(err, "Reading files from config drive")
+	}
+
+	if len(contents) != 2 {
+		return metaData, userData, bosherr.New("Expected 2 files from config drive, got %d", len(contents))
+	}
+
+	err = json.Unmarshal(contents[0], &metaData)
+	if err != nil {
+		return metaData, userData, bosherr.WrapError(err, "Unmarshalling config drive meta data")
+	}
+
+	err = json.Unmarshal(contents[1], &userData)
+	if err != nil {
+		return metaData, userData, bosherr.WrapError(err, "Unmarshalling config drive user data")
+	}
+
+	return metaData, userData, nil
+}
@@ -0,0 +1,192 @@
+package infrastructure_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/infrastructure"
+	fakeinf "bosh/infrastructure/fakes"
+	boshlog "bosh/logger"
+	fakeplatform "bosh/platform/fakes"
+)
+
+var _ = Describe("configDriveMetadataService", func() {
+	var (
+		dnsResolver     *fakeinf.FakeDNSResolver
+		platform        *fakeplatform.FakePlatform
+		metadataService MetadataService
+	)
+
+	updateMetadata := func(metaDataJSON, userDataJSON string) {
+		platform.GetFilesContentsFromDiskContents = [][]byte{
+			[]byte(metaDataJSON),
+			[]byte(userDataJSON),
+		}
+	}
+
+	BeforeEach(func() {
+		dnsResolver = &fakeinf.FakeDNSResolver{}
+		platform = fakeplatform.NewFakePlatform()
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+
+		metadataService = NewConfigDriveMetadataService(
+			dnsResolver,
+			platform,
+			[]string{"/fake-disk-path-1", "/fake-disk-path-2"},
+			"fake-meta-data-path",
+			"fake-user-data-path",
+			logger,
+		)
+
+		platform.GetFs().WriteFileString("/fake-disk-path-2", "")
+
+		updateMetadata(
+			`{"uuid":"fake-uuid","public_keys":{"b-key":"fake-public-key-2","a-key":"fake-public-key-1"}}`,
+			`{"server":{"name":"fake-server-name"},"registry":{"endpoint":"http://fake-registry.com"}}`,
+		)
+	})
+
+	Describe("IsAvailable", func() {
+		It("returns true if one of disk paths exists", func() {
+			Expect(metadataService.IsAvailable()).To(BeTrue())
+		})
+
+		It("returns false if none of disk paths exist", func() {
+			platform.GetFs().RemoveAll("/fake-disk-path-2")
+			Expect(metadataService.IsAvailable()).To(BeFalse())
+		})
+	})
+
+	Describe("GetPublicKey", func() {
+		It("returns public key with the first name", func() {
+			publicKey, err := metadataService.GetPublicKey()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(publicKey).To(Equal("fake-public-key-1"))
+		})
+
+		It("reads meta data and user data from present config drive", func() {
+			_, err := metadataService.GetPublicKey()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(platform.GetFilesContentsFromDiskDiskPaths).To(Equal([]string{"/fake-disk-path-2"}))
+			Expect(platform.GetFilesContentsFromDiskFileNames).To(Equal([][]string{
+				{"fake-meta-data-path", "fake-user-data-path"},
+			}))
+		})
+
+		It("returns error if there are no public keys", func() {
+			updateMetadata(`{"uuid":"fake-uuid"}`, `{}`)
+
+			_, err := metadataService.GetPublicKey()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("No public keys found"))
+		})
+	})
+
+	Describe("GetInstanceID", func() {
+		It("returns uuid from meta data", func() {
+			instanceID, err := metadataService.GetInstanceID()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instanceID).To(Equal("fake-uuid"))
+		})
+
+		It("returns error if meta data cannot be parsed", func() {
+			updateMetadata("fake-invalid-json", `{}`)
+
+			_, err := metadataService.GetInstanceID()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unmarshalling config drive meta data"))
+		})
+	})
+
+	Describe("GetServerName", func() {
+		It("returns server name from user data", func() {
+			serverName, err := metadataService.GetServerName()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(serverName).To(Equal("fake-server-name"))
+		})
+
+		It("returns error if reading config drive fails", func() {
+			platform.GetFilesContentsFromDiskErr = errors.New("fake-read-disk-err")
+
+			_, err := metadataService.GetServerName()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-read-disk-err"))
+		})
+
+		It("returns error if config drive is not present", func() {
+			platform.GetFs().RemoveAll("/fake-disk-path-2")
+
+			_, err := metadataService.GetServerName()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Config drive is not present"))
+		})
+	})
+
+	Describe("GetRegistryConfig", func() {
+		It("returns registry endpoint from user data", func() {
+			registryConfig, err := metadataService.GetRegistryConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(registryConfig.Endpoint).To(Equal("http://fake-registry.com"))
+		})
+
+		It("resolves registry endpoint when user data contains dns servers", func() {
+			updateMetadata(`{}`, `{
+				"registry":{"endpoint":"http://fake-registry.com:25777"},
+				"dns":{"nameserver":["fake-dns-server-ip"]}
+			}`)
+
+			dnsResolver.RegisterRecord(fakeinf.FakeDNSRecord{
+				DNSServers: []string{"fake-dns-server-ip"},
+				Host:       "fake-registry.com",
+				IP:         "fake-registry-ip",
+			})
+
+			registryConfig, err := metadataService.GetRegistryConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(registryConfig.Endpoint).To(Equal("http://fake-registry-ip:25777"))
+		})
+	})
+})
+
+var _ = Describe("multiSourceMetadataService", func() {
+	var (
+		configDrive *fakeinf.FakeMetadataService
+		http        *fakeinf.FakeMetadataService
+	)
+
+	BeforeEach(func() {
+		configDrive = &fakeinf.FakeMetadataService{ServerName: "fake-config-drive-server-name"}
+		http = &fakeinf.FakeMetadataService{ServerName: "fake-http-server-name", Available: true}
+	})
+
+	It("uses first available service", func() {
+		configDrive.Available = true
+		metadataService := NewMultiSourceMetadataService(configDrive, http)
+
+		serverName, err := metadataService.GetServerName()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serverName).To(Equal("fake-config-drive-server-name"))
+	})
+
+	It("falls back to next service when first one is not available", func() {
+		metadataService := NewMultiSourceMetadataService(configDrive, http)
+
+		serverName, err := metadataService.GetServerName()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serverName).To(Equal("fake-http-server-name"))
+	})
+
+	It("uses last service when none are available", func() {
+		http.Available = false
+		metadataService := NewMultiSourceMetadataService(configDrive, http)
+
+		Expect(metadataService.IsAvailable()).To(BeFalse())
+
+		serverName, err := metadataService.GetServerName()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serverName).To(Equal("fake-http-server-name"))
+	})
+})
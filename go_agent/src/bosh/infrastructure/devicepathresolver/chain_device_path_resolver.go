@@ -0,0 +1,57 @@
+package devicepathresolver
+
+import (
+	"strings"
+
+	bosherr "bosh/errors"
+)
+
+type chainDevicePathResolver struct {
+	resolvers []DevicePathResolver
+}
+
+// NewChainDevicePathResolver returns resolver that tries given resolvers
+// in order and returns the first successfully resolved path
+func NewChainDevicePathResolver(resolvers ...DevicePathResolver) chainDevicePathResolver {
+	return chainDevicePathResolver{resolvers: resolvers}
+}
+
+func (dpr chainDevicePathResolver) GetRealDevicePath(devicePath string) (string, error) {
+	var errMsgs []string
+
+	for _, resolver := range dpr.resolvers {
+		realPath, err := resolver.GetRealDevicePath(devicePath)
+		if err == nil {
+			return realPath, nil
+		}
+
+		errMsgs = append(errMsgs, err.Error())
+	}
+
+	return "", bosherr.New("Resolving device path for %s: %s", devicePath, strings.Join(errMsgs, "; "))
+}
+
+// GetRealDevicePathByVolumeID only tries resolvers that can find disks by volume ID
+func (dpr chainDevicePathResolver) GetRealDevicePathByVolumeID(volumeID string) (string, error) {
+	var errMsgs []string
+
+	for _, resolver := range dpr.resolvers {
+		volumeIDResolver, ok := resolver.(VolumeIDDevicePathResolver)
+		if !ok {
+			continue
+		}
+
+		realPath, err := volumeIDResolver.GetRealDevicePathByVolumeID(volumeID)
+		if err == nil {
+			return realPath, nil
+		}
+
+		errMsgs = append(errMsgs, err.Error())
+	}
+
+	if len(errMsgs) == 0 {
+		return "", bosherr.New("Resolving device path for volume %s: no resolver finds disks by volume ID", volumeID)
+	}
+
+	return "", bosherr.New("Resolving device path for volume %s: %s", volumeID, strings.Join(errMsgs, "; "))
+}
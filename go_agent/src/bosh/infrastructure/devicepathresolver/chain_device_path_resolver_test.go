@@ -0,0 +1,83 @@
+package devicepathresolver_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/infrastructure/devicepathresolver"
+	fakedpresolv "bosh/infrastructure/devicepathresolver/fakes"
+)
+
+var _ = Describe("chainDevicePathResolver", func() {
+	var (
+		firstResolver  *fakedpresolv.FakeDevicePathResolver
+		secondResolver *fakedpresolv.FakeDevicePathResolver
+		resolver       DevicePathResolver
+	)
+
+	BeforeEach(func() {
+		firstResolver = fakedpresolv.NewFakeDevicePathResolver()
+		secondResolver = fakedpresolv.NewFakeDevicePathResolver()
+		resolver = NewChainDevicePathResolver(firstResolver, secondResolver)
+	})
+
+	It("returns path from the first resolver that succeeds", func() {
+		firstResolver.GetRealDevicePathErr = errors.New("fake-first-err")
+		secondResolver.RegisterRealDevicePath("fake-volume-id", "/dev/vdc")
+
+		realPath, err := resolver.GetRealDevicePath("fake-volume-id")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(realPath).To(Equal("/dev/vdc"))
+	})
+
+	It("does not try later resolvers once path is resolved", func() {
+		firstResolver.RegisterRealDevicePath("fake-volume-id", "/dev/vdb")
+		secondResolver.GetRealDevicePathErr = errors.New("fake-second-err")
+
+		realPath, err := resolver.GetRealDevicePath("fake-volume-id")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(realPath).To(Equal("/dev/vdb"))
+	})
+
+	It("returns errors from all resolvers if none succeed", func() {
+		firstResolver.GetRealDevicePathErr = errors.New("fake-first-err")
+		secondResolver.GetRealDevicePathErr = errors.New("fake-second-err")
+
+		_, err := resolver.GetRealDevicePath("fake-volume-id")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-first-err"))
+		Expect(err.Error()).To(ContainSubstring("fake-second-err"))
+	})
+
+	Describe("GetRealDevicePathByVolumeID", func() {
+		var volumeIDResolver VolumeIDDevicePathResolver
+
+		BeforeEach(func() {
+			volumeIDResolver = NewChainDevicePathResolver(NewDummyDevicePathResolver(), firstResolver, secondResolver)
+		})
+
+		It("returns path from the first resolver that finds disk by volume ID", func() {
+			secondResolver.RegisterRealDevicePathForVolumeID("fake-volume-id", "/dev/nvme1n1")
+
+			realPath, err := volumeIDResolver.GetRealDevicePathByVolumeID("fake-volume-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(realPath).To(Equal("/dev/nvme1n1"))
+		})
+
+		It("returns error if no resolver finds disk by volume ID", func() {
+			_, err := volumeIDResolver.GetRealDevicePathByVolumeID("fake-volume-id")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-unknown-volume-id"))
+		})
+
+		It("returns error if no resolver can find disks by volume ID", func() {
+			volumeIDResolver = NewChainDevicePathResolver(NewDummyDevicePathResolver())
+
+			_, err := volumeIDResolver.GetRealDevicePathByVolumeID("fake-volume-id")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("no resolver finds disks by volume ID"))
+		})
+	})
+})
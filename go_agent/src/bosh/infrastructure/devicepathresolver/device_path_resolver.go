@@ -3,3 +3,9 @@ package devicepathresolver
 type DevicePathResolver interface {
 	GetRealDevicePath(devicePath string) (realPath string, err error)
 }
+
+// VolumeIDDevicePathResolver is implemented by resolvers that can find disk
+// by volume ID (disk CID) instead of device path given in settings
+type VolumeIDDevicePathResolver interface {
+	GetRealDevicePathByVolumeID(volumeID string) (realPath string, err error)
+}
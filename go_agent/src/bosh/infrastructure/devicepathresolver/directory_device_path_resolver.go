@@ -0,0 +1,46 @@
+package devicepathresolver
+
+import (
+	"path/filepath"
+	"time"
+
+	bosherr "bosh/errors"
+	boshsys "bosh/system"
+)
+
+type directoryDevicePathResolver struct {
+	diskWaitTimeout time.Duration
+	fs              boshsys.FileSystem
+}
+
+// NewDirectoryDevicePathResolver returns resolver for disks that are
+// directories bind-mounted into the container (e.g. by warden CPI)
+func NewDirectoryDevicePathResolver(
+	diskWaitTimeout time.Duration,
+	fs boshsys.FileSystem,
+) directoryDevicePathResolver {
+	return directoryDevicePathResolver{
+		diskWaitTimeout: diskWaitTimeout,
+		fs:              fs,
+	}
+}
+
+func (dpr directoryDevicePathResolver) GetRealDevicePath(dirPath string) (string, error) {
+	if !filepath.IsAbs(dirPath) {
+		return "", bosherr.New("Expected absolute disk directory path, got '%s'", dirPath)
+	}
+
+	dirPath = filepath.Clean(dirPath)
+
+	stopAfter := time.Now().Add(dpr.diskWaitTimeout)
+
+	for !dpr.fs.FileExists(dirPath) {
+		if time.Now().After(stopAfter) {
+			return "", bosherr.New("Timed out waiting for disk directory %s", dirPath)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return dirPath, nil
+}
@@ -0,0 +1,55 @@
+package devicepathresolver_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/infrastructure/devicepathresolver"
+	fakesys "bosh/system/fakes"
+)
+
+var _ = Describe("directoryDevicePathResolver", func() {
+	var (
+		fs       *fakesys.FakeFileSystem
+		resolver DevicePathResolver
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		resolver = NewDirectoryDevicePathResolver(time.Millisecond, fs)
+	})
+
+	It("returns disk directory when it exists", func() {
+		fs.MkdirAll("/warden-cpi-dev/fake-disk-id", 0755)
+
+		realPath, err := resolver.GetRealDevicePath("/warden-cpi-dev/fake-disk-id/")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(realPath).To(Equal("/warden-cpi-dev/fake-disk-id"))
+	})
+
+	It("waits for disk directory to be bind-mounted", func() {
+		resolver = NewDirectoryDevicePathResolver(time.Second, fs)
+
+		time.AfterFunc(200*time.Millisecond, func() {
+			fs.MkdirAll("/warden-cpi-dev/fake-disk-id", 0755)
+		})
+
+		realPath, err := resolver.GetRealDevicePath("/warden-cpi-dev/fake-disk-id")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(realPath).To(Equal("/warden-cpi-dev/fake-disk-id"))
+	})
+
+	It("times out when disk directory does not show up", func() {
+		_, err := resolver.GetRealDevicePath("/warden-cpi-dev/fake-disk-id")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Timed out waiting for disk directory /warden-cpi-dev/fake-disk-id"))
+	})
+
+	It("returns error for relative paths", func() {
+		_, err := resolver.GetRealDevicePath("fake-disk-id")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected absolute disk directory path"))
+	})
+})
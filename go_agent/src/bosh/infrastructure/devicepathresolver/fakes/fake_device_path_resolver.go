@@ -1,16 +1,22 @@
 package fakes
 
 import (
+	"errors"
 	"fmt"
 )
 
 type FakeDevicePathResolver struct {
 	realDevicePaths      map[string]string
 	GetRealDevicePathErr error
+
+	realDevicePathsByVolumeID map[string]string
 }
 
 func NewFakeDevicePathResolver() *FakeDevicePathResolver {
-	return &FakeDevicePathResolver{realDevicePaths: map[string]string{}}
+	return &FakeDevicePathResolver{
+		realDevicePaths:           map[string]string{},
+		realDevicePathsByVolumeID: map[string]string{},
+	}
 }
 
 func (r *FakeDevicePathResolver) RegisterRealDevicePath(devicePath, realDevicePath string) {
@@ -32,3 +38,16 @@ func (r *FakeDevicePathResolver) GetRealDevicePath(devicePath string) (string, e
 	}
 	return realDevicePath, nil
 }
+
+func (r *FakeDevicePathResolver) RegisterRealDevicePathForVolumeID(volumeID, realDevicePath string) {
+	r.realDevicePathsByVolumeID[volumeID] = realDevicePath
+}
+
+// GetRealDevicePathByVolumeID fails for unregistered volume IDs so that device path is used instead
+func (r *FakeDevicePathResolver) GetRealDevicePathByVolumeID(volumeID string) (string, error) {
+	realDevicePath, found := r.realDevicePathsByVolumeID[volumeID]
+	if !found {
+		return "", errors.New("fake-unknown-volume-id")
+	}
+	return realDevicePath, nil
+}
@@ -0,0 +1,190 @@
+package devicepathresolver
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	bosherr "bosh/errors"
+	boshsys "bosh/system"
+)
+
+// virtio truncates disk serial numbers to 20 characters
+const virtioSerialMaxLen = 20
+
+// Properties reported by udev that may contain volume ID
+var udevIDProperties = []string{"ID_SERIAL", "ID_SERIAL_SHORT", "ID_WWN", "ID_WWN_WITH_EXTENSION"}
+
+type idDevicePathResolver struct {
+	diskWaitTimeout time.Duration
+	runner          boshsys.CmdRunner
+	fs              boshsys.FileSystem
+}
+
+// NewIDDevicePathResolver returns resolver that finds disk by matching
+// volume ID against disk serial numbers (virtio, NVMe) and SCSI WWNs
+// exposed via /dev/disk/by-id symlinks and udev properties
+func NewIDDevicePathResolver(
+	diskWaitTimeout time.Duration,
+	runner boshsys.CmdRunner,
+	fs boshsys.FileSystem,
+) idDevicePathResolver {
+	return idDevicePathResolver{
+		diskWaitTimeout: diskWaitTimeout,
+		runner:          runner,
+		fs:              fs,
+	}
+}
+
+// GetRealDevicePath only resolves volume IDs given in place of device paths
+func (dpr idDevicePathResolver) GetRealDevicePath(devicePath string) (string, error) {
+	return dpr.GetRealDevicePathByVolumeID(devicePath)
+}
+
+func (dpr idDevicePathResolver) GetRealDevicePathByVolumeID(volumeID string) (string, error) {
+	// Device paths are left for other resolvers
+	if len(volumeID) == 0 || strings.HasPrefix(volumeID, "/dev/") {
+		return "", bosherr.New("Expected volume ID, got '%s'", volumeID)
+	}
+
+	stopAfter := time.Now().Add(dpr.diskWaitTimeout)
+
+	for {
+		realPath, found, err := dpr.findBySymlink(volumeID)
+		if err != nil {
+			return "", err
+		}
+
+		if found {
+			return realPath, nil
+		}
+
+		realPath, found = dpr.findByUdevProperties(volumeID)
+		if found {
+			return realPath, nil
+		}
+
+		if time.Now().After(stopAfter) {
+			return "", bosherr.New("Timed out getting real device path for %s", volumeID)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (dpr idDevicePathResolver) findBySymlink(volumeID string) (string, bool, error) {
+	symlinkPaths, err := dpr.fs.Glob("/dev/disk/by-id/*")
+	if err != nil {
+		return "", false, bosherr.WrapError(err, "Listing disks by id")
+	}
+
+	for _, symlinkPath := range symlinkPaths {
+		name := filepath.Base(symlinkPath)
+
+		if strings.Contains(name, "-part") {
+			continue
+		}
+
+		// Symlink names look like virtio-<serial>, nvme-<model>_<serial>, wwn-0x<wwn>
+		nameParts := strings.SplitN(name, "-", 2)
+		if len(nameParts) != 2 || !volumeIDMatches(volumeID, nameParts[1]) {
+			continue
+		}
+
+		targetPath, err := dpr.fs.ReadLink(symlinkPath)
+		if err != nil {
+			return "", false, bosherr.WrapError(err, "Reading device symlink %s", symlinkPath)
+		}
+
+		if !filepath.IsAbs(targetPath) {
+			targetPath = filepath.Join(filepath.Dir(symlinkPath), targetPath)
+		}
+
+		return filepath.Clean(targetPath), true, nil
+	}
+
+	return "", false, nil
+}
+
+// findByUdevProperties is used when udev rules do not create by-id symlinks
+func (dpr idDevicePathResolver) findByUdevProperties(volumeID string) (string, bool) {
+	blockDevicePaths, err := dpr.fs.Glob("/sys/block/*")
+	if err != nil {
+		return "", false
+	}
+
+	for _, blockDevicePath := range blockDevicePaths {
+		name := filepath.Base(blockDevicePath)
+
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+			continue
+		}
+
+		devicePath := filepath.Join("/dev", name)
+
+		stdout, _, _, err := dpr.runner.RunCommand("udevadm", "info", "--query=property", "--name="+devicePath)
+		if err != nil {
+			continue
+		}
+
+		properties := parseUdevProperties(stdout)
+
+		for _, property := range udevIDProperties {
+			if volumeIDMatches(volumeID, properties[property]) {
+				return devicePath, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func parseUdevProperties(output string) map[string]string {
+	properties := map[string]string{}
+
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(parts) == 2 {
+			properties[parts[0]] = parts[1]
+		}
+	}
+
+	return properties
+}
+
+// volumeIDMatches ignores case and separators since providers format
+// volume IDs differently than they are exposed by the kernel
+// e.g. AWS volume vol-0abc is exposed as NVMe serial vol0abc.
+// Only the whole serial is matched so that volume whose ID ends
+// with ID of another volume is not mistaken for it.
+func volumeIDMatches(volumeID, serial string) bool {
+	normalizedID := normalizeSerial(volumeID)
+
+	if len(normalizedID) == 0 || len(serial) == 0 {
+		return false
+	}
+
+	if normalizeSerial(serial) == normalizedID {
+		return true
+	}
+
+	// NVMe serials may be prefixed with model name (e.g. Amazon_Elastic_Block_Store_vol0abc)
+	if idx := strings.LastIndex(serial, "_"); idx != -1 {
+		if normalizeSerial(serial[idx+1:]) == normalizedID {
+			return true
+		}
+	}
+
+	if len(serial) == virtioSerialMaxLen && len(volumeID) > virtioSerialMaxLen {
+		return strings.EqualFold(volumeID[:virtioSerialMaxLen], serial)
+	}
+
+	return false
+}
+
+func normalizeSerial(serial string) string {
+	serial = strings.ToLower(serial)
+	serial = strings.TrimPrefix(serial, "0x")
+	serial = strings.Replace(serial, "-", "", -1)
+	return strings.Replace(serial, "_", "", -1)
+}
@@ -0,0 +1,179 @@
+package devicepathresolver_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/infrastructure/devicepathresolver"
+	fakesys "bosh/system/fakes"
+)
+
+var _ = Describe("idDevicePathResolver", func() {
+	var (
+		runner   *fakesys.FakeCmdRunner
+		fs       *fakesys.FakeFileSystem
+		resolver DevicePathResolver
+	)
+
+	BeforeEach(func() {
+		runner = fakesys.NewFakeCmdRunner()
+		fs = fakesys.NewFakeFileSystem()
+		resolver = NewIDDevicePathResolver(time.Millisecond, runner, fs)
+	})
+
+	Context("when disk is exposed via by-id symlink", func() {
+		It("matches virtio serial truncated to 20 characters", func() {
+			fs.SetGlob("/dev/disk/by-id/*", []string{
+				"/dev/disk/by-id/virtio-11111111-2222-3333-4",
+				"/dev/disk/by-id/virtio-aaaaaaaa-bbbb-cccc-d",
+				"/dev/disk/by-id/virtio-aaaaaaaa-bbbb-cccc-d-part1",
+			})
+			fs.Symlink("../../vdc", "/dev/disk/by-id/virtio-aaaaaaaa-bbbb-cccc-d")
+
+			realPath, err := resolver.GetRealDevicePath("aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(realPath).To(Equal("/dev/vdc"))
+		})
+
+		It("matches NVMe serial prefixed with model name", func() {
+			fs.SetGlob("/dev/disk/by-id/*", []string{
+				"/dev/disk/by-id/nvme-Amazon_Elastic_Block_Store_vol0123456789abcdef0",
+			})
+			fs.Symlink("../../nvme1n1", "/dev/disk/by-id/nvme-Amazon_Elastic_Block_Store_vol0123456789abcdef0")
+
+			realPath, err := resolver.GetRealDevicePath("vol-0123456789abcdef0")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(realPath).To(Equal("/dev/nvme1n1"))
+		})
+
+		It("matches SCSI WWN", func() {
+			fs.SetGlob("/dev/disk/by-id/*", []string{
+				"/dev/disk/by-id/wwn-0x6000c29a5f8e7d6c5b4a392817161514",
+			})
+			fs.Symlink("/dev/sdb", "/dev/disk/by-id/wwn-0x6000c29a5f8e7d6c5b4a392817161514")
+
+			realPath, err := resolver.GetRealDevicePath("6000C29A5F8E7D6C5B4A392817161514")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(realPath).To(Equal("/dev/sdb"))
+		})
+
+		It("does not match serial that only ends with volume ID", func() {
+			fs.SetGlob("/dev/disk/by-id/*", []string{
+				"/dev/disk/by-id/virtio-other-fake-volume-id",
+				"/dev/disk/by-id/virtio-fake-volume-id",
+			})
+			fs.Symlink("../../vdb", "/dev/disk/by-id/virtio-other-fake-volume-id")
+			fs.Symlink("../../vdc", "/dev/disk/by-id/virtio-fake-volume-id")
+
+			realPath, err := resolver.GetRealDevicePath("fake-volume-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(realPath).To(Equal("/dev/vdc"))
+		})
+
+		It("matches virtio serial only when it is whole or truncated volume ID", func() {
+			fs.SetGlob("/dev/disk/by-id/*", []string{
+				"/dev/disk/by-id/virtio-aaaaaaaa-bbbb-cccc-d",
+			})
+			fs.Symlink("../../vdc", "/dev/disk/by-id/virtio-aaaaaaaa-bbbb-cccc-d")
+
+			_, err := resolver.GetRealDevicePath("aaaaaaaa-bbbb-cccc-d")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = resolver.GetRealDevicePath("aaaaaaaa-bbbb-cccc")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Timed out getting real device path"))
+		})
+
+		It("returns error if symlink cannot be read", func() {
+			fs.SetGlob("/dev/disk/by-id/*", []string{"/dev/disk/by-id/virtio-fake-volume-id"})
+			fs.Symlink("../../vdc", "/dev/disk/by-id/virtio-fake-volume-id")
+			fs.ReadLinkError = errors.New("fake-read-link-err")
+
+			_, err := resolver.GetRealDevicePath("fake-volume-id")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-read-link-err"))
+		})
+	})
+
+	Context("when disk is only identified by udev properties", func() {
+		BeforeEach(func() {
+			fs.SetGlob("/sys/block/*", []string{"/sys/block/loop0", "/sys/block/sda", "/sys/block/sdb"})
+
+			runner.AddCmdResult("udevadm info --query=property --name=/dev/sda", fakesys.FakeCmdResult{
+				Stdout: "DEVNAME=/dev/sda\nID_SERIAL=fake-root-serial\n",
+			})
+			runner.AddCmdResult("udevadm info --query=property --name=/dev/sdb", fakesys.FakeCmdResult{
+				Stdout: "DEVNAME=/dev/sdb\nID_SERIAL_SHORT=fake-volume-id\n",
+			})
+		})
+
+		It("returns device with matching serial", func() {
+			realPath, err := resolver.GetRealDevicePath("fake-volume-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(realPath).To(Equal("/dev/sdb"))
+
+			Expect(runner.RunCommands).ToNot(ContainElement(
+				[]string{"udevadm", "info", "--query=property", "--name=/dev/loop0"},
+			))
+		})
+
+		It("does not match serial that only ends with volume ID", func() {
+			// Disks are checked again until timeout
+			runner.AddCmdResult("udevadm info --query=property --name=/dev/sda", fakesys.FakeCmdResult{
+				Stdout: "DEVNAME=/dev/sda\nID_SERIAL=fake-root-serial\n", Sticky: true,
+			})
+			runner.AddCmdResult("udevadm info --query=property --name=/dev/sdb", fakesys.FakeCmdResult{
+				Stdout: "DEVNAME=/dev/sdb\nID_SERIAL_SHORT=fake-volume-id\n", Sticky: true,
+			})
+
+			_, err := resolver.GetRealDevicePath("serial")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Timed out getting real device path"))
+		})
+	})
+
+	Context("when disk shows up after a while", func() {
+		BeforeEach(func() {
+			resolver = NewIDDevicePathResolver(time.Second, runner, fs)
+
+			fs.SetGlob("/dev/disk/by-id/*", []string{}, []string{"/dev/disk/by-id/virtio-fake-volume-id"})
+			fs.Symlink("../../vdc", "/dev/disk/by-id/virtio-fake-volume-id")
+		})
+
+		It("waits for the disk", func() {
+			realPath, err := resolver.GetRealDevicePath("fake-volume-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(realPath).To(Equal("/dev/vdc"))
+		})
+	})
+
+	It("times out when disk does not show up", func() {
+		_, err := resolver.GetRealDevicePath("fake-volume-id")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Timed out getting real device path for fake-volume-id"))
+	})
+
+	It("finds disk by volume ID", func() {
+		fs.SetGlob("/dev/disk/by-id/*", []string{
+			"/dev/disk/by-id/nvme-Amazon_Elastic_Block_Store_vol0123456789abcdef0",
+		})
+		fs.Symlink("../../nvme1n1", "/dev/disk/by-id/nvme-Amazon_Elastic_Block_Store_vol0123456789abcdef0")
+
+		volumeIDResolver := resolver.(VolumeIDDevicePathResolver)
+
+		realPath, err := volumeIDResolver.GetRealDevicePathByVolumeID("vol-0123456789abcdef0")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(realPath).To(Equal("/dev/nvme1n1"))
+	})
+
+	It("returns error right away when given device path", func() {
+		resolver = NewIDDevicePathResolver(time.Hour, runner, fs)
+
+		_, err := resolver.GetRealDevicePath("/dev/sdf")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected volume ID"))
+	})
+})
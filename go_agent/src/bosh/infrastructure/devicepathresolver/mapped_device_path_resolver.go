@@ -21,6 +21,10 @@ func NewMappedDevicePathResolver(
 }
 
 func (dpr mappedDevicePathResolver) GetRealDevicePath(devicePath string) (string, error) {
+	if !strings.HasPrefix(devicePath, "/dev/sd") {
+		return "", bosherr.New("Expected /dev/sd device path, got '%s'", devicePath)
+	}
+
 	stopAfter := time.Now().Add(dpr.diskWaitTimeout)
 
 	realPath, found := dpr.findPossibleDevice(devicePath)
@@ -39,7 +43,7 @@ func (dpr mappedDevicePathResolver) GetRealDevicePath(devicePath string) (string
 }
 
 func (dpr mappedDevicePathResolver) findPossibleDevice(devicePath string) (string, bool) {
-	pathSuffix := strings.Split(devicePath, "/dev/sd")[1]
+	pathSuffix := strings.TrimPrefix(devicePath, "/dev/sd")
 
 	possiblePrefixes := []string{
 		"/dev/xvd", // Xen
@@ -86,10 +86,10 @@ var _ = Describe("mappedDevicePathResolver", func() {
 	})
 
 	Context("when an invalid device name is passed in", func() {
-		It("panics", func() {
-			Expect(func() {
-				resolver.GetRealDevicePath("not even a device")
-			}).To(Panic())
+		It("returns error so that other resolvers can be tried", func() {
+			_, err := resolver.GetRealDevicePath("not even a device")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected /dev/sd device path"))
 		})
 	})
 })
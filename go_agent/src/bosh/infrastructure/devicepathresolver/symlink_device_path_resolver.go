@@ -0,0 +1,64 @@
+package devicepathresolver
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	bosherr "bosh/errors"
+	boshsys "bosh/system"
+)
+
+const (
+	// Symlinks created by udev for GCE persistent disks based on their device names
+	GCEDiskSymlinkFormat = "/dev/disk/by-id/google-%s"
+
+	// Symlinks created by Azure udev rules for data disks based on their LUNs
+	AzureDiskSymlinkFormat = "/dev/disk/azure/scsi1/lun%s"
+)
+
+type symlinkDevicePathResolver struct {
+	diskWaitTimeout time.Duration
+	symlinkFormat   string
+	fs              boshsys.FileSystem
+}
+
+// NewSymlinkDevicePathResolver returns resolver that follows
+// udev symlink built from symlinkFormat and given device id
+func NewSymlinkDevicePathResolver(
+	diskWaitTimeout time.Duration,
+	symlinkFormat string,
+	fs boshsys.FileSystem,
+) symlinkDevicePathResolver {
+	return symlinkDevicePathResolver{
+		diskWaitTimeout: diskWaitTimeout,
+		symlinkFormat:   symlinkFormat,
+		fs:              fs,
+	}
+}
+
+func (dpr symlinkDevicePathResolver) GetRealDevicePath(deviceID string) (string, error) {
+	symlinkPath := fmt.Sprintf(dpr.symlinkFormat, deviceID)
+
+	stopAfter := time.Now().Add(dpr.diskWaitTimeout)
+
+	for !dpr.fs.FileExists(symlinkPath) {
+		if time.Now().After(stopAfter) {
+			return "", bosherr.New("Timed out getting real device path for %s", deviceID)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	targetPath, err := dpr.fs.ReadLink(symlinkPath)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Reading device symlink %s", symlinkPath)
+	}
+
+	// udev symlinks are relative to their directory e.g. ../../sdb
+	if !filepath.IsAbs(targetPath) {
+		targetPath = filepath.Join(filepath.Dir(symlinkPath), targetPath)
+	}
+
+	return filepath.Clean(targetPath), nil
+}
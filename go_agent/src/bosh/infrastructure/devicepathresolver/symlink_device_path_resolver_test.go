@@ -0,0 +1,74 @@
+package devicepathresolver_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/infrastructure/devicepathresolver"
+	fakesys "bosh/system/fakes"
+)
+
+var _ = Describe("symlinkDevicePathResolver", func() {
+	var (
+		fs       *fakesys.FakeFileSystem
+		resolver DevicePathResolver
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		resolver = NewSymlinkDevicePathResolver(time.Second, GCEDiskSymlinkFormat, fs)
+	})
+
+	Context("when symlink points to relative path", func() {
+		BeforeEach(func() {
+			fs.Symlink("../../sdc", "/dev/disk/by-id/google-fake-disk-name")
+		})
+
+		It("returns absolute device path", func() {
+			realPath, err := resolver.GetRealDevicePath("fake-disk-name")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(realPath).To(Equal("/dev/sdc"))
+		})
+	})
+
+	Context("when symlink points to absolute path", func() {
+		BeforeEach(func() {
+			resolver = NewSymlinkDevicePathResolver(time.Second, AzureDiskSymlinkFormat, fs)
+			fs.Symlink("/dev/sdd", "/dev/disk/azure/scsi1/lun2")
+		})
+
+		It("returns target path", func() {
+			realPath, err := resolver.GetRealDevicePath("2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(realPath).To(Equal("/dev/sdd"))
+		})
+	})
+
+	Context("when symlink cannot be read", func() {
+		BeforeEach(func() {
+			fs.Symlink("../../sdc", "/dev/disk/by-id/google-fake-disk-name")
+			fs.ReadLinkError = errors.New("fake-read-link-err")
+		})
+
+		It("returns error", func() {
+			_, err := resolver.GetRealDevicePath("fake-disk-name")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-read-link-err"))
+		})
+	})
+
+	Context("when symlink does not appear", func() {
+		BeforeEach(func() {
+			resolver = NewSymlinkDevicePathResolver(time.Millisecond, GCEDiskSymlinkFormat, fs)
+		})
+
+		It("times out", func() {
+			_, err := resolver.GetRealDevicePath("fake-disk-name")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Timed out getting real device path for fake-disk-name"))
+		})
+	})
+})
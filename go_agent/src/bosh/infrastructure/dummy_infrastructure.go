@@ -58,6 +58,10 @@ func (inf dummyInfrastructure) GetSettings() (boshsettings.Settings, error) {
 	return settings, nil
 }
 
+func (inf dummyInfrastructure) GetRegistryEndpoint() (string, bool) {
+	return "", false
+}
+
 func (inf dummyInfrastructure) SetupNetworking(networks boshsettings.Networks) error {
 	return nil
 }
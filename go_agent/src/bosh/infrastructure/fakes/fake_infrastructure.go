@@ -10,6 +10,9 @@ type FakeInfrastructure struct {
 	SetupSshUsername        string
 	SetupNetworkingNetworks boshsettings.Networks
 
+	GetRegistryEndpointEndpoint string
+	GetRegistryEndpointFound    bool
+
 	GetEphemeralDiskPathDevicePath string
 	GetEphemeralDiskPathFound      bool
 	GetEphemeralDiskPathRealPath   string
@@ -42,6 +45,10 @@ func (i *FakeInfrastructure) SetupNetworking(networks boshsettings.Networks) (er
 	return
 }
 
+func (i *FakeInfrastructure) GetRegistryEndpoint() (string, bool) {
+	return i.GetRegistryEndpointEndpoint, i.GetRegistryEndpointFound
+}
+
 func (i *FakeInfrastructure) GetEphemeralDiskPath(devicePath string) (realPath string, found bool) {
 	i.GetEphemeralDiskPathDevicePath = devicePath
 	realPath = i.GetEphemeralDiskPathRealPath
@@ -1,5 +1,9 @@
 package fakes
 
+import (
+	boshinf "bosh/infrastructure"
+)
+
 type FakeMetadataService struct {
 	PublicKey       string
 	GetPublicKeyErr error
@@ -10,8 +14,10 @@ type FakeMetadataService struct {
 	ServerName       string
 	GetServerNameErr error
 
-	RegistryEndpoint       string
-	GetRegistryEndpointErr error
+	RegistryConfig       boshinf.RegistryConfig
+	GetRegistryConfigErr error
+
+	Available bool
 }
 
 func (ms FakeMetadataService) GetPublicKey() (string, error) {
@@ -26,6 +32,10 @@ func (ms FakeMetadataService) GetServerName() (string, error) {
 	return ms.ServerName, ms.GetServerNameErr
 }
 
-func (ms FakeMetadataService) GetRegistryEndpoint() (string, error) {
-	return ms.RegistryEndpoint, ms.GetRegistryEndpointErr
+func (ms FakeMetadataService) GetRegistryConfig() (boshinf.RegistryConfig, error) {
+	return ms.RegistryConfig, ms.GetRegistryConfigErr
+}
+
+func (ms FakeMetadataService) IsAvailable() bool {
+	return ms.Available
 }
@@ -0,0 +1,79 @@
+package infrastructure
+
+import (
+	bosherr "bosh/errors"
+	boshdpresolv "bosh/infrastructure/devicepathresolver"
+	boshlog "bosh/logger"
+	boshplatform "bosh/platform"
+	boshsettings "bosh/settings"
+)
+
+const gceInfrastructureLogTag = "gceInfrastructure"
+
+type gceInfrastructure struct {
+	metadataService    MetadataService
+	registry           Registry
+	platform           boshplatform.Platform
+	devicePathResolver boshdpresolv.DevicePathResolver
+	logger             boshlog.Logger
+}
+
+func NewGCEInfrastructure(
+	metadataService MetadataService,
+	registry Registry,
+	platform boshplatform.Platform,
+	devicePathResolver boshdpresolv.DevicePathResolver,
+	logger boshlog.Logger,
+) (inf gceInfrastructure) {
+	inf.metadataService = metadataService
+	inf.registry = registry
+	inf.platform = platform
+	inf.devicePathResolver = devicePathResolver
+	inf.logger = logger
+	return
+}
+
+func (inf gceInfrastructure) GetDevicePathResolver() boshdpresolv.DevicePathResolver {
+	return inf.devicePathResolver
+}
+
+func (inf gceInfrastructure) SetupSsh(username string) error {
+	publicKey, err := inf.metadataService.GetPublicKey()
+	if err != nil {
+		return bosherr.WrapError(err, "Error getting public key")
+	}
+
+	return inf.platform.SetupSsh(publicKey, username)
+}
+
+func (inf gceInfrastructure) GetSettings() (boshsettings.Settings, error) {
+	settings, err := inf.registry.GetSettings()
+	if err != nil {
+		return settings, bosherr.WrapError(err, "Getting settings from registry")
+	}
+
+	return settings, nil
+}
+
+func (inf gceInfrastructure) GetRegistryEndpoint() (string, bool) {
+	registryConfig, err := inf.metadataService.GetRegistryConfig()
+	if err != nil {
+		inf.logger.Error(gceInfrastructureLogTag, "Failed to get registry config: %s", err.Error())
+		return "", false
+	}
+
+	return registryConfig.Endpoint, registryConfig.Endpoint != ""
+}
+
+func (inf gceInfrastructure) SetupNetworking(networks boshsettings.Networks) (err error) {
+	return inf.platform.SetupDhcp(networks)
+}
+
+func (inf gceInfrastructure) GetEphemeralDiskPath(devicePath string) (realPath string, found bool) {
+	if devicePath == "" {
+		inf.logger.Info(gceInfrastructureLogTag, "Ephemeral disk path is empty")
+		return "", false
+	}
+
+	return inf.platform.NormalizeDiskPath(devicePath)
+}
@@ -0,0 +1,131 @@
+package infrastructure_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/infrastructure"
+	boshdpresolv "bosh/infrastructure/devicepathresolver"
+	fakeinf "bosh/infrastructure/fakes"
+	boshlog "bosh/logger"
+	fakeplatform "bosh/platform/fakes"
+	boshsettings "bosh/settings"
+)
+
+var _ = Describe("gceInfrastructure", func() {
+	var (
+		ts       *httptest.Server
+		platform *fakeplatform.FakePlatform
+		gce      Infrastructure
+	)
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+
+		mux.HandleFunc("/computeMetadata/v1/", func(w http.ResponseWriter, r *http.Request) {
+			GinkgoRecover()
+
+			Expect(r.Header.Get("Metadata-Flavor")).To(Equal("Google"))
+
+			switch r.URL.Path {
+			case "/computeMetadata/v1/instance/attributes/ssh-keys":
+				w.Write([]byte("vcap:fake-public-key"))
+			case "/computeMetadata/v1/instance/name":
+				w.Write([]byte("fake-instance-name"))
+			case "/computeMetadata/v1/instance/attributes/user_data":
+				w.Write([]byte(fmt.Sprintf(`{"registry":{"endpoint":"%s"}}`, ts.URL)))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+
+		mux.HandleFunc("/instances/fake-instance-name/settings", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"settings": "{\"agent_id\":\"fake-agent-id\"}"}`))
+		})
+
+		ts = httptest.NewServer(mux)
+
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		httpClient := NewHTTPClient(DefaultHTTPClientOptions, logger)
+
+		metadataService := NewGCEMetadataService(ts.URL, &fakeinf.FakeDNSResolver{}, httpClient)
+		registry := NewConcreteRegistry(metadataService, httpClient, true)
+
+		platform = fakeplatform.NewFakePlatform()
+
+		devicePathResolver := boshdpresolv.NewSymlinkDevicePathResolver(
+			time.Millisecond,
+			boshdpresolv.GCEDiskSymlinkFormat,
+			platform.GetFs(),
+		)
+
+		gce = NewGCEInfrastructure(metadataService, registry, platform, devicePathResolver, logger)
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	Describe("SetupSsh", func() {
+		It("sets up ssh with public key from instance attributes", func() {
+			err := gce.SetupSsh("vcap")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(platform.SetupSshPublicKey).To(Equal("fake-public-key"))
+			Expect(platform.SetupSshUsername).To(Equal("vcap"))
+		})
+	})
+
+	Describe("GetSettings", func() {
+		It("gets settings from registry by instance name", func() {
+			settings, err := gce.GetSettings()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(settings).To(Equal(boshsettings.Settings{AgentID: "fake-agent-id"}))
+		})
+	})
+
+	Describe("SetupNetworking", func() {
+		It("sets up DHCP on the platform", func() {
+			networks := boshsettings.Networks{"bosh": boshsettings.Network{}}
+
+			err := gce.SetupNetworking(networks)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(platform.SetupDhcpNetworks).To(Equal(networks))
+		})
+	})
+
+	Describe("GetDevicePathResolver", func() {
+		It("resolves disk device name to device path", func() {
+			platform.GetFs().Symlink("../../sdb", "/dev/disk/by-id/google-fake-disk-name")
+
+			realPath, err := gce.GetDevicePathResolver().GetRealDevicePath("fake-disk-name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(realPath).To(Equal("/dev/sdb"))
+		})
+	})
+
+	Describe("GetEphemeralDiskPath", func() {
+		It("returns false if device path is empty", func() {
+			realPath, found := gce.GetEphemeralDiskPath("")
+			Expect(found).To(BeFalse())
+			Expect(realPath).To(BeEmpty())
+
+			Expect(platform.NormalizeDiskPathCalled).To(BeFalse())
+		})
+
+		It("returns normalized disk path", func() {
+			platform.NormalizeDiskPathRealPath = "/dev/sdb"
+			platform.NormalizeDiskPathFound = true
+
+			realPath, found := gce.GetEphemeralDiskPath("/dev/sdb")
+			Expect(found).To(BeTrue())
+			Expect(realPath).To(Equal("/dev/sdb"))
+		})
+	})
+})
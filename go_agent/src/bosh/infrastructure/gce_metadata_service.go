@@ -0,0 +1,105 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	bosherr "bosh/errors"
+)
+
+const (
+	gceMetadataPath = "/computeMetadata/v1"
+
+	// Requests without this header are rejected by GCE metadata server
+	gceMetadataFlavorHeader = "Metadata-Flavor"
+	gceMetadataFlavor       = "Google"
+)
+
+type gceMetadataService struct {
+	metadataHost string
+	resolver     dnsResolver
+	client       HTTPClient
+}
+
+func NewGCEMetadataService(
+	metadataHost string,
+	resolver dnsResolver,
+	client HTTPClient,
+) gceMetadataService {
+	return gceMetadataService{
+		metadataHost: metadataHost,
+		resolver:     resolver,
+		client:       client,
+	}
+}
+
+// GetPublicKey returns first key from instance ssh-keys attribute.
+// Each line of the attribute has the form of <username>:<public key>.
+func (ms gceMetadataService) GetPublicKey() (string, error) {
+	sshKeys, err := ms.get("instance/attributes/ssh-keys")
+	if err != nil {
+		return "", bosherr.WrapError(err, "Getting ssh keys")
+	}
+
+	for _, line := range strings.Split(string(sshKeys), "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(parts) == 2 && len(parts[1]) > 0 {
+			return parts[1], nil
+		}
+	}
+
+	return "", bosherr.New("No ssh keys found in instance attributes")
+}
+
+func (ms gceMetadataService) GetInstanceID() (string, error) {
+	instanceID, err := ms.get("instance/id")
+	if err != nil {
+		return "", bosherr.WrapError(err, "Getting instance id")
+	}
+
+	return string(instanceID), nil
+}
+
+func (ms gceMetadataService) GetServerName() (string, error) {
+	serverName, err := ms.get("instance/name")
+	if err != nil {
+		return "", bosherr.WrapError(err, "Getting instance name")
+	}
+
+	if len(serverName) == 0 {
+		return "", bosherr.New("Empty server name")
+	}
+
+	return string(serverName), nil
+}
+
+func (ms gceMetadataService) IsAvailable() bool {
+	return true
+}
+
+func (ms gceMetadataService) GetRegistryConfig() (RegistryConfig, error) {
+	var userData userDataType
+
+	userDataBytes, err := ms.get("instance/attributes/user_data")
+	if err != nil {
+		return RegistryConfig{}, bosherr.WrapError(err, "Getting user data")
+	}
+
+	err = json.Unmarshal(userDataBytes, &userData)
+	if err != nil {
+		return RegistryConfig{}, bosherr.WrapError(err, "Unmarshalling user data")
+	}
+
+	return newRegistryConfig(ms.resolver, userData)
+}
+
+func (ms gceMetadataService) get(path string) ([]byte, error) {
+	url := fmt.Sprintf("%s%s/%s", ms.metadataHost, gceMetadataPath, path)
+
+	headers := http.Header{}
+	headers.Set(gceMetadataFlavorHeader, gceMetadataFlavor)
+
+	return ms.client.Get(url, headers)
+}
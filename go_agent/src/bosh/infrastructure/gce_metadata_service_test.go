@@ -0,0 +1,162 @@
+package infrastructure_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/infrastructure"
+	fakeinf "bosh/infrastructure/fakes"
+	boshlog "bosh/logger"
+)
+
+var _ = Describe("gceMetadataService", func() {
+	var (
+		ts              *httptest.Server
+		metadata        map[string]string
+		dnsResolver     *fakeinf.FakeDNSResolver
+		metadataService MetadataService
+	)
+
+	BeforeEach(func() {
+		metadata = map[string]string{}
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			GinkgoRecover()
+
+			Expect(r.Method).To(Equal("GET"))
+
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			value, found := metadata[r.URL.Path]
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.Write([]byte(value))
+		})
+
+		ts = httptest.NewServer(handler)
+
+		dnsResolver = &fakeinf.FakeDNSResolver{}
+		httpClient := NewHTTPClient(DefaultHTTPClientOptions, boshlog.NewLogger(boshlog.LevelNone))
+		metadataService = NewGCEMetadataService(ts.URL, dnsResolver, httpClient)
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	Describe("GetPublicKey", func() {
+		It("returns first public key from instance ssh-keys attribute", func() {
+			metadata["/computeMetadata/v1/instance/attributes/ssh-keys"] = "vcap:fake-public-key-1\nbosh:fake-public-key-2\n"
+
+			publicKey, err := metadataService.GetPublicKey()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(publicKey).To(Equal("fake-public-key-1"))
+		})
+
+		It("returns error if ssh-keys attribute does not contain keys", func() {
+			metadata["/computeMetadata/v1/instance/attributes/ssh-keys"] = "\n"
+
+			_, err := metadataService.GetPublicKey()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("No ssh keys found"))
+		})
+
+		It("returns error if ssh-keys attribute is not found", func() {
+			_, err := metadataService.GetPublicKey()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unexpected status code 404"))
+		})
+	})
+
+	Describe("GetInstanceID", func() {
+		It("returns instance id", func() {
+			metadata["/computeMetadata/v1/instance/id"] = "fake-instance-id"
+
+			instanceID, err := metadataService.GetInstanceID()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(instanceID).To(Equal("fake-instance-id"))
+		})
+	})
+
+	Describe("GetServerName", func() {
+		It("returns instance name", func() {
+			metadata["/computeMetadata/v1/instance/name"] = "fake-instance-name"
+
+			serverName, err := metadataService.GetServerName()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(serverName).To(Equal("fake-instance-name"))
+		})
+
+		It("returns error if instance name is empty", func() {
+			metadata["/computeMetadata/v1/instance/name"] = ""
+
+			_, err := metadataService.GetServerName()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Empty server name"))
+		})
+	})
+
+	Describe("GetRegistryConfig", func() {
+		It("returns registry endpoint from user_data attribute", func() {
+			metadata["/computeMetadata/v1/instance/attributes/user_data"] = `{"registry":{"endpoint":"http://fake-registry.com:8877"}}`
+
+			registryConfig, err := metadataService.GetRegistryConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(registryConfig.Endpoint).To(Equal("http://fake-registry.com:8877"))
+		})
+
+		It("resolves registry endpoint when user data contains dns servers", func() {
+			metadata["/computeMetadata/v1/instance/attributes/user_data"] = `{
+				"registry":{"endpoint":"http://fake-registry.com:8877"},
+				"dns":{"nameserver":["fake-dns-server-ip"]}
+			}`
+
+			dnsResolver.RegisterRecord(fakeinf.FakeDNSRecord{
+				DNSServers: []string{"fake-dns-server-ip"},
+				Host:       "fake-registry.com",
+				IP:         "fake-registry-ip",
+			})
+
+			registryConfig, err := metadataService.GetRegistryConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(registryConfig.Endpoint).To(Equal("http://fake-registry-ip:8877"))
+		})
+
+		It("returns registry credentials and tls options from user_data attribute", func() {
+			metadata["/computeMetadata/v1/instance/attributes/user_data"] = `{"registry":{
+				"endpoint":"https://fake-registry.com:8877",
+				"username":"fake-username",
+				"password":"fake-password",
+				"ca_cert":"fake-ca-cert",
+				"pinned_cert_fingerprints":["fake-fingerprint"]
+			}}`
+
+			registryConfig, err := metadataService.GetRegistryConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(registryConfig).To(Equal(RegistryConfig{
+				Endpoint:               "https://fake-registry.com:8877",
+				Username:               "fake-username",
+				Password:               "fake-password",
+				CACert:                 "fake-ca-cert",
+				PinnedCertFingerprints: []string{"fake-fingerprint"},
+			}))
+		})
+
+		It("returns error if user data cannot be parsed", func() {
+			metadata["/computeMetadata/v1/instance/attributes/user_data"] = "fake-invalid-json"
+
+			_, err := metadataService.GetRegistryConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unmarshalling user data"))
+		})
+	})
+})
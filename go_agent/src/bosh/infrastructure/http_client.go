@@ -0,0 +1,249 @@
+package infrastructure
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	bosherr "bosh/errors"
+	boshlog "bosh/logger"
+)
+
+const httpClientLogTag = "httpClient"
+
+// Only beginning of error response body is included in errors
+const httpErrorBodyExcerptLen = 256
+
+type HTTPClientOptions struct {
+	// Timeout of a single attempt including reading response body
+	RequestTimeout time.Duration
+
+	// Number of times request will be made before giving up
+	MaxAttempts int
+
+	// Delay after first failed attempt; doubled after each next failed attempt
+	InitialBackoff time.Duration
+
+	// Upper limit of delay between attempts
+	MaxBackoff time.Duration
+}
+
+var DefaultHTTPClientOptions = HTTPClientOptions{
+	RequestTimeout: 10 * time.Second,
+	MaxAttempts:    6,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+}
+
+// HTTPResponseError is returned when server responds with non-200 status code
+type HTTPResponseError struct {
+	URL         string
+	StatusCode  int
+	BodyExcerpt string
+}
+
+func (e HTTPResponseError) Error() string {
+	return fmt.Sprintf("Getting %s: Unexpected status code %d: %s", e.URL, e.StatusCode, e.BodyExcerpt)
+}
+
+// Retryable is true for server side errors since they are likely temporary
+func (e HTTPResponseError) Retryable() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+type TLSOptions struct {
+	// PEM encoded certificates used instead of system CAs
+	CACert string
+
+	// Host name to verify certificates against when URL host is an IP
+	ServerName string
+
+	// Hex encoded SHA-256 fingerprints; server certificate must match one of them
+	PinnedCertFingerprints []string
+}
+
+type HTTPClient interface {
+	// Get returns response body of successful GET request
+	// or HTTPResponseError if server responded with unexpected status code
+	Get(url string, headers http.Header) ([]byte, error)
+
+	// WithTLS returns client with the same options that verifies servers with given TLS options
+	WithTLS(options TLSOptions) (HTTPClient, error)
+}
+
+type httpClient struct {
+	client  *http.Client
+	options HTTPClientOptions
+	logger  boshlog.Logger
+}
+
+func NewHTTPClient(options HTTPClientOptions, logger boshlog.Logger) httpClient {
+	return httpClient{
+		client:  &http.Client{Timeout: options.RequestTimeout},
+		options: options,
+		logger:  logger,
+	}
+}
+
+func (c httpClient) WithTLS(options TLSOptions) (HTTPClient, error) {
+	tlsConfig := &tls.Config{ServerName: options.ServerName}
+
+	if len(options.CACert) > 0 {
+		certPool := x509.NewCertPool()
+
+		if !certPool.AppendCertsFromPEM([]byte(options.CACert)) {
+			return nil, bosherr.New("Parsing CA certificates")
+		}
+
+		tlsConfig.RootCAs = certPool
+	}
+
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+
+	if len(options.PinnedCertFingerprints) > 0 {
+		pinnedDialer := pinnedTLSDialer{
+			config:       tlsConfig,
+			fingerprints: normalizeFingerprints(options.PinnedCertFingerprints),
+		}
+		transport.DialTLS = pinnedDialer.Dial
+	}
+
+	return httpClient{
+		client:  &http.Client{Timeout: c.options.RequestTimeout, Transport: transport},
+		options: c.options,
+		logger:  c.logger,
+	}, nil
+}
+
+func (c httpClient) Get(url string, headers http.Header) ([]byte, error) {
+	var lastErr error
+
+	backoff := c.options.InitialBackoff
+
+	for attempt := 1; attempt <= c.options.MaxAttempts; attempt++ {
+		body, err := c.get(url, headers)
+		if err == nil {
+			return body, nil
+		}
+
+		lastErr = err
+
+		respErr, isRespErr := err.(HTTPResponseError)
+		if isRespErr && !respErr.Retryable() {
+			break
+		}
+
+		if attempt == c.options.MaxAttempts {
+			break
+		}
+
+		c.logger.Info(httpClientLogTag, "Failed attempt %d of %d: %s", attempt, c.options.MaxAttempts, err.Error())
+
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > c.options.MaxBackoff {
+			backoff = c.options.MaxBackoff
+		}
+	}
+
+	if _, isRespErr := lastErr.(HTTPResponseError); isRespErr {
+		return nil, lastErr
+	}
+
+	return nil, bosherr.WrapError(lastErr, "Getting %s", url)
+}
+
+func (c httpClient) get(url string, headers http.Header) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Building request")
+	}
+
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		excerpt, _ := ioutil.ReadAll(io.LimitReader(resp.Body, httpErrorBodyExcerptLen))
+		return nil, HTTPResponseError{URL: url, StatusCode: resp.StatusCode, BodyExcerpt: string(excerpt)}
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Reading response body")
+	}
+
+	return body, nil
+}
+
+// pinnedTLSDialer verifies server certificate fingerprint
+// right after handshake so that no request is sent to untrusted server
+type pinnedTLSDialer struct {
+	config       *tls.Config
+	fingerprints []string
+}
+
+func (d pinnedTLSDialer) Dial(network, addr string) (net.Conn, error) {
+	config := d.config.Clone()
+
+	if len(config.ServerName) == 0 {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Splitting host and port")
+		}
+
+		config.ServerName = host
+	}
+
+	conn, err := tls.Dial(network, addr, config)
+	if err != nil {
+		return nil, err
+	}
+
+	peerCerts := conn.ConnectionState().PeerCertificates
+	if len(peerCerts) > 0 {
+		fingerprint := sha256.Sum256(peerCerts[0].Raw)
+		actualFingerprint := hex.EncodeToString(fingerprint[:])
+
+		for _, pinnedFingerprint := range d.fingerprints {
+			if pinnedFingerprint == actualFingerprint {
+				return conn, nil
+			}
+		}
+	}
+
+	conn.Close()
+
+	return nil, bosherr.New("Server certificate for %s does not match pinned fingerprints", addr)
+}
+
+// normalizeFingerprints allows fingerprints in AA:BB:.. form
+func normalizeFingerprints(fingerprints []string) []string {
+	var normalized []string
+
+	for _, fingerprint := range fingerprints {
+		fingerprint = strings.Replace(fingerprint, ":", "", -1)
+		normalized = append(normalized, strings.ToLower(fingerprint))
+	}
+
+	return normalized
+}
@@ -0,0 +1,128 @@
+package infrastructure_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/infrastructure"
+	boshlog "bosh/logger"
+)
+
+var _ = Describe("httpClient", func() {
+	var (
+		ts       *httptest.Server
+		attempts int32
+		handler  func(attempt int32, w http.ResponseWriter, r *http.Request)
+		options  HTTPClientOptions
+		client   HTTPClient
+	)
+
+	BeforeEach(func() {
+		attempts = 0
+
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(atomic.AddInt32(&attempts, 1), w, r)
+		}))
+
+		options = HTTPClientOptions{
+			RequestTimeout: 100 * time.Millisecond,
+			MaxAttempts:    4,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     2 * time.Millisecond,
+		}
+	})
+
+	JustBeforeEach(func() {
+		client = NewHTTPClient(options, boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	Describe("Get", func() {
+		It("returns response body and sends given headers", func() {
+			handler = func(_ int32, w http.ResponseWriter, r *http.Request) {
+				GinkgoRecover()
+				Expect(r.Header.Get("X-Fake-Header")).To(Equal("fake-value"))
+				w.Write([]byte("fake-body"))
+			}
+
+			body, err := client.Get(ts.URL, http.Header{"X-Fake-Header": []string{"fake-value"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(Equal("fake-body"))
+			Expect(atomic.LoadInt32(&attempts)).To(Equal(int32(1)))
+		})
+
+		It("retries while server is flapping with server errors", func() {
+			handler = func(attempt int32, w http.ResponseWriter, r *http.Request) {
+				if attempt < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte("fake-body"))
+			}
+
+			body, err := client.Get(ts.URL, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(Equal("fake-body"))
+			Expect(atomic.LoadInt32(&attempts)).To(Equal(int32(3)))
+		})
+
+		It("retries requests that time out", func() {
+			handler = func(attempt int32, w http.ResponseWriter, r *http.Request) {
+				if attempt == 1 {
+					time.Sleep(300 * time.Millisecond)
+				}
+				w.Write([]byte("fake-body"))
+			}
+
+			body, err := client.Get(ts.URL, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(Equal("fake-body"))
+			Expect(atomic.LoadInt32(&attempts)).To(Equal(int32(2)))
+		})
+
+		It("gives up after max attempts and returns last error with body excerpt", func() {
+			handler = func(_ int32, w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte("fake-internal-error"))
+			}
+
+			_, err := client.Get(ts.URL, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unexpected status code 500: fake-internal-error"))
+			Expect(atomic.LoadInt32(&attempts)).To(Equal(int32(4)))
+		})
+
+		It("does not retry client errors", func() {
+			handler = func(_ int32, w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("fake-not-found"))
+			}
+
+			_, err := client.Get(ts.URL, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unexpected status code 404: fake-not-found"))
+			Expect(atomic.LoadInt32(&attempts)).To(Equal(int32(1)))
+		})
+
+		It("includes only beginning of long response body in error", func() {
+			handler = func(_ int32, w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(strings.Repeat("a", 300) + "fake-end-of-body"))
+			}
+
+			_, err := client.Get(ts.URL, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(strings.Repeat("a", 256)))
+			Expect(err.Error()).ToNot(ContainSubstring("fake-end-of-body"))
+		})
+	})
+})
@@ -9,6 +9,10 @@ type Infrastructure interface {
 	SetupSsh(username string) (err error)
 	GetSettings() (settings boshsettings.Settings, err error)
 	SetupNetworking(networks boshsettings.Networks) (err error)
+
+	// GetRegistryEndpoint returns false when settings do not come from registry
+	GetRegistryEndpoint() (endpoint string, found bool)
+
 	GetEphemeralDiskPath(devicePath string) (realPath string, found bool)
 	GetDevicePathResolver() (devicePathResolver boshdpresolv.DevicePathResolver)
 }
@@ -4,5 +4,8 @@ type MetadataService interface {
 	GetPublicKey() (string, error)
 	GetInstanceID() (string, error)
 	GetServerName() (string, error)
-	GetRegistryEndpoint() (string, error)
+	GetRegistryConfig() (RegistryConfig, error)
+
+	// IsAvailable returns false when metadata source is not present on the machine
+	IsAvailable() bool
 }
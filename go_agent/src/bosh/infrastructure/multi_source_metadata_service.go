@@ -0,0 +1,47 @@
+package infrastructure
+
+type multiSourceMetadataService struct {
+	services []MetadataService
+}
+
+// NewMultiSourceMetadataService returns metadata service that delegates
+// to the first available service; the last one is used if none are available
+func NewMultiSourceMetadataService(services ...MetadataService) multiSourceMetadataService {
+	return multiSourceMetadataService{services: services}
+}
+
+func (ms multiSourceMetadataService) GetPublicKey() (string, error) {
+	return ms.selected().GetPublicKey()
+}
+
+func (ms multiSourceMetadataService) GetInstanceID() (string, error) {
+	return ms.selected().GetInstanceID()
+}
+
+func (ms multiSourceMetadataService) GetServerName() (string, error) {
+	return ms.selected().GetServerName()
+}
+
+func (ms multiSourceMetadataService) GetRegistryConfig() (RegistryConfig, error) {
+	return ms.selected().GetRegistryConfig()
+}
+
+func (ms multiSourceMetadataService) IsAvailable() bool {
+	for _, service := range ms.services {
+		if service.IsAvailable() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ms multiSourceMetadataService) selected() MetadataService {
+	for _, service := range ms.services {
+		if service.IsAvailable() {
+			return service
+		}
+	}
+
+	return ms.services[len(ms.services)-1]
+}
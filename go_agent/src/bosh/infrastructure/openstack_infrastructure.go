@@ -55,6 +55,16 @@ func (inf openstackInfrastructure) GetSettings() (boshsettings.Settings, error)
 	return settings, nil
 }
 
+func (inf openstackInfrastructure) GetRegistryEndpoint() (string, bool) {
+	registryConfig, err := inf.metadataService.GetRegistryConfig()
+	if err != nil {
+		inf.logger.Error(openstackInfrastructureLogTag, "Failed to get registry config: %s", err.Error())
+		return "", false
+	}
+
+	return registryConfig.Endpoint, registryConfig.Endpoint != ""
+}
+
 func (inf openstackInfrastructure) SetupNetworking(networks boshsettings.Networks) (err error) {
 	return inf.platform.SetupDhcp(networks)
 }
@@ -0,0 +1,75 @@
+package infrastructure
+
+import (
+	"time"
+)
+
+const (
+	// Settings are looked up in registry by instance id reported by metadata service
+	IDStrategyInstanceID = "instance-id"
+
+	// Settings are looked up in registry by server name found in user data
+	IDStrategyServerName = "server-name"
+)
+
+const (
+	SettingsSourceHTTP        = "http"
+	SettingsSourceConfigDrive = "config-drive"
+)
+
+const defaultMetadataURL = "http://169.254.169.254"
+
+type ProviderOptions struct {
+	AWS       Options
+	OpenStack Options
+	GCE       Options
+	Azure     Options
+	Vsphere   Options
+	Warden    Options
+}
+
+// Options override infrastructure defaults; zero values keep defaults
+type Options struct {
+	// Base URL of HTTP metadata service
+	MetadataURL string
+
+	// Either IDStrategyInstanceID or IDStrategyServerName
+	IDStrategy string
+
+	// How long to wait for disk device to show up before giving up
+	DiskWaitTimeoutMs int
+
+	// Sources of metadata and user data; first available source is used
+	SettingsSources []string
+
+	// Source of SSH public key; defaults to the source used for settings
+	SSHKeySource string
+}
+
+func (o Options) DiskWaitTimeout() time.Duration {
+	return time.Duration(o.DiskWaitTimeoutMs) * time.Millisecond
+}
+
+func (o Options) withDefaults(defaults Options) Options {
+	if len(o.MetadataURL) == 0 {
+		o.MetadataURL = defaults.MetadataURL
+	}
+
+	if len(o.IDStrategy) == 0 {
+		o.IDStrategy = defaults.IDStrategy
+	}
+
+	if o.DiskWaitTimeoutMs == 0 {
+		o.DiskWaitTimeoutMs = defaults.DiskWaitTimeoutMs
+	}
+
+	if len(o.SettingsSources) == 0 {
+		o.SettingsSources = defaults.SettingsSources
+	}
+
+	if len(o.SSHKeySource) == 0 {
+		o.SSHKeySource = defaults.SSHKeySource
+	}
+
+	return o
+}
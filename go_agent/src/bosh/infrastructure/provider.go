@@ -1,8 +1,6 @@
 package infrastructure
 
 import (
-	"time"
-
 	bosherr "bosh/errors"
 	boshdpresolv "bosh/infrastructure/devicepathresolver"
 	boshlog "bosh/logger"
@@ -11,56 +9,217 @@ import (
 
 type Provider struct {
 	infrastructures map[string]Infrastructure
-}
 
-func NewProvider(logger boshlog.Logger, platform boshplatform.Platform) (p Provider) {
-	metadataService := NewConcreteMetadataService(
-		"http://169.254.169.254",
-		NewDigDNSResolver(logger),
-	)
+	// Infrastructures that could not be built because of invalid options
+	optionsErrs map[string]error
+}
 
-	// Currently useServerNameAsID boolean setting is hard coded below
-	// because we do not support arbitrary infrastructure configurations
-	awsRegistry := NewConcreteRegistry(metadataService, false)
-	openstackRegistry := NewConcreteRegistry(metadataService, true)
+func NewProvider(logger boshlog.Logger, platform boshplatform.Platform, options ProviderOptions) (p Provider) {
+	httpClient := NewHTTPClient(DefaultHTTPClientOptions, logger)
 
 	fs := platform.GetFs()
+	runner := platform.GetRunner()
 	dirProvider := platform.GetDirProvider()
 
-	mappedDevicePathResolver := boshdpresolv.NewMappedDevicePathResolver(500*time.Millisecond, fs)
-	vsphereDevicePathResolver := boshdpresolv.NewVsphereDevicePathResolver(500*time.Millisecond, fs)
-	dummyDevicePathResolver := boshdpresolv.NewDummyDevicePathResolver()
-
-	awsInfrastructure := NewAwsInfrastructure(
-		metadataService,
-		awsRegistry,
+	configDriveMetadataService := NewConfigDriveMetadataService(
+		NewDigDNSResolver(logger),
 		platform,
-		mappedDevicePathResolver,
+		[]string{"/dev/disk/by-label/CONFIG-2", "/dev/disk/by-label/config-2"},
+		"openstack/latest/meta_data.json",
+		"openstack/latest/user_data",
 		logger,
 	)
 
-	openstackInfrastructure := NewOpenstackInfrastructure(
-		metadataService,
-		openstackRegistry,
-		platform,
-		mappedDevicePathResolver,
-		logger,
+	awsOptions := options.AWS.withDefaults(Options{
+		MetadataURL:       defaultMetadataURL,
+		IDStrategy:        IDStrategyInstanceID,
+		DiskWaitTimeoutMs: 500,
+		SettingsSources:   []string{SettingsSourceHTTP},
+	})
+
+	// OpenStack prefers config drive when present and falls back to HTTP metadata service
+	openstackOptions := options.OpenStack.withDefaults(Options{
+		MetadataURL:       defaultMetadataURL,
+		IDStrategy:        IDStrategyServerName,
+		DiskWaitTimeoutMs: 500,
+		SettingsSources:   []string{SettingsSourceConfigDrive, SettingsSourceHTTP},
+	})
+
+	gceOptions := options.GCE.withDefaults(Options{
+		MetadataURL:       defaultMetadataURL,
+		IDStrategy:        IDStrategyServerName,
+		DiskWaitTimeoutMs: 500,
+		SettingsSources:   []string{SettingsSourceHTTP},
+	})
+
+	azureOptions := options.Azure.withDefaults(Options{
+		MetadataURL:       defaultMetadataURL,
+		IDStrategy:        IDStrategyServerName,
+		DiskWaitTimeoutMs: 500,
+		SettingsSources:   []string{SettingsSourceHTTP},
+	})
+
+	vsphereOptions := options.Vsphere.withDefaults(Options{
+		DiskWaitTimeoutMs: 500,
+	})
+
+	wardenOptions := options.Warden.withDefaults(Options{
+		DiskWaitTimeoutMs: 500,
+	})
+
+	// Volume IDs are matched against disk serials; device paths fall back to guessing by name
+	awsDevicePathResolver := boshdpresolv.NewChainDevicePathResolver(
+		boshdpresolv.NewIDDevicePathResolver(awsOptions.DiskWaitTimeout(), runner, fs),
+		boshdpresolv.NewMappedDevicePathResolver(awsOptions.DiskWaitTimeout(), fs),
 	)
+	openstackDevicePathResolver := boshdpresolv.NewChainDevicePathResolver(
+		boshdpresolv.NewIDDevicePathResolver(openstackOptions.DiskWaitTimeout(), runner, fs),
+		boshdpresolv.NewMappedDevicePathResolver(openstackOptions.DiskWaitTimeout(), fs),
+	)
+	vsphereDevicePathResolver := boshdpresolv.NewVsphereDevicePathResolver(vsphereOptions.DiskWaitTimeout(), fs)
+	gceDevicePathResolver := boshdpresolv.NewSymlinkDevicePathResolver(gceOptions.DiskWaitTimeout(), boshdpresolv.GCEDiskSymlinkFormat, fs)
+	azureDevicePathResolver := boshdpresolv.NewSymlinkDevicePathResolver(azureOptions.DiskWaitTimeout(), boshdpresolv.AzureDiskSymlinkFormat, fs)
+	wardenDevicePathResolver := boshdpresolv.NewDirectoryDevicePathResolver(wardenOptions.DiskWaitTimeout(), fs)
+	dummyDevicePathResolver := boshdpresolv.NewDummyDevicePathResolver()
 
 	p.infrastructures = map[string]Infrastructure{
-		"aws":       awsInfrastructure,
-		"openstack": openstackInfrastructure,
-		"dummy":     NewDummyInfrastructure(fs, dirProvider, platform, dummyDevicePathResolver),
-		"warden":    NewWardenInfrastructure(dirProvider, platform, dummyDevicePathResolver),
-		"vsphere":   NewVsphereInfrastructure(platform, vsphereDevicePathResolver, logger),
+		"dummy":   NewDummyInfrastructure(fs, dirProvider, platform, dummyDevicePathResolver),
+		"warden":  NewWardenInfrastructure(dirProvider, platform, wardenDevicePathResolver),
+		"vsphere": NewVsphereInfrastructure(platform, vsphereDevicePathResolver, logger),
+	}
+
+	p.optionsErrs = map[string]error{}
+
+	awsSources := settingsSources{
+		options:    awsOptions,
+		httpClient: httpClient,
+		services: map[string]MetadataService{
+			SettingsSourceHTTP:        NewConcreteMetadataService(awsOptions.MetadataURL, NewDigDNSResolver(logger), httpClient),
+			SettingsSourceConfigDrive: configDriveMetadataService,
+		},
+	}
+
+	p.add("aws", awsSources, func(metadataService MetadataService, registry Registry) Infrastructure {
+		return NewAwsInfrastructure(metadataService, registry, platform, awsDevicePathResolver, logger)
+	})
+
+	openstackSources := settingsSources{
+		options:    openstackOptions,
+		httpClient: httpClient,
+		services: map[string]MetadataService{
+			SettingsSourceHTTP:        NewConcreteMetadataService(openstackOptions.MetadataURL, NewDigDNSResolver(logger), httpClient),
+			SettingsSourceConfigDrive: configDriveMetadataService,
+		},
+	}
+
+	p.add("openstack", openstackSources, func(metadataService MetadataService, registry Registry) Infrastructure {
+		return NewOpenstackInfrastructure(metadataService, registry, platform, openstackDevicePathResolver, logger)
+	})
+
+	gceSources := settingsSources{
+		options:    gceOptions,
+		httpClient: httpClient,
+		services: map[string]MetadataService{
+			SettingsSourceHTTP:        NewGCEMetadataService(gceOptions.MetadataURL, NewDigDNSResolver(logger), httpClient),
+			SettingsSourceConfigDrive: configDriveMetadataService,
+		},
+	}
+
+	p.add("gce", gceSources, func(metadataService MetadataService, registry Registry) Infrastructure {
+		return NewGCEInfrastructure(metadataService, registry, platform, gceDevicePathResolver, logger)
+	})
+
+	azureSources := settingsSources{
+		options:    azureOptions,
+		httpClient: httpClient,
+		services: map[string]MetadataService{
+			SettingsSourceHTTP:        NewAzureMetadataService(azureOptions.MetadataURL, NewDigDNSResolver(logger), httpClient),
+			SettingsSourceConfigDrive: configDriveMetadataService,
+		},
 	}
+
+	p.add("azure", azureSources, func(metadataService MetadataService, registry Registry) Infrastructure {
+		return NewAzureInfrastructure(metadataService, registry, platform, azureDevicePathResolver, logger)
+	})
+
 	return
 }
 
 func (p Provider) Get(name string) (Infrastructure, error) {
+	if err, found := p.optionsErrs[name]; found {
+		return nil, bosherr.WrapError(err, "Configuring infrastructure %s", name)
+	}
+
 	inf, found := p.infrastructures[name]
 	if !found {
 		return nil, bosherr.New("Infrastructure %s could not be found", name)
 	}
 	return inf, nil
 }
+
+func (p Provider) add(
+	name string,
+	sources settingsSources,
+	buildFunc func(MetadataService, Registry) Infrastructure,
+) {
+	metadataService, registry, err := sources.build()
+	if err != nil {
+		p.optionsErrs[name] = err
+		return
+	}
+
+	p.infrastructures[name] = buildFunc(metadataService, registry)
+}
+
+type settingsSources struct {
+	options    Options
+	httpClient HTTPClient
+	services   map[string]MetadataService
+}
+
+// build returns metadata service used by infrastructure
+// and registry configured according to infrastructure options
+func (s settingsSources) build() (MetadataService, Registry, error) {
+	var services []MetadataService
+
+	for _, sourceName := range s.options.SettingsSources {
+		service, found := s.services[sourceName]
+		if !found {
+			return nil, nil, bosherr.New("Unknown settings source %s", sourceName)
+		}
+
+		services = append(services, service)
+	}
+
+	var metadataService MetadataService
+
+	if len(services) == 1 {
+		metadataService = services[0]
+	} else {
+		metadataService = NewMultiSourceMetadataService(services...)
+	}
+
+	var useServerNameAsID bool
+
+	switch s.options.IDStrategy {
+	case IDStrategyInstanceID:
+		useServerNameAsID = false
+	case IDStrategyServerName:
+		useServerNameAsID = true
+	default:
+		return nil, nil, bosherr.New("Unknown ID strategy %s", s.options.IDStrategy)
+	}
+
+	registry := NewConcreteRegistry(metadataService, s.httpClient, useServerNameAsID)
+
+	if len(s.options.SSHKeySource) > 0 {
+		publicKeyService, found := s.services[s.options.SSHKeySource]
+		if !found {
+			return nil, nil, bosherr.New("Unknown SSH key source %s", s.options.SSHKeySource)
+		}
+
+		metadataService = NewPublicKeyMetadataService(metadataService, publicKeyService)
+	}
+
+	return metadataService, registry, nil
+}
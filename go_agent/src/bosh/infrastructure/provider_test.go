@@ -17,12 +17,15 @@ var _ = Describe("Provider", func() {
 		logger   boshlog.Logger
 		platform *fakeplatform.FakePlatform
 		provider Provider
+
+		httpClient HTTPClient
 	)
 
 	BeforeEach(func() {
 		platform = fakeplatform.NewFakePlatform()
 		logger = boshlog.NewLogger(boshlog.LevelNone)
-		provider = NewProvider(logger, platform)
+		provider = NewProvider(logger, platform, ProviderOptions{})
+		httpClient = NewHTTPClient(DefaultHTTPClientOptions, logger)
 	})
 
 	Describe("Get", func() {
@@ -30,13 +33,14 @@ var _ = Describe("Provider", func() {
 			metadataService := NewConcreteMetadataService(
 				"http://169.254.169.254",
 				NewDigDNSResolver(logger),
+				httpClient,
 			)
 
-			registry := NewConcreteRegistry(metadataService, false)
+			registry := NewConcreteRegistry(metadataService, httpClient, false)
 
-			expectedDevicePathResolver := boshdpresolv.NewMappedDevicePathResolver(
-				500*time.Millisecond,
-				platform.GetFs(),
+			expectedDevicePathResolver := boshdpresolv.NewChainDevicePathResolver(
+				boshdpresolv.NewIDDevicePathResolver(500*time.Millisecond, platform.GetRunner(), platform.GetFs()),
+				boshdpresolv.NewMappedDevicePathResolver(500*time.Millisecond, platform.GetFs()),
 			)
 
 			expectedInf := NewAwsInfrastructure(
@@ -53,19 +57,58 @@ var _ = Describe("Provider", func() {
 		})
 
 		It("returns openstack infrastructure", func() {
-			metadataService := NewConcreteMetadataService(
+			metadataService := NewMultiSourceMetadataService(
+				NewConfigDriveMetadataService(
+					NewDigDNSResolver(logger),
+					platform,
+					[]string{"/dev/disk/by-label/CONFIG-2", "/dev/disk/by-label/config-2"},
+					"openstack/latest/meta_data.json",
+					"openstack/latest/user_data",
+					logger,
+				),
+				NewConcreteMetadataService(
+					"http://169.254.169.254",
+					NewDigDNSResolver(logger),
+					httpClient,
+				),
+			)
+
+			registry := NewConcreteRegistry(metadataService, httpClient, true)
+
+			expectedDevicePathResolver := boshdpresolv.NewChainDevicePathResolver(
+				boshdpresolv.NewIDDevicePathResolver(500*time.Millisecond, platform.GetRunner(), platform.GetFs()),
+				boshdpresolv.NewMappedDevicePathResolver(500*time.Millisecond, platform.GetFs()),
+			)
+
+			expectedInf := NewOpenstackInfrastructure(
+				metadataService,
+				registry,
+				platform,
+				expectedDevicePathResolver,
+				logger,
+			)
+
+			inf, err := provider.Get("openstack")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(inf).To(Equal(expectedInf))
+		})
+
+		It("returns gce infrastructure", func() {
+			metadataService := NewGCEMetadataService(
 				"http://169.254.169.254",
 				NewDigDNSResolver(logger),
+				httpClient,
 			)
 
-			registry := NewConcreteRegistry(metadataService, true)
+			registry := NewConcreteRegistry(metadataService, httpClient, true)
 
-			expectedDevicePathResolver := boshdpresolv.NewMappedDevicePathResolver(
+			expectedDevicePathResolver := boshdpresolv.NewSymlinkDevicePathResolver(
 				500*time.Millisecond,
+				boshdpresolv.GCEDiskSymlinkFormat,
 				platform.GetFs(),
 			)
 
-			expectedInf := NewOpenstackInfrastructure(
+			expectedInf := NewGCEInfrastructure(
 				metadataService,
 				registry,
 				platform,
@@ -73,7 +116,35 @@ var _ = Describe("Provider", func() {
 				logger,
 			)
 
-			inf, err := provider.Get("openstack")
+			inf, err := provider.Get("gce")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(inf).To(Equal(expectedInf))
+		})
+
+		It("returns azure infrastructure", func() {
+			metadataService := NewAzureMetadataService(
+				"http://169.254.169.254",
+				NewDigDNSResolver(logger),
+				httpClient,
+			)
+
+			registry := NewConcreteRegistry(metadataService, httpClient, true)
+
+			expectedDevicePathResolver := boshdpresolv.NewSymlinkDevicePathResolver(
+				500*time.Millisecond,
+				boshdpresolv.AzureDiskSymlinkFormat,
+				platform.GetFs(),
+			)
+
+			expectedInf := NewAzureInfrastructure(
+				metadataService,
+				registry,
+				platform,
+				expectedDevicePathResolver,
+				logger,
+			)
+
+			inf, err := provider.Get("azure")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(inf).To(Equal(expectedInf))
 		})
@@ -107,7 +178,10 @@ var _ = Describe("Provider", func() {
 		})
 
 		It("returns warden infrastructure", func() {
-			expectedDevicePathResolver := boshdpresolv.NewDummyDevicePathResolver()
+			expectedDevicePathResolver := boshdpresolv.NewDirectoryDevicePathResolver(
+				500*time.Millisecond,
+				platform.GetFs(),
+			)
 
 			expectedInf := NewWardenInfrastructure(
 				platform.GetDirProvider(),
@@ -124,5 +198,112 @@ var _ = Describe("Provider", func() {
 			_, err := provider.Get("some unknown infrastructure name")
 			Expect(err).To(HaveOccurred())
 		})
+
+		Context("when infrastructure options are given", func() {
+			It("returns aws infrastructure configured with options", func() {
+				provider = NewProvider(logger, platform, ProviderOptions{
+					AWS: Options{
+						MetadataURL:       "http://fake-metadata-host",
+						IDStrategy:        IDStrategyServerName,
+						DiskWaitTimeoutMs: 3000,
+						SettingsSources:   []string{SettingsSourceConfigDrive, SettingsSourceHTTP},
+						SSHKeySource:      SettingsSourceHTTP,
+					},
+				})
+
+				httpMetadataService := NewConcreteMetadataService(
+					"http://fake-metadata-host",
+					NewDigDNSResolver(logger),
+					httpClient,
+				)
+
+				settingsMetadataService := NewMultiSourceMetadataService(
+					NewConfigDriveMetadataService(
+						NewDigDNSResolver(logger),
+						platform,
+						[]string{"/dev/disk/by-label/CONFIG-2", "/dev/disk/by-label/config-2"},
+						"openstack/latest/meta_data.json",
+						"openstack/latest/user_data",
+						logger,
+					),
+					httpMetadataService,
+				)
+
+				registry := NewConcreteRegistry(settingsMetadataService, httpClient, true)
+
+				expectedDevicePathResolver := boshdpresolv.NewChainDevicePathResolver(
+					boshdpresolv.NewIDDevicePathResolver(3*time.Second, platform.GetRunner(), platform.GetFs()),
+					boshdpresolv.NewMappedDevicePathResolver(3*time.Second, platform.GetFs()),
+				)
+
+				expectedInf := NewAwsInfrastructure(
+					NewPublicKeyMetadataService(settingsMetadataService, httpMetadataService),
+					registry,
+					platform,
+					expectedDevicePathResolver,
+					logger,
+				)
+
+				inf, err := provider.Get("aws")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(inf).To(Equal(expectedInf))
+			})
+
+			It("returns vsphere infrastructure with configured disk wait timeout", func() {
+				provider = NewProvider(logger, platform, ProviderOptions{
+					Vsphere: Options{DiskWaitTimeoutMs: 2000},
+				})
+
+				expectedDevicePathResolver := boshdpresolv.NewVsphereDevicePathResolver(
+					2*time.Second,
+					platform.GetFs(),
+				)
+
+				expectedInf := NewVsphereInfrastructure(platform, expectedDevicePathResolver, logger)
+
+				inf, err := provider.Get("vsphere")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(inf).To(Equal(expectedInf))
+			})
+
+			It("returns an error when settings source is unknown", func() {
+				provider = NewProvider(logger, platform, ProviderOptions{
+					GCE: Options{SettingsSources: []string{"fake-source"}},
+				})
+
+				_, err := provider.Get("gce")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Unknown settings source fake-source"))
+			})
+
+			It("returns an error when id strategy is unknown", func() {
+				provider = NewProvider(logger, platform, ProviderOptions{
+					OpenStack: Options{IDStrategy: "fake-strategy"},
+				})
+
+				_, err := provider.Get("openstack")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Unknown ID strategy fake-strategy"))
+			})
+
+			It("returns an error when ssh key source is unknown", func() {
+				provider = NewProvider(logger, platform, ProviderOptions{
+					Azure: Options{SSHKeySource: "fake-source"},
+				})
+
+				_, err := provider.Get("azure")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Unknown SSH key source fake-source"))
+			})
+
+			It("does not affect other infrastructures when options are invalid", func() {
+				provider = NewProvider(logger, platform, ProviderOptions{
+					Azure: Options{SSHKeySource: "fake-source"},
+				})
+
+				_, err := provider.Get("aws")
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
 	})
 })
@@ -0,0 +1,19 @@
+package infrastructure
+
+type publicKeyMetadataService struct {
+	MetadataService
+	publicKeyService MetadataService
+}
+
+// NewPublicKeyMetadataService returns metadata service that fetches
+// public key from publicKeyService and everything else from metadataService
+func NewPublicKeyMetadataService(metadataService, publicKeyService MetadataService) publicKeyMetadataService {
+	return publicKeyMetadataService{
+		MetadataService:  metadataService,
+		publicKeyService: publicKeyService,
+	}
+}
+
+func (ms publicKeyMetadataService) GetPublicKey() (string, error) {
+	return ms.publicKeyService.GetPublicKey()
+}
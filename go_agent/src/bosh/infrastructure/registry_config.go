@@ -0,0 +1,78 @@
+package infrastructure
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	bosherr "bosh/errors"
+)
+
+type RegistryConfig struct {
+	Endpoint string
+
+	// Credentials are either given explicitly or as part of endpoint URL
+	Username string
+	Password string
+
+	// PEM encoded certificates used instead of system CAs to verify registry
+	CACert string
+
+	// Registry host name when endpoint host was resolved to IP
+	ServerName string
+
+	// Hex encoded SHA-256 fingerprints of accepted registry certificates
+	PinnedCertFingerprints []string
+}
+
+func newRegistryConfig(resolver dnsResolver, userData userDataType) (RegistryConfig, error) {
+	config := RegistryConfig{
+		Endpoint:               userData.Registry.Endpoint,
+		Username:               userData.Registry.Username,
+		Password:               userData.Registry.Password,
+		CACert:                 userData.Registry.CACert,
+		PinnedCertFingerprints: userData.Registry.PinnedCertFingerprints,
+	}
+
+	nameServers := userData.DNS.Nameserver
+
+	if len(nameServers) > 0 {
+		endpoint, err := resolveRegistryEndpoint(resolver, config.Endpoint, nameServers)
+		if err != nil {
+			return config, bosherr.WrapError(err, "Resolving registry endpoint")
+		}
+
+		namedURL, err := url.Parse(config.Endpoint)
+		if err != nil {
+			return config, bosherr.New("Parsing registry named endpoint: invalid URL")
+		}
+
+		config.Endpoint = endpoint
+		config.ServerName = strings.Split(namedURL.Host, ":")[0]
+	}
+
+	return config, nil
+}
+
+// resolveRegistryEndpoint replaces registry host with IP found by using given name servers
+func resolveRegistryEndpoint(resolver dnsResolver, namedEndpoint string, nameServers []string) (string, error) {
+	// Parse error is not wrapped since it includes the URL and hence credentials
+	registryURL, err := url.Parse(namedEndpoint)
+	if err != nil {
+		return "", bosherr.New("Parsing registry named endpoint: invalid URL")
+	}
+
+	registryHostAndPort := strings.Split(registryURL.Host, ":")
+	registryIP, err := resolver.LookupHost(nameServers, registryHostAndPort[0])
+	if err != nil {
+		return "", bosherr.WrapError(err, "Looking up registry")
+	}
+
+	if len(registryHostAndPort) == 2 {
+		registryURL.Host = fmt.Sprintf("%s:%s", registryIP, registryHostAndPort[1])
+	} else {
+		registryURL.Host = registryIP
+	}
+
+	return registryURL.String(), nil
+}
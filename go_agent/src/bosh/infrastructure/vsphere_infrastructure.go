@@ -57,6 +57,10 @@ func (inf vsphereInfrastructure) GetSettings() (boshsettings.Settings, error) {
 	return settings, nil
 }
 
+func (inf vsphereInfrastructure) GetRegistryEndpoint() (string, bool) {
+	return "", false
+}
+
 func (inf vsphereInfrastructure) SetupNetworking(networks boshsettings.Networks) error {
 	return inf.platform.SetupManualNetworking(networks)
 }
@@ -54,10 +54,19 @@ func (inf wardenInfrastructure) GetSettings() (boshsettings.Settings, error) {
 	return settings, nil
 }
 
+func (inf wardenInfrastructure) GetRegistryEndpoint() (string, bool) {
+	return "", false
+}
+
 func (inf wardenInfrastructure) SetupNetworking(networks boshsettings.Networks) error {
 	return nil
 }
 
 func (inf wardenInfrastructure) GetEphemeralDiskPath(devicePath string) (string, bool) {
+	// Containers do not get ephemeral disk; root file system is used instead
+	if devicePath == "" {
+		return "", true
+	}
+
 	return inf.platform.NormalizeDiskPath(devicePath)
 }
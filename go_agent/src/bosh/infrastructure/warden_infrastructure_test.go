@@ -17,16 +17,42 @@ import (
 
 var _ = Describe("wardenInfrastructure", func() {
 	var (
-		platform    *fakeplatform.FakePlatform
-		dirProvider boshdir.DirectoriesProvider
-		inf         Infrastructure
+		platform           *fakeplatform.FakePlatform
+		dirProvider        boshdir.DirectoriesProvider
+		devicePathResolver *fakedpresolv.FakeDevicePathResolver
+		inf                Infrastructure
 	)
 
 	BeforeEach(func() {
 		dirProvider = boshdir.NewDirectoriesProvider("/var/vcap")
 		platform = fakeplatform.NewFakePlatform()
-		fakeDevicePathResolver := fakedpresolv.NewFakeDevicePathResolver()
-		inf = NewWardenInfrastructure(dirProvider, platform, fakeDevicePathResolver)
+		devicePathResolver = fakedpresolv.NewFakeDevicePathResolver()
+		inf = NewWardenInfrastructure(dirProvider, platform, devicePathResolver)
+	})
+
+	Describe("GetDevicePathResolver", func() {
+		It("returns given device path resolver", func() {
+			Expect(inf.GetDevicePathResolver()).To(Equal(devicePathResolver))
+		})
+	})
+
+	Describe("GetEphemeralDiskPath", func() {
+		It("returns empty path when there is no ephemeral disk", func() {
+			realPath, found := inf.GetEphemeralDiskPath("")
+			Expect(found).To(BeTrue())
+			Expect(realPath).To(BeEmpty())
+			Expect(platform.NormalizeDiskPathCalled).To(BeFalse())
+		})
+
+		It("normalizes given path with platform", func() {
+			platform.NormalizeDiskPathRealPath = "/warden-cpi-dev/fake-ephemeral"
+			platform.NormalizeDiskPathFound = true
+
+			realPath, found := inf.GetEphemeralDiskPath("/warden-cpi-dev/fake-ephemeral")
+			Expect(found).To(BeTrue())
+			Expect(realPath).To(Equal("/warden-cpi-dev/fake-ephemeral"))
+			Expect(platform.NormalizeDiskPathPath).To(Equal("/warden-cpi-dev/fake-ephemeral"))
+		})
 	})
 
 	Describe("GetSettings", func() {
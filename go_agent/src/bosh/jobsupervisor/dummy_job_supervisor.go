@@ -1,5 +1,9 @@
 package jobsupervisor
 
+import (
+	"time"
+)
+
 type dummyJobSupervisor struct {
 	status string
 }
@@ -22,18 +26,42 @@ func (s *dummyJobSupervisor) Stop() error {
 	return nil
 }
 
+func (s *dummyJobSupervisor) StartAndWait(pollInterval, timeout time.Duration) ([]ProcessState, error) {
+	return []ProcessState{}, s.Start()
+}
+
 func (s *dummyJobSupervisor) Unmonitor() error {
 	return nil
 }
 
+func (s *dummyJobSupervisor) StartProcesses(names []string) error {
+	return nil
+}
+
+func (s *dummyJobSupervisor) StopProcesses(names []string) error {
+	return nil
+}
+
+func (s *dummyJobSupervisor) RestartProcesses(names []string) error {
+	return nil
+}
+
 func (s *dummyJobSupervisor) Status() (status string) {
 	return s.status
 }
 
-func (s *dummyJobSupervisor) AddJob(jobName string, jobIndex int, configPath string) error {
+func (s *dummyJobSupervisor) Processes() ([]Process, error) {
+	return []Process{}, nil
+}
+
+func (s *dummyJobSupervisor) AddJob(jobName string, jobIndex int, label string, configPath string) error {
 	return nil
 }
 
+func (s *dummyJobSupervisor) JobProcessNames(jobName string) ([]string, error) {
+	return []string{}, nil
+}
+
 func (s *dummyJobSupervisor) RemoveAllJobs() error {
 	return nil
 }
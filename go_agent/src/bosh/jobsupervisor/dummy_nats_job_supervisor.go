@@ -2,6 +2,7 @@ package jobsupervisor
 
 import (
 	"encoding/json"
+	"time"
 
 	boshalert "bosh/agent/alert"
 	boshhandler "bosh/handler"
@@ -24,7 +25,7 @@ func (d *dummyNatsJobSupervisor) Reload() error {
 	return nil
 }
 
-func (d *dummyNatsJobSupervisor) AddJob(jobName string, jobIndex int, configPath string) error {
+func (d *dummyNatsJobSupervisor) AddJob(jobName string, jobIndex int, label string, configPath string) error {
 	return nil
 }
 
@@ -36,10 +37,30 @@ func (d *dummyNatsJobSupervisor) Stop() error {
 	return nil
 }
 
+func (d *dummyNatsJobSupervisor) StartAndWait(pollInterval, timeout time.Duration) ([]ProcessState, error) {
+	return []ProcessState{}, nil
+}
+
 func (d *dummyNatsJobSupervisor) Unmonitor() error {
 	return nil
 }
 
+func (d *dummyNatsJobSupervisor) StartProcesses(names []string) error {
+	return nil
+}
+
+func (d *dummyNatsJobSupervisor) StopProcesses(names []string) error {
+	return nil
+}
+
+func (d *dummyNatsJobSupervisor) RestartProcesses(names []string) error {
+	return nil
+}
+
+func (d *dummyNatsJobSupervisor) JobProcessNames(jobName string) ([]string, error) {
+	return []string{}, nil
+}
+
 func (d *dummyNatsJobSupervisor) RemoveAllJobs() error {
 	return nil
 }
@@ -48,6 +69,10 @@ func (d *dummyNatsJobSupervisor) Status() string {
 	return d.status
 }
 
+func (d *dummyNatsJobSupervisor) Processes() ([]Process, error) {
+	return []Process{}, nil
+}
+
 func (d *dummyNatsJobSupervisor) MonitorJobFailures(handler JobFailureHandler) error {
 	d.jobFailureHandler = handler
 
@@ -1,6 +1,8 @@
 package fakes
 
 import (
+	"time"
+
 	boshalert "bosh/agent/alert"
 	boshjobsuper "bosh/jobsupervisor"
 )
@@ -20,17 +22,38 @@ type FakeJobSupervisor struct {
 	Stopped bool
 	StopErr error
 
+	StartAndWaitPollInterval time.Duration
+	StartAndWaitTimeout      time.Duration
+	StartAndWaitStates       []boshjobsuper.ProcessState
+	StartAndWaitErr          error
+
 	Unmonitored  bool
 	UnmonitorErr error
 
+	StartProcessesNames []string
+	StartProcessesErr   error
+
+	StopProcessesNames []string
+	StopProcessesErr   error
+
+	RestartProcessesNames []string
+	RestartProcessesErr   error
+
+	JobProcessNamesNames map[string][]string
+	JobProcessNamesErr   error
+
 	StatusStatus string
 
+	ProcessesProcesses []boshjobsuper.Process
+	ProcessesErr       error
+
 	JobFailureAlert *boshalert.MonitAlert
 }
 
 type AddJobArgs struct {
 	Name       string
 	Index      int
+	Label      string
 	ConfigPath string
 }
 
@@ -43,10 +66,11 @@ func (m *FakeJobSupervisor) Reload() error {
 	return m.ReloadErr
 }
 
-func (m *FakeJobSupervisor) AddJob(jobName string, jobIndex int, configPath string) error {
+func (m *FakeJobSupervisor) AddJob(jobName string, jobIndex int, label string, configPath string) error {
 	args := AddJobArgs{
 		Name:       jobName,
 		Index:      jobIndex,
+		Label:      label,
 		ConfigPath: configPath,
 	}
 	m.AddJobArgs = append(m.AddJobArgs, args)
@@ -68,15 +92,44 @@ func (m *FakeJobSupervisor) Stop() error {
 	return m.StopErr
 }
 
+func (m *FakeJobSupervisor) StartAndWait(pollInterval, timeout time.Duration) ([]boshjobsuper.ProcessState, error) {
+	m.StartAndWaitPollInterval = pollInterval
+	m.StartAndWaitTimeout = timeout
+	return m.StartAndWaitStates, m.StartAndWaitErr
+}
+
 func (m *FakeJobSupervisor) Unmonitor() error {
 	m.Unmonitored = true
 	return m.UnmonitorErr
 }
 
+func (m *FakeJobSupervisor) StartProcesses(names []string) error {
+	m.StartProcessesNames = names
+	return m.StartProcessesErr
+}
+
+func (m *FakeJobSupervisor) StopProcesses(names []string) error {
+	m.StopProcessesNames = names
+	return m.StopProcessesErr
+}
+
+func (m *FakeJobSupervisor) RestartProcesses(names []string) error {
+	m.RestartProcessesNames = names
+	return m.RestartProcessesErr
+}
+
+func (m *FakeJobSupervisor) JobProcessNames(jobName string) ([]string, error) {
+	return m.JobProcessNamesNames[jobName], m.JobProcessNamesErr
+}
+
 func (m *FakeJobSupervisor) Status() string {
 	return m.StatusStatus
 }
 
+func (m *FakeJobSupervisor) Processes() ([]boshjobsuper.Process, error) {
+	return m.ProcessesProcesses, m.ProcessesErr
+}
+
 func (m *FakeJobSupervisor) MonitorJobFailures(handler boshjobsuper.JobFailureHandler) error {
 	if m.JobFailureAlert != nil {
 		handler(*m.JobFailureAlert)
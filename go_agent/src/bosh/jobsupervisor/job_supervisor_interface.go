@@ -1,11 +1,49 @@
 package jobsupervisor
 
 import (
+	"time"
+
 	boshalert "bosh/agent/alert"
 )
 
 type JobFailureHandler func(boshalert.MonitAlert) error
 
+type Process struct {
+	Name      string `json:"name"`
+	Monitored bool   `json:"monitored"`
+	Status    string `json:"status"`
+
+	// Pid is 0 when process is not running
+	Pid int `json:"pid"`
+
+	// Uptime is in seconds
+	Uptime uint64 `json:"uptime"`
+
+	// Restarts is the number of times process was seen
+	// with a different pid; it is kept across agent restarts
+	Restarts int `json:"restarts"`
+
+	Memory ProcessMemory `json:"mem"`
+	CPU    ProcessCPU    `json:"cpu"`
+}
+
+type ProcessMemory struct {
+	Kb      uint64  `json:"kb"`
+	Percent float64 `json:"percent"`
+}
+
+type ProcessCPU struct {
+	Total float64 `json:"total"`
+}
+
+type ProcessState struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+
+	// Error is last error monit noticed for process that did not reach target state
+	Error string `json:"error,omitempty"`
+}
+
 type JobSupervisor interface {
 	Reload() error
 
@@ -13,16 +51,32 @@ type JobSupervisor interface {
 	Start() error
 	Stop() error
 
+	// StartAndWait starts all services and waits until they are running;
+	// services that do not become running before timeout are reported
+	// in returned states instead of failing
+	StartAndWait(pollInterval, timeout time.Duration) ([]ProcessState, error)
+
 	// Start and Stop should still function after Unmonitor.
 	// Calling Start after Unmonitor should re-monitor all jobs.
 	// Calling Stop after Unmonitor should not re-monitor all jobs.
 	// (Monit complies to above requirements.)
 	Unmonitor() error
 
+	// Actions taken on specific processes;
+	// each waits until all processes reach target state
+	StartProcesses(names []string) error
+	StopProcesses(names []string) error
+	RestartProcesses(names []string) error
+
 	Status() string
 
-	// Job management
-	AddJob(jobName string, jobIndex int, configPath string) error
+	// Processes returns status of each supervised process of all jobs
+	Processes() ([]Process, error)
+
+	// Job management;
+	// label is empty for job's main monit file and names its additional monit files
+	AddJob(jobName string, jobIndex int, label string, configPath string) error
+	JobProcessNames(jobName string) ([]string, error)
 	RemoveAllJobs() error
 
 	MonitorJobFailures(handler JobFailureHandler) error
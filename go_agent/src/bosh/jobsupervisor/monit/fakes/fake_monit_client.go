@@ -15,12 +15,19 @@ type FakeMonitClient struct {
 	StopServiceNames []string
 	StopServiceErr   error
 
+	RestartServiceNames []string
+	RestartServiceErr   error
+
 	UnmonitorServiceNames []string
 	UnmonitorServiceErrs  []error
 
 	StatusStatus FakeMonitStatus
 	StatusErr    error
 
+	// Returned in order by consecutive Status calls instead of StatusStatus;
+	// last one is repeated
+	StatusStatuses []FakeMonitStatus
+
 	Incarnations      []int
 	StatusCalledTimes int
 }
@@ -44,6 +51,11 @@ func (c *FakeMonitClient) StopService(name string) error {
 	return c.StopServiceErr
 }
 
+func (c *FakeMonitClient) RestartService(name string) error {
+	c.RestartServiceNames = append(c.RestartServiceNames, name)
+	return c.RestartServiceErr
+}
+
 func (c *FakeMonitClient) UnmonitorService(name string) error {
 	c.UnmonitorServiceNames = append(c.UnmonitorServiceNames, name)
 	return c.UnmonitorServiceErrs[len(c.UnmonitorServiceNames)-1]
@@ -51,6 +63,14 @@ func (c *FakeMonitClient) UnmonitorService(name string) error {
 
 func (c *FakeMonitClient) Status() (boshmonit.Status, error) {
 	s := c.StatusStatus
+	if len(c.StatusStatuses) > 0 {
+		i := c.StatusCalledTimes
+		if i >= len(c.StatusStatuses) {
+			i = len(c.StatusStatuses) - 1
+		}
+		s = c.StatusStatuses[i]
+	}
+
 	if len(c.Incarnations) > 0 {
 		s.Incarnation = c.Incarnations[c.StatusCalledTimes]
 	}
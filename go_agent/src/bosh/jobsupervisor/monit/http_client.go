@@ -89,6 +89,22 @@ func (c httpClient) StopService(serviceName string) error {
 	return nil
 }
 
+func (c httpClient) RestartService(serviceName string) error {
+	response, err := c.makeRequest(c.monitURL(serviceName), "POST", "action=restart")
+	if err != nil {
+		return bosherr.WrapError(err, "Sending restart request to monit")
+	}
+
+	defer response.Body.Close()
+
+	err = c.validateResponse(response)
+	if err != nil {
+		return bosherr.WrapError(err, "Restarting Monit service %s", serviceName)
+	}
+
+	return nil
+}
+
 func (c httpClient) UnmonitorService(serviceName string) error {
 	response, err := c.makeRequest(c.monitURL(serviceName), "POST", "action=unmonitor")
 	if err != nil {
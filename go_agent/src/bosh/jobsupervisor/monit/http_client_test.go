@@ -129,6 +129,44 @@ func init() {
 			})
 		})
 
+		Describe("RestartService", func() {
+			It("restarts service", func() {
+				var calledMonit bool
+
+				handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calledMonit = true
+					Expect(r.Method).To(Equal("POST"))
+					Expect(r.URL.Path).To(Equal("/test-service"))
+					Expect(r.PostFormValue("action")).To(Equal("restart"))
+					Expect(r.Header.Get("Content-Type")).To(Equal("application/x-www-form-urlencoded"))
+
+					expectedAuthEncoded := base64.URLEncoding.EncodeToString([]byte("fake-user:fake-pass"))
+					Expect(r.Header.Get("Authorization")).To(Equal(fmt.Sprintf("Basic %s", expectedAuthEncoded)))
+				})
+				ts := httptest.NewServer(handler)
+				defer ts.Close()
+
+				client := NewHTTPClient(ts.Listener.Addr().String(), "fake-user", "fake-pass", http.DefaultClient, 1*time.Millisecond, logger)
+
+				err := client.RestartService("test-service")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(calledMonit).To(BeTrue())
+			})
+
+			It("retries when non200 response", func() {
+				fakeHTTPClient := fakemonit.NewFakeHTTPClient()
+				fakeHTTPClient.StatusCode = 500
+				fakeHTTPClient.SetMessage("fake error message")
+
+				client := NewHTTPClient("agent.example.com", "fake-user", "fake-pass", fakeHTTPClient, 1*time.Millisecond, logger)
+
+				err := client.RestartService("test-service")
+				Expect(fakeHTTPClient.CallCount).To(Equal(20))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake error message"))
+			})
+		})
+
 		Describe("UnmonitorService", func() {
 			It("issues a call to unmontor service by name", func() {
 				var calledMonit bool
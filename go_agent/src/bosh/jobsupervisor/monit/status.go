@@ -25,6 +25,22 @@ type serviceTag struct {
 	Name    string   `xml:"name,attr"`
 	Status  int      `xml:"status"`
 	Monitor int      `xml:"monitor"`
+	Pid     int      `xml:"pid"`
+	Uptime  uint64   `xml:"uptime"`
+	Message string   `xml:"status_message"`
+	Memory  memoryTag
+	CPU     cpuTag
+}
+
+type memoryTag struct {
+	XMLName  xml.Name `xml:"memory"`
+	Percent  float64  `xml:"percent"`
+	Kilobyte uint64   `xml:"kilobyte"`
+}
+
+type cpuTag struct {
+	XMLName xml.Name `xml:"cpu"`
+	Percent float64  `xml:"percent"`
 }
 
 type serviceGroupsTag struct {
@@ -84,8 +100,16 @@ func (status status) ServicesInGroup(name string) (services []Service) {
 	for _, serviceTag := range status.Services.Services {
 		if serviceGroupTag.Contains(serviceTag.Name) {
 			service := Service{
+				Name:      serviceTag.Name,
 				Monitored: serviceTag.Monitor > 0,
 				Status:    serviceTag.StatusString(),
+				Pid:       serviceTag.Pid,
+				Uptime:    serviceTag.Uptime,
+				Message:   serviceTag.Message,
+
+				MemoryKb:      serviceTag.Memory.Kilobyte,
+				MemoryPercent: serviceTag.Memory.Percent,
+				CPUPercent:    serviceTag.CPU.Percent,
 			}
 
 			services = append(services, service)
@@ -6,6 +6,20 @@ type Status interface {
 }
 
 type Service struct {
+	Name      string
 	Monitored bool
 	Status    string
+
+	// Pid is 0 when process is not running
+	Pid int
+
+	// Uptime is in seconds
+	Uptime uint64
+
+	// Message describes last error that monit noticed
+	Message string
+
+	MemoryKb      uint64
+	MemoryPercent float64
+	CPUPercent    float64
 }
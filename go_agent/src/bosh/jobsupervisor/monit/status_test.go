@@ -51,10 +51,19 @@ var _ = Describe("status", func() {
 			Expect(err).ToNot(HaveOccurred())
 
 			expectedServices := []Service{
-				Service{Monitored: true, Status: "running"},
-				Service{Monitored: false, Status: "unknown"},
-				Service{Monitored: true, Status: "starting"},
-				Service{Monitored: true, Status: "failing"},
+				Service{
+					Name:          "running-service",
+					Monitored:     true,
+					Status:        "running",
+					Pid:           1234,
+					Uptime:        3600,
+					MemoryKb:      30720,
+					MemoryPercent: 1.5,
+					CPUPercent:    2.5,
+				},
+				Service{Name: "unmonitored-service", Monitored: false, Status: "unknown"},
+				Service{Name: "starting-service", Monitored: true, Status: "starting"},
+				Service{Name: "failing-service", Monitored: true, Status: "failing", Message: "process is not running"},
 			}
 
 			services := status.ServicesInGroup("vcap")
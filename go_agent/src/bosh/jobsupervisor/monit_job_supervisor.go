@@ -1,8 +1,10 @@
 package jobsupervisor
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/pivotal/go-smtpd/smtpd"
@@ -17,6 +19,13 @@ import (
 
 const monitJobSupervisorLogTag = "monitJobSupervisor"
 
+// Records which monit configuration files were added for each job
+// so that processes of a job can be found without guessing from file names
+const monitJobConfigsFileName = "job_configs.json"
+
+// Keeps restart counts of processes across agent restarts
+const processRestartsFileName = "process_restarts.json"
+
 type monitJobSupervisor struct {
 	fs          boshsys.FileSystem
 	runner      boshsys.CmdRunner
@@ -26,7 +35,10 @@ type monitJobSupervisor struct {
 
 	jobFailuresServerPort int
 
-	reloadOptions MonitReloadOptions
+	reloadOptions       MonitReloadOptions
+	processStateOptions MonitProcessStateOptions
+
+	restartCounter *processRestartCounter
 }
 
 type MonitReloadOptions struct {
@@ -41,6 +53,15 @@ type MonitReloadOptions struct {
 	DelayBetweenCheckTries time.Duration
 }
 
+type MonitProcessStateOptions struct {
+	// Number of times monit status will be checked
+	// for processes to reach target state
+	MaxCheckTries int
+
+	// Length of time between checking monit status
+	DelayBetweenCheckTries time.Duration
+}
+
 func NewMonitJobSupervisor(
 	fs boshsys.FileSystem,
 	runner boshsys.CmdRunner,
@@ -49,6 +70,7 @@ func NewMonitJobSupervisor(
 	dirProvider boshdir.DirectoriesProvider,
 	jobFailuresServerPort int,
 	reloadOptions MonitReloadOptions,
+	processStateOptions MonitProcessStateOptions,
 ) (m monitJobSupervisor) {
 	return monitJobSupervisor{
 		fs:          fs,
@@ -59,7 +81,14 @@ func NewMonitJobSupervisor(
 
 		jobFailuresServerPort: jobFailuresServerPort,
 
-		reloadOptions: reloadOptions,
+		reloadOptions:       reloadOptions,
+		processStateOptions: processStateOptions,
+
+		restartCounter: newProcessRestartCounter(
+			fs,
+			filepath.Join(dirProvider.BoshDir(), processRestartsFileName),
+			logger,
+		),
 	}
 }
 
@@ -115,6 +144,8 @@ func (m monitJobSupervisor) Start() error {
 		return bosherr.WrapError(err, "Getting vcap services")
 	}
 
+	m.restartCounter.ExpectPidChange(services)
+
 	for _, service := range services {
 		err = m.client.StartService(service)
 		if err != nil {
@@ -132,6 +163,8 @@ func (m monitJobSupervisor) Stop() error {
 		return bosherr.WrapError(err, "Getting vcap services")
 	}
 
+	m.restartCounter.ExpectPidChange(services)
+
 	for _, service := range services {
 		err = m.client.StopService(service)
 		if err != nil {
@@ -143,6 +176,190 @@ func (m monitJobSupervisor) Stop() error {
 	return nil
 }
 
+func (m monitJobSupervisor) StartAndWait(pollInterval, timeout time.Duration) ([]ProcessState, error) {
+	names, err := m.client.ServicesInGroup("vcap")
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Getting vcap services")
+	}
+
+	m.restartCounter.ExpectPidChange(names)
+
+	for _, name := range names {
+		m.logger.Debug(monitJobSupervisorLogTag, "Starting service %s", name)
+
+		err = m.client.StartService(name)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Starting service %s", name)
+		}
+	}
+
+	maxCheckTries := 1
+	if pollInterval > 0 {
+		maxCheckTries += int(timeout / pollInterval)
+	}
+
+	servicesByName, _, err := m.waitForServices(names, "running", isRunningService, maxCheckTries, pollInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	states := []ProcessState{}
+
+	for _, name := range names {
+		service, found := servicesByName[name]
+		if !found {
+			states = append(states, ProcessState{Name: name, Status: "unknown", Error: "Service is missing from monit status"})
+			continue
+		}
+
+		state := ProcessState{Name: name, Status: service.Status}
+		if !isRunningService(service) {
+			state.Error = service.Message
+		}
+
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+func isRunningService(service boshmonit.Service) bool {
+	return service.Monitored && service.Status == "running"
+}
+
+func (m monitJobSupervisor) StartProcesses(names []string) error {
+	m.restartCounter.ExpectPidChange(names)
+
+	for _, name := range names {
+		m.logger.Debug(monitJobSupervisorLogTag, "Starting service %s", name)
+
+		err := m.client.StartService(name)
+		if err != nil {
+			return bosherr.WrapError(err, "Starting service %s", name)
+		}
+	}
+
+	return m.waitForProcesses(names, "running", isRunningService)
+}
+
+func (m monitJobSupervisor) StopProcesses(names []string) error {
+	m.restartCounter.ExpectPidChange(names)
+
+	for _, name := range names {
+		m.logger.Debug(monitJobSupervisorLogTag, "Stopping service %s", name)
+
+		err := m.client.StopService(name)
+		if err != nil {
+			return bosherr.WrapError(err, "Stopping service %s", name)
+		}
+	}
+
+	// Monit stops monitoring services that it stopped
+	return m.waitForProcesses(names, "stopped", func(service boshmonit.Service) bool {
+		return !service.Monitored
+	})
+}
+
+func (m monitJobSupervisor) RestartProcesses(names []string) error {
+	monitStatus, err := m.client.Status()
+	if err != nil {
+		return bosherr.WrapError(err, "Getting monit status")
+	}
+
+	oldPids := map[string]int{}
+
+	for _, service := range monitStatus.ServicesInGroup("vcap") {
+		oldPids[service.Name] = service.Pid
+	}
+
+	m.restartCounter.ExpectPidChange(names)
+
+	for _, name := range names {
+		m.logger.Debug(monitJobSupervisorLogTag, "Restarting service %s", name)
+
+		err = m.client.RestartService(name)
+		if err != nil {
+			return bosherr.WrapError(err, "Restarting service %s", name)
+		}
+	}
+
+	// Process that was running before restart is only considered restarted
+	// once monit reports it with a new pid
+	return m.waitForProcesses(names, "restarted", func(service boshmonit.Service) bool {
+		oldPid := oldPids[service.Name]
+		return service.Monitored && service.Status == "running" && (oldPid == 0 || service.Pid != oldPid)
+	})
+}
+
+func (m monitJobSupervisor) waitForProcesses(names []string, state string, reachedState func(boshmonit.Service) bool) error {
+	_, pendingNames, err := m.waitForServices(
+		names,
+		state,
+		reachedState,
+		m.processStateOptions.MaxCheckTries,
+		m.processStateOptions.DelayBetweenCheckTries,
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(pendingNames) > 0 {
+		return bosherr.New("Services did not become %s: %s", state, strings.Join(pendingNames, ", "))
+	}
+
+	return nil
+}
+
+// waitForServices returns last seen services and names of services that did not reach state
+func (m monitJobSupervisor) waitForServices(
+	names []string,
+	state string,
+	reachedState func(boshmonit.Service) bool,
+	maxCheckTries int,
+	delayBetweenCheckTries time.Duration,
+) (map[string]boshmonit.Service, []string, error) {
+	var servicesByName map[string]boshmonit.Service
+	var pendingNames []string
+
+	for i := 0; i < maxCheckTries; i++ {
+		if i > 0 {
+			time.Sleep(delayBetweenCheckTries)
+		}
+
+		monitStatus, err := m.client.Status()
+		if err != nil {
+			return nil, nil, bosherr.WrapError(err, "Getting monit status")
+		}
+
+		servicesByName = map[string]boshmonit.Service{}
+
+		for _, service := range monitStatus.ServicesInGroup("vcap") {
+			servicesByName[service.Name] = service
+		}
+
+		pendingNames = []string{}
+
+		for _, name := range names {
+			service, found := servicesByName[name]
+			if !found || !reachedState(service) {
+				pendingNames = append(pendingNames, name)
+			}
+		}
+
+		if len(pendingNames) == 0 {
+			break
+		}
+
+		m.logger.Debug(
+			monitJobSupervisorLogTag,
+			"Waiting for services to be %s: %s",
+			state, strings.Join(pendingNames, ", "),
+		)
+	}
+
+	return servicesByName, pendingNames, nil
+}
+
 func (m monitJobSupervisor) Unmonitor() error {
 	services, err := m.client.ServicesInGroup("vcap")
 	if err != nil {
@@ -168,18 +385,59 @@ func (m monitJobSupervisor) Status() (status string) {
 		return
 	}
 
+	starting := false
+
+	// All services are observed so that restarts are not missed
+	// when one of them is still starting
 	for _, service := range monitStatus.ServicesInGroup("vcap") {
+		m.restartCounter.Observe(service.Name, service.Pid)
+
 		if service.Status == "starting" {
-			return "starting"
+			starting = true
 		}
 		if !service.Monitored || service.Status != "running" {
 			status = "failing"
 		}
 	}
 
+	if starting {
+		status = "starting"
+	}
+
 	return
 }
 
+func (m monitJobSupervisor) Processes() ([]Process, error) {
+	monitStatus, err := m.client.Status()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Getting monit status")
+	}
+
+	processes := []Process{}
+
+	for _, service := range monitStatus.ServicesInGroup("vcap") {
+		process := Process{
+			Name:      service.Name,
+			Monitored: service.Monitored,
+			Status:    service.Status,
+			Pid:       service.Pid,
+			Uptime:    service.Uptime,
+			Restarts:  m.restartCounter.Observe(service.Name, service.Pid),
+			Memory: ProcessMemory{
+				Kb:      service.MemoryKb,
+				Percent: service.MemoryPercent,
+			},
+			CPU: ProcessCPU{
+				Total: service.CPUPercent,
+			},
+		}
+
+		processes = append(processes, process)
+	}
+
+	return processes, nil
+}
+
 func (m monitJobSupervisor) getIncarnation() (int, error) {
 	monitStatus, err := m.client.Status()
 	if err != nil {
@@ -189,8 +447,13 @@ func (m monitJobSupervisor) getIncarnation() (int, error) {
 	return monitStatus.GetIncarnation()
 }
 
-func (m monitJobSupervisor) AddJob(jobName string, jobIndex int, configPath string) error {
-	targetFilename := fmt.Sprintf("%04d_%s.monitrc", jobIndex, jobName)
+func (m monitJobSupervisor) AddJob(jobName string, jobIndex int, label string, configPath string) error {
+	configName := jobName
+	if label != "" {
+		configName = fmt.Sprintf("%s_%s", jobName, label)
+	}
+
+	targetFilename := fmt.Sprintf("%04d_%s.monitrc", jobIndex, configName)
 	targetConfigPath := filepath.Join(m.dirProvider.MonitJobsDir(), targetFilename)
 
 	configContent, err := m.fs.ReadFile(configPath)
@@ -203,6 +466,85 @@ func (m monitJobSupervisor) AddJob(jobName string, jobIndex int, configPath stri
 		return bosherr.WrapError(err, "Writing to job config file")
 	}
 
+	jobConfigs, err := m.readJobConfigs()
+	if err != nil {
+		return err
+	}
+
+	jobConfigs[jobName] = append(jobConfigs[jobName], targetFilename)
+
+	err = m.writeJobConfigs(jobConfigs)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// JobProcessNames finds processes in monit configuration files added for a job
+func (m monitJobSupervisor) JobProcessNames(jobName string) ([]string, error) {
+	jobConfigs, err := m.readJobConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+
+	for _, configFilename := range jobConfigs[jobName] {
+		configPath := filepath.Join(m.dirProvider.MonitJobsDir(), configFilename)
+
+		config, err := m.fs.ReadFileString(configPath)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Reading job monit configuration %s", configPath)
+		}
+
+		for _, line := range strings.Split(config, "\n") {
+			// e.g. check process router with pidfile /var/vcap/sys/run/router.pid
+			fields := strings.Fields(line)
+			if len(fields) >= 3 && fields[0] == "check" && fields[1] == "process" {
+				names = append(names, fields[2])
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// readJobConfigs returns monit configuration file names keyed by job name
+func (m monitJobSupervisor) readJobConfigs() (map[string][]string, error) {
+	jobConfigs := map[string][]string{}
+
+	jobConfigsPath := filepath.Join(m.dirProvider.MonitJobsDir(), monitJobConfigsFileName)
+	if !m.fs.FileExists(jobConfigsPath) {
+		return jobConfigs, nil
+	}
+
+	jobConfigsJSON, err := m.fs.ReadFile(jobConfigsPath)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Reading job monit configurations")
+	}
+
+	err = json.Unmarshal(jobConfigsJSON, &jobConfigs)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Unmarshalling job monit configurations")
+	}
+
+	return jobConfigs, nil
+}
+
+func (m monitJobSupervisor) writeJobConfigs(jobConfigs map[string][]string) error {
+	jobConfigsJSON, err := json.Marshal(jobConfigs)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling job monit configurations")
+	}
+
+	jobConfigsPath := filepath.Join(m.dirProvider.MonitJobsDir(), monitJobConfigsFileName)
+
+	err = m.fs.WriteFile(jobConfigsPath, jobConfigsJSON)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing job monit configurations")
+	}
+
 	return nil
 }
 
@@ -57,6 +57,10 @@ var _ = Describe("monitJobSupervisor", func() {
 				MaxCheckTries:          10,
 				DelayBetweenCheckTries: 0 * time.Millisecond,
 			},
+			MonitProcessStateOptions{
+				MaxCheckTries:          3,
+				DelayBetweenCheckTries: 0 * time.Millisecond,
+			},
 		)
 	})
 
@@ -171,6 +175,205 @@ var _ = Describe("monitJobSupervisor", func() {
 		})
 	})
 
+	Describe("StartAndWait", func() {
+		BeforeEach(func() {
+			client.ServicesInGroupServices = []string{"fake-service-1", "fake-service-2"}
+		})
+
+		It("starts each service in group vcap and waits for them to be running", func() {
+			client.StatusStatuses = []fakemonit.FakeMonitStatus{
+				fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service-1", Monitored: true, Status: "starting"},
+						boshmonit.Service{Name: "fake-service-2", Monitored: true, Status: "running"},
+					},
+				},
+				fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service-1", Monitored: true, Status: "running"},
+						boshmonit.Service{Name: "fake-service-2", Monitored: true, Status: "running"},
+					},
+				},
+			}
+
+			states, err := monit.StartAndWait(1*time.Millisecond, 1*time.Second)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(states).To(Equal([]ProcessState{
+				ProcessState{Name: "fake-service-1", Status: "running"},
+				ProcessState{Name: "fake-service-2", Status: "running"},
+			}))
+
+			Expect(client.ServicesInGroupName).To(Equal("vcap"))
+			Expect(client.StartServiceNames).To(Equal([]string{"fake-service-1", "fake-service-2"}))
+			Expect(client.StatusCalledTimes).To(Equal(2))
+		})
+
+		It("returns last error of services that do not become running before timeout", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					boshmonit.Service{Name: "fake-service-1", Monitored: true, Status: "failing", Message: "process is not running"},
+				},
+			}
+
+			states, err := monit.StartAndWait(1*time.Millisecond, 3*time.Millisecond)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(states).To(Equal([]ProcessState{
+				ProcessState{Name: "fake-service-1", Status: "failing", Error: "process is not running"},
+				ProcessState{Name: "fake-service-2", Status: "unknown", Error: "Service is missing from monit status"},
+			}))
+
+			Expect(client.StatusCalledTimes).To(Equal(4))
+		})
+
+		It("returns error when starting service fails", func() {
+			client.StartServiceErr = errors.New("fake-start-service-err")
+
+			_, err := monit.StartAndWait(1*time.Millisecond, 1*time.Second)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-start-service-err"))
+		})
+
+		It("returns error when monit status cannot be retrieved", func() {
+			client.StatusErr = errors.New("fake-monit-client-error")
+
+			_, err := monit.StartAndWait(1*time.Millisecond, 1*time.Second)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-monit-client-error"))
+		})
+	})
+
+	Describe("StartProcesses", func() {
+		It("starts each service and waits for them to be running", func() {
+			client.StatusStatuses = []fakemonit.FakeMonitStatus{
+				fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service-1", Monitored: true, Status: "starting"},
+						boshmonit.Service{Name: "fake-service-2", Monitored: true, Status: "running"},
+					},
+				},
+				fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service-1", Monitored: true, Status: "running"},
+						boshmonit.Service{Name: "fake-service-2", Monitored: true, Status: "running"},
+					},
+				},
+			}
+
+			err := monit.StartProcesses([]string{"fake-service-1", "fake-service-2"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.StartServiceNames).To(Equal([]string{"fake-service-1", "fake-service-2"}))
+			Expect(client.StatusCalledTimes).To(Equal(2))
+		})
+
+		It("returns error when services do not become running", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					boshmonit.Service{Name: "fake-service-1", Monitored: true, Status: "failing"},
+					boshmonit.Service{Name: "fake-service-2", Monitored: true, Status: "running"},
+				},
+			}
+
+			err := monit.StartProcesses([]string{"fake-service-1", "fake-service-2", "fake-missing-service"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Services did not become running: fake-service-1, fake-missing-service"))
+
+			Expect(client.StatusCalledTimes).To(Equal(3))
+		})
+
+		It("returns error when starting service fails", func() {
+			client.StartServiceErr = errors.New("fake-start-service-err")
+
+			err := monit.StartProcesses([]string{"fake-service"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-start-service-err"))
+		})
+	})
+
+	Describe("StopProcesses", func() {
+		It("stops each service and waits for them to be no longer monitored", func() {
+			client.StatusStatuses = []fakemonit.FakeMonitStatus{
+				fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service", Monitored: true, Status: "running"},
+					},
+				},
+				fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service", Monitored: false, Status: "unknown"},
+					},
+				},
+			}
+
+			err := monit.StopProcesses([]string{"fake-service"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.StopServiceNames).To(Equal([]string{"fake-service"}))
+			Expect(client.StatusCalledTimes).To(Equal(2))
+		})
+
+		It("returns error when services do not stop", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					boshmonit.Service{Name: "fake-service", Monitored: true, Status: "running"},
+				},
+			}
+
+			err := monit.StopProcesses([]string{"fake-service"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Services did not become stopped: fake-service"))
+		})
+	})
+
+	Describe("RestartProcesses", func() {
+		It("restarts each service and waits for them to be running with a new pid", func() {
+			client.StatusStatuses = []fakemonit.FakeMonitStatus{
+				fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service-1", Monitored: true, Status: "running", Pid: 123},
+						boshmonit.Service{Name: "fake-service-2", Monitored: true, Status: "failing"},
+					},
+				},
+				fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service-1", Monitored: true, Status: "running", Pid: 123},
+						boshmonit.Service{Name: "fake-service-2", Monitored: true, Status: "running", Pid: 456},
+					},
+				},
+				fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service-1", Monitored: true, Status: "running", Pid: 789},
+						boshmonit.Service{Name: "fake-service-2", Monitored: true, Status: "running", Pid: 456},
+					},
+				},
+			}
+
+			err := monit.RestartProcesses([]string{"fake-service-1", "fake-service-2"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.RestartServiceNames).To(Equal([]string{"fake-service-1", "fake-service-2"}))
+			Expect(client.StatusCalledTimes).To(Equal(3))
+		})
+
+		It("returns error when restarting service fails", func() {
+			client.RestartServiceErr = errors.New("fake-restart-service-err")
+
+			err := monit.RestartProcesses([]string{"fake-service"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-restart-service-err"))
+		})
+
+		It("returns error when monit status cannot be retrieved", func() {
+			client.StatusErr = errors.New("fake-monit-client-error")
+
+			err := monit.RestartProcesses([]string{"fake-service"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-monit-client-error"))
+
+			Expect(client.RestartServiceNames).To(BeEmpty())
+		})
+	})
+
 	Describe("Status", func() {
 		It("status returns running when all services are monitored and running", func() {
 			client.StatusStatus = fakemonit.FakeMonitStatus{
@@ -229,6 +432,244 @@ var _ = Describe("monitJobSupervisor", func() {
 		})
 	})
 
+	Describe("Processes", func() {
+		It("returns status of each service", func() {
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					boshmonit.Service{
+						Name:          "fake-service-1",
+						Monitored:     true,
+						Status:        "running",
+						Pid:           123,
+						Uptime:        3600,
+						MemoryKb:      30720,
+						MemoryPercent: 1.5,
+						CPUPercent:    2.5,
+					},
+					boshmonit.Service{Name: "fake-service-2", Monitored: false, Status: "unknown"},
+				},
+			}
+
+			processes, err := monit.Processes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processes).To(Equal([]Process{
+				Process{
+					Name:      "fake-service-1",
+					Monitored: true,
+					Status:    "running",
+					Pid:       123,
+					Uptime:    3600,
+					Memory:    ProcessMemory{Kb: 30720, Percent: 1.5},
+					CPU:       ProcessCPU{Total: 2.5},
+				},
+				Process{Name: "fake-service-2", Status: "unknown"},
+			}))
+		})
+
+		It("counts restarts when service is seen with a different pid", func() {
+			statusWithPid := func(pid int) fakemonit.FakeMonitStatus {
+				return fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service", Monitored: true, Status: "running", Pid: pid},
+					},
+				}
+			}
+
+			for _, pid := range []int{123, 123, 0, 456} {
+				client.StatusStatus = statusWithPid(pid)
+				monit.Status()
+			}
+
+			client.StatusStatus = statusWithPid(789)
+
+			processes, err := monit.Processes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processes[0].Restarts).To(Equal(2))
+		})
+
+		It("counts restarts of services listed after a starting service", func() {
+			statusWithPid := func(pid int) fakemonit.FakeMonitStatus {
+				return fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service-1", Monitored: true, Status: "starting"},
+						boshmonit.Service{Name: "fake-service-2", Monitored: true, Status: "running", Pid: pid},
+					},
+				}
+			}
+
+			for _, pid := range []int{123, 456} {
+				client.StatusStatus = statusWithPid(pid)
+				Expect(monit.Status()).To(Equal("starting"))
+			}
+
+			client.StatusStatus = statusWithPid(789)
+
+			processes, err := monit.Processes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processes[1].Restarts).To(Equal(2))
+		})
+
+		It("keeps restart counts across agent restarts", func() {
+			statusWithPid := func(pid int) fakemonit.FakeMonitStatus {
+				return fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service", Monitored: true, Status: "running", Pid: pid},
+					},
+				}
+			}
+
+			for _, pid := range []int{123, 456} {
+				client.StatusStatus = statusWithPid(pid)
+				monit.Status()
+			}
+
+			Expect(fs.FileExists("/var/vcap/bosh/process_restarts.json")).To(BeTrue())
+
+			restartedMonit := NewMonitJobSupervisor(
+				fs,
+				runner,
+				client,
+				logger,
+				dirProvider,
+				jobFailuresServerPort,
+				MonitReloadOptions{},
+				MonitProcessStateOptions{},
+			)
+
+			client.StatusStatus = statusWithPid(789)
+
+			processes, err := restartedMonit.Processes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processes[0].Restarts).To(Equal(2))
+		})
+
+		It("does not count pid changes across reboots", func() {
+			statusWithPid := func(pid int) fakemonit.FakeMonitStatus {
+				return fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service", Monitored: true, Status: "running", Pid: pid},
+					},
+				}
+			}
+
+			fs.WriteFileString("/proc/sys/kernel/random/boot_id", "fake-boot-id-1\n")
+
+			for _, pid := range []int{123, 456} {
+				client.StatusStatus = statusWithPid(pid)
+				monit.Status()
+			}
+
+			fs.WriteFileString("/proc/sys/kernel/random/boot_id", "fake-boot-id-2\n")
+
+			rebootedMonit := NewMonitJobSupervisor(
+				fs,
+				runner,
+				client,
+				logger,
+				dirProvider,
+				jobFailuresServerPort,
+				MonitReloadOptions{},
+				MonitProcessStateOptions{},
+			)
+
+			client.StatusStatus = statusWithPid(789)
+
+			processes, err := rebootedMonit.Processes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processes[0].Restarts).To(Equal(1))
+		})
+
+		It("does not count pid changes caused by restarting processes", func() {
+			statusWithPid := func(pid int) fakemonit.FakeMonitStatus {
+				return fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service", Monitored: true, Status: "running", Pid: pid},
+					},
+				}
+			}
+
+			client.StatusStatuses = []fakemonit.FakeMonitStatus{
+				statusWithPid(123),
+				statusWithPid(123),
+				statusWithPid(456),
+			}
+			monit.Status()
+
+			err := monit.RestartProcesses([]string{"fake-service"})
+			Expect(err).ToNot(HaveOccurred())
+
+			processes, err := monit.Processes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processes[0].Restarts).To(Equal(0))
+
+			client.StatusStatuses = nil
+			client.StatusStatus = statusWithPid(789)
+
+			processes, err = monit.Processes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processes[0].Restarts).To(Equal(1))
+		})
+
+		It("does not count pid changes caused by stopping and starting processes", func() {
+			client.ServicesInGroupServices = []string{"fake-service"}
+
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					boshmonit.Service{Name: "fake-service", Monitored: true, Status: "running", Pid: 123},
+				},
+			}
+			monit.Status()
+
+			err := monit.Stop()
+			Expect(err).ToNot(HaveOccurred())
+
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					boshmonit.Service{Name: "fake-service", Monitored: false, Status: "unknown"},
+				},
+			}
+			monit.Status()
+
+			err = monit.Start()
+			Expect(err).ToNot(HaveOccurred())
+
+			client.StatusStatus = fakemonit.FakeMonitStatus{
+				Services: []boshmonit.Service{
+					boshmonit.Service{Name: "fake-service", Monitored: true, Status: "running", Pid: 456},
+				},
+			}
+
+			processes, err := monit.Processes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processes[0].Restarts).To(Equal(0))
+		})
+
+		It("still counts restarts when restart counts cannot be saved", func() {
+			fs.WriteToFileError = errors.New("fake-write-err")
+
+			for _, pid := range []int{123, 456} {
+				client.StatusStatus = fakemonit.FakeMonitStatus{
+					Services: []boshmonit.Service{
+						boshmonit.Service{Name: "fake-service", Monitored: true, Status: "running", Pid: pid},
+					},
+				}
+				monit.Status()
+			}
+
+			processes, err := monit.Processes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processes[0].Restarts).To(Equal(1))
+		})
+
+		It("returns error when monit status cannot be retrieved", func() {
+			client.StatusErr = errors.New("fake-monit-client-error")
+
+			_, err := monit.Processes()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-monit-client-error"))
+		})
+	})
+
 	Describe("MonitorJobFailures", func() {
 		It("monitor job failures", func() {
 			var handledAlert boshalert.MonitAlert
@@ -276,6 +717,70 @@ var _ = Describe("monitJobSupervisor", func() {
 		})
 	})
 
+	Describe("JobProcessNames", func() {
+		BeforeEach(func() {
+			fs.WriteFileString("/fake/router/monit", `
+check process router
+  with pidfile /var/vcap/sys/run/router/router.pid
+  group vcap
+
+check process router_cleaner
+  with pidfile /var/vcap/sys/run/router/cleaner.pid
+  group vcap
+`)
+			fs.WriteFileString("/fake/router/worker.monit", "check process router_worker\n")
+			fs.WriteFileString("/fake/nats/monit", "check process nats\n")
+			fs.WriteFileString("/fake/nats_stream_forwarder/monit", "check process nats_stream_forwarder\n")
+
+			err := monit.AddJob("router", 0, "", "/fake/router/monit")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = monit.AddJob("router", 0, "worker", "/fake/router/worker.monit")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = monit.AddJob("nats", 1, "", "/fake/nats/monit")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = monit.AddJob("nats_stream_forwarder", 2, "", "/fake/nats_stream_forwarder/monit")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns processes from monit configuration of job and its additional monit files", func() {
+			names, err := monit.JobProcessNames("router")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names).To(Equal([]string{"router", "router_cleaner", "router_worker"}))
+		})
+
+		It("does not return processes of other jobs whose names start with job name", func() {
+			names, err := monit.JobProcessNames("nats")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names).To(Equal([]string{"nats"}))
+		})
+
+		It("returns no processes when job does not have monit configuration", func() {
+			names, err := monit.JobProcessNames("fake-job")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names).To(BeEmpty())
+		})
+
+		It("returns no processes after all jobs are removed", func() {
+			err := monit.RemoveAllJobs()
+			Expect(err).ToNot(HaveOccurred())
+
+			names, err := monit.JobProcessNames("router")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names).To(BeEmpty())
+		})
+
+		It("returns error when job monit configurations cannot be read", func() {
+			fs.ReadFileError = errors.New("fake-read-err")
+
+			_, err := monit.JobProcessNames("router")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-read-err"))
+		})
+	})
+
 	Describe("AddJob", func() {
 		BeforeEach(func() {
 			fs.WriteFileString("/some/config/path", "fake-config")
@@ -284,7 +789,7 @@ var _ = Describe("monitJobSupervisor", func() {
 		Context("when reading configuration from config path succeeds", func() {
 			Context("when writing job configuration succeeds", func() {
 				It("returns no error because monit can track added job in jobs directory", func() {
-					err := monit.AddJob("router", 0, "/some/config/path")
+					err := monit.AddJob("router", 0, "", "/some/config/path")
 					Expect(err).ToNot(HaveOccurred())
 
 					writtenConfig, err := fs.ReadFileString(
@@ -292,13 +797,23 @@ var _ = Describe("monitJobSupervisor", func() {
 					Expect(err).ToNot(HaveOccurred())
 					Expect(writtenConfig).To(Equal("fake-config"))
 				})
+
+				It("names configuration of additional monit file after job and label", func() {
+					err := monit.AddJob("router", 0, "worker", "/some/config/path")
+					Expect(err).ToNot(HaveOccurred())
+
+					writtenConfig, err := fs.ReadFileString(
+						dirProvider.MonitJobsDir() + "/0000_router_worker.monitrc")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(writtenConfig).To(Equal("fake-config"))
+				})
 			})
 
 			Context("when writing job configuration fails", func() {
 				It("returns error", func() {
 					fs.WriteToFileError = errors.New("fake-write-error")
 
-					err := monit.AddJob("router", 0, "/some/config/path")
+					err := monit.AddJob("router", 0, "", "/some/config/path")
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(ContainSubstring("fake-write-error"))
 				})
@@ -309,7 +824,7 @@ var _ = Describe("monitJobSupervisor", func() {
 			It("returns error", func() {
 				fs.ReadFileError = errors.New("fake-read-error")
 
-				err := monit.AddJob("router", 0, "/some/config/path")
+				err := monit.AddJob("router", 0, "", "/some/config/path")
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-read-error"))
 			})
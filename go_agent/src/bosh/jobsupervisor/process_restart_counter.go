@@ -0,0 +1,170 @@
+package jobsupervisor
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	bosherr "bosh/errors"
+	boshlog "bosh/logger"
+	boshsys "bosh/system"
+)
+
+const (
+	processRestartCounterLogTag     = "processRestartCounter"
+	processRestartCounterBootIDPath = "/proc/sys/kernel/random/boot_id"
+)
+
+// processRestartCounter counts restarts by watching pid changes
+// since monit status does not include number of restarts.
+// Restarts are only noticed when status is checked.
+// Counts are kept in a file so that they survive agent restarts.
+// Pid changes after a reboot or caused by the agent itself are not restarts.
+type processRestartCounter struct {
+	fs     boshsys.FileSystem
+	path   string
+	logger boshlog.Logger
+
+	lock      sync.Mutex
+	loaded    bool
+	bootID    string
+	processes map[string]processRestarts
+}
+
+type processRestarts struct {
+	// BootID tells whether LastPid was seen in current boot
+	BootID  string
+	LastPid int
+
+	// PidChangeExpected is set when the agent starts, stops or restarts the process
+	PidChangeExpected bool
+
+	Restarts int
+}
+
+func newProcessRestartCounter(fs boshsys.FileSystem, path string, logger boshlog.Logger) *processRestartCounter {
+	return &processRestartCounter{
+		fs:        fs,
+		path:      path,
+		logger:    logger,
+		processes: map[string]processRestarts{},
+	}
+}
+
+// Observe records current pid of a process and returns its restart count
+func (c *processRestartCounter) Observe(name string, pid int) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.ensureLoaded()
+
+	process := c.processes[name]
+
+	// Pids from previous boot say nothing about restarts
+	if process.BootID != c.bootID {
+		process.BootID = c.bootID
+		process.LastPid = 0
+		process.PidChangeExpected = false
+	}
+
+	// Stopped process (pid 0) is not a restart until it comes back with a new pid
+	if pid != 0 && process.LastPid != pid {
+		if process.LastPid != 0 && !process.PidChangeExpected {
+			process.Restarts++
+		}
+		process.LastPid = pid
+		process.PidChangeExpected = false
+		c.processes[name] = process
+
+		c.saveOrLog()
+	}
+
+	return process.Restarts
+}
+
+// ExpectPidChange makes next pid change of given processes not count as a restart
+func (c *processRestartCounter) ExpectPidChange(names []string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.ensureLoaded()
+
+	for _, name := range names {
+		process := c.processes[name]
+		process.PidChangeExpected = true
+		c.processes[name] = process
+	}
+
+	c.saveOrLog()
+}
+
+func (c *processRestartCounter) ensureLoaded() {
+	if c.loaded {
+		return
+	}
+
+	c.bootID = c.currentBootID()
+
+	err := c.load()
+	if err != nil {
+		c.logger.Error(processRestartCounterLogTag, "Failed to load restart counts: %s", err.Error())
+	}
+
+	c.loaded = true
+}
+
+// currentBootID returns empty string when boot id is not known
+func (c *processRestartCounter) currentBootID() string {
+	bootID, err := c.fs.ReadFileString(processRestartCounterBootIDPath)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(bootID)
+}
+
+// saveOrLog only logs failures since counts are still returned when they cannot be kept
+func (c *processRestartCounter) saveOrLog() {
+	err := c.save()
+	if err != nil {
+		c.logger.Error(processRestartCounterLogTag, "Failed to save restart counts: %s", err.Error())
+	}
+}
+
+func (c *processRestartCounter) load() error {
+	if !c.fs.FileExists(c.path) {
+		return nil
+	}
+
+	contents, err := c.fs.ReadFile(c.path)
+	if err != nil {
+		return bosherr.WrapError(err, "Reading restart counts")
+	}
+
+	processes := map[string]processRestarts{}
+
+	err = json.Unmarshal(contents, &processes)
+	if err != nil {
+		return bosherr.WrapError(err, "Unmarshalling restart counts")
+	}
+
+	if processes != nil {
+		c.processes = processes
+	}
+
+	return nil
+}
+
+func (c *processRestartCounter) save() error {
+	contents, err := json.Marshal(c.processes)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling restart counts")
+	}
+
+	err = c.fs.WriteFile(c.path, contents)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing restart counts")
+	}
+
+	return nil
+}
@@ -34,6 +34,10 @@ func NewProvider(
 			MaxCheckTries:          6,
 			DelayBetweenCheckTries: 5 * time.Second,
 		},
+		MonitProcessStateOptions{
+			MaxCheckTries:          60,
+			DelayBetweenCheckTries: 1 * time.Second,
+		},
 	)
 
 	p.supervisors = map[string]JobSupervisor{
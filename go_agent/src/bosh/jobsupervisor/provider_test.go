@@ -59,6 +59,10 @@ func init() {
 					MaxCheckTries:          6,
 					DelayBetweenCheckTries: 5 * time.Second,
 				},
+				MonitProcessStateOptions{
+					MaxCheckTries:          60,
+					DelayBetweenCheckTries: 1 * time.Second,
+				},
 			)
 			Expect(actualSupervisor).To(Equal(expectedSupervisor))
 		})
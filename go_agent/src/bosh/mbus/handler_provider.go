@@ -8,6 +8,7 @@ import (
 	bosherr "bosh/errors"
 	boshhandler "bosh/handler"
 	boshlog "bosh/logger"
+	boshmetrics "bosh/metrics"
 	"bosh/micro"
 	boshplatform "bosh/platform"
 	boshsettings "bosh/settings"
@@ -16,15 +17,18 @@ import (
 
 type MbusHandlerProvider struct {
 	settingsService boshsettings.Service
+	metrics         boshmetrics.Registry
 	logger          boshlog.Logger
 	handler         boshhandler.Handler
 }
 
 func NewHandlerProvider(
 	settingsService boshsettings.Service,
+	metrics boshmetrics.Registry,
 	logger boshlog.Logger,
 ) (p MbusHandlerProvider) {
 	p.settingsService = settingsService
+	p.metrics = metrics
 	p.logger = logger
 	return
 }
@@ -46,7 +50,7 @@ func (p MbusHandlerProvider) Get(
 
 	switch mbusURL.Scheme {
 	case "nats":
-		handler = NewNatsHandler(p.settingsService, yagnats.NewClient(), p.logger)
+		handler = NewNatsHandler(p.settingsService, yagnats.NewClient(), p.metrics, p.logger)
 	case "https":
 		handler = micro.NewHTTPSHandler(mbusURL, p.logger, platform.GetFs(), dirProvider)
 	default:
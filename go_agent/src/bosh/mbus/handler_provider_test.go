@@ -10,6 +10,7 @@ import (
 
 	boshlog "bosh/logger"
 	. "bosh/mbus"
+	fakemetrics "bosh/metrics/fakes"
 	"bosh/micro"
 	fakeplatform "bosh/platform/fakes"
 	boshdir "bosh/settings/directories"
@@ -30,7 +31,7 @@ var _ = Describe("MbusHandlerProvider", func() {
 		logger = boshlog.NewLogger(boshlog.LevelNone)
 		platform = fakeplatform.NewFakePlatform()
 		dirProvider = boshdir.NewDirectoriesProvider("/var/vcap")
-		provider = NewHandlerProvider(settingsService, logger)
+		provider = NewHandlerProvider(settingsService, fakemetrics.NewFakeRegistry(), logger)
 	})
 
 	Describe("Get", func() {
@@ -40,7 +41,7 @@ var _ = Describe("MbusHandlerProvider", func() {
 			Expect(err).ToNot(HaveOccurred())
 
 			// yagnats.NewClient returns new object every time
-			expectedHandler := NewNatsHandler(settingsService, yagnats.NewClient(), logger)
+			expectedHandler := NewNatsHandler(settingsService, yagnats.NewClient(), fakemetrics.NewFakeRegistry(), logger)
 			Expect(reflect.TypeOf(handler)).To(Equal(reflect.TypeOf(expectedHandler)))
 		})
 
@@ -1,14 +1,16 @@
 package mbus
 
 import (
+	boshjobsuper "bosh/jobsupervisor"
 	boshvitals "bosh/platform/vitals"
 )
 
 type Heartbeat struct {
-	Job      *string           `json:"job"`
-	Index    *int              `json:"index"`
-	JobState string            `json:"job_state"`
-	Vitals   boshvitals.Vitals `json:"vitals"`
+	Job       *string                `json:"job"`
+	Index     *int                   `json:"index"`
+	JobState  string                 `json:"job_state"`
+	Processes []boshjobsuper.Process `json:"processes,omitempty"`
+	Vitals    boshvitals.Vitals      `json:"vitals"`
 }
 
 //Heartbeat payload example:
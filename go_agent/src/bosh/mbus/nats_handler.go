@@ -13,6 +13,7 @@ import (
 	bosherr "bosh/errors"
 	boshhandler "bosh/handler"
 	boshlog "bosh/logger"
+	boshmetrics "bosh/metrics"
 	boshsettings "bosh/settings"
 )
 
@@ -24,6 +25,7 @@ const (
 type natsHandler struct {
 	settingsService boshsettings.Service
 	client          yagnats.NATSClient
+	metrics         boshmetrics.Registry
 	logger          boshlog.Logger
 	handlerFuncs    []boshhandler.HandlerFunc
 }
@@ -31,11 +33,13 @@ type natsHandler struct {
 func NewNatsHandler(
 	settingsService boshsettings.Service,
 	client yagnats.NATSClient,
+	metrics boshmetrics.Registry,
 	logger boshlog.Logger,
 ) *natsHandler {
 	return &natsHandler{
 		settingsService: settingsService,
 		client:          client,
+		metrics:         metrics,
 		logger:          logger,
 	}
 }
@@ -104,7 +108,14 @@ func (h natsHandler) SendToHealthManager(topic string, payload interface{}) erro
 	settings := h.settingsService.GetSettings()
 
 	subject := fmt.Sprintf("hm.agent.%s.%s", topic, settings.AgentID)
-	return h.client.Publish(subject, msgBytes)
+
+	err := h.client.Publish(subject, msgBytes)
+	if err != nil {
+		h.countPublishFailure(topic)
+		return err
+	}
+
+	return nil
 }
 
 func (h natsHandler) Stop() {
@@ -124,10 +135,18 @@ func (h natsHandler) handleNatsMsg(natsMsg *yagnats.Message, handlerFunc boshhan
 	}
 
 	if len(respBytes) > 0 {
-		h.client.Publish(req.ReplyTo, respBytes)
+		err = h.client.Publish(req.ReplyTo, respBytes)
+		if err != nil {
+			h.logger.Error(natsHandlerLogTag, "Publishing response: %s", err)
+			h.countPublishFailure("response")
+		}
 	}
 }
 
+func (h natsHandler) countPublishFailure(topic string) {
+	h.metrics.AddToCounter(boshmetrics.NatsPublishFailuresTotal, boshmetrics.Labels{"topic": topic}, 1)
+}
+
 func (h natsHandler) runUntilInterrupted() {
 	defer h.client.Disconnect()
 
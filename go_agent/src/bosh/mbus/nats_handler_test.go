@@ -12,6 +12,7 @@ import (
 	boshhandler "bosh/handler"
 	boshlog "bosh/logger"
 	. "bosh/mbus"
+	fakemetrics "bosh/metrics/fakes"
 	boshsettings "bosh/settings"
 	fakesettings "bosh/settings/fakes"
 )
@@ -34,7 +35,7 @@ func init() {
 			}
 			logger = boshlog.NewLogger(boshlog.LevelNone)
 			client = fakeyagnats.New()
-			handler = NewNatsHandler(settingsService, client, logger)
+			handler = NewNatsHandler(settingsService, client, fakemetrics.NewFakeRegistry(), logger)
 		})
 
 		Describe("Start", func() {
@@ -187,7 +188,7 @@ func init() {
 
 			It("does not err when no username and password", func() {
 				settingsService.Settings.Mbus = "nats://127.0.0.1:1234"
-				handler = NewNatsHandler(settingsService, client, logger)
+				handler = NewNatsHandler(settingsService, client, fakemetrics.NewFakeRegistry(), logger)
 
 				err := handler.Start(func(req boshhandler.Request) (res boshhandler.Response) { return })
 				Expect(err).ToNot(HaveOccurred())
@@ -196,7 +197,7 @@ func init() {
 
 			It("errs when has username without password", func() {
 				settingsService.Settings.Mbus = "nats://foo@127.0.0.1:1234"
-				handler = NewNatsHandler(settingsService, client, logger)
+				handler = NewNatsHandler(settingsService, client, fakemetrics.NewFakeRegistry(), logger)
 
 				err := handler.Start(func(req boshhandler.Request) (res boshhandler.Response) { return })
 				Expect(err).To(HaveOccurred())
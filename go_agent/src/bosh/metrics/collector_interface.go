@@ -0,0 +1,26 @@
+package metrics
+
+type MetricType string
+
+const (
+	MetricTypeCounter MetricType = "counter"
+	MetricTypeGauge   MetricType = "gauge"
+	MetricTypeSummary MetricType = "summary"
+)
+
+// Sample is a single value of a metric; metrics are grouped by name
+type Sample struct {
+	Name string
+
+	// Suffix distinguishes values of summary (e.g. "_sum" and "_count")
+	Suffix string
+
+	Type   MetricType
+	Labels Labels
+	Value  float64
+}
+
+// Collector returns current samples when metrics are scraped
+type Collector interface {
+	Collect() ([]Sample, error)
+}
@@ -0,0 +1,154 @@
+package metrics_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshtask "bosh/agent/task"
+	faketask "bosh/agent/task/fakes"
+	boshmonit "bosh/jobsupervisor/monit"
+	fakemonit "bosh/jobsupervisor/monit/fakes"
+	. "bosh/metrics"
+	boshntp "bosh/platform/ntp"
+	fakentp "bosh/platform/ntp/fakes"
+	boshvitals "bosh/platform/vitals"
+	fakevitals "bosh/platform/vitals/fakes"
+)
+
+var _ = Describe("vitalsCollector", func() {
+	var (
+		vitalsService *fakevitals.FakeService
+		collector     Collector
+	)
+
+	BeforeEach(func() {
+		vitalsService = fakevitals.NewFakeService()
+		collector = NewVitalsCollector(vitalsService)
+	})
+
+	It("returns vitals as gauges skipping values that are not set", func() {
+		vitalsService.GetVitals = boshvitals.Vitals{
+			Load: []string{"0.20", "4.55", "1.12"},
+			CPU:  boshvitals.CPUVitals{User: "56.0", Sys: "10.0"},
+			Mem:  boshvitals.MemoryVitals{Kb: "700", Percent: "70"},
+			Swap: boshvitals.MemoryVitals{Kb: "600", Percent: "60"},
+			Disk: boshvitals.DiskVitals{
+				"system": boshvitals.SpecificDiskVitals{Percent: "50", InodePercent: "10"},
+			},
+			DNS: &boshvitals.DNSVitals{CacheHits: "10", CacheMisses: "3"},
+		}
+
+		samples, err := collector.Collect()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(samples).To(Equal([]Sample{
+			Sample{Name: "bosh_agent_load_average", Type: MetricTypeGauge, Labels: Labels{"period": "1m"}, Value: 0.2},
+			Sample{Name: "bosh_agent_load_average", Type: MetricTypeGauge, Labels: Labels{"period": "5m"}, Value: 4.55},
+			Sample{Name: "bosh_agent_load_average", Type: MetricTypeGauge, Labels: Labels{"period": "15m"}, Value: 1.12},
+			Sample{Name: "bosh_agent_cpu_percent", Type: MetricTypeGauge, Labels: Labels{"mode": "user"}, Value: 56},
+			Sample{Name: "bosh_agent_cpu_percent", Type: MetricTypeGauge, Labels: Labels{"mode": "sys"}, Value: 10},
+			Sample{Name: "bosh_agent_mem_used_kb", Type: MetricTypeGauge, Value: 700},
+			Sample{Name: "bosh_agent_mem_used_percent", Type: MetricTypeGauge, Value: 70},
+			Sample{Name: "bosh_agent_swap_used_kb", Type: MetricTypeGauge, Value: 600},
+			Sample{Name: "bosh_agent_swap_used_percent", Type: MetricTypeGauge, Value: 60},
+			Sample{Name: "bosh_agent_disk_used_percent", Type: MetricTypeGauge, Labels: Labels{"disk": "system"}, Value: 50},
+			Sample{Name: "bosh_agent_disk_inode_used_percent", Type: MetricTypeGauge, Labels: Labels{"disk": "system"}, Value: 10},
+			Sample{Name: "bosh_agent_dns_cache_hits_total", Type: MetricTypeCounter, Value: 10},
+			Sample{Name: "bosh_agent_dns_cache_misses_total", Type: MetricTypeCounter, Value: 3},
+		}))
+	})
+
+	It("returns error when vitals cannot be retrieved", func() {
+		vitalsService.GetErr = errors.New("fake-vitals-err")
+
+		_, err := collector.Collect()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-vitals-err"))
+	})
+})
+
+var _ = Describe("taskCollector", func() {
+	It("returns number of tasks in each state", func() {
+		taskService := faketask.NewFakeService()
+		taskService.StartedTasks["fake-task-id-1"] = boshtask.Task{State: boshtask.TaskStateRunning}
+		taskService.StartedTasks["fake-task-id-2"] = boshtask.Task{State: boshtask.TaskStateDone}
+		taskService.StartedTasks["fake-task-id-3"] = boshtask.Task{State: boshtask.TaskStateDone}
+
+		samples, err := NewTaskCollector(taskService).Collect()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(samples).To(Equal([]Sample{
+			Sample{Name: "bosh_agent_tasks", Type: MetricTypeGauge, Labels: Labels{"state": "running"}, Value: 1},
+			Sample{Name: "bosh_agent_tasks", Type: MetricTypeGauge, Labels: Labels{"state": "done"}, Value: 2},
+			Sample{Name: "bosh_agent_tasks", Type: MetricTypeGauge, Labels: Labels{"state": "failed"}, Value: 0},
+		}))
+	})
+})
+
+var _ = Describe("monitCollector", func() {
+	var (
+		client    *fakemonit.FakeMonitClient
+		collector Collector
+	)
+
+	BeforeEach(func() {
+		client = fakemonit.NewFakeMonitClient()
+		collector = NewMonitCollector(client)
+	})
+
+	It("returns status of each service", func() {
+		client.StatusStatus = fakemonit.FakeMonitStatus{
+			Services: []boshmonit.Service{
+				boshmonit.Service{Name: "fake-service-1", Monitored: true, Status: "running"},
+				boshmonit.Service{Name: "fake-service-2", Monitored: false, Status: "unknown"},
+			},
+		}
+
+		samples, err := collector.Collect()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(samples).To(Equal([]Sample{
+			Sample{Name: "bosh_agent_monit_service_status", Type: MetricTypeGauge, Labels: Labels{"service": "fake-service-1", "status": "running"}, Value: 1},
+			Sample{Name: "bosh_agent_monit_service_monitored", Type: MetricTypeGauge, Labels: Labels{"service": "fake-service-1"}, Value: 1},
+			Sample{Name: "bosh_agent_monit_service_status", Type: MetricTypeGauge, Labels: Labels{"service": "fake-service-2", "status": "unknown"}, Value: 1},
+			Sample{Name: "bosh_agent_monit_service_monitored", Type: MetricTypeGauge, Labels: Labels{"service": "fake-service-2"}, Value: 0},
+		}))
+	})
+
+	It("returns error when monit status cannot be retrieved", func() {
+		client.StatusErr = errors.New("fake-monit-err")
+
+		_, err := collector.Collect()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-monit-err"))
+	})
+})
+
+var _ = Describe("ntpCollector", func() {
+	var (
+		ntpService *fakentp.FakeService
+		collector  Collector
+	)
+
+	BeforeEach(func() {
+		ntpService = &fakentp.FakeService{}
+		collector = NewNTPCollector(ntpService)
+	})
+
+	It("returns ntp offset", func() {
+		ntpService.GetOffsetNTPOffset = boshntp.NTPInfo{Offset: "-0.081236", Timestamp: "10 Oct 17:15:21"}
+
+		samples, err := collector.Collect()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(samples).To(Equal([]Sample{
+			Sample{Name: "bosh_agent_ntp_offset_seconds", Type: MetricTypeGauge, Value: -0.081236},
+		}))
+	})
+
+	It("returns nothing when time has not been synchronized", func() {
+		ntpService.GetOffsetNTPOffset = boshntp.NTPInfo{Message: "bad ntp server"}
+
+		samples, err := collector.Collect()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(samples).To(BeEmpty())
+	})
+})
@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type registryEntry struct {
+	name   string
+	labels Labels
+
+	// Counters only use value; summaries use value as sum
+	value float64
+	count uint64
+}
+
+type concreteRegistry struct {
+	lock      sync.Mutex
+	counters  map[string]*registryEntry
+	summaries map[string]*registryEntry
+}
+
+func NewRegistry() Registry {
+	return &concreteRegistry{
+		counters:  map[string]*registryEntry{},
+		summaries: map[string]*registryEntry{},
+	}
+}
+
+func (r *concreteRegistry) AddToCounter(name string, labels Labels, value float64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	entry := r.findOrCreateEntry(r.counters, name, labels)
+	entry.value += value
+}
+
+func (r *concreteRegistry) ObserveDuration(name string, labels Labels, duration time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	entry := r.findOrCreateEntry(r.summaries, name, labels)
+	entry.value += duration.Seconds()
+	entry.count++
+}
+
+func (r *concreteRegistry) Collect() ([]Sample, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var samples []Sample
+
+	for _, entry := range sortedEntries(r.counters) {
+		samples = append(samples, Sample{
+			Name:   entry.name,
+			Type:   MetricTypeCounter,
+			Labels: entry.labels,
+			Value:  entry.value,
+		})
+	}
+
+	for _, entry := range sortedEntries(r.summaries) {
+		samples = append(samples,
+			Sample{
+				Name:   entry.name,
+				Suffix: "_sum",
+				Type:   MetricTypeSummary,
+				Labels: entry.labels,
+				Value:  entry.value,
+			},
+			Sample{
+				Name:   entry.name,
+				Suffix: "_count",
+				Type:   MetricTypeSummary,
+				Labels: entry.labels,
+				Value:  float64(entry.count),
+			},
+		)
+	}
+
+	return samples, nil
+}
+
+func (r *concreteRegistry) findOrCreateEntry(entries map[string]*registryEntry, name string, labels Labels) *registryEntry {
+	key := entryKey(name, labels)
+
+	entry, found := entries[key]
+	if !found {
+		// Copy labels so that callers can reuse their maps
+		entryLabels := Labels{}
+		for k, v := range labels {
+			entryLabels[k] = v
+		}
+
+		entry = &registryEntry{name: name, labels: entryLabels}
+		entries[key] = entry
+	}
+
+	return entry
+}
+
+func entryKey(name string, labels Labels) string {
+	parts := []string{name}
+
+	for _, labelName := range sortedLabelNames(labels) {
+		parts = append(parts, labelName+"="+labels[labelName])
+	}
+
+	return strings.Join(parts, "\x00")
+}
+
+func sortedEntries(entries map[string]*registryEntry) []*registryEntry {
+	var keys []string
+	for key := range entries {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var sorted []*registryEntry
+	for _, key := range keys {
+		sorted = append(sorted, entries[key])
+	}
+
+	return sorted
+}
+
+func sortedLabelNames(labels Labels) []string {
+	var names []string
+	for name := range labels {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
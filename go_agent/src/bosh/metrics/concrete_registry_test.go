@@ -0,0 +1,57 @@
+package metrics_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/metrics"
+)
+
+var _ = Describe("concreteRegistry", func() {
+	var (
+		registry Registry
+	)
+
+	BeforeEach(func() {
+		registry = NewRegistry()
+	})
+
+	Describe("Collect", func() {
+		It("returns nothing when nothing was recorded", func() {
+			samples, err := registry.Collect()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(samples).To(BeEmpty())
+		})
+
+		It("returns counters accumulated per labels", func() {
+			labels := Labels{"topic": "heartbeat"}
+			registry.AddToCounter("fake_total", labels, 1)
+			registry.AddToCounter("fake_total", Labels{"topic": "alert"}, 3)
+			registry.AddToCounter("fake_total", labels, 2)
+
+			// Changing labels after recording does not affect recorded metric
+			labels["topic"] = "fake-changed-topic"
+
+			samples, err := registry.Collect()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(samples).To(Equal([]Sample{
+				Sample{Name: "fake_total", Type: MetricTypeCounter, Labels: Labels{"topic": "alert"}, Value: 3},
+				Sample{Name: "fake_total", Type: MetricTypeCounter, Labels: Labels{"topic": "heartbeat"}, Value: 3},
+			}))
+		})
+
+		It("returns sum and count of observed durations", func() {
+			registry.ObserveDuration("fake_seconds", Labels{"action": "ping"}, 500*time.Millisecond)
+			registry.ObserveDuration("fake_seconds", Labels{"action": "ping"}, 2*time.Second)
+
+			samples, err := registry.Collect()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(samples).To(Equal([]Sample{
+				Sample{Name: "fake_seconds", Suffix: "_sum", Type: MetricTypeSummary, Labels: Labels{"action": "ping"}, Value: 2.5},
+				Sample{Name: "fake_seconds", Suffix: "_count", Type: MetricTypeSummary, Labels: Labels{"action": "ping"}, Value: 2},
+			}))
+		})
+	})
+})
@@ -0,0 +1,49 @@
+package fakes
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	boshmetrics "bosh/metrics"
+)
+
+type FakeRegistry struct {
+	Counters  map[string]float64
+	Durations map[string][]time.Duration
+
+	CollectSamples []boshmetrics.Sample
+	CollectErr     error
+}
+
+func NewFakeRegistry() *FakeRegistry {
+	return &FakeRegistry{
+		Counters:  map[string]float64{},
+		Durations: map[string][]time.Duration{},
+	}
+}
+
+func (r *FakeRegistry) AddToCounter(name string, labels boshmetrics.Labels, value float64) {
+	r.Counters[FakeRegistryKey(name, labels)] += value
+}
+
+func (r *FakeRegistry) ObserveDuration(name string, labels boshmetrics.Labels, duration time.Duration) {
+	key := FakeRegistryKey(name, labels)
+	r.Durations[key] = append(r.Durations[key], duration)
+}
+
+func (r *FakeRegistry) Collect() ([]boshmetrics.Sample, error) {
+	return r.CollectSamples, r.CollectErr
+}
+
+// FakeRegistryKey identifies metric by name and labels, e.g. "name{a=1,b=2}"
+func FakeRegistryKey(name string, labels boshmetrics.Labels) string {
+	var pairs []string
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+
+	sort.Strings(pairs)
+
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
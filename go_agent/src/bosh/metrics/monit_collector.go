@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	bosherr "bosh/errors"
+	boshmonit "bosh/jobsupervisor/monit"
+)
+
+type monitCollector struct {
+	client boshmonit.Client
+}
+
+func NewMonitCollector(client boshmonit.Client) Collector {
+	return monitCollector{client: client}
+}
+
+func (c monitCollector) Collect() ([]Sample, error) {
+	status, err := c.client.Status()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Getting monit status")
+	}
+
+	var samples []Sample
+
+	for _, service := range status.ServicesInGroup("vcap") {
+		var monitored float64
+		if service.Monitored {
+			monitored = 1
+		}
+
+		samples = append(samples,
+			Sample{
+				Name:   "bosh_agent_monit_service_status",
+				Type:   MetricTypeGauge,
+				Labels: Labels{"service": service.Name, "status": service.Status},
+				Value:  1,
+			},
+			Sample{
+				Name:   "bosh_agent_monit_service_monitored",
+				Type:   MetricTypeGauge,
+				Labels: Labels{"service": service.Name},
+				Value:  monitored,
+			},
+		)
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,10 @@
+package metrics
+
+const (
+	ActionDurationSeconds = "bosh_agent_action_duration_seconds"
+
+	NatsPublishFailuresTotal = "bosh_agent_nats_publish_failures_total"
+
+	BlobstoreTransferredBytesTotal = "bosh_agent_blobstore_transferred_bytes_total"
+	BlobstoreRetriesTotal          = "bosh_agent_blobstore_retries_total"
+)
@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"strconv"
+
+	boshntp "bosh/platform/ntp"
+)
+
+type ntpCollector struct {
+	ntpService boshntp.Service
+}
+
+func NewNTPCollector(ntpService boshntp.Service) Collector {
+	return ntpCollector{ntpService: ntpService}
+}
+
+// Collect does not return offset when ntpdate has not synchronized time
+func (c ntpCollector) Collect() ([]Sample, error) {
+	offset, err := strconv.ParseFloat(c.ntpService.GetInfo().Offset, 64)
+	if err != nil {
+		return []Sample{}, nil
+	}
+
+	return []Sample{
+		Sample{Name: "bosh_agent_ntp_offset_seconds", Type: MetricTypeGauge, Value: offset},
+	}, nil
+}
@@ -0,0 +1,19 @@
+package metrics
+
+import (
+	"time"
+)
+
+type Labels map[string]string
+
+// Registry accumulates metrics reported by agent components
+// for the lifetime of the agent process
+type Registry interface {
+	Collector
+
+	// AddToCounter increases counter that only goes up (e.g. number of failures)
+	AddToCounter(name string, labels Labels, value float64)
+
+	// ObserveDuration records duration in summary that keeps count and sum of observations
+	ObserveDuration(name string, labels Labels, duration time.Duration)
+}
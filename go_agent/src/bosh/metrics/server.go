@@ -0,0 +1,181 @@
+package metrics
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+
+	bosherr "bosh/errors"
+	boshlog "bosh/logger"
+)
+
+const metricsServerLogTag = "Metrics Server"
+
+type Options struct {
+	// Metrics are only served when port is set
+	Port int
+
+	// Network interface (e.g. eth0) whose IPv4 address is listened on;
+	// by default only localhost is listened on.
+	// Metrics served on other than loopback address require mbus credentials.
+	Interface string
+}
+
+type Server interface {
+	// Start blocks until server is stopped
+	Start() error
+	Stop()
+}
+
+type httpServer struct {
+	options     Options
+	collectors  []Collector
+	credentials *url.Userinfo
+	logger      boshlog.Logger
+
+	requireAuth bool
+
+	listener     net.Listener
+	listenerLock sync.Mutex
+}
+
+// NewServer takes credentials (e.g. of mbus) that are required from clients
+// when metrics are served on other than loopback address
+func NewServer(options Options, collectors []Collector, credentials *url.Userinfo, logger boshlog.Logger) Server {
+	return &httpServer{
+		options:     options,
+		collectors:  collectors,
+		credentials: credentials,
+		logger:      logger,
+	}
+}
+
+func (s *httpServer) Start() error {
+	host, err := s.listenHost()
+	if err != nil {
+		return bosherr.WrapError(err, "Resolving listen address")
+	}
+
+	// Metrics reveal details of the VM so they are not served unauthenticated to other hosts
+	s.requireAuth = !net.ParseIP(host).IsLoopback()
+
+	if s.requireAuth && !s.hasCredentials() {
+		return bosherr.New("Refusing to serve metrics on %s without credentials", host)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, fmt.Sprintf("%d", s.options.Port)))
+	if err != nil {
+		return bosherr.WrapError(err, "Listening for metrics requests")
+	}
+
+	s.listenerLock.Lock()
+	s.listener = listener
+	s.listenerLock.Unlock()
+
+	s.logger.Info(metricsServerLogTag, "Serving metrics on %s", listener.Addr())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.handleMetrics)
+
+	return http.Serve(listener, mux)
+}
+
+func (s *httpServer) Stop() {
+	s.listenerLock.Lock()
+	defer s.listenerLock.Unlock()
+
+	if s.listener != nil {
+		s.listener.Close()
+	}
+}
+
+func (s *httpServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if s.requireAuth && !s.isAuthorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="metrics"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var samples []Sample
+
+	// Scrape still succeeds with metrics of the remaining collectors
+	for _, collector := range s.collectors {
+		collectedSamples, err := collector.Collect()
+		if err != nil {
+			s.logger.Error(metricsServerLogTag, "Collecting metrics: %s", err.Error())
+			continue
+		}
+
+		samples = append(samples, collectedSamples...)
+	}
+
+	var buf bytes.Buffer
+
+	err := WriteText(&buf, samples)
+	if err != nil {
+		s.logger.Error(metricsServerLogTag, "Writing metrics: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(buf.Bytes())
+}
+
+func (s *httpServer) hasCredentials() bool {
+	if s.credentials == nil {
+		return false
+	}
+
+	password, _ := s.credentials.Password()
+
+	return len(s.credentials.Username()) > 0 && len(password) > 0
+}
+
+func (s *httpServer) isAuthorized(r *http.Request) bool {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	expectedPassword, _ := s.credentials.Password()
+
+	usernameMatches := subtle.ConstantTimeCompare([]byte(username), []byte(s.credentials.Username())) == 1
+	passwordMatches := subtle.ConstantTimeCompare([]byte(password), []byte(expectedPassword)) == 1
+
+	return usernameMatches && passwordMatches
+}
+
+func (s *httpServer) listenHost() (string, error) {
+	if s.options.Interface == "" {
+		return "127.0.0.1", nil
+	}
+
+	iface, err := net.InterfaceByName(s.options.Interface)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Finding interface %s", s.options.Interface)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return "", bosherr.WrapError(err, "Getting addresses of interface %s", s.options.Interface)
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if ok && ipNet.IP.To4() != nil {
+			return ipNet.IP.String(), nil
+		}
+	}
+
+	return "", bosherr.New("Interface %s does not have IPv4 address", s.options.Interface)
+}
@@ -0,0 +1,216 @@
+package metrics_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshlog "bosh/logger"
+	. "bosh/metrics"
+	fakemetrics "bosh/metrics/fakes"
+)
+
+var _ = Describe("httpServer", func() {
+	var (
+		collector       *fakemetrics.FakeRegistry
+		failedCollector *fakemetrics.FakeRegistry
+		logger          boshlog.Logger
+		port            int
+		server          Server
+	)
+
+	BeforeEach(func() {
+		collector = fakemetrics.NewFakeRegistry()
+		collector.CollectSamples = []Sample{
+			Sample{Name: "fake_tasks", Type: MetricTypeGauge, Labels: Labels{"state": "running"}, Value: 2},
+		}
+
+		failedCollector = fakemetrics.NewFakeRegistry()
+		failedCollector.CollectErr = errors.New("fake-collect-err")
+
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+
+		// Find free port
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		port = listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+	})
+
+	Context("when interface is not configured", func() {
+		BeforeEach(func() {
+			server = NewServer(Options{Port: port}, []Collector{failedCollector, collector}, nil, logger)
+			go server.Start()
+		})
+
+		AfterEach(func() {
+			server.Stop()
+		})
+
+		get := func(method string) *http.Response {
+			url := fmt.Sprintf("http://127.0.0.1:%d/metrics", port)
+
+			var resp *http.Response
+			var err error
+
+			// Wait for server to start listening
+			for i := 0; i < 100; i++ {
+				var req *http.Request
+				req, err = http.NewRequest(method, url, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				resp, err = http.DefaultClient.Do(req)
+				if err == nil {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			Expect(err).ToNot(HaveOccurred())
+			return resp
+		}
+
+		It("serves metrics of collectors that succeed on localhost", func() {
+			resp := get("GET")
+			defer resp.Body.Close()
+
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(Equal("text/plain; version=0.0.4"))
+
+			body, err := ioutil.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(Equal("# TYPE fake_tasks gauge\nfake_tasks{state=\"running\"} 2\n"))
+		})
+
+		It("does not allow other methods than GET", func() {
+			resp := get("POST")
+			defer resp.Body.Close()
+
+			Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+		})
+	})
+
+	Context("when interface is configured", func() {
+		var (
+			ifaceName string
+			ifaceIP   string
+		)
+
+		BeforeEach(func() {
+			// Any interface with non-loopback IPv4 address will do
+			ifaces, err := net.Interfaces()
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, iface := range ifaces {
+				addrs, err := iface.Addrs()
+				Expect(err).ToNot(HaveOccurred())
+
+				for _, addr := range addrs {
+					ipNet, ok := addr.(*net.IPNet)
+					if ok && ipNet.IP.To4() != nil && !ipNet.IP.IsLoopback() && ifaceName == "" {
+						ifaceName = iface.Name
+						ifaceIP = ipNet.IP.String()
+					}
+				}
+			}
+
+			Expect(ifaceName).ToNot(BeEmpty(), "Expected interface with non-loopback IPv4 address")
+		})
+
+		get := func(username, password string) *http.Response {
+			url := fmt.Sprintf("http://%s/metrics", net.JoinHostPort(ifaceIP, fmt.Sprintf("%d", port)))
+
+			var resp *http.Response
+			var err error
+
+			// Wait for server to start listening
+			for i := 0; i < 100; i++ {
+				var req *http.Request
+				req, err = http.NewRequest("GET", url, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				if username != "" {
+					req.SetBasicAuth(username, password)
+				}
+
+				resp, err = http.DefaultClient.Do(req)
+				if err == nil {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			Expect(err).ToNot(HaveOccurred())
+			return resp
+		}
+
+		Context("when credentials are given", func() {
+			BeforeEach(func() {
+				credentials := neturl.UserPassword("fake-user", "fake-password")
+				server = NewServer(Options{Port: port, Interface: ifaceName}, []Collector{collector}, credentials, logger)
+				go server.Start()
+			})
+
+			AfterEach(func() {
+				server.Stop()
+			})
+
+			It("serves metrics to clients with matching credentials", func() {
+				resp := get("fake-user", "fake-password")
+				defer resp.Body.Close()
+
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+				body, err := ioutil.ReadAll(resp.Body)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(body)).To(Equal("# TYPE fake_tasks gauge\nfake_tasks{state=\"running\"} 2\n"))
+			})
+
+			It("does not serve metrics to clients without credentials", func() {
+				resp := get("", "")
+				defer resp.Body.Close()
+
+				Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+				Expect(resp.Header.Get("WWW-Authenticate")).To(Equal(`Basic realm="metrics"`))
+			})
+
+			It("does not serve metrics to clients with wrong credentials", func() {
+				resp := get("fake-user", "fake-wrong-password")
+				defer resp.Body.Close()
+
+				Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+
+		It("returns error without listening when credentials are not given", func() {
+			server = NewServer(Options{Port: port, Interface: ifaceName}, []Collector{collector}, nil, logger)
+
+			err := server.Start()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Refusing to serve metrics on " + ifaceIP + " without credentials"))
+		})
+
+		It("returns error without listening when credentials do not have password", func() {
+			server = NewServer(Options{Port: port, Interface: ifaceName}, []Collector{collector}, neturl.User("fake-user"), logger)
+
+			err := server.Start()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("without credentials"))
+		})
+
+		It("returns error when interface does not exist", func() {
+			server = NewServer(Options{Port: port, Interface: "fake-missing-iface"}, []Collector{}, nil, logger)
+
+			err := server.Start()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Finding interface fake-missing-iface"))
+		})
+	})
+})
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	boshtask "bosh/agent/task"
+)
+
+type taskCollector struct {
+	taskService boshtask.Service
+}
+
+func NewTaskCollector(taskService boshtask.Service) Collector {
+	return taskCollector{taskService: taskService}
+}
+
+func (c taskCollector) Collect() ([]Sample, error) {
+	counts := c.taskService.CountTasksByState()
+
+	var samples []Sample
+
+	// All states are always reported so that missing series do not look like scrape failures
+	for _, state := range []boshtask.TaskState{boshtask.TaskStateRunning, boshtask.TaskStateDone, boshtask.TaskStateFailed} {
+		samples = append(samples, Sample{
+			Name:   "bosh_agent_tasks",
+			Type:   MetricTypeGauge,
+			Labels: Labels{"state": string(state)},
+			Value:  float64(counts[state]),
+		})
+	}
+
+	return samples, nil
+}
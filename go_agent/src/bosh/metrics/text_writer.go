@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteText writes samples in Prometheus text exposition format (version 0.0.4).
+// Samples of the same metric are written together in the order the metric first appears.
+func WriteText(w io.Writer, samples []Sample) error {
+	var names []string
+	samplesByName := map[string][]Sample{}
+
+	for _, sample := range samples {
+		if _, found := samplesByName[sample.Name]; !found {
+			names = append(names, sample.Name)
+		}
+		samplesByName[sample.Name] = append(samplesByName[sample.Name], sample)
+	}
+
+	for _, name := range names {
+		namedSamples := samplesByName[name]
+
+		_, err := fmt.Fprintf(w, "# TYPE %s %s\n", name, namedSamples[0].Type)
+		if err != nil {
+			return err
+		}
+
+		for _, sample := range namedSamples {
+			_, err = fmt.Fprintf(w, "%s%s%s %s\n", sample.Name, sample.Suffix, formatLabels(sample.Labels), formatValue(sample.Value))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	var pairs []string
+
+	for _, name := range sortedLabelNames(labels) {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, labelValueReplacer.Replace(labels[name])))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
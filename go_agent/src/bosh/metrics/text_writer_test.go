@@ -0,0 +1,69 @@
+package metrics_test
+
+import (
+	"bytes"
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/metrics"
+)
+
+var _ = Describe("WriteText", func() {
+	It("writes samples grouped by metric name with type", func() {
+		var buf bytes.Buffer
+
+		err := WriteText(&buf, []Sample{
+			Sample{Name: "fake_tasks", Type: MetricTypeGauge, Labels: Labels{"state": "running"}, Value: 1},
+			Sample{Name: "fake_offset", Type: MetricTypeGauge, Value: -0.25},
+			Sample{Name: "fake_tasks", Type: MetricTypeGauge, Labels: Labels{"state": "done"}, Value: 12},
+			Sample{Name: "fake_seconds", Suffix: "_sum", Type: MetricTypeSummary, Value: 1.5},
+			Sample{Name: "fake_seconds", Suffix: "_count", Type: MetricTypeSummary, Value: 3},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(buf.String()).To(Equal(`# TYPE fake_tasks gauge
+fake_tasks{state="running"} 1
+fake_tasks{state="done"} 12
+# TYPE fake_offset gauge
+fake_offset -0.25
+# TYPE fake_seconds summary
+fake_seconds_sum 1.5
+fake_seconds_count 3
+`))
+	})
+
+	It("sorts labels and escapes label values", func() {
+		var buf bytes.Buffer
+
+		err := WriteText(&buf, []Sample{
+			Sample{
+				Name:   "fake_status",
+				Type:   MetricTypeGauge,
+				Labels: Labels{"status": "fake\n\"status\"", "service": `fake\service`},
+				Value:  1,
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(buf.String()).To(Equal(`# TYPE fake_status gauge
+fake_status{service="fake\\service",status="fake\n\"status\""} 1
+`))
+	})
+
+	It("writes special float values", func() {
+		var buf bytes.Buffer
+
+		err := WriteText(&buf, []Sample{
+			Sample{Name: "fake_value", Type: MetricTypeGauge, Labels: Labels{"v": "nan"}, Value: math.NaN()},
+			Sample{Name: "fake_value", Type: MetricTypeGauge, Labels: Labels{"v": "inf"}, Value: math.Inf(1)},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(buf.String()).To(Equal(`# TYPE fake_value gauge
+fake_value{v="nan"} NaN
+fake_value{v="inf"} +Inf
+`))
+	})
+})
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"strconv"
+
+	bosherr "bosh/errors"
+	boshvitals "bosh/platform/vitals"
+)
+
+type vitalsCollector struct {
+	vitalsService boshvitals.Service
+}
+
+func NewVitalsCollector(vitalsService boshvitals.Service) Collector {
+	return vitalsCollector{vitalsService: vitalsService}
+}
+
+func (c vitalsCollector) Collect() ([]Sample, error) {
+	vitals, err := c.vitalsService.Get()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Getting vitals")
+	}
+
+	var samples []Sample
+
+	// Vitals are formatted for heartbeats; values that are not set are skipped
+	add := func(name string, metricType MetricType, labels Labels, value string) {
+		parsedValue, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return
+		}
+
+		samples = append(samples, Sample{Name: name, Type: metricType, Labels: labels, Value: parsedValue})
+	}
+
+	for i, period := range []string{"1m", "5m", "15m"} {
+		if i < len(vitals.Load) {
+			add("bosh_agent_load_average", MetricTypeGauge, Labels{"period": period}, vitals.Load[i])
+		}
+	}
+
+	add("bosh_agent_cpu_percent", MetricTypeGauge, Labels{"mode": "user"}, vitals.CPU.User)
+	add("bosh_agent_cpu_percent", MetricTypeGauge, Labels{"mode": "sys"}, vitals.CPU.Sys)
+	add("bosh_agent_cpu_percent", MetricTypeGauge, Labels{"mode": "wait"}, vitals.CPU.Wait)
+
+	add("bosh_agent_mem_used_kb", MetricTypeGauge, nil, vitals.Mem.Kb)
+	add("bosh_agent_mem_used_percent", MetricTypeGauge, nil, vitals.Mem.Percent)
+	add("bosh_agent_swap_used_kb", MetricTypeGauge, nil, vitals.Swap.Kb)
+	add("bosh_agent_swap_used_percent", MetricTypeGauge, nil, vitals.Swap.Percent)
+
+	for _, name := range []string{"system", "ephemeral", "persistent"} {
+		diskVitals, found := vitals.Disk[name]
+		if !found {
+			continue
+		}
+
+		add("bosh_agent_disk_used_percent", MetricTypeGauge, Labels{"disk": name}, diskVitals.Percent)
+		add("bosh_agent_disk_inode_used_percent", MetricTypeGauge, Labels{"disk": name}, diskVitals.InodePercent)
+	}
+
+	if vitals.DNS != nil {
+		add("bosh_agent_dns_cache_hits_total", MetricTypeCounter, nil, vitals.DNS.CacheHits)
+		add("bosh_agent_dns_cache_misses_total", MetricTypeCounter, nil, vitals.DNS.CacheMisses)
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,16 @@
+package disk
+
+type CheckResult struct {
+	FileSystem FileSystemType `json:"file_system"`
+	ExitStatus int            `json:"exit_status"`
+
+	// Errors were found and corrected
+	Repaired bool `json:"repaired"`
+
+	Output string `json:"output,omitempty"`
+}
+
+type Checker interface {
+	// Check returns error when file system has errors that could not be corrected
+	Check(partitionPath string, fsType FileSystemType) (CheckResult, error)
+}
@@ -0,0 +1,18 @@
+package fakes
+
+import (
+	boshdisk "bosh/platform/disk"
+)
+
+type FakeChecker struct {
+	CheckPartitionPaths []string
+	CheckFsTypes        []boshdisk.FileSystemType
+	CheckResult         boshdisk.CheckResult
+	CheckErr            error
+}
+
+func (c *FakeChecker) Check(partitionPath string, fsType boshdisk.FileSystemType) (boshdisk.CheckResult, error) {
+	c.CheckPartitionPaths = append(c.CheckPartitionPaths, partitionPath)
+	c.CheckFsTypes = append(c.CheckFsTypes, fsType)
+	return c.CheckResult, c.CheckErr
+}
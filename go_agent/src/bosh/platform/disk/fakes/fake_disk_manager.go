@@ -5,16 +5,22 @@ import (
 )
 
 type FakeDiskManager struct {
-	FakePartitioner *FakePartitioner
-	FakeFormatter   *FakeFormatter
-	FakeMounter     *FakeMounter
+	FakePartitioner   *FakePartitioner
+	FakeFormatter     *FakeFormatter
+	FakeChecker       *FakeChecker
+	FakeResizer       *FakeResizer
+	FakeMounter       *FakeMounter
+	FakeVolumeManager *FakeVolumeManager
 }
 
 func NewFakeDiskManager() (manager *FakeDiskManager) {
 	manager = &FakeDiskManager{}
 	manager.FakePartitioner = &FakePartitioner{}
 	manager.FakeFormatter = &FakeFormatter{}
+	manager.FakeChecker = &FakeChecker{}
+	manager.FakeResizer = &FakeResizer{}
 	manager.FakeMounter = &FakeMounter{}
+	manager.FakeVolumeManager = &FakeVolumeManager{}
 	return
 }
 
@@ -26,6 +32,18 @@ func (m FakeDiskManager) GetFormatter() boshdisk.Formatter {
 	return m.FakeFormatter
 }
 
+func (m FakeDiskManager) GetChecker() boshdisk.Checker {
+	return m.FakeChecker
+}
+
+func (m FakeDiskManager) GetResizer() boshdisk.Resizer {
+	return m.FakeResizer
+}
+
 func (m FakeDiskManager) GetMounter() boshdisk.Mounter {
 	return m.FakeMounter
 }
+
+func (m FakeDiskManager) GetVolumeManager() boshdisk.VolumeManager {
+	return m.FakeVolumeManager
+}
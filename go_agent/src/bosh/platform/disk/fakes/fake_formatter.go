@@ -8,11 +8,19 @@ type FakeFormatter struct {
 	FormatCalled         bool
 	FormatPartitionPaths []string
 	FormatFsTypes        []boshdisk.FileSystemType
+	FormatErr            error
+
+	DetectFileSystemTypeTypes map[string]boshdisk.FileSystemType
 }
 
 func (p *FakeFormatter) Format(partitionPath string, fsType boshdisk.FileSystemType) (err error) {
 	p.FormatCalled = true
 	p.FormatPartitionPaths = append(p.FormatPartitionPaths, partitionPath)
 	p.FormatFsTypes = append(p.FormatFsTypes, fsType)
+	err = p.FormatErr
 	return
 }
+
+func (p *FakeFormatter) DetectFileSystemType(partitionPath string) boshdisk.FileSystemType {
+	return p.DetectFileSystemTypeTypes[partitionPath]
+}
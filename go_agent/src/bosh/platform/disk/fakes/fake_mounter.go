@@ -10,6 +10,10 @@ type FakeMounter struct {
 	RemountAsReadonlyPath   string
 	RemountAsReadonlyErr    error
 
+	RemountAsReadWriteCalled bool
+	RemountAsReadWritePath   string
+	RemountAsReadWriteErr    error
+
 	RemountFromMountPoint string
 	RemountToMountPoint   string
 	RemountMountOptions   []string
@@ -18,9 +22,10 @@ type FakeMounter struct {
 	SwapOnPartitionPaths []string
 	SwapOnErr            error
 
-	UnmountPartitionPathOrMountPoint string
-	UnmountDidUnmount                bool
-	UnmountErr                       error
+	UnmountPartitionPathOrMountPoint   string
+	UnmountPartitionPathsOrMountPoints []string
+	UnmountDidUnmount                  bool
+	UnmountErr                         error
 
 	IsMountPointPath   string
 	IsMountPointResult bool
@@ -44,6 +49,12 @@ func (m *FakeMounter) RemountAsReadonly(mountPoint string) (err error) {
 	return m.RemountAsReadonlyErr
 }
 
+func (m *FakeMounter) RemountAsReadWrite(mountPoint string) (err error) {
+	m.RemountAsReadWriteCalled = true
+	m.RemountAsReadWritePath = mountPoint
+	return m.RemountAsReadWriteErr
+}
+
 func (m *FakeMounter) Remount(fromMountPoint, toMountPoint string, mountOptions ...string) (err error) {
 	m.RemountFromMountPoint = fromMountPoint
 	m.RemountToMountPoint = toMountPoint
@@ -58,6 +69,7 @@ func (m *FakeMounter) SwapOn(partitionPath string) (err error) {
 
 func (m *FakeMounter) Unmount(partitionPathOrMountPoint string) (didUnmount bool, err error) {
 	m.UnmountPartitionPathOrMountPoint = partitionPathOrMountPoint
+	m.UnmountPartitionPathsOrMountPoints = append(m.UnmountPartitionPathsOrMountPoints, partitionPathOrMountPoint)
 	return m.UnmountDidUnmount, m.UnmountErr
 }
 
@@ -10,6 +10,15 @@ type FakePartitioner struct {
 	PartitionPartitions []boshdisk.Partition
 
 	GetDeviceSizeInMbSizes map[string]uint64
+
+	HasPartitionsDevicePath string
+	HasPartitionsPartitions []boshdisk.Partition
+	HasPartitionsResult     bool
+
+	GrowPartitionDevicePath      string
+	GrowPartitionPartitionNumber int
+	GrowPartitionGrown           bool
+	GrowPartitionErr             error
 }
 
 func (p *FakePartitioner) Partition(devicePath string, partitions []boshdisk.Partition) (err error) {
@@ -23,3 +32,15 @@ func (p *FakePartitioner) GetDeviceSizeInMb(devicePath string) (size uint64, err
 	size = p.GetDeviceSizeInMbSizes[devicePath]
 	return
 }
+
+func (p *FakePartitioner) HasPartitions(devicePath string, partitions []boshdisk.Partition) bool {
+	p.HasPartitionsDevicePath = devicePath
+	p.HasPartitionsPartitions = partitions
+	return p.HasPartitionsResult
+}
+
+func (p *FakePartitioner) GrowPartition(devicePath string, partitionNumber int) (bool, error) {
+	p.GrowPartitionDevicePath = devicePath
+	p.GrowPartitionPartitionNumber = partitionNumber
+	return p.GrowPartitionGrown, p.GrowPartitionErr
+}
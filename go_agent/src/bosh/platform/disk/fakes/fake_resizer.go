@@ -0,0 +1,30 @@
+package fakes
+
+import (
+	boshdisk "bosh/platform/disk"
+)
+
+type FakeResizer struct {
+	NeedsToGrowFileSystemPartitionPath string
+	NeedsToGrowFileSystemNeeds         bool
+	NeedsToGrowFileSystemErr           error
+
+	GrowFileSystemCalled        bool
+	GrowFileSystemPartitionPath string
+	GrowFileSystemMountPoint    string
+	GrowFileSystemFsType        boshdisk.FileSystemType
+	GrowFileSystemErr           error
+}
+
+func (r *FakeResizer) NeedsToGrowFileSystem(partitionPath, mountPoint string, fsType boshdisk.FileSystemType) (bool, error) {
+	r.NeedsToGrowFileSystemPartitionPath = partitionPath
+	return r.NeedsToGrowFileSystemNeeds, r.NeedsToGrowFileSystemErr
+}
+
+func (r *FakeResizer) GrowFileSystem(partitionPath, mountPoint string, fsType boshdisk.FileSystemType) error {
+	r.GrowFileSystemCalled = true
+	r.GrowFileSystemPartitionPath = partitionPath
+	r.GrowFileSystemMountPoint = mountPoint
+	r.GrowFileSystemFsType = fsType
+	return r.GrowFileSystemErr
+}
@@ -0,0 +1,96 @@
+package fakes
+
+import (
+	boshdisk "bosh/platform/disk"
+)
+
+type FakeVolumeManager struct {
+	FindVolumePhysicalVolumePath string
+	FindVolumeName               string
+	FindVolumeVolume             boshdisk.LogicalVolume
+	FindVolumeFound              bool
+	FindVolumeErr                error
+
+	CreateVolumeCalled             bool
+	CreateVolumePhysicalVolumePath string
+	CreateVolumeName               string
+	CreateVolumePercentOfGroup     int
+	CreateVolumeVolume             boshdisk.LogicalVolume
+	CreateVolumeErr                error
+
+	GrowVolumeCalled             bool
+	GrowVolumePhysicalVolumePath string
+	GrowVolumeVolume             boshdisk.LogicalVolume
+	GrowVolumePercentOfGroup     int
+	GrowVolumeErr                error
+
+	ActivateVolumeGroupName string
+	ActivateVolumeGroupErr  error
+
+	DeactivateVolumeGroupName string
+	DeactivateVolumeGroupErr  error
+
+	CreateSnapshotOrigin          boshdisk.LogicalVolume
+	CreateSnapshotName            string
+	CreateSnapshotPercentOfOrigin int
+	CreateSnapshotSnapshot        boshdisk.LogicalVolume
+	CreateSnapshotErr             error
+
+	ListSnapshotsOrigin    boshdisk.LogicalVolume
+	ListSnapshotsSnapshots []boshdisk.LogicalVolume
+	ListSnapshotsErr       error
+
+	DeleteSnapshotOrigin boshdisk.LogicalVolume
+	DeleteSnapshotName   string
+	DeleteSnapshotErr    error
+}
+
+func (m *FakeVolumeManager) FindVolume(physicalVolumePath, name string) (boshdisk.LogicalVolume, bool, error) {
+	m.FindVolumePhysicalVolumePath = physicalVolumePath
+	m.FindVolumeName = name
+	return m.FindVolumeVolume, m.FindVolumeFound, m.FindVolumeErr
+}
+
+func (m *FakeVolumeManager) CreateVolume(physicalVolumePath, name string, percentOfGroup int) (boshdisk.LogicalVolume, error) {
+	m.CreateVolumeCalled = true
+	m.CreateVolumePhysicalVolumePath = physicalVolumePath
+	m.CreateVolumeName = name
+	m.CreateVolumePercentOfGroup = percentOfGroup
+	return m.CreateVolumeVolume, m.CreateVolumeErr
+}
+
+func (m *FakeVolumeManager) GrowVolume(physicalVolumePath string, volume boshdisk.LogicalVolume, percentOfGroup int) error {
+	m.GrowVolumeCalled = true
+	m.GrowVolumePhysicalVolumePath = physicalVolumePath
+	m.GrowVolumeVolume = volume
+	m.GrowVolumePercentOfGroup = percentOfGroup
+	return m.GrowVolumeErr
+}
+
+func (m *FakeVolumeManager) ActivateVolumeGroup(name string) error {
+	m.ActivateVolumeGroupName = name
+	return m.ActivateVolumeGroupErr
+}
+
+func (m *FakeVolumeManager) DeactivateVolumeGroup(name string) error {
+	m.DeactivateVolumeGroupName = name
+	return m.DeactivateVolumeGroupErr
+}
+
+func (m *FakeVolumeManager) CreateSnapshot(origin boshdisk.LogicalVolume, name string, percentOfOrigin int) (boshdisk.LogicalVolume, error) {
+	m.CreateSnapshotOrigin = origin
+	m.CreateSnapshotName = name
+	m.CreateSnapshotPercentOfOrigin = percentOfOrigin
+	return m.CreateSnapshotSnapshot, m.CreateSnapshotErr
+}
+
+func (m *FakeVolumeManager) ListSnapshots(origin boshdisk.LogicalVolume) ([]boshdisk.LogicalVolume, error) {
+	m.ListSnapshotsOrigin = origin
+	return m.ListSnapshotsSnapshots, m.ListSnapshotsErr
+}
+
+func (m *FakeVolumeManager) DeleteSnapshot(origin boshdisk.LogicalVolume, name string) error {
+	m.DeleteSnapshotOrigin = origin
+	m.DeleteSnapshotName = name
+	return m.DeleteSnapshotErr
+}
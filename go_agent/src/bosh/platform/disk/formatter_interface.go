@@ -5,8 +5,12 @@ type FileSystemType string
 const (
 	FileSystemSwap FileSystemType = "swap"
 	FileSystemExt4 FileSystemType = "ext4"
+	FileSystemXFS  FileSystemType = "xfs"
 )
 
 type Formatter interface {
 	Format(partitionPath string, fsType FileSystemType) (err error)
+
+	// DetectFileSystemType returns empty type when partition is not formatted
+	DetectFileSystemType(partitionPath string) FileSystemType
 }
@@ -19,6 +19,11 @@ func (m linuxBindMounter) RemountAsReadonly(mountPoint string) error {
 	return nil
 }
 
+func (m linuxBindMounter) RemountAsReadWrite(mountPoint string) error {
+	// Mount point was never remounted as readonly
+	return nil
+}
+
 func (m linuxBindMounter) Remount(fromMountPoint, toMountPoint string, mountOptions ...string) error {
 	mountOptions = append(mountOptions, "--bind")
 	return m.delegateMounter.Remount(fromMountPoint, toMountPoint, mountOptions...)
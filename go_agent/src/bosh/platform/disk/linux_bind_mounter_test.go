@@ -47,6 +47,14 @@ var _ = Describe("linuxBindMounter", func() {
 		})
 	})
 
+	Describe("RemountAsReadWrite", func() {
+		It("does not delegate to mounter since mount point was not remounted as readonly", func() {
+			err := mounter.RemountAsReadWrite("fake-path")
+			Expect(err).To(BeNil())
+			Expect(delegateMounter.RemountAsReadWriteCalled).To(BeFalse())
+		})
+	})
+
 	Describe("Remount", func() {
 		It("delegates to mounter and adds --bind option to mount as a bind-mount", func() {
 			delegateMounter.RemountErr = delegateErr
@@ -0,0 +1,54 @@
+package disk
+
+import (
+	bosherr "bosh/errors"
+	boshsys "bosh/system"
+)
+
+// e2fsck exit status bits
+const (
+	e2fsckErrorsCorrected         = 1
+	e2fsckErrorsCorrectedReboot   = 2
+	e2fsckErrorsUncorrectedOrMore = 4
+)
+
+type linuxChecker struct {
+	runner boshsys.CmdRunner
+}
+
+func NewLinuxChecker(runner boshsys.CmdRunner) linuxChecker {
+	return linuxChecker{runner: runner}
+}
+
+func (c linuxChecker) Check(partitionPath string, fsType FileSystemType) (CheckResult, error) {
+	result := CheckResult{FileSystem: fsType}
+
+	switch fsType {
+	case FileSystemExt4:
+		// Preen mode only fixes problems that can be safely fixed without human intervention
+		stdout, stderr, exitStatus, err := c.runner.RunCommand("e2fsck", "-p", partitionPath)
+		result.ExitStatus = exitStatus
+		result.Output = stdout + stderr
+
+		if err != nil && (exitStatus < 0 || exitStatus >= e2fsckErrorsUncorrectedOrMore) {
+			return result, bosherr.WrapError(err, "Shelling out to e2fsck")
+		}
+
+		result.Repaired = exitStatus&(e2fsckErrorsCorrected|e2fsckErrorsCorrectedReboot) != 0
+
+	case FileSystemXFS:
+		// XFS repairs its log on mount so only check without modifying anything
+		stdout, stderr, exitStatus, err := c.runner.RunCommand("xfs_repair", "-n", partitionPath)
+		result.ExitStatus = exitStatus
+		result.Output = stdout + stderr
+
+		if err != nil {
+			return result, bosherr.WrapError(err, "Shelling out to xfs_repair")
+		}
+
+	default:
+		return result, bosherr.New("Unsupported file system type %s", fsType)
+	}
+
+	return result, nil
+}
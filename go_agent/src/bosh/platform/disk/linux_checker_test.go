@@ -0,0 +1,108 @@
+package disk_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/platform/disk"
+	fakesys "bosh/system/fakes"
+)
+
+var _ = Describe("linuxChecker", func() {
+	var (
+		runner  *fakesys.FakeCmdRunner
+		checker Checker
+	)
+
+	BeforeEach(func() {
+		runner = fakesys.NewFakeCmdRunner()
+		checker = NewLinuxChecker(runner)
+	})
+
+	Describe("Check", func() {
+		Context("when file system is ext4", func() {
+			It("checks partition in preen mode", func() {
+				runner.AddCmdResult("e2fsck -p /dev/sdf1", fakesys.FakeCmdResult{Stdout: "fake-output"})
+
+				result, err := checker.Check("/dev/sdf1", FileSystemExt4)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(CheckResult{
+					FileSystem: FileSystemExt4,
+					ExitStatus: 0,
+					Repaired:   false,
+					Output:     "fake-output",
+				}))
+
+				Expect(runner.RunCommands).To(Equal([][]string{{"e2fsck", "-p", "/dev/sdf1"}}))
+			})
+
+			It("reports corrected errors without returning error", func() {
+				runner.AddCmdResult("e2fsck -p /dev/sdf1", fakesys.FakeCmdResult{
+					Stdout:     "fake-output",
+					ExitStatus: 1,
+					Error:      errors.New("fake-e2fsck-err"),
+				})
+
+				result, err := checker.Check("/dev/sdf1", FileSystemExt4)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.ExitStatus).To(Equal(1))
+				Expect(result.Repaired).To(BeTrue())
+			})
+
+			It("returns error with result when errors were left uncorrected", func() {
+				runner.AddCmdResult("e2fsck -p /dev/sdf1", fakesys.FakeCmdResult{
+					Stderr:     "fake-stderr",
+					ExitStatus: 4,
+					Error:      errors.New("fake-e2fsck-err"),
+				})
+
+				result, err := checker.Check("/dev/sdf1", FileSystemExt4)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-e2fsck-err"))
+				Expect(result.ExitStatus).To(Equal(4))
+				Expect(result.Output).To(Equal("fake-stderr"))
+			})
+
+			It("returns error when e2fsck cannot be run", func() {
+				runner.AddCmdResult("e2fsck -p /dev/sdf1", fakesys.FakeCmdResult{
+					ExitStatus: -1,
+					Error:      errors.New("fake-e2fsck-err"),
+				})
+
+				_, err := checker.Check("/dev/sdf1", FileSystemExt4)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-e2fsck-err"))
+			})
+		})
+
+		Context("when file system is xfs", func() {
+			It("checks partition without modifying it", func() {
+				runner.AddCmdResult("xfs_repair -n /dev/sdf1", fakesys.FakeCmdResult{Stdout: "fake-output"})
+
+				result, err := checker.Check("/dev/sdf1", FileSystemXFS)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(CheckResult{FileSystem: FileSystemXFS, Output: "fake-output"}))
+			})
+
+			It("returns error with result when corruption is detected", func() {
+				runner.AddCmdResult("xfs_repair -n /dev/sdf1", fakesys.FakeCmdResult{
+					ExitStatus: 1,
+					Error:      errors.New("fake-xfs-repair-err"),
+				})
+
+				result, err := checker.Check("/dev/sdf1", FileSystemXFS)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-xfs-repair-err"))
+				Expect(result.ExitStatus).To(Equal(1))
+			})
+		})
+
+		It("returns error for unsupported file system", func() {
+			_, err := checker.Check("/dev/sdf1", FileSystemSwap)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unsupported file system type swap"))
+		})
+	})
+})
@@ -10,7 +10,10 @@ import (
 type linuxDiskManager struct {
 	partitioner Partitioner
 	formatter   Formatter
+	checker     Checker
+	resizer     Resizer
 	mounter     Mounter
+	volumes     VolumeManager
 }
 
 func NewLinuxDiskManager(
@@ -23,8 +26,9 @@ func NewLinuxDiskManager(
 	var mountsSearcher MountsSearcher
 
 	// By default we want to use most reliable source of
-	// mount information which is /proc/mounts
-	mountsSearcher = NewProcMountsSearcher(fs)
+	// mount information which is /proc/self/mountinfo;
+	// unlike /proc/mounts it tells bind mounts apart
+	mountsSearcher = NewMountInfoSearcher(fs)
 
 	// Bind mounting in a container (warden) will not allow
 	// reliably determine which device backs a mount point,
@@ -43,10 +47,16 @@ func NewLinuxDiskManager(
 	return linuxDiskManager{
 		partitioner: NewSfdiskPartitioner(logger, runner),
 		formatter:   NewLinuxFormatter(runner, fs),
+		checker:     NewLinuxChecker(runner),
+		resizer:     NewLinuxResizer(runner),
 		mounter:     mounter,
+		volumes:     NewLVMVolumeManager(runner, logger),
 	}
 }
 
-func (m linuxDiskManager) GetPartitioner() Partitioner { return m.partitioner }
-func (m linuxDiskManager) GetFormatter() Formatter     { return m.formatter }
-func (m linuxDiskManager) GetMounter() Mounter         { return m.mounter }
+func (m linuxDiskManager) GetPartitioner() Partitioner     { return m.partitioner }
+func (m linuxDiskManager) GetFormatter() Formatter         { return m.formatter }
+func (m linuxDiskManager) GetChecker() Checker             { return m.checker }
+func (m linuxDiskManager) GetResizer() Resizer             { return m.resizer }
+func (m linuxDiskManager) GetMounter() Mounter             { return m.mounter }
+func (m linuxDiskManager) GetVolumeManager() VolumeManager { return m.volumes }
@@ -26,7 +26,7 @@ var _ = Describe("NewLinuxDiskManager", func() {
 
 	Context("when bindMount is set to false", func() {
 		It("returns disk manager configured not to do bind mounting", func() {
-			expectedMountsSearcher := NewProcMountsSearcher(fs)
+			expectedMountsSearcher := NewMountInfoSearcher(fs)
 			expectedMounter := NewLinuxMounter(runner, expectedMountsSearcher, 1*time.Second)
 
 			diskManager := NewLinuxDiskManager(logger, runner, fs, false)
@@ -3,8 +3,7 @@ package disk
 import (
 	bosherr "bosh/errors"
 	boshsys "bosh/system"
-	"fmt"
-	"strings"
+	"regexp"
 )
 
 type linuxFormatter struct {
@@ -39,15 +38,36 @@ func (f linuxFormatter) Format(partitionPath string, fsType FileSystemType) (err
 		if err != nil {
 			err = bosherr.WrapError(err, "Shelling out to mke2fs")
 		}
+
+	case FileSystemXFS:
+		// Force is needed to overwrite signature left by previous file system
+		_, _, _, err = f.runner.RunCommand("mkfs.xfs", "-f", partitionPath)
+		if err != nil {
+			err = bosherr.WrapError(err, "Shelling out to mkfs.xfs")
+		}
+
+	default:
+		err = bosherr.New("Unsupported file system type %s", fsType)
 	}
 	return
 }
 
-func (f linuxFormatter) partitionHasGivenType(partitionPath string, fsType FileSystemType) bool {
+func (f linuxFormatter) DetectFileSystemType(partitionPath string) FileSystemType {
 	stdout, _, _, err := f.runner.RunCommand("blkid", "-p", partitionPath)
 	if err != nil {
-		return false
+		return ""
 	}
 
-	return strings.Contains(stdout, fmt.Sprintf(` TYPE="%s"`, fsType))
+	matches := blkidTypeRegexp.FindStringSubmatch(stdout)
+	if len(matches) != 2 {
+		return ""
+	}
+
+	return FileSystemType(matches[1])
+}
+
+var blkidTypeRegexp = regexp.MustCompile(` TYPE="([^"]*)"`)
+
+func (f linuxFormatter) partitionHasGivenType(partitionPath string, fsType FileSystemType) bool {
+	return f.DetectFileSystemType(partitionPath) == fsType
 }
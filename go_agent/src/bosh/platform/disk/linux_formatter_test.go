@@ -1,6 +1,8 @@
 package disk_test
 
 import (
+	"errors"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -71,5 +73,58 @@ func init() {
 			Expect(1).To(Equal(len(fakeRunner.RunCommands)))
 			Expect(fakeRunner.RunCommands[0]).To(Equal([]string{"blkid", "-p", "/dev/xvda1"}))
 		})
+		It("linux format when using xfs fs", func() {
+
+			fakeRunner := fakesys.NewFakeCmdRunner()
+			fakeFs := fakesys.NewFakeFileSystem()
+			fakeRunner.AddCmdResult("blkid -p /dev/xvda2", fakesys.FakeCmdResult{Stdout: `xxxxx TYPE="ext4" yyyy zzzz`})
+
+			formatter := NewLinuxFormatter(fakeRunner, fakeFs)
+			err := formatter.Format("/dev/xvda2", FileSystemXFS)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(2).To(Equal(len(fakeRunner.RunCommands)))
+			Expect(fakeRunner.RunCommands[1]).To(Equal([]string{"mkfs.xfs", "-f", "/dev/xvda2"}))
+		})
+		It("linux format when using xfs fs and partition is xfs", func() {
+
+			fakeRunner := fakesys.NewFakeCmdRunner()
+			fakeFs := fakesys.NewFakeFileSystem()
+			fakeRunner.AddCmdResult("blkid -p /dev/xvda2", fakesys.FakeCmdResult{Stdout: `xxxxx TYPE="xfs" yyyy zzzz`})
+
+			formatter := NewLinuxFormatter(fakeRunner, fakeFs)
+			err := formatter.Format("/dev/xvda2", FileSystemXFS)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(1).To(Equal(len(fakeRunner.RunCommands)))
+		})
+		It("linux format returns error for unsupported fs", func() {
+
+			fakeRunner := fakesys.NewFakeCmdRunner()
+			fakeFs := fakesys.NewFakeFileSystem()
+
+			formatter := NewLinuxFormatter(fakeRunner, fakeFs)
+			err := formatter.Format("/dev/xvda2", FileSystemType("fake-fs"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unsupported file system type fake-fs"))
+		})
+		It("linux detect file system type", func() {
+
+			fakeRunner := fakesys.NewFakeCmdRunner()
+			fakeFs := fakesys.NewFakeFileSystem()
+			fakeRunner.AddCmdResult("blkid -p /dev/xvda1", fakesys.FakeCmdResult{Stdout: `/dev/xvda1: UUID="xxx" TYPE="xfs" USAGE="filesystem"`})
+
+			formatter := NewLinuxFormatter(fakeRunner, fakeFs)
+			Expect(formatter.DetectFileSystemType("/dev/xvda1")).To(Equal(FileSystemXFS))
+		})
+		It("linux detect file system type when partition is not formatted", func() {
+
+			fakeRunner := fakesys.NewFakeCmdRunner()
+			fakeFs := fakesys.NewFakeFileSystem()
+			fakeRunner.AddCmdResult("blkid -p /dev/xvda1", fakesys.FakeCmdResult{ExitStatus: 2, Error: errors.New("fake-blkid-err")})
+
+			formatter := NewLinuxFormatter(fakeRunner, fakeFs)
+			Expect(formatter.DetectFileSystemType("/dev/xvda1")).To(Equal(FileSystemType("")))
+		})
 	})
 }
@@ -49,21 +49,63 @@ func (m linuxMounter) Mount(partitionPath, mountPoint string, mountOptions ...st
 }
 
 func (m linuxMounter) RemountAsReadonly(mountPoint string) error {
-	return m.Remount(mountPoint, mountPoint, "-o", "ro")
+	return m.remountInPlace(mountPoint, "ro")
+}
+
+func (m linuxMounter) RemountAsReadWrite(mountPoint string) error {
+	return m.remountInPlace(mountPoint, "rw")
+}
+
+// remountInPlace changes mount to be readonly (ro) or writable (rw)
+func (m linuxMounter) remountInPlace(mountPoint, mode string) error {
+	mount, found, err := m.findMountAtMountPoint(mountPoint)
+	if err != nil {
+		return bosherr.WrapError(err, "Error finding device for mount point %s", mountPoint)
+	}
+
+	if !found {
+		return bosherr.New("Error finding device for mount point %s", mountPoint)
+	}
+
+	// Without knowing whether it is a bind mount it can only be mounted again
+	if len(mount.Root) == 0 {
+		return m.Remount(mountPoint, mountPoint, "-o", mode)
+	}
+
+	if mount.IsReadOnly() == (mode == "ro") {
+		return nil
+	}
+
+	// Remounting bind mount only changes its own flags and keeps the rest of the file system as is
+	options := "remount," + mode
+	if mount.IsBindMount() {
+		options = "remount,bind," + mode
+	}
+
+	_, _, _, err = m.runner.RunCommand("mount", "-o", options, mountPoint)
+	if err != nil {
+		return bosherr.WrapError(err, "Shelling out to mount")
+	}
+
+	return nil
 }
 
 func (m linuxMounter) Remount(fromMountPoint, toMountPoint string, mountOptions ...string) error {
-	partitionPath, found, err := m.findDeviceMatchingMountPoint(fromMountPoint)
-	if err != nil || !found {
+	mount, found, err := m.findMountAtMountPoint(fromMountPoint)
+	if err != nil {
 		return bosherr.WrapError(err, "Error finding device for mount point %s", fromMountPoint)
 	}
 
+	if !found {
+		return bosherr.New("Error finding device for mount point %s", fromMountPoint)
+	}
+
 	_, err = m.Unmount(fromMountPoint)
 	if err != nil {
 		return bosherr.WrapError(err, "Unmounting %s", fromMountPoint)
 	}
 
-	return m.Mount(partitionPath, toMountPoint, mountOptions...)
+	return m.Mount(mount.PartitionPath, toMountPoint, mountOptions...)
 }
 
 func (m linuxMounter) SwapOn(partitionPath string) (err error) {
@@ -121,34 +163,52 @@ func (m linuxMounter) IsMountPoint(path string) (bool, error) {
 	return false, nil
 }
 
-func (m linuxMounter) findDeviceMatchingMountPoint(mountPoint string) (string, bool, error) {
+// findMountAtMountPoint returns the most recent mount when several are stacked on the same mount point
+func (m linuxMounter) findMountAtMountPoint(mountPoint string) (Mount, bool, error) {
 	mounts, err := m.mountsSearcher.SearchMounts()
 	if err != nil {
-		return "", false, bosherr.WrapError(err, "Searching mounts")
+		return Mount{}, false, bosherr.WrapError(err, "Searching mounts")
 	}
 
+	var found bool
+	var result Mount
+
 	for _, mount := range mounts {
 		if mount.MountPoint == mountPoint {
-			return mount.PartitionPath, true, nil
+			result = mount
+			found = true
 		}
 	}
 
-	return "", false, nil
+	return result, found, nil
 }
 
 func (m linuxMounter) IsMounted(partitionOrMountPoint string) (bool, error) {
+	mounts, err := m.findMounts(partitionOrMountPoint)
+	if err != nil {
+		return false, err
+	}
+
+	return len(mounts) > 0, nil
+}
+
+// findMounts ignores partition paths of virtual file systems (e.g. tmpfs or overlay)
+// since they are arbitrary names and not devices
+func (m linuxMounter) findMounts(partitionOrMountPoint string) ([]Mount, error) {
 	mounts, err := m.mountsSearcher.SearchMounts()
 	if err != nil {
-		return false, bosherr.WrapError(err, "Searching mounts")
+		return nil, bosherr.WrapError(err, "Searching mounts")
 	}
 
+	var matchingMounts []Mount
+
 	for _, mount := range mounts {
-		if mount.PartitionPath == partitionOrMountPoint || mount.MountPoint == partitionOrMountPoint {
-			return true, nil
+		if mount.MountPoint == partitionOrMountPoint || (mount.PartitionPath == partitionOrMountPoint && !mount.IsVirtual()) {
+			matchingMounts = append(matchingMounts, mount)
 		}
 	}
 
-	return false, nil
+	return matchingMounts, nil
 }
 
 func (m linuxMounter) shouldMount(partitionPath, mountPoint string) (bool, error) {
@@ -158,6 +218,10 @@ func (m linuxMounter) shouldMount(partitionPath, mountPoint string) (bool, error
 	}
 
 	for _, mount := range mounts {
+		if mount.IsVirtual() && mount.MountPoint != mountPoint {
+			continue
+		}
+
 		switch {
 		case mount.PartitionPath == partitionPath && mount.MountPoint == mountPoint:
 			return false, nil
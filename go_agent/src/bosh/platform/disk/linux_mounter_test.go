@@ -84,6 +84,16 @@ var _ = Describe("linuxMounter", func() {
 			Expect(0).To(Equal(len(runner.RunCommands)))
 		})
 
+		It("allows to mount virtual file system with the same name at another mount point", func() {
+			mountsSearcher.SearchMountsMounts = []Mount{
+				Mount{PartitionPath: "tmpfs", MountPoint: "/mnt/bar", FileSystemType: "tmpfs"},
+			}
+
+			err := mounter.Mount("tmpfs", "/mnt/foo", "-t", "tmpfs")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(runner.RunCommands).To(Equal([][]string{{"mount", "tmpfs", "/mnt/foo", "-t", "tmpfs"}}))
+		})
+
 		It("returns error and does not try to mount anything when searching mounts fails", func() {
 			mountsSearcher.SearchMountsErr = errors.New("fake-search-mounts-err")
 
@@ -98,6 +108,7 @@ var _ = Describe("linuxMounter", func() {
 		It("remount as readonly", func() {
 			changingMountsSearcher := &changingMountsSearcher{
 				[][]Mount{
+					[]Mount{Mount{PartitionPath: "/dev/baz", MountPoint: "/mnt/bar"}},
 					[]Mount{Mount{PartitionPath: "/dev/baz", MountPoint: "/mnt/bar"}},
 					[]Mount{Mount{PartitionPath: "/dev/baz", MountPoint: "/mnt/bar"}},
 					[]Mount{},
@@ -113,6 +124,57 @@ var _ = Describe("linuxMounter", func() {
 			Expect(runner.RunCommands[1]).To(Equal([]string{"mount", "/dev/baz", "/mnt/bar", "-o", "ro"}))
 		})
 
+		Context("when mount information comes from mountinfo", func() {
+			It("remounts file system as readonly in place", func() {
+				mountsSearcher.SearchMountsMounts = []Mount{
+					Mount{PartitionPath: "/dev/baz", MountPoint: "/mnt/bar", Root: "/", MountOptions: []string{"rw"}},
+				}
+
+				err := mounter.RemountAsReadonly("/mnt/bar")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(runner.RunCommands).To(Equal([][]string{{"mount", "-o", "remount,ro", "/mnt/bar"}}))
+			})
+
+			It("only remounts bind mount as readonly", func() {
+				mountsSearcher.SearchMountsMounts = []Mount{
+					Mount{PartitionPath: "/dev/baz", MountPoint: "/mnt/foo", Root: "/", MountOptions: []string{"rw"}},
+					Mount{PartitionPath: "/dev/baz", MountPoint: "/mnt/bar", Root: "/sub", MountOptions: []string{"rw"}},
+				}
+
+				err := mounter.RemountAsReadonly("/mnt/bar")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(runner.RunCommands).To(Equal([][]string{{"mount", "-o", "remount,bind,ro", "/mnt/bar"}}))
+			})
+
+			It("does nothing when mount is already readonly", func() {
+				mountsSearcher.SearchMountsMounts = []Mount{
+					Mount{PartitionPath: "/dev/baz", MountPoint: "/mnt/bar", Root: "/", MountOptions: []string{"ro", "relatime"}},
+				}
+
+				err := mounter.RemountAsReadonly("/mnt/bar")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(runner.RunCommands).To(BeEmpty())
+			})
+
+			It("returns error when remounting fails", func() {
+				mountsSearcher.SearchMountsMounts = []Mount{
+					Mount{PartitionPath: "/dev/baz", MountPoint: "/mnt/bar", Root: "/", MountOptions: []string{"rw"}},
+				}
+				runner.AddCmdResult("mount -o remount,ro /mnt/bar", fakesys.FakeCmdResult{Error: errors.New("fake-mount-err")})
+
+				err := mounter.RemountAsReadonly("/mnt/bar")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-mount-err"))
+			})
+		})
+
+		It("returns error when nothing is mounted at mount point", func() {
+			err := mounter.RemountAsReadonly("/mnt/bar")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Error finding device for mount point /mnt/bar"))
+			Expect(runner.RunCommands).To(BeEmpty())
+		})
+
 		It("returns error and does not try to unmount/mount anything when searching mounts fails", func() {
 			mountsSearcher.SearchMountsErr = errors.New("fake-search-mounts-err")
 
@@ -123,6 +185,44 @@ var _ = Describe("linuxMounter", func() {
 		})
 	})
 
+	Describe("RemountAsReadWrite", func() {
+		It("remounts readonly file system as writable in place", func() {
+			mountsSearcher.SearchMountsMounts = []Mount{
+				Mount{PartitionPath: "/dev/baz", MountPoint: "/mnt/bar", Root: "/", MountOptions: []string{"ro"}},
+			}
+
+			err := mounter.RemountAsReadWrite("/mnt/bar")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(runner.RunCommands).To(Equal([][]string{{"mount", "-o", "remount,rw", "/mnt/bar"}}))
+		})
+
+		It("only remounts bind mount as writable", func() {
+			mountsSearcher.SearchMountsMounts = []Mount{
+				Mount{PartitionPath: "/dev/baz", MountPoint: "/mnt/bar", Root: "/sub", MountOptions: []string{"ro"}},
+			}
+
+			err := mounter.RemountAsReadWrite("/mnt/bar")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(runner.RunCommands).To(Equal([][]string{{"mount", "-o", "remount,bind,rw", "/mnt/bar"}}))
+		})
+
+		It("does nothing when mount is already writable", func() {
+			mountsSearcher.SearchMountsMounts = []Mount{
+				Mount{PartitionPath: "/dev/baz", MountPoint: "/mnt/bar", Root: "/", MountOptions: []string{"rw"}},
+			}
+
+			err := mounter.RemountAsReadWrite("/mnt/bar")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(runner.RunCommands).To(BeEmpty())
+		})
+
+		It("returns error when nothing is mounted at mount point", func() {
+			err := mounter.RemountAsReadWrite("/mnt/bar")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Error finding device for mount point /mnt/bar"))
+		})
+	})
+
 	Describe("Remount", func() {
 		It("remount", func() {
 			changingMountsSearcher := &changingMountsSearcher{
@@ -195,6 +295,43 @@ var _ = Describe("linuxMounter", func() {
 			Expect(runner.RunCommands[0]).To(Equal([]string{"umount", "/dev/xvdb2"}))
 		})
 
+		It("only unmounts partition once when partition is mounted more than once", func() {
+			mountsSearcher.SearchMountsMounts = []Mount{
+				Mount{PartitionPath: "/dev/xvdb2", MountPoint: "/var/vcap/data"},
+				Mount{PartitionPath: "/dev/xvdb2", MountPoint: "/var/vcap/data/sys/log", Root: "/log"},
+			}
+
+			didUnmount, err := mounter.Unmount("/dev/xvdb2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(didUnmount).To(BeTrue())
+
+			Expect(runner.RunCommands).To(Equal([][]string{{"umount", "/dev/xvdb2"}}))
+		})
+
+		It("only unmounts given mount point when partition is mounted more than once", func() {
+			mountsSearcher.SearchMountsMounts = []Mount{
+				Mount{PartitionPath: "/dev/xvdb2", MountPoint: "/var/vcap/data"},
+				Mount{PartitionPath: "/dev/xvdb2", MountPoint: "/var/vcap/data/sys/log", Root: "/log"},
+			}
+
+			didUnmount, err := mounter.Unmount("/var/vcap/data/sys/log")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(didUnmount).To(BeTrue())
+
+			Expect(runner.RunCommands).To(Equal([][]string{{"umount", "/var/vcap/data/sys/log"}}))
+		})
+
+		It("does not unmount virtual file systems whose name matches partition", func() {
+			mountsSearcher.SearchMountsMounts = []Mount{
+				Mount{PartitionPath: "tmpfs", MountPoint: "/var/vcap/data/sys/run", FileSystemType: "tmpfs"},
+			}
+
+			didUnmount, err := mounter.Unmount("tmpfs")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(didUnmount).To(BeFalse())
+			Expect(runner.RunCommands).To(BeEmpty())
+		})
+
 		It("unmount based on mount point when mount point is mounted", func() {
 			didUnmount, err := mounter.Unmount("/var/vcap/data")
 			Expect(err).ToNot(HaveOccurred())
@@ -287,6 +424,30 @@ var _ = Describe("linuxMounter", func() {
 			Expect(isMounted).To(BeFalse())
 		})
 
+		It("is not mounted when only virtual file system with matching name is mounted", func() {
+			mountsSearcher.SearchMountsMounts = []Mount{
+				Mount{PartitionPath: "overlay", MountPoint: "/var/lib/overlay", FileSystemType: "overlay"},
+			}
+
+			isMounted, err := mounter.IsMounted("overlay")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isMounted).To(BeFalse())
+
+			isMounted, err = mounter.IsMounted("/var/lib/overlay")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isMounted).To(BeTrue())
+		})
+
+		It("is mounted when block backed file system with anonymous device numbers is mounted", func() {
+			mountsSearcher.SearchMountsMounts = []Mount{
+				Mount{PartitionPath: "/dev/xvdc1", MountPoint: "/var/vcap/store", MajorMinor: "0:47", FileSystemType: "btrfs"},
+			}
+
+			isMounted, err := mounter.IsMounted("/dev/xvdc1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isMounted).To(BeTrue())
+		})
+
 		It("returns error when searching mounts fails", func() {
 			mountsSearcher.SearchMountsErr = errors.New("fake-search-mounts-err")
 
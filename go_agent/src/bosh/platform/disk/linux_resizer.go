@@ -0,0 +1,120 @@
+package disk
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	bosherr "bosh/errors"
+	boshsys "bosh/system"
+)
+
+type linuxResizer struct {
+	runner boshsys.CmdRunner
+}
+
+func NewLinuxResizer(runner boshsys.CmdRunner) linuxResizer {
+	return linuxResizer{runner: runner}
+}
+
+func (r linuxResizer) NeedsToGrowFileSystem(partitionPath, mountPoint string, fsType FileSystemType) (bool, error) {
+	stdout, _, _, err := r.runner.RunCommand("blockdev", "--getsize64", partitionPath)
+	if err != nil {
+		return false, bosherr.WrapError(err, "Shelling out to blockdev")
+	}
+
+	partitionSize, err := strconv.ParseUint(strings.TrimSpace(stdout), 10, 64)
+	if err != nil {
+		return false, bosherr.WrapError(err, "Parsing partition size")
+	}
+
+	blockSize, blockCount, err := r.fileSystemBlocks(partitionPath, mountPoint, fsType)
+	if err != nil {
+		return false, err
+	}
+
+	fileSystemSize := blockSize * blockCount
+	if fileSystemSize >= partitionSize {
+		return false, nil
+	}
+
+	// Partition is usually not a multiple of file system block size
+	return partitionSize-fileSystemSize >= blockSize, nil
+}
+
+func (r linuxResizer) GrowFileSystem(partitionPath, mountPoint string, fsType FileSystemType) error {
+	switch fsType {
+	case FileSystemExt4:
+		_, _, _, err := r.runner.RunCommand("resize2fs", partitionPath)
+		if err != nil {
+			return bosherr.WrapError(err, "Shelling out to resize2fs")
+		}
+
+	case FileSystemXFS:
+		// xfs_growfs only works with mounted file systems
+		_, _, _, err := r.runner.RunCommand("xfs_growfs", mountPoint)
+		if err != nil {
+			return bosherr.WrapError(err, "Shelling out to xfs_growfs")
+		}
+
+	default:
+		return bosherr.New("Unsupported file system type %s", fsType)
+	}
+
+	return nil
+}
+
+var (
+	dumpe2fsBlockCountRegexp = regexp.MustCompile(`(?m)^Block count:\s+(\d+)$`)
+	dumpe2fsBlockSizeRegexp  = regexp.MustCompile(`(?m)^Block size:\s+(\d+)$`)
+	xfsInfoDataRegexp        = regexp.MustCompile(`(?m)^data\s+=\s+bsize=(\d+)\s+blocks=(\d+),`)
+)
+
+// fileSystemBlocks returns block size and number of blocks of file system
+func (r linuxResizer) fileSystemBlocks(partitionPath, mountPoint string, fsType FileSystemType) (uint64, uint64, error) {
+	var blockSizeStr, blockCountStr string
+
+	switch fsType {
+	case FileSystemExt4:
+		stdout, _, _, err := r.runner.RunCommand("dumpe2fs", "-h", partitionPath)
+		if err != nil {
+			return 0, 0, bosherr.WrapError(err, "Shelling out to dumpe2fs")
+		}
+
+		sizeMatches := dumpe2fsBlockSizeRegexp.FindStringSubmatch(stdout)
+		countMatches := dumpe2fsBlockCountRegexp.FindStringSubmatch(stdout)
+		if sizeMatches == nil || countMatches == nil {
+			return 0, 0, bosherr.New("Finding block size and count in dumpe2fs output")
+		}
+
+		blockSizeStr, blockCountStr = sizeMatches[1], countMatches[1]
+
+	case FileSystemXFS:
+		stdout, _, _, err := r.runner.RunCommand("xfs_info", mountPoint)
+		if err != nil {
+			return 0, 0, bosherr.WrapError(err, "Shelling out to xfs_info")
+		}
+
+		matches := xfsInfoDataRegexp.FindStringSubmatch(stdout)
+		if matches == nil {
+			return 0, 0, bosherr.New("Finding block size and count in xfs_info output")
+		}
+
+		blockSizeStr, blockCountStr = matches[1], matches[2]
+
+	default:
+		return 0, 0, bosherr.New("Unsupported file system type %s", fsType)
+	}
+
+	blockSize, err := strconv.ParseUint(blockSizeStr, 10, 64)
+	if err != nil {
+		return 0, 0, bosherr.WrapError(err, "Parsing block size")
+	}
+
+	blockCount, err := strconv.ParseUint(blockCountStr, 10, 64)
+	if err != nil {
+		return 0, 0, bosherr.WrapError(err, "Parsing block count")
+	}
+
+	return blockSize, blockCount, nil
+}
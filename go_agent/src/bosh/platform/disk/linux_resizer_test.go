@@ -0,0 +1,135 @@
+package disk_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/platform/disk"
+	fakesys "bosh/system/fakes"
+)
+
+var _ = Describe("linuxResizer", func() {
+	var (
+		runner  *fakesys.FakeCmdRunner
+		resizer Resizer
+	)
+
+	BeforeEach(func() {
+		runner = fakesys.NewFakeCmdRunner()
+		resizer = NewLinuxResizer(runner)
+	})
+
+	Describe("NeedsToGrowFileSystem", func() {
+		BeforeEach(func() {
+			runner.AddCmdResult("blockdev --getsize64 /dev/sdf1", fakesys.FakeCmdResult{Stdout: "2147483648\n"})
+		})
+
+		Context("when file system is ext4", func() {
+			It("returns true when file system is smaller than partition", func() {
+				runner.AddCmdResult("dumpe2fs -h /dev/sdf1", fakesys.FakeCmdResult{
+					Stdout: "Filesystem volume name:   <none>\nBlock count:              262144\nBlock size:               4096\n",
+				})
+
+				needs, err := resizer.NeedsToGrowFileSystem("/dev/sdf1", "/fake-mount-point", FileSystemExt4)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(needs).To(BeTrue())
+			})
+
+			It("returns false when file system fills partition", func() {
+				runner.AddCmdResult("dumpe2fs -h /dev/sdf1", fakesys.FakeCmdResult{
+					Stdout: "Block count:              524288\nBlock size:               4096\n",
+				})
+
+				needs, err := resizer.NeedsToGrowFileSystem("/dev/sdf1", "/fake-mount-point", FileSystemExt4)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(needs).To(BeFalse())
+			})
+
+			It("returns false when partition is larger by less than a block", func() {
+				runner.AddCmdResult("blockdev --getsize64 /dev/sdf2", fakesys.FakeCmdResult{Stdout: "2147485184\n"})
+				runner.AddCmdResult("dumpe2fs -h /dev/sdf2", fakesys.FakeCmdResult{
+					Stdout: "Block count:              524288\nBlock size:               4096\n",
+				})
+
+				needs, err := resizer.NeedsToGrowFileSystem("/dev/sdf2", "/fake-mount-point", FileSystemExt4)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(needs).To(BeFalse())
+			})
+
+			It("returns error when dumpe2fs output does not include block count", func() {
+				runner.AddCmdResult("dumpe2fs -h /dev/sdf1", fakesys.FakeCmdResult{Stdout: "Block size: 4096\n"})
+
+				_, err := resizer.NeedsToGrowFileSystem("/dev/sdf1", "/fake-mount-point", FileSystemExt4)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Finding block size and count in dumpe2fs output"))
+			})
+		})
+
+		Context("when file system is xfs", func() {
+			It("returns true when file system mounted at mount point is smaller than partition", func() {
+				runner.AddCmdResult("xfs_info /fake-mount-point", fakesys.FakeCmdResult{
+					Stdout: "meta-data=/dev/sdf1              isize=256    agcount=4, agsize=65536 blks\n" +
+						"data     =                       bsize=4096   blocks=262144, imaxpct=25\n",
+				})
+
+				needs, err := resizer.NeedsToGrowFileSystem("/dev/sdf1", "/fake-mount-point", FileSystemXFS)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(needs).To(BeTrue())
+			})
+
+			It("returns false when file system fills partition", func() {
+				runner.AddCmdResult("xfs_info /fake-mount-point", fakesys.FakeCmdResult{
+					Stdout: "data     =                       bsize=4096   blocks=524288, imaxpct=25\n",
+				})
+
+				needs, err := resizer.NeedsToGrowFileSystem("/dev/sdf1", "/fake-mount-point", FileSystemXFS)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(needs).To(BeFalse())
+			})
+		})
+
+		It("returns error when partition size cannot be determined", func() {
+			runner.AddCmdResult("blockdev --getsize64 /dev/sdf2", fakesys.FakeCmdResult{Error: errors.New("fake-blockdev-err")})
+
+			_, err := resizer.NeedsToGrowFileSystem("/dev/sdf2", "/fake-mount-point", FileSystemExt4)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-blockdev-err"))
+		})
+
+		It("returns error for unsupported file system", func() {
+			_, err := resizer.NeedsToGrowFileSystem("/dev/sdf1", "/fake-mount-point", FileSystemSwap)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unsupported file system type swap"))
+		})
+	})
+
+	Describe("GrowFileSystem", func() {
+		It("grows ext4 file system by its partition", func() {
+			err := resizer.GrowFileSystem("/dev/sdf1", "/fake-mount-point", FileSystemExt4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(runner.RunCommands).To(Equal([][]string{{"resize2fs", "/dev/sdf1"}}))
+		})
+
+		It("grows xfs file system by its mount point", func() {
+			err := resizer.GrowFileSystem("/dev/sdf1", "/fake-mount-point", FileSystemXFS)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(runner.RunCommands).To(Equal([][]string{{"xfs_growfs", "/fake-mount-point"}}))
+		})
+
+		It("returns error if resize2fs fails", func() {
+			runner.AddCmdResult("resize2fs /dev/sdf1", fakesys.FakeCmdResult{Error: errors.New("fake-resize2fs-err")})
+
+			err := resizer.GrowFileSystem("/dev/sdf1", "/fake-mount-point", FileSystemExt4)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-resize2fs-err"))
+		})
+
+		It("returns error for unsupported file system", func() {
+			err := resizer.GrowFileSystem("/dev/sdf1", "/fake-mount-point", FileSystemSwap)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unsupported file system type swap"))
+		})
+	})
+})
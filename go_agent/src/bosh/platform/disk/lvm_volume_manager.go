@@ -0,0 +1,273 @@
+package disk
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	bosherr "bosh/errors"
+	boshlog "bosh/logger"
+	boshsys "bosh/system"
+)
+
+const lvmVolumeGroupNamePrefix = "bosh_persistent_"
+
+const lvmVolumeManagerLogTag = "lvmVolumeManager"
+
+type lvmVolumeManager struct {
+	runner boshsys.CmdRunner
+	logger boshlog.Logger
+}
+
+func NewLVMVolumeManager(runner boshsys.CmdRunner, logger boshlog.Logger) lvmVolumeManager {
+	return lvmVolumeManager{runner: runner, logger: logger}
+}
+
+func (m lvmVolumeManager) FindVolume(physicalVolumePath, name string) (LogicalVolume, bool, error) {
+	stdout, _, _, err := m.runner.RunCommand("pvs", "--noheadings", "--separator", ",", "-o", "pv_name,vg_name")
+	if err != nil {
+		return LogicalVolume{}, false, bosherr.WrapError(err, "Shelling out to pvs")
+	}
+
+	var vgName string
+
+	for _, fields := range m.splitReport(stdout) {
+		if len(fields) == 2 && fields[0] == physicalVolumePath {
+			vgName = fields[1]
+			break
+		}
+	}
+
+	if len(vgName) == 0 {
+		return LogicalVolume{}, false, nil
+	}
+
+	volumes, err := m.listVolumes(vgName)
+	if err != nil {
+		return LogicalVolume{}, false, err
+	}
+
+	for _, volume := range volumes {
+		if volume.Name == name {
+			return volume, true, nil
+		}
+	}
+
+	return LogicalVolume{}, false, nil
+}
+
+func (m lvmVolumeManager) CreateVolume(physicalVolumePath, name string, percentOfGroup int) (LogicalVolume, error) {
+	vgName, err := m.unusedVolumeGroupName()
+	if err != nil {
+		return LogicalVolume{}, err
+	}
+
+	m.logger.Debug(lvmVolumeManagerLogTag, "Creating volume group %s on %s", vgName, physicalVolumePath)
+
+	_, _, _, err = m.runner.RunCommand("pvcreate", physicalVolumePath)
+	if err != nil {
+		return LogicalVolume{}, bosherr.WrapError(err, "Shelling out to pvcreate")
+	}
+
+	_, _, _, err = m.runner.RunCommand("vgcreate", vgName, physicalVolumePath)
+	if err != nil {
+		return LogicalVolume{}, bosherr.WrapError(err, "Shelling out to vgcreate")
+	}
+
+	_, _, _, err = m.runner.RunCommand("lvcreate", "-n", name, "-l", fmt.Sprintf("%d%%VG", percentOfGroup), vgName)
+	if err != nil {
+		return LogicalVolume{}, bosherr.WrapError(err, "Shelling out to lvcreate")
+	}
+
+	return m.newVolume(vgName, name), nil
+}
+
+var lvextendNoSizeChangeRegexp = regexp.MustCompile(`matches existing size|not larger than existing size`)
+
+func (m lvmVolumeManager) GrowVolume(physicalVolumePath string, volume LogicalVolume, percentOfGroup int) error {
+	_, _, _, err := m.runner.RunCommand("pvresize", physicalVolumePath)
+	if err != nil {
+		return bosherr.WrapError(err, "Shelling out to pvresize")
+	}
+
+	_, stderr, _, err := m.runner.RunCommand("lvextend", "-l", fmt.Sprintf("%d%%VG", percentOfGroup), m.volumeID(volume))
+	if err != nil {
+		// lvextend exits non-zero when volume already has requested size
+		if lvextendNoSizeChangeRegexp.MatchString(stderr) {
+			return nil
+		}
+
+		return bosherr.WrapError(err, "Shelling out to lvextend")
+	}
+
+	return nil
+}
+
+func (m lvmVolumeManager) ActivateVolumeGroup(name string) error {
+	_, _, _, err := m.runner.RunCommand("vgchange", "-a", "y", name)
+	if err != nil {
+		return bosherr.WrapError(err, "Shelling out to vgchange")
+	}
+
+	return nil
+}
+
+func (m lvmVolumeManager) DeactivateVolumeGroup(name string) error {
+	_, _, _, err := m.runner.RunCommand("vgchange", "-a", "n", name)
+	if err != nil {
+		return bosherr.WrapError(err, "Shelling out to vgchange")
+	}
+
+	return nil
+}
+
+func (m lvmVolumeManager) CreateSnapshot(origin LogicalVolume, name string, percentOfOrigin int) (LogicalVolume, error) {
+	m.logger.Debug(lvmVolumeManagerLogTag, "Creating snapshot %s of %s", name, m.volumeID(origin))
+
+	// lvcreate freezes mounted file system while snapshot is being taken
+	_, _, _, err := m.runner.RunCommand(
+		"lvcreate", "-s", "-n", name, "-l", fmt.Sprintf("%d%%ORIGIN", percentOfOrigin), m.volumeID(origin),
+	)
+	if err != nil {
+		return LogicalVolume{}, bosherr.WrapError(err, "Shelling out to lvcreate")
+	}
+
+	snapshot := m.newVolume(origin.VolumeGroup, name)
+	snapshot.Origin = origin.Name
+
+	return snapshot, nil
+}
+
+func (m lvmVolumeManager) ListSnapshots(origin LogicalVolume) ([]LogicalVolume, error) {
+	volumes, err := m.listVolumes(origin.VolumeGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []LogicalVolume{}
+
+	for _, volume := range volumes {
+		if volume.Origin == origin.Name {
+			snapshots = append(snapshots, volume)
+		}
+	}
+
+	return snapshots, nil
+}
+
+func (m lvmVolumeManager) DeleteSnapshot(origin LogicalVolume, name string) error {
+	snapshots, err := m.ListSnapshots(origin)
+	if err != nil {
+		return err
+	}
+
+	// Only snapshots are looked up so that origin volume can never be removed
+	for _, snapshot := range snapshots {
+		if snapshot.Name == name {
+			_, _, _, err = m.runner.RunCommand("lvremove", "-f", m.volumeID(snapshot))
+			if err != nil {
+				return bosherr.WrapError(err, "Shelling out to lvremove")
+			}
+
+			return nil
+		}
+	}
+
+	return bosherr.New("Snapshot %s of %s not found", name, m.volumeID(origin))
+}
+
+func (m lvmVolumeManager) listVolumes(vgName string) ([]LogicalVolume, error) {
+	stdout, _, _, err := m.runner.RunCommand(
+		"lvs", "--noheadings", "--nosuffix", "--units", "b", "--separator", ",",
+		"-o", "lv_name,origin,lv_size,snap_percent", vgName,
+	)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Shelling out to lvs")
+	}
+
+	var volumes []LogicalVolume
+
+	for _, fields := range m.splitReport(stdout) {
+		if len(fields) != 4 {
+			return nil, bosherr.New("Parsing lvs output '%s'", strings.Join(fields, ","))
+		}
+
+		volume := m.newVolume(vgName, fields[0])
+		volume.Origin = fields[1]
+
+		volume.SizeInBytes, err = strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Parsing size of %s", volume.Name)
+		}
+
+		if len(fields[3]) > 0 {
+			volume.SnapshotUsagePercent, err = strconv.ParseFloat(fields[3], 64)
+			if err != nil {
+				return nil, bosherr.WrapError(err, "Parsing snapshot usage of %s", volume.Name)
+			}
+		}
+
+		volumes = append(volumes, volume)
+	}
+
+	return volumes, nil
+}
+
+// unusedVolumeGroupName picks name that does not collide with volume groups
+// of other attached disks, e.g. while persistent disk is being migrated
+func (m lvmVolumeManager) unusedVolumeGroupName() (string, error) {
+	stdout, _, _, err := m.runner.RunCommand("vgs", "--noheadings", "-o", "vg_name")
+	if err != nil {
+		return "", bosherr.WrapError(err, "Shelling out to vgs")
+	}
+
+	existingNames := map[string]bool{}
+
+	for _, fields := range m.splitReport(stdout) {
+		existingNames[fields[0]] = true
+	}
+
+	for i := 0; ; i++ {
+		name := lvmVolumeGroupNamePrefix + strconv.Itoa(i)
+		if !existingNames[name] {
+			return name, nil
+		}
+	}
+}
+
+func (m lvmVolumeManager) newVolume(vgName, name string) LogicalVolume {
+	// Device mapper escapes dashes in volume group and volume names by doubling them
+	escape := func(s string) string { return strings.Replace(s, "-", "--", -1) }
+
+	return LogicalVolume{
+		Name:        name,
+		VolumeGroup: vgName,
+		Path:        "/dev/mapper/" + escape(vgName) + "-" + escape(name),
+	}
+}
+
+func (m lvmVolumeManager) volumeID(volume LogicalVolume) string {
+	return volume.VolumeGroup + "/" + volume.Name
+}
+
+// splitReport splits lvm report output into trimmed comma separated fields
+func (m lvmVolumeManager) splitReport(stdout string) [][]string {
+	var rows [][]string
+
+	for _, line := range strings.Split(stdout, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+		for i, field := range fields {
+			fields[i] = strings.TrimSpace(field)
+		}
+
+		rows = append(rows, fields)
+	}
+
+	return rows
+}
@@ -0,0 +1,249 @@
+package disk_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshlog "bosh/logger"
+	. "bosh/platform/disk"
+	fakesys "bosh/system/fakes"
+)
+
+const lvsCmd = "lvs --noheadings --nosuffix --units b --separator , -o lv_name,origin,lv_size,snap_percent fake-vg"
+
+var _ = Describe("lvmVolumeManager", func() {
+	var (
+		runner        *fakesys.FakeCmdRunner
+		volumeManager VolumeManager
+		origin        LogicalVolume
+	)
+
+	BeforeEach(func() {
+		runner = fakesys.NewFakeCmdRunner()
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		volumeManager = NewLVMVolumeManager(runner, logger)
+
+		origin = LogicalVolume{
+			Name:        "store",
+			VolumeGroup: "fake-vg",
+			Path:        "/dev/mapper/fake--vg-store",
+		}
+	})
+
+	Describe("FindVolume", func() {
+		It("returns volume from volume group of physical volume", func() {
+			runner.AddCmdResult("pvs --noheadings --separator , -o pv_name,vg_name", fakesys.FakeCmdResult{
+				Stdout: "  /dev/sda1,other-vg\n  /dev/sdf1,fake-vg\n",
+			})
+
+			runner.AddCmdResult(lvsCmd, fakesys.FakeCmdResult{
+				Stdout: "  snap-1,store,1048576,12.50\n  store,,8388608,\n",
+			})
+
+			volume, found, err := volumeManager.FindVolume("/dev/sdf1", "store")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(volume).To(Equal(LogicalVolume{
+				Name:        "store",
+				VolumeGroup: "fake-vg",
+				Path:        "/dev/mapper/fake--vg-store",
+				SizeInBytes: 8388608,
+			}))
+		})
+
+		It("returns not found when partition is not a physical volume", func() {
+			runner.AddCmdResult("pvs --noheadings --separator , -o pv_name,vg_name", fakesys.FakeCmdResult{
+				Stdout: "  /dev/sda1,other-vg\n",
+			})
+
+			_, found, err := volumeManager.FindVolume("/dev/sdf1", "store")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeFalse())
+			Expect(len(runner.RunCommands)).To(Equal(1))
+		})
+
+		It("returns not found when physical volume does not belong to volume group", func() {
+			runner.AddCmdResult("pvs --noheadings --separator , -o pv_name,vg_name", fakesys.FakeCmdResult{
+				Stdout: "  /dev/sdf1,\n",
+			})
+
+			_, found, err := volumeManager.FindVolume("/dev/sdf1", "store")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		It("returns error if pvs fails", func() {
+			runner.AddCmdResult("pvs --noheadings --separator , -o pv_name,vg_name", fakesys.FakeCmdResult{
+				Error: errors.New("fake-pvs-err"),
+			})
+
+			_, _, err := volumeManager.FindVolume("/dev/sdf1", "store")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-pvs-err"))
+		})
+	})
+
+	Describe("CreateVolume", func() {
+		It("creates physical volume, volume group with unused name and logical volume", func() {
+			runner.AddCmdResult("vgs --noheadings -o vg_name", fakesys.FakeCmdResult{
+				Stdout: "  bosh_persistent_0\n  other-vg\n",
+			})
+
+			volume, err := volumeManager.CreateVolume("/dev/sdf1", "store", 80)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(volume).To(Equal(LogicalVolume{
+				Name:        "store",
+				VolumeGroup: "bosh_persistent_1",
+				Path:        "/dev/mapper/bosh_persistent_1-store",
+			}))
+
+			Expect(runner.RunCommands).To(Equal([][]string{
+				{"vgs", "--noheadings", "-o", "vg_name"},
+				{"pvcreate", "/dev/sdf1"},
+				{"vgcreate", "bosh_persistent_1", "/dev/sdf1"},
+				{"lvcreate", "-n", "store", "-l", "80%VG", "bosh_persistent_1"},
+			}))
+		})
+
+		It("returns error if pvcreate fails", func() {
+			runner.AddCmdResult("pvcreate /dev/sdf1", fakesys.FakeCmdResult{Error: errors.New("fake-pvcreate-err")})
+
+			_, err := volumeManager.CreateVolume("/dev/sdf1", "store", 80)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-pvcreate-err"))
+		})
+	})
+
+	Describe("GrowVolume", func() {
+		It("resizes physical volume and extends logical volume", func() {
+			err := volumeManager.GrowVolume("/dev/sdf1", origin, 80)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(runner.RunCommands).To(Equal([][]string{
+				{"pvresize", "/dev/sdf1"},
+				{"lvextend", "-l", "80%VG", "fake-vg/store"},
+			}))
+		})
+
+		It("does not return error when logical volume already has requested size", func() {
+			runner.AddCmdResult("lvextend -l 80%VG fake-vg/store", fakesys.FakeCmdResult{
+				Stderr:     "  New size (204 extents) matches existing size (204 extents)\n",
+				ExitStatus: 5,
+				Error:      errors.New("fake-lvextend-err"),
+			})
+
+			err := volumeManager.GrowVolume("/dev/sdf1", origin, 80)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns error if lvextend fails", func() {
+			runner.AddCmdResult("lvextend -l 80%VG fake-vg/store", fakesys.FakeCmdResult{Error: errors.New("fake-lvextend-err")})
+
+			err := volumeManager.GrowVolume("/dev/sdf1", origin, 80)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-lvextend-err"))
+		})
+	})
+
+	Describe("ActivateVolumeGroup", func() {
+		It("activates volume group", func() {
+			err := volumeManager.ActivateVolumeGroup("fake-vg")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(runner.RunCommands).To(Equal([][]string{{"vgchange", "-a", "y", "fake-vg"}}))
+		})
+	})
+
+	Describe("DeactivateVolumeGroup", func() {
+		It("deactivates volume group", func() {
+			err := volumeManager.DeactivateVolumeGroup("fake-vg")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(runner.RunCommands).To(Equal([][]string{{"vgchange", "-a", "n", "fake-vg"}}))
+		})
+	})
+
+	Describe("CreateSnapshot", func() {
+		It("creates snapshot sized relative to origin", func() {
+			snapshot, err := volumeManager.CreateSnapshot(origin, "snap-1", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(snapshot).To(Equal(LogicalVolume{
+				Name:        "snap-1",
+				VolumeGroup: "fake-vg",
+				Origin:      "store",
+				Path:        "/dev/mapper/fake--vg-snap--1",
+			}))
+
+			Expect(runner.RunCommands).To(Equal([][]string{
+				{"lvcreate", "-s", "-n", "snap-1", "-l", "10%ORIGIN", "fake-vg/store"},
+			}))
+		})
+
+		It("returns error if lvcreate fails", func() {
+			runner.AddCmdResult("lvcreate -s -n snap-1 -l 10%ORIGIN fake-vg/store", fakesys.FakeCmdResult{
+				Error: errors.New("fake-lvcreate-err"),
+			})
+
+			_, err := volumeManager.CreateSnapshot(origin, "snap-1", 10)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-lvcreate-err"))
+		})
+	})
+
+	Describe("ListSnapshots", func() {
+		It("returns only snapshots of origin", func() {
+			runner.AddCmdResult(lvsCmd, fakesys.FakeCmdResult{
+				Stdout: "  snap-1,store,1048576,12.50\n  snap-2,other,1048576,0.00\n  store,,8388608,\n",
+			})
+
+			snapshots, err := volumeManager.ListSnapshots(origin)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(snapshots).To(Equal([]LogicalVolume{
+				{
+					Name:                 "snap-1",
+					VolumeGroup:          "fake-vg",
+					Origin:               "store",
+					Path:                 "/dev/mapper/fake--vg-snap--1",
+					SizeInBytes:          1048576,
+					SnapshotUsagePercent: 12.5,
+				},
+			}))
+		})
+
+		It("returns empty list when there are no snapshots", func() {
+			runner.AddCmdResult(lvsCmd, fakesys.FakeCmdResult{Stdout: "  store,,8388608,\n"})
+
+			snapshots, err := volumeManager.ListSnapshots(origin)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(snapshots).To(Equal([]LogicalVolume{}))
+		})
+
+		It("returns error if lvs output cannot be parsed", func() {
+			runner.AddCmdResult(lvsCmd, fakesys.FakeCmdResult{Stdout: "  snap-1,store,fake-size,\n"})
+
+			_, err := volumeManager.ListSnapshots(origin)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing size of snap-1"))
+		})
+	})
+
+	Describe("DeleteSnapshot", func() {
+		BeforeEach(func() {
+			runner.AddCmdResult(lvsCmd, fakesys.FakeCmdResult{
+				Stdout: "  snap-1,store,1048576,12.50\n  store,,8388608,\n",
+			})
+		})
+
+		It("removes snapshot of origin", func() {
+			err := volumeManager.DeleteSnapshot(origin, "snap-1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(runner.RunCommands[1]).To(Equal([]string{"lvremove", "-f", "fake-vg/snap-1"}))
+		})
+
+		It("does not remove origin itself", func() {
+			err := volumeManager.DeleteSnapshot(origin, "store")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Snapshot store of fake-vg/store not found"))
+			Expect(len(runner.RunCommands)).To(Equal(1))
+		})
+	})
+})
@@ -3,5 +3,8 @@ package disk
 type Manager interface {
 	GetPartitioner() Partitioner
 	GetFormatter() Formatter
+	GetChecker() Checker
+	GetResizer() Resizer
 	GetMounter() Mounter
+	GetVolumeManager() VolumeManager
 }
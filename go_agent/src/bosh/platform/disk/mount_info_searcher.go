@@ -0,0 +1,109 @@
+package disk
+
+import (
+	"strconv"
+	"strings"
+
+	bosherr "bosh/errors"
+	boshsys "bosh/system"
+)
+
+type mountInfoSearcher struct {
+	fs boshsys.FileSystem
+}
+
+func NewMountInfoSearcher(fs boshsys.FileSystem) mountInfoSearcher {
+	return mountInfoSearcher{fs}
+}
+
+// SearchMounts returns mounts in the order they were mounted
+func (s mountInfoSearcher) SearchMounts() ([]Mount, error) {
+	var mounts []Mount
+
+	mountInfo, err := s.fs.ReadFileString("/proc/self/mountinfo")
+	if err != nil {
+		return mounts, bosherr.WrapError(err, "Reading /proc/self/mountinfo")
+	}
+
+	for _, mountEntry := range strings.Split(mountInfo, "\n") {
+		if mountEntry == "" {
+			continue
+		}
+
+		mount, err := s.parseMountEntry(mountEntry)
+		if err != nil {
+			return mounts, bosherr.WrapError(err, "Parsing mount entry '%s'", mountEntry)
+		}
+
+		mounts = append(mounts, mount)
+	}
+
+	return mounts, nil
+}
+
+// e.g. '36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue'
+// Number of optional fields before '-' separator varies
+func (s mountInfoSearcher) parseMountEntry(mountEntry string) (Mount, error) {
+	var mount Mount
+
+	fields := strings.Fields(mountEntry)
+
+	separatorIndex := -1
+
+	for i := 6; i < len(fields); i++ {
+		if fields[i] == "-" {
+			separatorIndex = i
+			break
+		}
+	}
+
+	if separatorIndex < 0 || len(fields) < separatorIndex+4 {
+		return mount, bosherr.New("Expected optional fields to be followed by '-' and 3 fields")
+	}
+
+	id, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return mount, bosherr.WrapError(err, "Parsing mount id")
+	}
+
+	parentID, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return mount, bosherr.WrapError(err, "Parsing parent mount id")
+	}
+
+	mount.ID = id
+	mount.ParentID = parentID
+	mount.MajorMinor = fields[2]
+	mount.Root = s.unescape(fields[3])
+	mount.MountPoint = s.unescape(fields[4])
+	mount.MountOptions = strings.Split(fields[5], ",")
+	mount.FileSystemType = fields[separatorIndex+1]
+	mount.PartitionPath = s.unescape(fields[separatorIndex+2])
+	mount.SuperOptions = strings.Split(fields[separatorIndex+3], ",")
+
+	return mount, nil
+}
+
+// unescape decodes octal escapes kernel uses for space, tab, newline and backslash
+func (s mountInfoSearcher) unescape(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+
+	var result []byte
+
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			code, err := strconv.ParseUint(field[i+1:i+4], 8, 8)
+			if err == nil {
+				result = append(result, byte(code))
+				i += 3
+				continue
+			}
+		}
+
+		result = append(result, field[i])
+	}
+
+	return string(result)
+}
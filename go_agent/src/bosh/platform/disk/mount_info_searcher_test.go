@@ -0,0 +1,141 @@
+package disk_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/platform/disk"
+	fakesys "bosh/system/fakes"
+)
+
+var _ = Describe("mountInfoSearcher", func() {
+	var (
+		fs       *fakesys.FakeFileSystem
+		searcher MountsSearcher
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		searcher = NewMountInfoSearcher(fs)
+	})
+
+	Describe("SearchMounts", func() {
+		Context("when reading /proc/self/mountinfo succeeds", func() {
+			It("returns parsed mount information", func() {
+				fs.WriteFileString(
+					"/proc/self/mountinfo",
+					`17 22 0:16 / /sys rw,nosuid,nodev,noexec,relatime shared:7 - sysfs sysfs rw
+22 1 202:1 / / rw,relatime shared:1 - ext4 /dev/xvda1 rw,data=ordered
+40 22 202:18 / /var/vcap/data rw,relatime shared:24 master:3 - ext4 /dev/xvdb2 rw,data=ordered
+41 40 202:18 /sys/log /var/log rw,relatime - ext4 /dev/xvdb2 rw,data=ordered
+42 22 0:35 / /var/vcap/data/sys/run rw,relatime - tmpfs tmpfs rw,size=1024k
+`,
+				)
+
+				mounts, err := searcher.SearchMounts()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mounts).To(Equal([]Mount{
+					Mount{
+						PartitionPath:  "sysfs",
+						MountPoint:     "/sys",
+						ID:             17,
+						ParentID:       22,
+						MajorMinor:     "0:16",
+						Root:           "/",
+						FileSystemType: "sysfs",
+						MountOptions:   []string{"rw", "nosuid", "nodev", "noexec", "relatime"},
+						SuperOptions:   []string{"rw"},
+					},
+					Mount{
+						PartitionPath:  "/dev/xvda1",
+						MountPoint:     "/",
+						ID:             22,
+						ParentID:       1,
+						MajorMinor:     "202:1",
+						Root:           "/",
+						FileSystemType: "ext4",
+						MountOptions:   []string{"rw", "relatime"},
+						SuperOptions:   []string{"rw", "data=ordered"},
+					},
+					Mount{
+						PartitionPath:  "/dev/xvdb2",
+						MountPoint:     "/var/vcap/data",
+						ID:             40,
+						ParentID:       22,
+						MajorMinor:     "202:18",
+						Root:           "/",
+						FileSystemType: "ext4",
+						MountOptions:   []string{"rw", "relatime"},
+						SuperOptions:   []string{"rw", "data=ordered"},
+					},
+					Mount{
+						PartitionPath:  "/dev/xvdb2",
+						MountPoint:     "/var/log",
+						ID:             41,
+						ParentID:       40,
+						MajorMinor:     "202:18",
+						Root:           "/sys/log",
+						FileSystemType: "ext4",
+						MountOptions:   []string{"rw", "relatime"},
+						SuperOptions:   []string{"rw", "data=ordered"},
+					},
+					Mount{
+						PartitionPath:  "tmpfs",
+						MountPoint:     "/var/vcap/data/sys/run",
+						ID:             42,
+						ParentID:       22,
+						MajorMinor:     "0:35",
+						Root:           "/",
+						FileSystemType: "tmpfs",
+						MountOptions:   []string{"rw", "relatime"},
+						SuperOptions:   []string{"rw", "size=1024k"},
+					},
+				}))
+
+				Expect(mounts[3].IsBindMount()).To(BeTrue())
+				Expect(mounts[2].IsBindMount()).To(BeFalse())
+				Expect(mounts[4].IsVirtual()).To(BeTrue())
+				Expect(mounts[2].IsVirtual()).To(BeFalse())
+			})
+
+			It("does not consider block backed file systems with anonymous device numbers to be virtual", func() {
+				mount := Mount{PartitionPath: "/dev/xvdc1", MajorMinor: "0:47", FileSystemType: "btrfs"}
+				Expect(mount.IsVirtual()).To(BeFalse())
+			})
+
+			It("decodes escaped characters in paths", func() {
+				fs.WriteFileString(
+					"/proc/self/mountinfo",
+					`40 22 202:18 /dir\134name /mnt/with\040space ro,relatime - ext4 /dev/xvdb2 rw`,
+				)
+
+				mounts, err := searcher.SearchMounts()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mounts[0].Root).To(Equal(`/dir\name`))
+				Expect(mounts[0].MountPoint).To(Equal("/mnt/with space"))
+				Expect(mounts[0].IsReadOnly()).To(BeTrue())
+			})
+
+			It("returns error when entry cannot be parsed", func() {
+				fs.WriteFileString("/proc/self/mountinfo", "40 22 202:18 / /mnt rw,relatime ext4 /dev/xvdb2 rw")
+
+				_, err := searcher.SearchMounts()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Parsing mount entry"))
+			})
+		})
+
+		Context("when reading /proc/self/mountinfo fails", func() {
+			It("returns error", func() {
+				fs.WriteFileString("/proc/self/mountinfo", "")
+				fs.ReadFileError = errors.New("fake-read-err")
+
+				_, err := searcher.SearchMounts()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-read-err"))
+			})
+		})
+	})
+})
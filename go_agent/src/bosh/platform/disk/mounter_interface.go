@@ -5,6 +5,7 @@ type Mounter interface {
 	Unmount(partitionOrMountPoint string) (didUnmount bool, err error)
 
 	RemountAsReadonly(mountPoint string) (err error)
+	RemountAsReadWrite(mountPoint string) (err error)
 	Remount(fromMountPoint, toMountPoint string, mountOptions ...string) (err error)
 
 	SwapOn(partitionPath string) (err error)
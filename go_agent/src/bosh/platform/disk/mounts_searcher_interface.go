@@ -3,6 +3,70 @@ package disk
 type Mount struct {
 	PartitionPath string
 	MountPoint    string
+
+	// Fields below are only known when mounts are read from /proc/self/mountinfo
+
+	ID       int
+	ParentID int
+
+	// Device numbers of the mounted file system, e.g. "8:17"
+	MajorMinor string
+
+	// Directory of the file system that is mounted; other than "/" for bind mounts of subdirectories
+	Root string
+
+	FileSystemType string
+
+	MountOptions []string
+	SuperOptions []string
+}
+
+// IsBindMount is true when only a subdirectory of the file system is mounted
+func (m Mount) IsBindMount() bool {
+	return len(m.Root) > 0 && m.Root != "/"
+}
+
+// virtualFileSystemTypes are not backed by a block device.
+// Device numbers cannot tell them apart since some block backed
+// file systems (e.g. btrfs) also report anonymous major number 0.
+var virtualFileSystemTypes = map[string]bool{
+	"autofs":      true,
+	"binfmt_misc": true,
+	"bpf":         true,
+	"cgroup":      true,
+	"cgroup2":     true,
+	"configfs":    true,
+	"debugfs":     true,
+	"devpts":      true,
+	"devtmpfs":    true,
+	"fusectl":     true,
+	"hugetlbfs":   true,
+	"mqueue":      true,
+	"nsfs":        true,
+	"overlay":     true,
+	"proc":        true,
+	"pstore":      true,
+	"ramfs":       true,
+	"securityfs":  true,
+	"sysfs":       true,
+	"tmpfs":       true,
+	"tracefs":     true,
+}
+
+// IsVirtual is true for file systems that are not backed by a block device
+// (e.g. tmpfs or overlay) so their partition path is just an arbitrary name
+func (m Mount) IsVirtual() bool {
+	return virtualFileSystemTypes[m.FileSystemType]
+}
+
+func (m Mount) IsReadOnly() bool {
+	for _, option := range m.MountOptions {
+		if option == "ro" {
+			return true
+		}
+	}
+
+	return false
 }
 
 type MountsSearcher interface {
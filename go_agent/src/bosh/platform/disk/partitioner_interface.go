@@ -16,4 +16,12 @@ type Partition struct {
 type Partitioner interface {
 	Partition(devicePath string, partitions []Partition) (err error)
 	GetDeviceSizeInMb(devicePath string) (size uint64, err error)
+
+	// HasPartitions is true when device is already partitioned as given;
+	// last partition is expected to take the rest of the device
+	HasPartitions(devicePath string, partitions []Partition) bool
+
+	// GrowPartition extends last partition to the end of the device
+	// when device was grown; returns false if there was nothing to grow
+	GrowPartition(devicePath string, partitionNumber int) (grown bool, err error)
 }
@@ -0,0 +1,10 @@
+package disk
+
+type Resizer interface {
+	// NeedsToGrowFileSystem returns true when mounted file system
+	// is smaller than its partition by at least one file system block
+	NeedsToGrowFileSystem(partitionPath, mountPoint string, fsType FileSystemType) (bool, error)
+
+	// GrowFileSystem extends mounted file system to fill its partition
+	GrowFileSystem(partitionPath, mountPoint string, fsType FileSystemType) error
+}
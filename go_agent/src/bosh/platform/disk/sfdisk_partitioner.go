@@ -70,6 +70,53 @@ func (p sfdiskPartitioner) GetDeviceSizeInMb(devicePath string) (size uint64, er
 	return
 }
 
+func (p sfdiskPartitioner) HasPartitions(devicePath string, partitions []Partition) bool {
+	return p.diskMatchesPartitions(devicePath, partitions)
+}
+
+func (p sfdiskPartitioner) GrowPartition(devicePath string, partitionNumber int) (bool, error) {
+	// Blank disk without partition table cannot be dumped and has nothing to grow
+	existingPartitions, err := p.getPartitions(devicePath)
+	if err != nil {
+		p.logger.Debug(p.logTag, "Not growing partition of %s since partitions cannot be read: %s", devicePath, err.Error())
+		return false, nil
+	}
+
+	var usedDiskSpace uint64
+	lastPartitionNumber := 0
+
+	for index, partition := range existingPartitions {
+		if partition.Type != PartitionTypeEmpty {
+			usedDiskSpace += partition.SizeInMb
+			lastPartitionNumber = index + 1
+		}
+	}
+
+	// Only last partition can be grown without moving other partitions
+	if lastPartitionNumber == 0 || lastPartitionNumber != partitionNumber {
+		return false, nil
+	}
+
+	diskSpace, err := p.GetDeviceSizeInMb(devicePath)
+	if err != nil {
+		return false, bosherr.WrapError(err, "Getting device size for %s", devicePath)
+	}
+
+	// Same delta as used when matching partitions accounts for partition table and alignment
+	if diskSpace <= usedDiskSpace+20 {
+		return false, nil
+	}
+
+	p.logger.Info(p.logTag, "Growing partition %d of %s from %dMB to fill %dMB", partitionNumber, devicePath, usedDiskSpace, diskSpace)
+
+	_, _, _, err = p.cmdRunner.RunCommand("growpart", devicePath, strconv.Itoa(partitionNumber))
+	if err != nil {
+		return false, bosherr.WrapError(err, "Shelling out to growpart")
+	}
+
+	return true, nil
+}
+
 func (p sfdiskPartitioner) diskMatchesPartitions(devicePath string, partitionsToMatch []Partition) (result bool) {
 	existingPartitions, err := p.getPartitions(devicePath)
 	if err != nil {
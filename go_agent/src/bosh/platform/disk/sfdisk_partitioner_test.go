@@ -1,6 +1,7 @@
 package disk_test
 
 import (
+	"errors"
 	"fmt"
 
 	. "github.com/onsi/ginkgo"
@@ -49,6 +50,15 @@ unit: sectors
 /dev/sda4 : start=        0, size=    0, Id= 0
 `
 
+const devSdaSfdiskDumpSinglePartition = `# partition table of /dev/sda
+unit: sectors
+
+/dev/sda1 : start=     2048, size= xxxx, Id=83
+/dev/sda2 : start=        0, size=    0, Id= 0
+/dev/sda3 : start=        0, size=    0, Id= 0
+/dev/sda4 : start=        0, size=    0, Id= 0
+`
+
 func init() {
 	Describe("Testing with Ginkgo", func() {
 		It("sfdisk partition", func() {
@@ -153,5 +163,85 @@ func init() {
 
 			Expect(0).To(Equal(len(runner.RunCommandsWithInput)))
 		})
+		It("sfdisk grow partition when device is bigger than partitions", func() {
+
+			runner := fakesys.NewFakeCmdRunner()
+			runner.AddCmdResult("sfdisk -d /dev/sda", fakesys.FakeCmdResult{Stdout: devSdaSfdiskDumpSinglePartition})
+			runner.AddCmdResult("sfdisk -s /dev/sda", fakesys.FakeCmdResult{Stdout: fmt.Sprintf("%d\n", 4096*1024)})
+			runner.AddCmdResult("sfdisk -s /dev/sda1", fakesys.FakeCmdResult{Stdout: fmt.Sprintf("%d\n", 2047*1024)})
+			partitioner := createSfdiskPartitionerForTests(runner)
+
+			grown, err := partitioner.GrowPartition("/dev/sda", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(grown).To(BeTrue())
+
+			Expect(runner.RunCommands).To(ContainElement([]string{"growpart", "/dev/sda", "1"}))
+		})
+		It("sfdisk grow partition when partition already fills device", func() {
+
+			runner := fakesys.NewFakeCmdRunner()
+			runner.AddCmdResult("sfdisk -d /dev/sda", fakesys.FakeCmdResult{Stdout: devSdaSfdiskDumpSinglePartition})
+			runner.AddCmdResult("sfdisk -s /dev/sda", fakesys.FakeCmdResult{Stdout: fmt.Sprintf("%d\n", 2048*1024)})
+			runner.AddCmdResult("sfdisk -s /dev/sda1", fakesys.FakeCmdResult{Stdout: fmt.Sprintf("%d\n", 2047*1024)})
+			partitioner := createSfdiskPartitionerForTests(runner)
+
+			grown, err := partitioner.GrowPartition("/dev/sda", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(grown).To(BeFalse())
+
+			Expect(runner.RunCommands).ToNot(ContainElement([]string{"growpart", "/dev/sda", "1"}))
+		})
+		It("sfdisk grow partition when device is not partitioned", func() {
+
+			runner := fakesys.NewFakeCmdRunner()
+			runner.AddCmdResult("sfdisk -d /dev/sda", fakesys.FakeCmdResult{Stdout: devSdaSfdiskEmptyDump})
+			partitioner := createSfdiskPartitionerForTests(runner)
+
+			grown, err := partitioner.GrowPartition("/dev/sda", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(grown).To(BeFalse())
+
+			Expect(len(runner.RunCommands)).To(Equal(1))
+		})
+		It("sfdisk grow partition when device has no partition table", func() {
+
+			runner := fakesys.NewFakeCmdRunner()
+			runner.AddCmdResult("sfdisk -d /dev/sda", fakesys.FakeCmdResult{
+				Stderr: devSdaSfdiskNotableDumpStderr,
+				Error:  errors.New("fake-sfdisk-dump-err"),
+			})
+			partitioner := createSfdiskPartitionerForTests(runner)
+
+			grown, err := partitioner.GrowPartition("/dev/sda", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(grown).To(BeFalse())
+
+			Expect(runner.RunCommands).To(Equal([][]string{{"sfdisk", "-d", "/dev/sda"}}))
+		})
+		It("sfdisk grow partition when partition is not the last one", func() {
+
+			runner := fakesys.NewFakeCmdRunner()
+			runner.AddCmdResult("sfdisk -d /dev/sda", fakesys.FakeCmdResult{Stdout: devSdaSfdiskDumpOnePartition})
+			runner.AddCmdResult("sfdisk -s /dev/sda1", fakesys.FakeCmdResult{Stdout: fmt.Sprintf("%d\n", 1024*1024)})
+			runner.AddCmdResult("sfdisk -s /dev/sda2", fakesys.FakeCmdResult{Stdout: fmt.Sprintf("%d\n", 1024*1024)})
+			partitioner := createSfdiskPartitionerForTests(runner)
+
+			grown, err := partitioner.GrowPartition("/dev/sda", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(grown).To(BeFalse())
+		})
+		It("sfdisk grow partition returns error when growpart fails", func() {
+
+			runner := fakesys.NewFakeCmdRunner()
+			runner.AddCmdResult("sfdisk -d /dev/sda", fakesys.FakeCmdResult{Stdout: devSdaSfdiskDumpSinglePartition})
+			runner.AddCmdResult("sfdisk -s /dev/sda", fakesys.FakeCmdResult{Stdout: fmt.Sprintf("%d\n", 4096*1024)})
+			runner.AddCmdResult("sfdisk -s /dev/sda1", fakesys.FakeCmdResult{Stdout: fmt.Sprintf("%d\n", 2047*1024)})
+			runner.AddCmdResult("growpart /dev/sda 1", fakesys.FakeCmdResult{Error: errors.New("fake-growpart-err")})
+			partitioner := createSfdiskPartitionerForTests(runner)
+
+			_, err := partitioner.GrowPartition("/dev/sda", 1)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-growpart-err"))
+		})
 	})
 }
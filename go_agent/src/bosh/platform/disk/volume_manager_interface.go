@@ -0,0 +1,38 @@
+package disk
+
+type LogicalVolume struct {
+	Name        string
+	VolumeGroup string
+
+	// Name of the volume this snapshot was taken from; empty unless volume is a snapshot
+	Origin string
+
+	// Device mapper path (e.g. /dev/mapper/vg-lv) as it shows up in /proc/mounts
+	Path string
+
+	SizeInBytes uint64
+
+	// Percent of snapshot space taken by changes to the origin;
+	// snapshot becomes invalid when it reaches 100
+	SnapshotUsagePercent float64
+}
+
+type VolumeManager interface {
+	// FindVolume looks for logical volume in the volume group of given physical volume
+	FindVolume(physicalVolumePath, name string) (LogicalVolume, bool, error)
+
+	// CreateVolume turns partition into a physical volume of a new volume group
+	// and creates logical volume taking given percent of the group
+	CreateVolume(physicalVolumePath, name string, percentOfGroup int) (LogicalVolume, error)
+
+	// GrowVolume resizes physical volume to fill its partition
+	// and extends logical volume to given percent of the group
+	GrowVolume(physicalVolumePath string, volume LogicalVolume, percentOfGroup int) error
+
+	ActivateVolumeGroup(name string) error
+	DeactivateVolumeGroup(name string) error
+
+	CreateSnapshot(origin LogicalVolume, name string, percentOfOrigin int) (LogicalVolume, error)
+	ListSnapshots(origin LogicalVolume) ([]LogicalVolume, error)
+	DeleteSnapshot(origin LogicalVolume, name string) error
+}
@@ -0,0 +1,15 @@
+package diskcopier
+
+import (
+	bosherr "bosh/errors"
+)
+
+var ErrCancelled = bosherr.New("Copying was cancelled")
+
+type Copier interface {
+	// Copy copies contents of fromDir into toDir preserving file metadata
+	// and verifies copied files; files that were already copied
+	// by a previous interrupted copy are not copied again.
+	// Returns ErrCancelled when cancelCh receives before copy finishes.
+	Copy(fromDir, toDir string, progress *Progress, cancelCh <-chan struct{}) error
+}
@@ -0,0 +1,13 @@
+package diskcopier_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestDiskcopier(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Diskcopier Suite")
+}
@@ -0,0 +1,23 @@
+package fakes
+
+import (
+	boshdiskcopier "bosh/platform/diskcopier"
+)
+
+type FakeCopier struct {
+	CopyCalled   bool
+	CopyFromDir  string
+	CopyToDir    string
+	CopyProgress *boshdiskcopier.Progress
+	CopyCancelCh <-chan struct{}
+	CopyErr      error
+}
+
+func (c *FakeCopier) Copy(fromDir, toDir string, progress *boshdiskcopier.Progress, cancelCh <-chan struct{}) error {
+	c.CopyCalled = true
+	c.CopyFromDir = fromDir
+	c.CopyToDir = toDir
+	c.CopyProgress = progress
+	c.CopyCancelCh = cancelCh
+	return c.CopyErr
+}
@@ -0,0 +1,690 @@
+package diskcopier
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	bosherr "bosh/errors"
+	boshlog "bosh/logger"
+)
+
+const nativeCopierLogTag = "nativeCopier"
+
+// Large buffer keeps number of syscalls low when copying big database files
+const copyBufferSize = 1024 * 1024
+
+// Blocks of zeros this size are skipped when writing files
+// so that holes of sparse files are preserved
+const sparseBlockSize = 4096
+
+// lost+found is created by mkfs on both disks so it is not compared
+const lostAndFoundDir = "lost+found"
+
+type nativeCopier struct {
+	logger boshlog.Logger
+}
+
+func NewNativeCopier(logger boshlog.Logger) nativeCopier {
+	return nativeCopier{logger: logger}
+}
+
+func (c nativeCopier) Copy(fromDir, toDir string, progress *Progress, cancelCh <-chan struct{}) error {
+	progress.reset()
+
+	c.logger.Info(nativeCopierLogTag, "Scanning %s", fromDir)
+
+	srcTree, err := scanTree(fromDir, cancelCh)
+	if err != nil {
+		return bosherr.WrapError(err, "Scanning %s", fromDir)
+	}
+
+	progress.setTotals(srcTree.files, srcTree.bytes)
+	progress.setPhase(PhaseCopying)
+
+	c.logger.Info(nativeCopierLogTag, "Copying %d files (%d bytes) from %s to %s", srcTree.files, srcTree.bytes, fromDir, toDir)
+
+	tc := treeCopy{
+		fromDir:   fromDir,
+		toDir:     toDir,
+		progress:  progress,
+		cancelCh:  cancelCh,
+		hardlinks: map[fileID]string{},
+		buf:       make([]byte, copyBufferSize),
+		logger:    c.logger,
+	}
+
+	err = tc.run()
+	if err != nil {
+		return err
+	}
+
+	progress.setPhase(PhaseVerifying)
+
+	c.logger.Info(nativeCopierLogTag, "Verifying %s against %s", toDir, fromDir)
+
+	err = c.verify(fromDir, toDir, srcTree.files, progress, cancelCh)
+	if err != nil {
+		return err
+	}
+
+	progress.setPhase(PhaseDone)
+
+	return nil
+}
+
+// verify compares number of files in both trees and checksums of regular files.
+// Files with mismatching checksums are removed so that retried copy copies them again.
+func (c nativeCopier) verify(fromDir, toDir string, srcFiles uint64, progress *Progress, cancelCh <-chan struct{}) error {
+	destTree, err := scanTree(toDir, cancelCh)
+	if err != nil {
+		return bosherr.WrapError(err, "Scanning %s", toDir)
+	}
+
+	if destTree.files != srcFiles {
+		return bosherr.New("Expected %d files in %s, found %d", srcFiles, toDir, destTree.files)
+	}
+
+	buf := make([]byte, copyBufferSize)
+	seen := map[fileID]bool{}
+
+	var mismatchedPaths []string
+
+	err = walkTree(fromDir, cancelCh, func(path, relPath string, info os.FileInfo, stat *syscall.Stat_t) error {
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		id := fileID{dev: uint64(stat.Dev), ino: stat.Ino}
+		if seen[id] {
+			return nil
+		}
+		seen[id] = true
+
+		destPath := filepath.Join(toDir, relPath)
+
+		srcSum, err := checksum(path, buf, cancelCh, progress)
+		if err != nil {
+			return bosherr.WrapError(err, "Calculating checksum of %s", path)
+		}
+
+		destSum, err := checksum(destPath, buf, cancelCh, nil)
+		if err != nil {
+			return bosherr.WrapError(err, "Calculating checksum of %s", destPath)
+		}
+
+		if !bytes.Equal(srcSum, destSum) {
+			mismatchedPaths = append(mismatchedPaths, relPath)
+
+			err = os.Remove(destPath)
+			if err != nil {
+				c.logger.Error(nativeCopierLogTag, "Failed to remove mismatched file %s: %s", destPath, err.Error())
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(mismatchedPaths) > 0 {
+		return bosherr.New("Checksums of %d files do not match (first is %s)", len(mismatchedPaths), mismatchedPaths[0])
+	}
+
+	return nil
+}
+
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+type treeCopy struct {
+	fromDir  string
+	toDir    string
+	progress *Progress
+	cancelCh <-chan struct{}
+
+	// Destination path of the first copied link of each hardlinked file
+	hardlinks map[fileID]string
+
+	// Directory metadata is applied after their contents are copied
+	// so that copying does not change directory timestamps and permissions
+	dirs []copiedDir
+
+	buf    []byte
+	logger boshlog.Logger
+}
+
+type copiedDir struct {
+	srcPath  string
+	destPath string
+	info     os.FileInfo
+	stat     *syscall.Stat_t
+}
+
+func (t *treeCopy) run() error {
+	err := walkTree(t.fromDir, t.cancelCh, t.copyEntry)
+	if err != nil {
+		return err
+	}
+
+	for i := len(t.dirs) - 1; i >= 0; i-- {
+		dir := t.dirs[i]
+
+		err = t.copyMetadata(dir.srcPath, dir.destPath, dir.info, dir.stat)
+		if err != nil {
+			return err
+		}
+	}
+
+	syscall.Sync()
+
+	return nil
+}
+
+func (t *treeCopy) copyEntry(srcPath, relPath string, info os.FileInfo, stat *syscall.Stat_t) error {
+	destPath := filepath.Join(t.toDir, relPath)
+	mode := info.Mode()
+
+	switch {
+	case mode.IsDir():
+		err := t.copyDir(destPath)
+		if err != nil {
+			return bosherr.WrapError(err, "Copying directory %s", relPath)
+		}
+
+		t.dirs = append(t.dirs, copiedDir{srcPath: srcPath, destPath: destPath, info: info, stat: stat})
+
+		return nil
+
+	case mode.IsRegular():
+		if stat.Nlink > 1 {
+			linked, err := t.copyHardlink(destPath, stat)
+			if err != nil {
+				return bosherr.WrapError(err, "Linking %s", relPath)
+			}
+
+			if linked {
+				t.progress.addCopiedFile()
+				return nil
+			}
+		}
+
+		err := t.copyFile(srcPath, destPath, info)
+		if err != nil {
+			return bosherr.WrapError(err, "Copying file %s", relPath)
+		}
+
+	case mode&os.ModeSymlink != 0:
+		err := t.copySymlink(srcPath, destPath)
+		if err != nil {
+			return bosherr.WrapError(err, "Copying symlink %s", relPath)
+		}
+
+		// Permissions and timestamps of symlinks are not used
+		err = os.Lchown(destPath, int(stat.Uid), int(stat.Gid))
+		if err != nil {
+			return bosherr.WrapError(err, "Changing owner of symlink %s", relPath)
+		}
+
+		t.progress.addCopiedFile()
+
+		return nil
+
+	case mode&(os.ModeDevice|os.ModeNamedPipe) != 0:
+		err := t.copySpecialFile(destPath, stat)
+		if err != nil {
+			return bosherr.WrapError(err, "Copying special file %s", relPath)
+		}
+
+	default:
+		// Sockets are recreated by processes listening on them
+		t.logger.Debug(nativeCopierLogTag, "Skipping %s with mode %s", relPath, mode)
+		return nil
+	}
+
+	err := t.copyMetadata(srcPath, destPath, info, stat)
+	if err != nil {
+		return err
+	}
+
+	t.progress.addCopiedFile()
+
+	return nil
+}
+
+func (t *treeCopy) copyDir(destPath string) error {
+	destInfo, err := os.Lstat(destPath)
+	if err == nil && destInfo.IsDir() {
+		return nil
+	}
+
+	err = removeExisting(destPath, err)
+	if err != nil {
+		return err
+	}
+
+	// Restrictive mode is replaced with the actual one after contents are copied
+	return os.Mkdir(destPath, os.FileMode(0700))
+}
+
+// copyHardlink links destPath to already copied file with the same inode
+func (t *treeCopy) copyHardlink(destPath string, stat *syscall.Stat_t) (bool, error) {
+	id := fileID{dev: uint64(stat.Dev), ino: stat.Ino}
+
+	linkedPath, found := t.hardlinks[id]
+	if !found {
+		t.hardlinks[id] = destPath
+		return false, nil
+	}
+
+	destInfo, err := os.Lstat(destPath)
+	if err == nil {
+		linkedInfo, err := os.Lstat(linkedPath)
+		if err == nil && os.SameFile(destInfo, linkedInfo) {
+			return true, nil
+		}
+	}
+
+	err = removeExisting(destPath, err)
+	if err != nil {
+		return false, err
+	}
+
+	return true, os.Link(linkedPath, destPath)
+}
+
+// copyFile resumes copying from the end of previously partially copied file.
+// Modification time is copied last so that matching size and modification time
+// indicate that file was fully copied before.
+func (t *treeCopy) copyFile(srcPath, destPath string, info os.FileInfo) error {
+	var offset int64
+
+	destInfo, err := os.Lstat(destPath)
+	if err == nil && destInfo.Mode().IsRegular() {
+		if destInfo.Size() == info.Size() && destInfo.ModTime().Equal(info.ModTime()) {
+			t.progress.addCopiedBytes(uint64(info.Size()))
+			return nil
+		}
+
+		if destInfo.Size() <= info.Size() {
+			offset = destInfo.Size()
+		}
+	} else {
+		err = removeExisting(destPath, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	srcFile, err := os.Open(srcPath)
+	if err != nil {
+		return bosherr.WrapError(err, "Opening source file")
+	}
+
+	defer srcFile.Close()
+
+	destFile, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE, os.FileMode(0600))
+	if err != nil {
+		return bosherr.WrapError(err, "Opening destination file")
+	}
+
+	defer destFile.Close()
+
+	err = destFile.Truncate(offset)
+	if err != nil {
+		return bosherr.WrapError(err, "Truncating destination file")
+	}
+
+	if offset > 0 {
+		t.logger.Debug(nativeCopierLogTag, "Resuming copy of %s from %d bytes", srcPath, offset)
+
+		_, err = srcFile.Seek(offset, 0)
+		if err != nil {
+			return bosherr.WrapError(err, "Seeking source file")
+		}
+
+		_, err = destFile.Seek(offset, 0)
+		if err != nil {
+			return bosherr.WrapError(err, "Seeking destination file")
+		}
+
+		t.progress.addCopiedBytes(uint64(offset))
+	}
+
+	err = copyChunks(sparseFileWriter{destFile}, srcFile, t.buf, t.cancelCh, t.progress.addCopiedBytes)
+	if err != nil {
+		return err
+	}
+
+	// Trailing holes are not written so file is extended to its full size
+	err = destFile.Truncate(info.Size())
+	if err != nil {
+		return bosherr.WrapError(err, "Truncating destination file")
+	}
+
+	return destFile.Close()
+}
+
+func (t *treeCopy) copySymlink(srcPath, destPath string) error {
+	target, err := os.Readlink(srcPath)
+	if err != nil {
+		return bosherr.WrapError(err, "Reading symlink")
+	}
+
+	destTarget, err := os.Readlink(destPath)
+	if err == nil && destTarget == target {
+		return nil
+	}
+
+	_, err = os.Lstat(destPath)
+
+	err = removeExisting(destPath, err)
+	if err != nil {
+		return err
+	}
+
+	return os.Symlink(target, destPath)
+}
+
+func (t *treeCopy) copySpecialFile(destPath string, stat *syscall.Stat_t) error {
+	destInfo, err := os.Lstat(destPath)
+	if err == nil {
+		destStat, ok := destInfo.Sys().(*syscall.Stat_t)
+		if ok && destStat.Mode&syscall.S_IFMT == stat.Mode&syscall.S_IFMT && destStat.Rdev == stat.Rdev {
+			return nil
+		}
+	}
+
+	err = removeExisting(destPath, err)
+	if err != nil {
+		return err
+	}
+
+	return syscall.Mknod(destPath, stat.Mode, int(stat.Rdev))
+}
+
+// copyMetadata copies owner, extended attributes, mode and timestamps in that order
+// since changing owner clears setuid bits and file capabilities
+func (t *treeCopy) copyMetadata(srcPath, destPath string, info os.FileInfo, stat *syscall.Stat_t) error {
+	err := os.Lchown(destPath, int(stat.Uid), int(stat.Gid))
+	if err != nil {
+		return bosherr.WrapError(err, "Changing owner of %s", destPath)
+	}
+
+	err = t.copyXattrs(srcPath, destPath)
+	if err != nil {
+		return bosherr.WrapError(err, "Copying extended attributes of %s", destPath)
+	}
+
+	err = os.Chmod(destPath, info.Mode())
+	if err != nil {
+		return bosherr.WrapError(err, "Changing mode of %s", destPath)
+	}
+
+	atimeSec, atimeNsec := stat.Atim.Unix()
+
+	err = os.Chtimes(destPath, time.Unix(atimeSec, atimeNsec), info.ModTime())
+	if err != nil {
+		return bosherr.WrapError(err, "Changing timestamps of %s", destPath)
+	}
+
+	return nil
+}
+
+func (t *treeCopy) copyXattrs(srcPath, destPath string) error {
+	names, err := listXattrs(srcPath)
+	if err == syscall.ENOTSUP {
+		return nil
+	} else if err != nil {
+		return bosherr.WrapError(err, "Listing extended attributes")
+	}
+
+	for _, name := range names {
+		value, err := getXattr(srcPath, name)
+		if err != nil {
+			return bosherr.WrapError(err, "Getting extended attribute %s", name)
+		}
+
+		err = syscall.Setxattr(destPath, name, value, 0)
+		if err == syscall.ENOTSUP {
+			t.logger.Info(nativeCopierLogTag, "Destination does not support extended attribute %s of %s", name, srcPath)
+			continue
+		} else if err != nil {
+			return bosherr.WrapError(err, "Setting extended attribute %s", name)
+		}
+	}
+
+	return nil
+}
+
+type scannedTree struct {
+	files uint64
+	bytes uint64
+}
+
+// scanTree counts files that are copied and bytes of their contents
+// counting each hardlinked file only once
+func scanTree(dir string, cancelCh <-chan struct{}) (scannedTree, error) {
+	var tree scannedTree
+
+	seen := map[fileID]bool{}
+
+	err := walkTree(dir, cancelCh, func(path, relPath string, info os.FileInfo, stat *syscall.Stat_t) error {
+		if info.Mode()&os.ModeSocket != 0 {
+			return nil
+		}
+
+		tree.files++
+
+		if info.Mode().IsRegular() {
+			id := fileID{dev: uint64(stat.Dev), ino: stat.Ino}
+			if !seen[id] {
+				seen[id] = true
+				tree.bytes += uint64(info.Size())
+			}
+		}
+
+		return nil
+	})
+
+	return tree, err
+}
+
+type walkTreeFunc func(path, relPath string, info os.FileInfo, stat *syscall.Stat_t) error
+
+// walkTree walks dir in lexical order including dir itself
+// and skips lost+found created by mkfs
+func walkTree(dir string, cancelCh <-chan struct{}, walkFunc walkTreeFunc) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-cancelCh:
+			return ErrCancelled
+		default:
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return bosherr.WrapError(err, "Determining relative path of %s", path)
+		}
+
+		if relPath == lostAndFoundDir && info.IsDir() {
+			return filepath.SkipDir
+		}
+
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			return bosherr.New("Getting stat of %s", path)
+		}
+
+		return walkFunc(path, relPath, info, stat)
+	})
+}
+
+func copyChunks(dst io.Writer, src io.Reader, buf []byte, cancelCh <-chan struct{}, chunkFunc func(uint64)) error {
+	for {
+		select {
+		case <-cancelCh:
+			return ErrCancelled
+		default:
+		}
+
+		n, err := src.Read(buf)
+		if n > 0 {
+			_, writeErr := dst.Write(buf[:n])
+			if writeErr != nil {
+				return bosherr.WrapError(writeErr, "Writing")
+			}
+
+			chunkFunc(uint64(n))
+		}
+
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return bosherr.WrapError(err, "Reading")
+		}
+	}
+}
+
+// sparseFileWriter seeks over blocks of zeros instead of writing them
+// leaving holes in the file; consecutive non-zero blocks are written at once
+type sparseFileWriter struct {
+	file *os.File
+}
+
+func (w sparseFileWriter) Write(p []byte) (int, error) {
+	written := 0
+
+	for written < len(p) {
+		dataLen := 0
+		for written+dataLen < len(p) {
+			block := p[written+dataLen : minInt(written+dataLen+sparseBlockSize, len(p))]
+			if isZeroBlock(block) {
+				break
+			}
+			dataLen += len(block)
+		}
+
+		if dataLen > 0 {
+			n, err := w.file.Write(p[written : written+dataLen])
+			written += n
+			if err != nil {
+				return written, err
+			}
+			continue
+		}
+
+		holeLen := len(p[written:minInt(written+sparseBlockSize, len(p))])
+
+		_, err := w.file.Seek(int64(holeLen), 1)
+		if err != nil {
+			return written, err
+		}
+
+		written += holeLen
+	}
+
+	return written, nil
+}
+
+func isZeroBlock(block []byte) bool {
+	for _, b := range block {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// checksum reports read bytes as verified to progress when it is given
+func checksum(path string, buf []byte, cancelCh <-chan struct{}, progress *Progress) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	hash := sha256.New()
+
+	chunkFunc := func(uint64) {}
+	if progress != nil {
+		chunkFunc = progress.addVerifiedBytes
+	}
+
+	err = copyChunks(hash, file, buf, cancelCh, chunkFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	return hash.Sum(nil), nil
+}
+
+// removeExisting removes path unless lstatErr indicates it does not exist
+func removeExisting(path string, lstatErr error) error {
+	if os.IsNotExist(lstatErr) {
+		return nil
+	} else if lstatErr != nil {
+		return lstatErr
+	}
+
+	return os.RemoveAll(path)
+}
+
+func listXattrs(path string) ([]string, error) {
+	size, err := syscall.Listxattr(path, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+
+	buf := make([]byte, size)
+
+	size, err = syscall.Listxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+
+	for _, name := range bytes.Split(buf[:size], []byte{0}) {
+		if len(name) > 0 {
+			names = append(names, string(name))
+		}
+	}
+
+	return names, nil
+}
+
+func getXattr(path, name string) ([]byte, error) {
+	size, err := syscall.Getxattr(path, name, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+
+	buf := make([]byte, size)
+
+	size, err = syscall.Getxattr(path, name, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf[:size], nil
+}
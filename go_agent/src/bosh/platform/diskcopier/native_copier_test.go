@@ -0,0 +1,295 @@
+package diskcopier_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshlog "bosh/logger"
+	. "bosh/platform/diskcopier"
+)
+
+var _ = Describe("nativeCopier", func() {
+	var (
+		fromDir  string
+		toDir    string
+		progress *Progress
+		cancelCh chan struct{}
+		copier   Copier
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		fromDir, err = ioutil.TempDir("", "native-copier-from")
+		Expect(err).ToNot(HaveOccurred())
+
+		toDir, err = ioutil.TempDir("", "native-copier-to")
+		Expect(err).ToNot(HaveOccurred())
+
+		progress = NewProgress()
+		cancelCh = make(chan struct{})
+		copier = NewNativeCopier(boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(fromDir)
+		os.RemoveAll(toDir)
+	})
+
+	writeFile := func(relPath, contents string, mode os.FileMode) string {
+		path := filepath.Join(fromDir, relPath)
+
+		err := os.MkdirAll(filepath.Dir(path), os.FileMode(0755))
+		Expect(err).ToNot(HaveOccurred())
+
+		err = ioutil.WriteFile(path, []byte(contents), mode)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = os.Chmod(path, mode)
+		Expect(err).ToNot(HaveOccurred())
+
+		return path
+	}
+
+	readDestFile := func(relPath string) string {
+		contents, err := ioutil.ReadFile(filepath.Join(toDir, relPath))
+		Expect(err).ToNot(HaveOccurred())
+		return string(contents)
+	}
+
+	Describe("Copy", func() {
+		It("copies files, directories and symlinks", func() {
+			writeFile("fake-file", "fake-contents", os.FileMode(0640))
+			writeFile("fake-dir/fake-nested-file", "fake-nested-contents", os.FileMode(0600))
+
+			err := os.Symlink("fake-dir/fake-nested-file", filepath.Join(fromDir, "fake-symlink"))
+			Expect(err).ToNot(HaveOccurred())
+
+			err = copier.Copy(fromDir, toDir, progress, cancelCh)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(readDestFile("fake-file")).To(Equal("fake-contents"))
+			Expect(readDestFile("fake-dir/fake-nested-file")).To(Equal("fake-nested-contents"))
+
+			target, err := os.Readlink(filepath.Join(toDir, "fake-symlink"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(target).To(Equal("fake-dir/fake-nested-file"))
+		})
+
+		It("preserves modes, owners and modification times", func() {
+			path := writeFile("fake-dir/fake-file", "fake-contents", os.FileMode(04750))
+
+			err := os.Chown(path, 1234, 5678)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = os.Chmod(path, os.FileMode(0750)|os.ModeSetuid)
+			Expect(err).ToNot(HaveOccurred())
+
+			mtime := time.Date(2010, 1, 2, 3, 4, 5, 0, time.UTC)
+
+			err = os.Chtimes(path, mtime, mtime)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = os.Chmod(filepath.Join(fromDir, "fake-dir"), os.FileMode(0710))
+			Expect(err).ToNot(HaveOccurred())
+
+			err = os.Chtimes(filepath.Join(fromDir, "fake-dir"), mtime, mtime)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = copier.Copy(fromDir, toDir, progress, cancelCh)
+			Expect(err).ToNot(HaveOccurred())
+
+			info, err := os.Stat(filepath.Join(toDir, "fake-dir", "fake-file"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode()).To(Equal(os.FileMode(0750) | os.ModeSetuid))
+			Expect(info.ModTime().Equal(mtime)).To(BeTrue())
+
+			stat := info.Sys().(*syscall.Stat_t)
+			Expect(stat.Uid).To(Equal(uint32(1234)))
+			Expect(stat.Gid).To(Equal(uint32(5678)))
+
+			dirInfo, err := os.Stat(filepath.Join(toDir, "fake-dir"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dirInfo.Mode().Perm()).To(Equal(os.FileMode(0710)))
+			Expect(dirInfo.ModTime().Equal(mtime)).To(BeTrue())
+		})
+
+		It("preserves extended attributes", func() {
+			path := writeFile("fake-file", "fake-contents", os.FileMode(0640))
+
+			err := syscall.Setxattr(path, "user.fake-attr", []byte("fake-value"), 0)
+			if err == syscall.ENOTSUP {
+				return // temp dir file system does not support user extended attributes
+			}
+			Expect(err).ToNot(HaveOccurred())
+
+			err = copier.Copy(fromDir, toDir, progress, cancelCh)
+			Expect(err).ToNot(HaveOccurred())
+
+			buf := make([]byte, 64)
+			size, err := syscall.Getxattr(filepath.Join(toDir, "fake-file"), "user.fake-attr", buf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(buf[:size])).To(Equal("fake-value"))
+		})
+
+		It("preserves hardlinks", func() {
+			path := writeFile("fake-file", "fake-contents", os.FileMode(0640))
+
+			err := os.Link(path, filepath.Join(fromDir, "fake-link"))
+			Expect(err).ToNot(HaveOccurred())
+
+			err = copier.Copy(fromDir, toDir, progress, cancelCh)
+			Expect(err).ToNot(HaveOccurred())
+
+			fileInfo, err := os.Stat(filepath.Join(toDir, "fake-file"))
+			Expect(err).ToNot(HaveOccurred())
+
+			linkInfo, err := os.Stat(filepath.Join(toDir, "fake-link"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(os.SameFile(fileInfo, linkInfo)).To(BeTrue())
+		})
+
+		It("preserves holes of sparse files", func() {
+			path := filepath.Join(fromDir, "fake-sparse-file")
+
+			file, err := os.Create(path)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = file.WriteAt([]byte("fake-start"), 0)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = file.WriteAt([]byte("fake-middle"), 32*1024*1024)
+			Expect(err).ToNot(HaveOccurred())
+
+			// Trailing hole
+			err = file.Truncate(64 * 1024 * 1024)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = file.Close()
+			Expect(err).ToNot(HaveOccurred())
+
+			err = copier.Copy(fromDir, toDir, progress, cancelCh)
+			Expect(err).ToNot(HaveOccurred())
+
+			srcInfo, err := os.Stat(path)
+			Expect(err).ToNot(HaveOccurred())
+
+			destInfo, err := os.Stat(filepath.Join(toDir, "fake-sparse-file"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(destInfo.Size()).To(Equal(srcInfo.Size()))
+
+			// Allocated size is reported in 512 byte blocks
+			srcBlocks := srcInfo.Sys().(*syscall.Stat_t).Blocks
+			destBlocks := destInfo.Sys().(*syscall.Stat_t).Blocks
+			Expect(destBlocks <= srcBlocks).To(BeTrue())
+			Expect(destBlocks < 1024).To(BeTrue())
+		})
+
+		It("reports progress", func() {
+			writeFile("fake-file-1", "12345", os.FileMode(0640))
+			writeFile("fake-file-2", "1234567890", os.FileMode(0640))
+
+			err := copier.Copy(fromDir, toDir, progress, cancelCh)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(progress.Value()).To(Equal(ProgressValue{
+				Phase:         PhaseDone,
+				TotalFiles:    3, // including root directory
+				CopiedFiles:   2,
+				TotalBytes:    15,
+				CopiedBytes:   15,
+				VerifiedBytes: 15,
+			}))
+		})
+
+		It("ignores lost+found directories", func() {
+			writeFile("fake-file", "fake-contents", os.FileMode(0640))
+
+			err := os.Mkdir(filepath.Join(toDir, "lost+found"), os.FileMode(0700))
+			Expect(err).ToNot(HaveOccurred())
+
+			err = copier.Copy(fromDir, toDir, progress, cancelCh)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("when previous copy was interrupted", func() {
+			It("continues copying partially copied file", func() {
+				writeFile("fake-file", "fake-contents", os.FileMode(0640))
+
+				err := ioutil.WriteFile(filepath.Join(toDir, "fake-file"), []byte("fake-"), os.FileMode(0600))
+				Expect(err).ToNot(HaveOccurred())
+
+				err = copier.Copy(fromDir, toDir, progress, cancelCh)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(readDestFile("fake-file")).To(Equal("fake-contents"))
+			})
+
+			It("does not copy already copied files again", func() {
+				path := writeFile("fake-file", "fake-contents", os.FileMode(0640))
+
+				err := copier.Copy(fromDir, toDir, progress, cancelCh)
+				Expect(err).ToNot(HaveOccurred())
+
+				destInfo, err := os.Stat(filepath.Join(toDir, "fake-file"))
+				Expect(err).ToNot(HaveOccurred())
+
+				// Changing contents of the source without changing size and modification time
+				err = ioutil.WriteFile(path, []byte("fake-changed!"), os.FileMode(0640))
+				Expect(err).ToNot(HaveOccurred())
+
+				err = os.Chtimes(path, destInfo.ModTime(), destInfo.ModTime())
+				Expect(err).ToNot(HaveOccurred())
+
+				err = copier.Copy(fromDir, toDir, progress, cancelCh)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Checksums of 1 files do not match (first is fake-file)"))
+
+				// Mismatched file is removed so that next copy copies it
+				_, err = os.Stat(filepath.Join(toDir, "fake-file"))
+				Expect(os.IsNotExist(err)).To(BeTrue())
+
+				err = copier.Copy(fromDir, toDir, progress, cancelCh)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(readDestFile("fake-file")).To(Equal("fake-changed!"))
+			})
+		})
+
+		It("returns error if destination has extra files", func() {
+			writeFile("fake-file", "fake-contents", os.FileMode(0640))
+
+			err := ioutil.WriteFile(filepath.Join(toDir, "fake-extra-file"), []byte{}, os.FileMode(0600))
+			Expect(err).ToNot(HaveOccurred())
+
+			err = copier.Copy(fromDir, toDir, progress, cancelCh)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected 2 files"))
+		})
+
+		It("returns cancelled error when cancelled", func() {
+			writeFile("fake-file", "fake-contents", os.FileMode(0640))
+
+			close(cancelCh)
+
+			err := copier.Copy(fromDir, toDir, progress, cancelCh)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(ErrCancelled.Error()))
+
+			_, err = os.Stat(filepath.Join(toDir, "fake-file"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("returns error if source directory does not exist", func() {
+			err := copier.Copy(filepath.Join(fromDir, "fake-missing"), toDir, progress, cancelCh)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Scanning"))
+		})
+	})
+})
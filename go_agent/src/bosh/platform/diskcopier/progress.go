@@ -0,0 +1,76 @@
+package diskcopier
+
+import (
+	"sync"
+)
+
+type Phase string
+
+const (
+	PhaseScanning  Phase = "scanning"
+	PhaseCopying   Phase = "copying"
+	PhaseVerifying Phase = "verifying"
+	PhaseDone      Phase = "done"
+)
+
+type ProgressValue struct {
+	Phase Phase `json:"phase"`
+
+	TotalFiles  uint64 `json:"total_files"`
+	CopiedFiles uint64 `json:"copied_files"`
+
+	TotalBytes    uint64 `json:"total_bytes"`
+	CopiedBytes   uint64 `json:"copied_bytes"`
+	VerifiedBytes uint64 `json:"verified_bytes"`
+}
+
+// Progress can be read while copy is updating it
+type Progress struct {
+	lock  sync.RWMutex
+	value ProgressValue
+}
+
+func NewProgress() *Progress {
+	return &Progress{}
+}
+
+func (p *Progress) Value() ProgressValue {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.value
+}
+
+func (p *Progress) update(updateFunc func(*ProgressValue)) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	updateFunc(&p.value)
+}
+
+func (p *Progress) reset() {
+	p.update(func(v *ProgressValue) { *v = ProgressValue{Phase: PhaseScanning} })
+}
+
+func (p *Progress) setPhase(phase Phase) {
+	p.update(func(v *ProgressValue) { v.Phase = phase })
+}
+
+func (p *Progress) setTotals(files, bytes uint64) {
+	p.update(func(v *ProgressValue) {
+		v.TotalFiles = files
+		v.TotalBytes = bytes
+	})
+}
+
+func (p *Progress) addCopiedFile() {
+	p.update(func(v *ProgressValue) { v.CopiedFiles++ })
+}
+
+func (p *Progress) addCopiedBytes(bytes uint64) {
+	p.update(func(v *ProgressValue) { v.CopiedBytes += bytes })
+}
+
+func (p *Progress) addVerifiedBytes(bytes uint64) {
+	p.update(func(v *ProgressValue) { v.VerifiedBytes += bytes })
+}
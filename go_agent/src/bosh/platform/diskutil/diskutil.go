@@ -0,0 +1,80 @@
+package diskutil
+
+import (
+	"path/filepath"
+
+	bosherr "bosh/errors"
+	boshlog "bosh/logger"
+	boshdisk "bosh/platform/disk"
+	boshsys "bosh/system"
+)
+
+const diskUtilLogTag = "diskUtil"
+
+type diskUtil struct {
+	diskPath string
+	mounter  boshdisk.Mounter
+	fs       boshsys.FileSystem
+	logger   boshlog.Logger
+}
+
+func NewDiskUtil(
+	diskPath string,
+	mounter boshdisk.Mounter,
+	fs boshsys.FileSystem,
+	logger boshlog.Logger,
+) DiskUtil {
+	return diskUtil{
+		diskPath: diskPath,
+		mounter:  mounter,
+		fs:       fs,
+		logger:   logger,
+	}
+}
+
+func (util diskUtil) GetFilesContents(fileNames []string) ([][]byte, error) {
+	mountPath, err := util.fs.TempDir("diskutil")
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Creating temporary disk mount point")
+	}
+
+	defer util.fs.RemoveAll(mountPath)
+
+	// Disk is not expected to be modified by the agent
+	err = util.mounter.Mount(util.diskPath, mountPath, "-o", "ro")
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Mounting disk %s", util.diskPath)
+	}
+
+	contents, readErr := util.readFiles(mountPath, fileNames)
+
+	_, err = util.mounter.Unmount(mountPath)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Unmounting disk %s", util.diskPath)
+	}
+
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	return contents, nil
+}
+
+func (util diskUtil) readFiles(mountPath string, fileNames []string) ([][]byte, error) {
+	var contents [][]byte
+
+	for _, fileName := range fileNames {
+		filePath := filepath.Join(mountPath, fileName)
+
+		util.logger.Debug(diskUtilLogTag, "Reading %s from disk %s", fileName, util.diskPath)
+
+		content, err := util.fs.ReadFile(filePath)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Reading %s from disk", fileName)
+		}
+
+		contents = append(contents, content)
+	}
+
+	return contents, nil
+}
@@ -0,0 +1,6 @@
+package diskutil
+
+type DiskUtil interface {
+	// GetFilesContents returns contents of files in the same order as file names
+	GetFilesContents(fileNames []string) (contents [][]byte, err error)
+}
@@ -0,0 +1,13 @@
+package diskutil_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestDiskutil(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Diskutil Suite")
+}
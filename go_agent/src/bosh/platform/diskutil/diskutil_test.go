@@ -0,0 +1,82 @@
+package diskutil_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshlog "bosh/logger"
+	fakedisk "bosh/platform/disk/fakes"
+	. "bosh/platform/diskutil"
+	fakesys "bosh/system/fakes"
+)
+
+var _ = Describe("diskUtil", func() {
+	var (
+		fs       *fakesys.FakeFileSystem
+		mounter  *fakedisk.FakeMounter
+		diskUtil DiskUtil
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		fs.TempDirDir = "/fake-tmp-dir"
+		mounter = &fakedisk.FakeMounter{}
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		diskUtil = NewDiskUtil("/dev/disk/by-label/config-2", mounter, fs, logger)
+	})
+
+	Describe("GetFilesContents", func() {
+		BeforeEach(func() {
+			fs.WriteFileString("/fake-tmp-dir/file1", "fake-contents-1")
+			fs.WriteFileString("/fake-tmp-dir/dir/file2", "fake-contents-2")
+		})
+
+		It("mounts disk read-only and returns contents of files", func() {
+			contents, err := diskUtil.GetFilesContents([]string{"file1", "dir/file2"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(contents).To(Equal([][]byte{
+				[]byte("fake-contents-1"),
+				[]byte("fake-contents-2"),
+			}))
+
+			Expect(mounter.MountPartitionPaths).To(Equal([]string{"/dev/disk/by-label/config-2"}))
+			Expect(mounter.MountMountPoints).To(Equal([]string{"/fake-tmp-dir"}))
+			Expect(mounter.MountMountOptions).To(Equal([][]string{{"-o", "ro"}}))
+		})
+
+		It("unmounts disk and removes mount point", func() {
+			_, err := diskUtil.GetFilesContents([]string{"file1"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(mounter.UnmountPartitionPathOrMountPoint).To(Equal("/fake-tmp-dir"))
+			Expect(fs.FileExists("/fake-tmp-dir")).To(BeFalse())
+		})
+
+		It("returns error if mounting disk fails", func() {
+			mounter.MountErr = errors.New("fake-mount-err")
+
+			_, err := diskUtil.GetFilesContents([]string{"file1"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-mount-err"))
+		})
+
+		It("returns error and unmounts disk if reading file fails", func() {
+			_, err := diskUtil.GetFilesContents([]string{"fake-missing-file"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Reading fake-missing-file from disk"))
+
+			Expect(mounter.UnmountPartitionPathOrMountPoint).To(Equal("/fake-tmp-dir"))
+		})
+
+		It("returns error if unmounting disk fails", func() {
+			mounter.UnmountErr = errors.New("fake-unmount-err")
+
+			_, err := diskUtil.GetFilesContents([]string{"file1"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-unmount-err"))
+		})
+	})
+})
@@ -0,0 +1,16 @@
+package fakes
+
+type FakeDiskUtil struct {
+	GetFilesContentsFileNames []string
+	GetFilesContentsContents  [][]byte
+	GetFilesContentsErr       error
+}
+
+func NewFakeDiskUtil() *FakeDiskUtil {
+	return &FakeDiskUtil{}
+}
+
+func (util *FakeDiskUtil) GetFilesContents(fileNames []string) ([][]byte, error) {
+	util.GetFilesContentsFileNames = fileNames
+	return util.GetFilesContentsContents, util.GetFilesContentsErr
+}
@@ -8,6 +8,7 @@ import (
 	boshdpresolv "bosh/infrastructure/devicepathresolver"
 	boshlog "bosh/logger"
 	boshcmd "bosh/platform/commands"
+	boshdiskcopier "bosh/platform/diskcopier"
 	boshstats "bosh/platform/stats"
 	boshvitals "bosh/platform/vitals"
 	boshsettings "bosh/settings"
@@ -42,7 +43,7 @@ func NewDummyPlatform(
 		compressor:    boshcmd.NewTarballCompressor(cmdRunner, fs),
 		copier:        boshcmd.NewCpCopier(cmdRunner, fs, logger),
 		dirProvider:   dirProvider,
-		vitalsService: boshvitals.NewService(collector, dirProvider),
+		vitalsService: boshvitals.NewService(collector, dirProvider, nil),
 	}
 }
 
@@ -135,11 +136,11 @@ func (p dummyPlatform) SetupTmpDir() error {
 	return nil
 }
 
-func (p dummyPlatform) MountPersistentDisk(devicePath, mountPoint string) (err error) {
+func (p dummyPlatform) MountPersistentDisk(volumeID, devicePath, mountPoint string) (result PersistentDiskMountResult, err error) {
 	return
 }
 
-func (p dummyPlatform) UnmountPersistentDisk(devicePath string) (didUnmount bool, err error) {
+func (p dummyPlatform) UnmountPersistentDisk(volumeID, devicePath string) (didUnmount bool, err error) {
 	return
 }
 
@@ -151,7 +152,11 @@ func (p dummyPlatform) GetFileContentsFromCDROM(filePath string) (contents []byt
 	return
 }
 
-func (p dummyPlatform) MigratePersistentDisk(fromMountPoint, toMountPoint string) (err error) {
+func (p dummyPlatform) GetFilesContentsFromDisk(diskPath string, fileNames []string) (contents [][]byte, err error) {
+	return
+}
+
+func (p dummyPlatform) MigratePersistentDisk(fromMountPoint, toMountPoint string, progress *boshdiskcopier.Progress, cancelCh <-chan struct{}) (err error) {
 	return
 }
 
@@ -159,7 +164,23 @@ func (p dummyPlatform) IsMountPoint(path string) (result bool, err error) {
 	return
 }
 
-func (p dummyPlatform) IsPersistentDiskMounted(path string) (result bool, err error) {
+func (p dummyPlatform) IsPersistentDiskMounted(volumeID, devicePath string) (result bool, err error) {
+	return
+}
+
+func (p dummyPlatform) CheckPersistentDiskSnapshot(volumeID, devicePath, name string) (err error) {
+	return
+}
+
+func (p dummyPlatform) SnapshotPersistentDisk(volumeID, devicePath, name string) (snapshot PersistentDiskSnapshot, err error) {
+	return
+}
+
+func (p dummyPlatform) ListPersistentDiskSnapshots(volumeID, devicePath string) (snapshots []PersistentDiskSnapshot, err error) {
+	return
+}
+
+func (p dummyPlatform) DeletePersistentDiskSnapshot(volumeID, devicePath, name string) (err error) {
 	return
 }
 
@@ -179,6 +200,22 @@ func (p dummyPlatform) PrepareForNetworkingChange() error {
 	return nil
 }
 
+func (p dummyPlatform) StartDNSCache(networks boshsettings.Networks) error {
+	return nil
+}
+
+func (p dummyPlatform) VerifyNetworking(networks boshsettings.Networks, endpoints []string) (err error) {
+	return
+}
+
+func (p dummyPlatform) HasUnverifiedNetworking() (result bool, err error) {
+	return
+}
+
+func (p dummyPlatform) GetNetworkingFailure() (failure string, err error) {
+	return
+}
+
 func (p dummyPlatform) GetDefaultNetwork() (boshsettings.Network, error) {
 	var network boshsettings.Network
 
@@ -0,0 +1,217 @@
+package platform
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	bosherr "bosh/errors"
+	boshdisk "bosh/platform/disk"
+)
+
+const (
+	// EphemeralSwapSizeNone disables swap partition on ephemeral disk
+	EphemeralSwapSizeNone = "none"
+
+	// sfdisk only creates primary partitions on msdos partition tables
+	maxEphemeralDiskPartitions = 4
+)
+
+type EphemeralDiskOptions struct {
+	// Size of swap partition: empty to size it to RAM (at most half of the disk),
+	// "none" to skip swap, size in MB (e.g. "2048") or percent of the disk (e.g. "10%")
+	SwapSize string
+
+	// File system used for data and extra partitions; ext4 by default
+	FileSystem boshdisk.FileSystemType
+
+	// Extra partitions placed between swap and data partitions
+	Partitions []EphemeralPartitionOptions
+
+	// When set to true disk is re-partitioned and re-formatted, losing its data,
+	// if its layout changed since it was set up (e.g. different swap size or file system);
+	// otherwise setting up ephemeral disk fails
+	AllowRepartition bool
+}
+
+type EphemeralPartitionOptions struct {
+	// Mount point relative to data dir (e.g. "sys/log")
+	MountPoint string
+
+	// Size in MB (e.g. "2048") or percent of the disk (e.g. "10%")
+	Size string
+}
+
+type ephemeralPartition struct {
+	boshdisk.Partition
+
+	fsType boshdisk.FileSystemType
+
+	// Empty for swap partition
+	mountPoint string
+}
+
+// ephemeralPartitionRecord describes partition of the layout ephemeral disk was set up with
+type ephemeralPartitionRecord struct {
+	SizeInMb   uint64
+	Type       boshdisk.PartitionType
+	FileSystem boshdisk.FileSystemType
+	MountPoint string
+}
+
+// ephemeralDiskLayoutRecord is empty when disk was not set up before
+type ephemeralDiskLayoutRecord []ephemeralPartitionRecord
+
+func newEphemeralDiskLayoutRecord(layout []ephemeralPartition) ephemeralDiskLayoutRecord {
+	var records ephemeralDiskLayoutRecord
+
+	for _, partition := range layout {
+		records = append(records, ephemeralPartitionRecord{
+			SizeInMb:   partition.SizeInMb,
+			Type:       partition.Type,
+			FileSystem: partition.fsType,
+			MountPoint: partition.mountPoint,
+		})
+	}
+
+	return records
+}
+
+func (r ephemeralDiskLayoutRecord) partitions() []boshdisk.Partition {
+	var partitions []boshdisk.Partition
+
+	for _, record := range r {
+		partitions = append(partitions, boshdisk.Partition{SizeInMb: record.SizeInMb, Type: record.Type})
+	}
+
+	return partitions
+}
+
+// swapSizeInMb returns size of swap partition disk was set up with or 0 if there was none
+func (r ephemeralDiskLayoutRecord) swapSizeInMb() uint64 {
+	for _, record := range r {
+		if record.Type == boshdisk.PartitionTypeSwap {
+			return record.SizeInMb
+		}
+	}
+
+	return 0
+}
+
+// layout returns partitions in the order they are laid out on disk.
+// Data partition is always the last one so that it takes the rest of the disk.
+func (o EphemeralDiskOptions) layout(diskSizeInMb, totalMemInMb uint64, dataDir string, previous ephemeralDiskLayoutRecord) ([]ephemeralPartition, error) {
+	var partitions []ephemeralPartition
+	var usedSizeInMb uint64
+
+	fsType := o.FileSystem
+	if len(fsType) == 0 {
+		fsType = boshdisk.FileSystemExt4
+	}
+
+	switch fsType {
+	case boshdisk.FileSystemExt4, boshdisk.FileSystemXFS:
+	default:
+		return nil, bosherr.New("Unsupported ephemeral disk file system %s", fsType)
+	}
+
+	swapSize, swapEnabled, err := o.swapSizeInMb(diskSizeInMb, totalMemInMb, previous.swapSizeInMb())
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Parsing swap size")
+	}
+
+	if swapEnabled {
+		partitions = append(partitions, ephemeralPartition{
+			Partition: boshdisk.Partition{SizeInMb: swapSize, Type: boshdisk.PartitionTypeSwap},
+			fsType:    boshdisk.FileSystemSwap,
+		})
+		usedSizeInMb += swapSize
+	}
+
+	for _, partitionOpts := range o.Partitions {
+		if len(partitionOpts.MountPoint) == 0 || filepath.IsAbs(partitionOpts.MountPoint) {
+			return nil, bosherr.New("Expected mount point relative to data dir, got '%s'", partitionOpts.MountPoint)
+		}
+
+		size, err := parseEphemeralPartitionSize(partitionOpts.Size, diskSizeInMb)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Parsing size of %s partition", partitionOpts.MountPoint)
+		}
+
+		if size == 0 {
+			return nil, bosherr.New("Expected non-zero size of %s partition", partitionOpts.MountPoint)
+		}
+
+		partitions = append(partitions, ephemeralPartition{
+			Partition:  boshdisk.Partition{SizeInMb: size, Type: boshdisk.PartitionTypeLinux},
+			fsType:     fsType,
+			mountPoint: filepath.Join(dataDir, partitionOpts.MountPoint),
+		})
+		usedSizeInMb += size
+	}
+
+	if usedSizeInMb > 0 && usedSizeInMb >= diskSizeInMb {
+		return nil, bosherr.New("Disk of %dMB is too small for %dMB of swap and extra partitions", diskSizeInMb, usedSizeInMb)
+	}
+
+	partitions = append(partitions, ephemeralPartition{
+		Partition:  boshdisk.Partition{SizeInMb: diskSizeInMb - usedSizeInMb, Type: boshdisk.PartitionTypeLinux},
+		fsType:     fsType,
+		mountPoint: dataDir,
+	})
+
+	if len(partitions) > maxEphemeralDiskPartitions {
+		return nil, bosherr.New("Expected at most %d partitions, got %d", maxEphemeralDiskPartitions, len(partitions))
+	}
+
+	return partitions, nil
+}
+
+// swapSizeInMb always enables swap by default to keep layout of already partitioned disks.
+// Swap sized to RAM keeps size disk was set up with so that RAM changes do not change layout.
+func (o EphemeralDiskOptions) swapSizeInMb(diskSizeInMb, totalMemInMb, previousSwapSizeInMb uint64) (uint64, bool, error) {
+	switch o.SwapSize {
+	case "":
+		if previousSwapSizeInMb > 0 && previousSwapSizeInMb <= diskSizeInMb/2 {
+			return previousSwapSizeInMb, true, nil
+		}
+
+		if totalMemInMb > diskSizeInMb/2 {
+			return diskSizeInMb / 2, true, nil
+		}
+		return totalMemInMb, true, nil
+
+	case EphemeralSwapSizeNone:
+		return 0, false, nil
+	}
+
+	size, err := parseEphemeralPartitionSize(o.SwapSize, diskSizeInMb)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return size, size > 0, nil
+}
+
+// parseEphemeralPartitionSize accepts size in MB or percent of the disk
+func parseEphemeralPartitionSize(size string, diskSizeInMb uint64) (uint64, error) {
+	if strings.HasSuffix(size, "%") {
+		percent, err := strconv.ParseUint(strings.TrimSuffix(size, "%"), 10, 64)
+		if err != nil {
+			return 0, bosherr.WrapError(err, "Parsing percent '%s'", size)
+		}
+
+		if percent > 100 {
+			return 0, bosherr.New("Expected percent to be at most 100, got '%s'", size)
+		}
+
+		return diskSizeInMb * percent / 100, nil
+	}
+
+	sizeInMb, err := strconv.ParseUint(size, 10, 64)
+	if err != nil {
+		return 0, bosherr.WrapError(err, "Parsing size '%s'", size)
+	}
+
+	return sizeInMb, nil
+}
@@ -4,8 +4,10 @@ import (
 	boshdpresolv "bosh/infrastructure/devicepathresolver"
 	fakedpresolv "bosh/infrastructure/devicepathresolver/fakes"
 	boshlog "bosh/logger"
+	boshplatform "bosh/platform"
 	boshcmd "bosh/platform/commands"
 	fakecmd "bosh/platform/commands/fakes"
+	boshdiskcopier "bosh/platform/diskcopier"
 	boshvitals "bosh/platform/vitals"
 	fakevitals "bosh/platform/vitals/fakes"
 	boshsettings "bosh/settings"
@@ -59,16 +61,24 @@ type FakePlatform struct {
 	SetupDhcpErr      error
 
 	MountPersistentDiskCalled     bool
+	MountPersistentDiskVolumeID   string
 	MountPersistentDiskDevicePath string
 	MountPersistentDiskMountPoint string
+	MountPersistentDiskResult     boshplatform.PersistentDiskMountResult
 	MountPersistentDiskErr        error
 
 	UnmountPersistentDiskDidUnmount bool
+	UnmountPersistentDiskVolumeID   string
 	UnmountPersistentDiskDevicePath string
 
 	GetFileContentsFromCDROMPath     string
 	GetFileContentsFromCDROMContents []byte
 
+	GetFilesContentsFromDiskDiskPaths []string
+	GetFilesContentsFromDiskFileNames [][]string
+	GetFilesContentsFromDiskContents  [][]byte
+	GetFilesContentsFromDiskErr       error
+
 	NormalizeDiskPathCalled   bool
 	NormalizeDiskPathPath     string
 	NormalizeDiskPathFound    bool
@@ -78,6 +88,13 @@ type FakePlatform struct {
 
 	MigratePersistentDiskFromMountPoint string
 	MigratePersistentDiskToMountPoint   string
+	MigratePersistentDiskProgress       *boshdiskcopier.Progress
+	MigratePersistentDiskCancelCh       <-chan struct{}
+	MigratePersistentDiskErr            error
+
+	// MigratePersistentDiskStarted is closed when migration starts;
+	// migration then blocks until it is cancelled
+	MigratePersistentDiskStarted chan struct{}
 
 	IsMountPointPath   string
 	IsMountPointResult bool
@@ -85,6 +102,27 @@ type FakePlatform struct {
 
 	MountedDevicePaths []string
 
+	CheckPersistentDiskSnapshotVolumeID   string
+	CheckPersistentDiskSnapshotDevicePath string
+	CheckPersistentDiskSnapshotName       string
+	CheckPersistentDiskSnapshotErr        error
+
+	SnapshotPersistentDiskVolumeID   string
+	SnapshotPersistentDiskDevicePath string
+	SnapshotPersistentDiskName       string
+	SnapshotPersistentDiskSnapshot   boshplatform.PersistentDiskSnapshot
+	SnapshotPersistentDiskErr        error
+
+	ListPersistentDiskSnapshotsVolumeID   string
+	ListPersistentDiskSnapshotsDevicePath string
+	ListPersistentDiskSnapshotsSnapshots  []boshplatform.PersistentDiskSnapshot
+	ListPersistentDiskSnapshotsErr        error
+
+	DeletePersistentDiskSnapshotVolumeID   string
+	DeletePersistentDiskSnapshotDevicePath string
+	DeletePersistentDiskSnapshotName       string
+	DeletePersistentDiskSnapshotErr        error
+
 	StartMonitStarted           bool
 	SetupMonitUserSetup         bool
 	GetMonitCredentialsUsername string
@@ -96,6 +134,20 @@ type FakePlatform struct {
 	GetDefaultNetworkCalled  bool
 	GetDefaultNetworkNetwork boshsettings.Network
 	GetDefaultNetworkErr     error
+
+	StartDNSCacheCalled   bool
+	StartDNSCacheNetworks boshsettings.Networks
+	StartDNSCacheErr      error
+
+	VerifyNetworkingNetworks  boshsettings.Networks
+	VerifyNetworkingEndpoints []string
+	VerifyNetworkingErr       error
+
+	HasUnverifiedNetworkingResult bool
+	HasUnverifiedNetworkingErr    error
+
+	GetNetworkingFailureFailure string
+	GetNetworkingFailureErr     error
 }
 
 func NewFakePlatform() (platform *FakePlatform) {
@@ -220,14 +272,16 @@ func (p *FakePlatform) SetupTmpDir() error {
 	return p.SetupTmpDirErr
 }
 
-func (p *FakePlatform) MountPersistentDisk(devicePath, mountPoint string) (err error) {
+func (p *FakePlatform) MountPersistentDisk(volumeID, devicePath, mountPoint string) (boshplatform.PersistentDiskMountResult, error) {
 	p.MountPersistentDiskCalled = true
+	p.MountPersistentDiskVolumeID = volumeID
 	p.MountPersistentDiskDevicePath = devicePath
 	p.MountPersistentDiskMountPoint = mountPoint
-	return p.MountPersistentDiskErr
+	return p.MountPersistentDiskResult, p.MountPersistentDiskErr
 }
 
-func (p *FakePlatform) UnmountPersistentDisk(devicePath string) (didUnmount bool, err error) {
+func (p *FakePlatform) UnmountPersistentDisk(volumeID, devicePath string) (didUnmount bool, err error) {
+	p.UnmountPersistentDiskVolumeID = volumeID
 	p.UnmountPersistentDiskDevicePath = devicePath
 	didUnmount = p.UnmountPersistentDiskDidUnmount
 	return
@@ -247,10 +301,51 @@ func (p *FakePlatform) GetFileContentsFromCDROM(path string) (contents []byte, e
 	return
 }
 
-func (p *FakePlatform) MigratePersistentDisk(fromMountPoint, toMountPoint string) (err error) {
+func (p *FakePlatform) GetFilesContentsFromDisk(diskPath string, fileNames []string) ([][]byte, error) {
+	p.GetFilesContentsFromDiskDiskPaths = append(p.GetFilesContentsFromDiskDiskPaths, diskPath)
+	p.GetFilesContentsFromDiskFileNames = append(p.GetFilesContentsFromDiskFileNames, fileNames)
+	return p.GetFilesContentsFromDiskContents, p.GetFilesContentsFromDiskErr
+}
+
+func (p *FakePlatform) MigratePersistentDisk(fromMountPoint, toMountPoint string, progress *boshdiskcopier.Progress, cancelCh <-chan struct{}) error {
 	p.MigratePersistentDiskFromMountPoint = fromMountPoint
 	p.MigratePersistentDiskToMountPoint = toMountPoint
-	return
+	p.MigratePersistentDiskProgress = progress
+	p.MigratePersistentDiskCancelCh = cancelCh
+
+	if p.MigratePersistentDiskStarted != nil {
+		close(p.MigratePersistentDiskStarted)
+		<-cancelCh
+	}
+
+	return p.MigratePersistentDiskErr
+}
+
+func (p *FakePlatform) CheckPersistentDiskSnapshot(volumeID, devicePath, name string) error {
+	p.CheckPersistentDiskSnapshotVolumeID = volumeID
+	p.CheckPersistentDiskSnapshotDevicePath = devicePath
+	p.CheckPersistentDiskSnapshotName = name
+	return p.CheckPersistentDiskSnapshotErr
+}
+
+func (p *FakePlatform) SnapshotPersistentDisk(volumeID, devicePath, name string) (boshplatform.PersistentDiskSnapshot, error) {
+	p.SnapshotPersistentDiskVolumeID = volumeID
+	p.SnapshotPersistentDiskDevicePath = devicePath
+	p.SnapshotPersistentDiskName = name
+	return p.SnapshotPersistentDiskSnapshot, p.SnapshotPersistentDiskErr
+}
+
+func (p *FakePlatform) ListPersistentDiskSnapshots(volumeID, devicePath string) ([]boshplatform.PersistentDiskSnapshot, error) {
+	p.ListPersistentDiskSnapshotsVolumeID = volumeID
+	p.ListPersistentDiskSnapshotsDevicePath = devicePath
+	return p.ListPersistentDiskSnapshotsSnapshots, p.ListPersistentDiskSnapshotsErr
+}
+
+func (p *FakePlatform) DeletePersistentDiskSnapshot(volumeID, devicePath, name string) error {
+	p.DeletePersistentDiskSnapshotVolumeID = volumeID
+	p.DeletePersistentDiskSnapshotDevicePath = devicePath
+	p.DeletePersistentDiskSnapshotName = name
+	return p.DeletePersistentDiskSnapshotErr
 }
 
 func (p *FakePlatform) IsMountPoint(path string) (bool, error) {
@@ -258,9 +353,9 @@ func (p *FakePlatform) IsMountPoint(path string) (bool, error) {
 	return p.IsMountPointResult, p.IsMountPointErr
 }
 
-func (p *FakePlatform) IsPersistentDiskMounted(path string) (result bool, err error) {
+func (p *FakePlatform) IsPersistentDiskMounted(volumeID, devicePath string) (result bool, err error) {
 	for _, mountedPath := range p.MountedDevicePaths {
-		if mountedPath == path {
+		if mountedPath == devicePath {
 			return true, nil
 		}
 	}
@@ -292,3 +387,23 @@ func (p *FakePlatform) GetDefaultNetwork() (boshsettings.Network, error) {
 	p.GetDefaultNetworkCalled = true
 	return p.GetDefaultNetworkNetwork, p.GetDefaultNetworkErr
 }
+
+func (p *FakePlatform) StartDNSCache(networks boshsettings.Networks) error {
+	p.StartDNSCacheCalled = true
+	p.StartDNSCacheNetworks = networks
+	return p.StartDNSCacheErr
+}
+
+func (p *FakePlatform) VerifyNetworking(networks boshsettings.Networks, endpoints []string) error {
+	p.VerifyNetworkingNetworks = networks
+	p.VerifyNetworkingEndpoints = endpoints
+	return p.VerifyNetworkingErr
+}
+
+func (p *FakePlatform) HasUnverifiedNetworking() (bool, error) {
+	return p.HasUnverifiedNetworkingResult, p.HasUnverifiedNetworkingErr
+}
+
+func (p *FakePlatform) GetNetworkingFailure() (string, error) {
+	return p.GetNetworkingFailureFailure, p.GetNetworkingFailureErr
+}
@@ -2,9 +2,11 @@ package platform
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strings"
 	"text/template"
@@ -16,7 +18,10 @@ import (
 	boshcd "bosh/platform/cdutil"
 	boshcmd "bosh/platform/commands"
 	boshdisk "bosh/platform/disk"
+	boshdiskcopier "bosh/platform/diskcopier"
+	boshdu "bosh/platform/diskutil"
 	boshnet "bosh/platform/net"
+	boshdns "bosh/platform/net/dns"
 	boshstats "bosh/platform/stats"
 	boshvitals "bosh/platform/vitals"
 	boshsettings "bosh/settings"
@@ -34,8 +39,32 @@ type LinuxOptions struct {
 	// otherwise agent will partition and format it right before mounting
 	UsePreformattedPersistentDisk bool
 
-	// When set to true persistent disk will be mounted as a bind-mount
+	// When set to true persistent disk will be mounted as a bind-mount;
+	// disk paths are expected to be directories so they are never partitioned
 	BindMountPersistentDisk bool
+
+	// When set to true agent runs caching DNS resolver on 127.0.0.1
+	// and points resolv.conf at it so that job processes use it;
+	// dhclient and systemd-resolved are pointed at it as well
+	UseDNSCache bool
+
+	// File system used when formatting persistent disk: ext4 (default) or xfs;
+	// disks that are already formatted with either of them keep their file system
+	PersistentDiskFileSystem boshdisk.FileSystemType
+
+	// When set to true persistent disk file system is checked before it is mounted
+	CheckPersistentDiskOnMount bool
+
+	// When set to true persistent disk partition becomes LVM physical volume
+	// with logical volume that leaves room for snapshots;
+	// partitions that already have a file system are used as is
+	UseLVMForPersistentDisk bool
+
+	// Layout of ephemeral disk; by default disk is split into swap and ext4 data partitions
+	EphemeralDisk EphemeralDiskOptions
+
+	// When set to true stats are read directly from /proc instead of through sigar
+	UseProcStatsCollector bool
 }
 
 type linux struct {
@@ -44,11 +73,14 @@ type linux struct {
 	collector          boshstats.StatsCollector
 	compressor         boshcmd.Compressor
 	copier             boshcmd.Copier
+	diskCopier         boshdiskcopier.Copier
 	dirProvider        boshdirs.DirectoriesProvider
 	vitalsService      boshvitals.Service
 	cdutil             boshcd.CdUtil
 	diskManager        boshdisk.Manager
 	netManager         boshnet.NetManager
+	netVerifier        boshnet.NetworkVerifier
+	dnsManager         boshdns.Manager
 	diskScanDuration   time.Duration
 	devicePathResolver boshdpresolv.DevicePathResolver
 	options            LinuxOptions
@@ -61,11 +93,14 @@ func NewLinuxPlatform(
 	collector boshstats.StatsCollector,
 	compressor boshcmd.Compressor,
 	copier boshcmd.Copier,
+	diskCopier boshdiskcopier.Copier,
 	dirProvider boshdirs.DirectoriesProvider,
 	vitalsService boshvitals.Service,
 	cdutil boshcd.CdUtil,
 	diskManager boshdisk.Manager,
 	netManager boshnet.NetManager,
+	netVerifier boshnet.NetworkVerifier,
+	dnsManager boshdns.Manager,
 	diskScanDuration time.Duration,
 	options LinuxOptions,
 	logger boshlog.Logger,
@@ -76,11 +111,14 @@ func NewLinuxPlatform(
 		collector:        collector,
 		compressor:       compressor,
 		copier:           copier,
+		diskCopier:       diskCopier,
 		dirProvider:      dirProvider,
 		vitalsService:    vitalsService,
 		cdutil:           cdutil,
 		diskManager:      diskManager,
 		netManager:       netManager,
+		netVerifier:      netVerifier,
+		dnsManager:       dnsManager,
 		diskScanDuration: diskScanDuration,
 		options:          options,
 		logger:           logger,
@@ -116,6 +154,11 @@ func (p linux) GetFileContentsFromCDROM(fileName string) (contents []byte, err e
 	return p.cdutil.GetFileContents(fileName)
 }
 
+func (p linux) GetFilesContentsFromDisk(diskPath string, fileNames []string) (contents [][]byte, err error) {
+	diskUtil := boshdu.NewDiskUtil(diskPath, p.diskManager.GetMounter(), p.fs, p.logger)
+	return diskUtil.GetFilesContents(fileNames)
+}
+
 func (p linux) GetDevicePathResolver() (devicePathResolver boshdpresolv.DevicePathResolver) {
 	return p.devicePathResolver
 }
@@ -354,14 +397,24 @@ func (p linux) SetupEphemeralDiskWithPath(realPath string) error {
 		return nil
 	}
 
-	swapSize, linuxSize, err := p.calculateEphemeralDiskPartitionSizes(realPath)
+	previousLayout, err := p.loadEphemeralDiskLayout()
+	if err != nil {
+		return err
+	}
+
+	layout, err := p.calculateEphemeralDiskLayout(realPath, previousLayout)
 	if err != nil {
 		return bosherr.WrapError(err, "Calculating partition sizes")
 	}
 
-	partitions := []boshdisk.Partition{
-		{SizeInMb: swapSize, Type: boshdisk.PartitionTypeSwap},
-		{SizeInMb: linuxSize, Type: boshdisk.PartitionTypeLinux},
+	err = p.checkEphemeralDiskLayout(realPath, previousLayout, layout)
+	if err != nil {
+		return err
+	}
+
+	var partitions []boshdisk.Partition
+	for _, partition := range layout {
+		partitions = append(partitions, partition.Partition)
 	}
 
 	err = p.diskManager.GetPartitioner().Partition(realPath, partitions)
@@ -369,28 +422,53 @@ func (p linux) SetupEphemeralDiskWithPath(realPath string) error {
 		return bosherr.WrapError(err, "Partitioning disk")
 	}
 
-	swapPartitionPath := realPath + "1"
-	dataPartitionPath := realPath + "2"
-	err = p.diskManager.GetFormatter().Format(swapPartitionPath, boshdisk.FileSystemSwap)
-	if err != nil {
-		return bosherr.WrapError(err, "Formatting swap")
+	for i, partition := range layout {
+		partitionPath := fmt.Sprintf("%s%d", realPath, i+1)
+
+		err = p.diskManager.GetFormatter().Format(partitionPath, partition.fsType)
+		if err != nil {
+			return bosherr.WrapError(err, "Formatting partition %s with %s", partitionPath, partition.fsType)
+		}
 	}
 
-	err = p.diskManager.GetFormatter().Format(dataPartitionPath, boshdisk.FileSystemExt4)
+	err = p.saveEphemeralDiskLayout(layout)
 	if err != nil {
-		return bosherr.WrapError(err, "Formatting data partition with ext4")
+		return err
 	}
 
-	err = p.diskManager.GetMounter().SwapOn(swapPartitionPath)
-	if err != nil {
-		return bosherr.WrapError(err, "Mounting swap")
+	for i, partition := range layout {
+		if partition.fsType == boshdisk.FileSystemSwap {
+			err = p.diskManager.GetMounter().SwapOn(fmt.Sprintf("%s%d", realPath, i+1))
+			if err != nil {
+				return bosherr.WrapError(err, "Mounting swap")
+			}
+		}
 	}
 
-	err = p.diskManager.GetMounter().Mount(dataPartitionPath, mountPoint)
+	// Data partition is mounted first since extra partitions are mounted inside of it
+	dataPartitionIndex := len(layout) - 1
+
+	err = p.diskManager.GetMounter().Mount(fmt.Sprintf("%s%d", realPath, dataPartitionIndex+1), mountPoint)
 	if err != nil {
 		return bosherr.WrapError(err, "Mounting data partition")
 	}
 
+	for i, partition := range layout[:dataPartitionIndex] {
+		if partition.fsType == boshdisk.FileSystemSwap {
+			continue
+		}
+
+		err = p.fs.MkdirAll(partition.mountPoint, os.FileMode(0750))
+		if err != nil {
+			return bosherr.WrapError(err, "Creating %s dir", partition.mountPoint)
+		}
+
+		err = p.diskManager.GetMounter().Mount(fmt.Sprintf("%s%d", realPath, i+1), partition.mountPoint)
+		if err != nil {
+			return bosherr.WrapError(err, "Mounting partition at %s", partition.mountPoint)
+		}
+	}
+
 	return nil
 }
 
@@ -511,60 +589,440 @@ func (p linux) changeTmpDirPermissions(path string) error {
 	return nil
 }
 
-func (p linux) MountPersistentDisk(devicePath, mountPoint string) error {
-	p.logger.Debug("platform", "Mounting persistent disk %s at %s", devicePath, mountPoint)
+const (
+	persistentDiskVolumeName = "store"
+
+	// Rest of volume group is left for snapshots
+	persistentDiskVolumePercentOfGroup    = 80
+	persistentDiskSnapshotPercentOfOrigin = 10
+)
+
+// Same characters lvcreate allows in volume names; names are also used as mount points
+var persistentDiskSnapshotNameRegexp = regexp.MustCompile(`\A[a-zA-Z0-9_+][a-zA-Z0-9_.+-]*\z`)
+
+func (p linux) MountPersistentDisk(volumeID, devicePath, mountPoint string) (PersistentDiskMountResult, error) {
+	var result PersistentDiskMountResult
+
+	p.logger.Debug("platform", "Mounting persistent disk %s (%s) at %s", volumeID, devicePath, mountPoint)
 
 	err := p.fs.MkdirAll(mountPoint, os.FileMode(0700))
 	if err != nil {
-		return bosherr.WrapError(err, "Creating directory %s", mountPoint)
+		return result, bosherr.WrapError(err, "Creating directory %s", mountPoint)
 	}
 
-	realPath, err := p.devicePathResolver.GetRealDevicePath(devicePath)
+	realPath, err := p.resolvePersistentDiskPath(volumeID, devicePath)
+	if err != nil {
+		return result, bosherr.WrapError(err, "Getting real device path")
+	}
+
+	if !p.partitionsPersistentDisk() {
+		err = p.diskManager.GetMounter().Mount(realPath, mountPoint)
+		if err != nil {
+			return result, bosherr.WrapError(err, "Mounting partition")
+		}
+
+		return result, nil
+	}
+
+	// Growing partition before matching partitions keeps disk from being re-partitioned
+	_, err = p.diskManager.GetPartitioner().GrowPartition(realPath, 1)
 	if err != nil {
-		return bosherr.WrapError(err, "Getting real device path")
+		return result, bosherr.WrapError(err, "Growing partition")
 	}
 
-	if !p.options.UsePreformattedPersistentDisk {
-		partitions := []boshdisk.Partition{
-			{Type: boshdisk.PartitionTypeLinux},
+	partitions := []boshdisk.Partition{
+		{Type: boshdisk.PartitionTypeLinux},
+	}
+
+	err = p.diskManager.GetPartitioner().Partition(realPath, partitions)
+	if err != nil {
+		return result, bosherr.WrapError(err, "Partitioning disk")
+	}
+
+	partitionPath := realPath + "1"
+
+	if p.options.UseLVMForPersistentDisk {
+		// Logical volume is formatted and mounted instead of the partition
+		partitionPath, err = p.setupPersistentDiskVolume(partitionPath)
+		if err != nil {
+			return result, err
 		}
+	}
+
+	fsType, formatted, err := p.formatPersistentDiskPartition(partitionPath)
+	if err != nil {
+		return result, err
+	}
+
+	result.FileSystem = fsType
 
-		err = p.diskManager.GetPartitioner().Partition(realPath, partitions)
+	if p.options.CheckPersistentDiskOnMount && !formatted {
+		checkResult, err := p.diskManager.GetChecker().Check(partitionPath, fsType)
+		result.Check = &checkResult
 		if err != nil {
-			return bosherr.WrapError(err, "Partitioning disk")
+			return result, bosherr.WrapError(err, "Checking file system")
 		}
+	}
 
-		partitionPath := realPath + "1"
+	err = p.diskManager.GetMounter().Mount(partitionPath, mountPoint)
+	if err != nil {
+		return result, bosherr.WrapError(err, "Mounting partition")
+	}
 
-		err = p.diskManager.GetFormatter().Format(partitionPath, boshdisk.FileSystemExt4)
+	if !formatted {
+		// Sizes are compared instead of relying on partition being grown during this mount
+		// so that file system left behind by an interrupted resize is grown as well
+		resizer := p.diskManager.GetResizer()
+
+		needsToGrow, err := resizer.NeedsToGrowFileSystem(partitionPath, mountPoint, fsType)
 		if err != nil {
-			return bosherr.WrapError(err, "Formatting partition with ext4")
+			return result, bosherr.WrapError(err, "Comparing file system and partition sizes")
+		}
+
+		if needsToGrow {
+			err = resizer.GrowFileSystem(partitionPath, mountPoint, fsType)
+			if err != nil {
+				return result, bosherr.WrapError(err, "Growing file system")
+			}
+
+			result.Resized = true
 		}
+	}
+
+	return result, nil
+}
 
-		realPath = partitionPath
+// formatPersistentDiskPartition keeps supported file system already present on the partition
+// so that changing configured file system does not wipe existing persistent data
+func (p linux) formatPersistentDiskPartition(partitionPath string) (boshdisk.FileSystemType, bool, error) {
+	fsType := p.options.PersistentDiskFileSystem
+	if len(fsType) == 0 {
+		fsType = boshdisk.FileSystemExt4
 	}
 
-	err = p.diskManager.GetMounter().Mount(realPath, mountPoint)
+	if fsType != boshdisk.FileSystemExt4 && fsType != boshdisk.FileSystemXFS {
+		return "", false, bosherr.New("Unsupported persistent disk file system %s", fsType)
+	}
+
+	existingFsType := p.diskManager.GetFormatter().DetectFileSystemType(partitionPath)
+
+	switch existingFsType {
+	case fsType:
+		return fsType, false, nil
+
+	case boshdisk.FileSystemExt4, boshdisk.FileSystemXFS:
+		p.logger.Info("platform", "Keeping %s file system on %s instead of configured %s", existingFsType, partitionPath, fsType)
+		return existingFsType, false, nil
+
+	case "":
+		// Only partitions without any detected contents are formatted
+
+	default:
+		// e.g. LVM2_member when LVM was used before it was turned off
+		return "", false, bosherr.New("Refusing to format %s since it already contains %s", partitionPath, existingFsType)
+	}
+
+	err := p.diskManager.GetFormatter().Format(partitionPath, fsType)
 	if err != nil {
-		return bosherr.WrapError(err, "Mounting partition")
+		return "", false, bosherr.WrapError(err, "Formatting partition with %s", fsType)
 	}
 
-	return nil
+	return fsType, true, nil
 }
 
-func (p linux) UnmountPersistentDisk(devicePath string) (bool, error) {
-	p.logger.Debug("platform", "Unmounting persistent disk %s", devicePath)
+// setupPersistentDiskVolume returns path of logical volume on persistent disk partition;
+// existing volume is always extended since its partition might have been grown earlier
+func (p linux) setupPersistentDiskVolume(partitionPath string) (string, error) {
+	volumeManager := p.diskManager.GetVolumeManager()
 
-	realPath, err := p.devicePathResolver.GetRealDevicePath(devicePath)
+	volume, found, err := p.findPersistentDiskVolume(partitionPath)
+	if err != nil {
+		return "", err
+	}
+
+	if !found {
+		// Turning partition into physical volume would destroy data that is already on it
+		existingFsType := p.diskManager.GetFormatter().DetectFileSystemType(partitionPath)
+		if existingFsType == boshdisk.FileSystemExt4 || existingFsType == boshdisk.FileSystemXFS {
+			p.logger.Info("platform", "Keeping %s file system on %s instead of creating logical volume", existingFsType, partitionPath)
+			return partitionPath, nil
+		}
+
+		volume, err = volumeManager.CreateVolume(partitionPath, persistentDiskVolumeName, persistentDiskVolumePercentOfGroup)
+		if err != nil {
+			return "", bosherr.WrapError(err, "Creating logical volume")
+		}
+
+		return volume.Path, nil
+	}
+
+	err = volumeManager.ActivateVolumeGroup(volume.VolumeGroup)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Activating volume group")
+	}
+
+	err = volumeManager.GrowVolume(partitionPath, volume, persistentDiskVolumePercentOfGroup)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Growing logical volume")
+	}
+
+	return volume.Path, nil
+}
+
+func (p linux) findPersistentDiskVolume(partitionPath string) (boshdisk.LogicalVolume, bool, error) {
+	if !p.options.UseLVMForPersistentDisk {
+		return boshdisk.LogicalVolume{}, false, nil
+	}
+
+	volume, found, err := p.diskManager.GetVolumeManager().FindVolume(partitionPath, persistentDiskVolumeName)
+	if err != nil {
+		return volume, false, bosherr.WrapError(err, "Finding logical volume")
+	}
+
+	return volume, found, nil
+}
+
+func (p linux) UnmountPersistentDisk(volumeID, devicePath string) (bool, error) {
+	p.logger.Debug("platform", "Unmounting persistent disk %s (%s)", volumeID, devicePath)
+
+	realPath, err := p.resolvePersistentDiskPath(volumeID, devicePath)
 	if err != nil {
 		return false, bosherr.WrapError(err, "Getting real device path")
 	}
 
-	if !p.options.UsePreformattedPersistentDisk {
-		realPath += "1"
+	if !p.partitionsPersistentDisk() {
+		return p.diskManager.GetMounter().Unmount(realPath)
+	}
+
+	partitionPath := realPath + "1"
+
+	volume, found, err := p.findPersistentDiskVolume(partitionPath)
+	if err != nil {
+		return false, err
+	}
+
+	if !found {
+		return p.diskManager.GetMounter().Unmount(partitionPath)
+	}
+
+	return p.unmountPersistentDiskVolume(volume)
+}
+
+// unmountPersistentDiskVolume deactivates volume group so that disk can be detached
+func (p linux) unmountPersistentDiskVolume(volume boshdisk.LogicalVolume) (bool, error) {
+	volumeManager := p.diskManager.GetVolumeManager()
+	mounter := p.diskManager.GetMounter()
+
+	snapshots, err := volumeManager.ListSnapshots(volume)
+	if err != nil {
+		return false, bosherr.WrapError(err, "Listing snapshots")
+	}
+
+	for _, snapshot := range snapshots {
+		_, err = mounter.Unmount(snapshot.Path)
+		if err != nil {
+			return false, bosherr.WrapError(err, "Unmounting snapshot %s", snapshot.Name)
+		}
+	}
+
+	didUnmount, err := mounter.Unmount(volume.Path)
+	if err != nil {
+		return false, bosherr.WrapError(err, "Unmounting logical volume")
+	}
+
+	err = volumeManager.DeactivateVolumeGroup(volume.VolumeGroup)
+	if err != nil {
+		return false, bosherr.WrapError(err, "Deactivating volume group")
+	}
+
+	return didUnmount, nil
+}
+
+func (p linux) CheckPersistentDiskSnapshot(volumeID, devicePath, name string) error {
+	_, err := p.checkPersistentDiskSnapshot(volumeID, devicePath, name)
+	return err
+}
+
+func (p linux) SnapshotPersistentDisk(volumeID, devicePath, name string) (PersistentDiskSnapshot, error) {
+	origin, err := p.checkPersistentDiskSnapshot(volumeID, devicePath, name)
+	if err != nil {
+		return PersistentDiskSnapshot{}, err
+	}
+
+	volumeManager := p.diskManager.GetVolumeManager()
+
+	snapshot, err := volumeManager.CreateSnapshot(origin, name, persistentDiskSnapshotPercentOfOrigin)
+	if err != nil {
+		return PersistentDiskSnapshot{}, bosherr.WrapError(err, "Creating snapshot")
+	}
+
+	mountPoint := filepath.Join(p.dirProvider.StoreSnapshotsDir(), name)
+
+	err = p.fs.MkdirAll(mountPoint, os.FileMode(0700))
+	if err != nil {
+		return PersistentDiskSnapshot{}, bosherr.WrapError(err, "Creating directory %s", mountPoint)
+	}
+
+	mountOptions := "ro"
+
+	// Snapshot of xfs file system has the same uuid as its origin which xfs refuses to mount twice
+	if p.diskManager.GetFormatter().DetectFileSystemType(snapshot.Path) == boshdisk.FileSystemXFS {
+		mountOptions += ",nouuid"
+	}
+
+	err = p.diskManager.GetMounter().Mount(snapshot.Path, mountPoint, "-o", mountOptions)
+	if err != nil {
+		return PersistentDiskSnapshot{}, bosherr.WrapError(err, "Mounting snapshot")
+	}
+
+	// Size of the snapshot is only known once it is created
+	snapshots, err := volumeManager.ListSnapshots(origin)
+	if err != nil {
+		return PersistentDiskSnapshot{}, bosherr.WrapError(err, "Listing snapshots")
+	}
+
+	for _, s := range snapshots {
+		if s.Name == name {
+			snapshot = s
+		}
+	}
+
+	return PersistentDiskSnapshot{
+		Name:         snapshot.Name,
+		SizeInBytes:  snapshot.SizeInBytes,
+		UsagePercent: snapshot.SnapshotUsagePercent,
+		MountPoint:   mountPoint,
+	}, nil
+}
+
+func (p linux) ListPersistentDiskSnapshots(volumeID, devicePath string) ([]PersistentDiskSnapshot, error) {
+	origin, err := p.findPersistentDiskOrigin(volumeID, devicePath)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := p.diskManager.GetVolumeManager().ListSnapshots(origin)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Listing snapshots")
+	}
+
+	results := []PersistentDiskSnapshot{}
+
+	for _, snapshot := range snapshots {
+		result := PersistentDiskSnapshot{
+			Name:         snapshot.Name,
+			SizeInBytes:  snapshot.SizeInBytes,
+			UsagePercent: snapshot.SnapshotUsagePercent,
+		}
+
+		mounted, err := p.diskManager.GetMounter().IsMounted(snapshot.Path)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Checking whether snapshot %s is mounted", snapshot.Name)
+		}
+
+		if mounted {
+			result.MountPoint = filepath.Join(p.dirProvider.StoreSnapshotsDir(), snapshot.Name)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (p linux) DeletePersistentDiskSnapshot(volumeID, devicePath, name string) error {
+	origin, err := p.findPersistentDiskOrigin(volumeID, devicePath)
+	if err != nil {
+		return err
+	}
+
+	volumeManager := p.diskManager.GetVolumeManager()
+
+	snapshots, err := volumeManager.ListSnapshots(origin)
+	if err != nil {
+		return bosherr.WrapError(err, "Listing snapshots")
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot.Name != name {
+			continue
+		}
+
+		didUnmount, err := p.diskManager.GetMounter().Unmount(snapshot.Path)
+		if err != nil {
+			return bosherr.WrapError(err, "Unmounting snapshot")
+		}
+
+		if didUnmount {
+			err = p.fs.RemoveAll(filepath.Join(p.dirProvider.StoreSnapshotsDir(), name))
+			if err != nil {
+				return bosherr.WrapError(err, "Removing snapshot mount point")
+			}
+		}
+
+		err = volumeManager.DeleteSnapshot(origin, name)
+		if err != nil {
+			return bosherr.WrapError(err, "Deleting snapshot")
+		}
+
+		return nil
+	}
+
+	return bosherr.New("Snapshot %s not found", name)
+}
+
+// checkPersistentDiskSnapshot validates snapshot name and returns logical volume to snapshot
+func (p linux) checkPersistentDiskSnapshot(volumeID, devicePath, name string) (boshdisk.LogicalVolume, error) {
+	if !persistentDiskSnapshotNameRegexp.MatchString(name) {
+		return boshdisk.LogicalVolume{}, bosherr.New("Invalid snapshot name '%s'", name)
+	}
+
+	return p.findPersistentDiskOrigin(volumeID, devicePath)
+}
+
+// findPersistentDiskOrigin returns logical volume that snapshots are taken from
+func (p linux) findPersistentDiskOrigin(volumeID, devicePath string) (boshdisk.LogicalVolume, error) {
+	if !p.options.UseLVMForPersistentDisk || !p.partitionsPersistentDisk() {
+		return boshdisk.LogicalVolume{}, bosherr.New("Persistent disk snapshots require LVM")
+	}
+
+	realPath, err := p.resolvePersistentDiskPath(volumeID, devicePath)
+	if err != nil {
+		return boshdisk.LogicalVolume{}, bosherr.WrapError(err, "Getting real device path")
 	}
 
-	return p.diskManager.GetMounter().Unmount(realPath)
+	volume, found, err := p.findPersistentDiskVolume(realPath + "1")
+	if err != nil {
+		return volume, err
+	}
+
+	if !found {
+		return volume, bosherr.New("Persistent disk %s is not an LVM logical volume", devicePath)
+	}
+
+	return volume, nil
+}
+
+// resolvePersistentDiskPath prefers finding disk by volume ID since device path
+// in settings is only a guess made by the infrastructure (e.g. /dev/sdf for NVMe disk)
+func (p linux) resolvePersistentDiskPath(volumeID, devicePath string) (string, error) {
+	volumeIDResolver, ok := p.devicePathResolver.(boshdpresolv.VolumeIDDevicePathResolver)
+	if ok && len(volumeID) > 0 {
+		realPath, err := volumeIDResolver.GetRealDevicePathByVolumeID(volumeID)
+		if err == nil {
+			return realPath, nil
+		}
+
+		p.logger.Debug("platform", "Falling back to device path %s for volume %s: %s", devicePath, volumeID, err.Error())
+	}
+
+	return p.devicePathResolver.GetRealDevicePath(devicePath)
+}
+
+// partitionsPersistentDisk is false when persistent disk is a directory
+// bind-mounted from the host (warden) or is expected to be pre-formatted
+func (p linux) partitionsPersistentDisk() bool {
+	return !p.options.UsePreformattedPersistentDisk && !p.options.BindMountPersistentDisk
 }
 
 func (p linux) NormalizeDiskPath(devicePath string) (string, bool) {
@@ -580,48 +1038,69 @@ func (p linux) IsMountPoint(path string) (bool, error) {
 	return p.diskManager.GetMounter().IsMountPoint(path)
 }
 
-func (p linux) MigratePersistentDisk(fromMountPoint, toMountPoint string) (err error) {
+func (p linux) MigratePersistentDisk(fromMountPoint, toMountPoint string, progress *boshdiskcopier.Progress, cancelCh <-chan struct{}) (err error) {
 	p.logger.Debug("platform", "Migrating persistent disk %v to %v", fromMountPoint, toMountPoint)
 
 	err = p.diskManager.GetMounter().RemountAsReadonly(fromMountPoint)
 	if err != nil {
-		err = bosherr.WrapError(err, "Remounting persistent disk as readonly")
-		return
+		return bosherr.WrapError(err, "Remounting persistent disk as readonly")
 	}
 
-	// Golang does not implement a file copy that would allow us to preserve dates...
-	// So we have to shell out to tar to perform the copy instead of delegating to the FileSystem
-	tarCopy := fmt.Sprintf("(tar -C %s -cf - .) | (tar -C %s -xpf -)", fromMountPoint, toMountPoint)
-	_, _, _, err = p.cmdRunner.RunCommand("sh", "-c", tarCopy)
+	// Old disk stays in use when migration fails or is cancelled
+	oldDiskMounted := true
+	defer func() {
+		if err == nil || !oldDiskMounted {
+			return
+		}
+
+		remountErr := p.diskManager.GetMounter().RemountAsReadWrite(fromMountPoint)
+		if remountErr != nil {
+			p.logger.Error("platform", "Failed to remount persistent disk as read-write: %s", remountErr.Error())
+		}
+	}()
+
+	err = p.diskCopier.Copy(fromMountPoint, toMountPoint, progress, cancelCh)
 	if err != nil {
-		err = bosherr.WrapError(err, "Copying files from old disk to new disk")
-		return
+		return bosherr.WrapError(err, "Copying files from old disk to new disk")
 	}
 
 	_, err = p.diskManager.GetMounter().Unmount(fromMountPoint)
 	if err != nil {
-		err = bosherr.WrapError(err, "Unmounting old persistent disk")
-		return
+		return bosherr.WrapError(err, "Unmounting old persistent disk")
 	}
 
+	oldDiskMounted = false
+
 	err = p.diskManager.GetMounter().Remount(toMountPoint, fromMountPoint)
 	if err != nil {
-		err = bosherr.WrapError(err, "Remounting new disk on original mountpoint")
+		return bosherr.WrapError(err, "Remounting new disk on original mountpoint")
 	}
-	return
+
+	return nil
 }
 
-func (p linux) IsPersistentDiskMounted(path string) (bool, error) {
-	realPath, err := p.devicePathResolver.GetRealDevicePath(path)
+func (p linux) IsPersistentDiskMounted(volumeID, devicePath string) (bool, error) {
+	realPath, err := p.resolvePersistentDiskPath(volumeID, devicePath)
 	if err != nil {
 		return false, bosherr.WrapError(err, "Getting real device path")
 	}
 
-	if !p.options.UsePreformattedPersistentDisk {
-		realPath += "1"
+	if !p.partitionsPersistentDisk() {
+		return p.diskManager.GetMounter().IsMounted(realPath)
+	}
+
+	partitionPath := realPath + "1"
+
+	volume, found, err := p.findPersistentDiskVolume(partitionPath)
+	if err != nil {
+		return false, err
 	}
 
-	return p.diskManager.GetMounter().IsMounted(realPath)
+	if found {
+		return p.diskManager.GetMounter().IsMounted(volume.Path)
+	}
+
+	return p.diskManager.GetMounter().IsMounted(partitionPath)
 }
 
 func (p linux) StartMonit() error {
@@ -670,6 +1149,12 @@ func (p linux) PrepareForNetworkingChange() error {
 		return bosherr.WrapError(err, "Removing network rules file")
 	}
 
+	// Failure from previous networking change should not be reported for upcoming change
+	err = p.fs.RemoveAll(p.networkingFailurePath())
+	if err != nil {
+		return bosherr.WrapError(err, "Removing networking failure file")
+	}
+
 	return nil
 }
 
@@ -677,27 +1162,160 @@ func (p linux) GetDefaultNetwork() (boshsettings.Network, error) {
 	return p.netManager.GetDefaultNetwork()
 }
 
-func (p linux) calculateEphemeralDiskPartitionSizes(devicePath string) (swapSize, linuxSize uint64, err error) {
+// StartDNSCache starts in-process DNS cache and points resolv.conf at it.
+// Cache does not survive agent restarts so it has to be started every time.
+func (p linux) StartDNSCache(networks boshsettings.Networks) error {
+	if !p.options.UseDNSCache {
+		return nil
+	}
+
+	config := boshdns.NewConfigFromNetworks(networks)
+	if len(config.Servers) == 0 {
+		p.logger.Debug("platform", "Not starting DNS cache since there are no DNS servers")
+		return nil
+	}
+
+	err := p.dnsManager.Configure(config)
+	if err != nil {
+		return bosherr.WrapError(err, "Configuring DNS")
+	}
+
+	return nil
+}
+
+func (p linux) VerifyNetworking(networks boshsettings.Networks, endpoints []string) error {
+	verifyErr := p.netVerifier.Verify(networks, endpoints)
+	if verifyErr == nil {
+		err := p.netManager.CommitManualNetworking()
+		if err != nil {
+			return bosherr.WrapError(err, "Committing networking")
+		}
+
+		err = p.fs.RemoveAll(p.networkingFailurePath())
+		if err != nil {
+			return bosherr.WrapError(err, "Removing networking failure file")
+		}
+
+		return nil
+	}
+
+	err := p.netManager.RollbackManualNetworking()
+	if err != nil {
+		p.logger.Error("platform", "Failed to roll back networking: %s", err.Error())
+	}
+
+	err = p.fs.WriteFileString(p.networkingFailurePath(), verifyErr.Error())
+	if err != nil {
+		p.logger.Error("platform", "Failed to record networking failure: %s", err.Error())
+	}
+
+	return bosherr.WrapError(verifyErr, "Verifying networking")
+}
+
+func (p linux) HasUnverifiedNetworking() (bool, error) {
+	return p.netManager.HasUncommittedManualNetworking()
+}
+
+func (p linux) GetNetworkingFailure() (string, error) {
+	failurePath := p.networkingFailurePath()
+
+	if !p.fs.FileExists(failurePath) {
+		return "", nil
+	}
+
+	failure, err := p.fs.ReadFileString(failurePath)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Reading networking failure file")
+	}
+
+	return failure, nil
+}
+
+func (p linux) networkingFailurePath() string {
+	return filepath.Join(p.dirProvider.BoshDir(), "networking_failure")
+}
+
+// checkEphemeralDiskLayout keeps ephemeral disk set up by the agent from being
+// re-partitioned when its configured layout changed unless it is explicitly allowed.
+// Disks without recorded layout (e.g. new or pre-formatted by infrastructure)
+// and disks no longer partitioned as recorded (e.g. blank after VM recreation) are partitioned.
+func (p linux) checkEphemeralDiskLayout(devicePath string, previousLayout ephemeralDiskLayoutRecord, layout []ephemeralPartition) error {
+	if len(previousLayout) == 0 {
+		return nil
+	}
+
+	if reflect.DeepEqual(previousLayout, newEphemeralDiskLayoutRecord(layout)) {
+		return nil
+	}
+
+	// There is no data to lose
+	if !p.diskManager.GetPartitioner().HasPartitions(devicePath, previousLayout.partitions()) {
+		p.logger.Info("platform", "Partitioning ephemeral disk since it is not partitioned as recorded %v", previousLayout)
+		return nil
+	}
+
+	if !p.options.EphemeralDisk.AllowRepartition {
+		return bosherr.New("Refusing to re-partition ephemeral disk since its layout changed from %v; set EphemeralDisk.AllowRepartition to allow losing its data", previousLayout)
+	}
+
+	p.logger.Info("platform", "Re-partitioning ephemeral disk since its layout changed from %v", previousLayout)
+
+	return nil
+}
+
+func (p linux) loadEphemeralDiskLayout() (ephemeralDiskLayoutRecord, error) {
+	layoutPath := p.ephemeralDiskLayoutPath()
+
+	if !p.fs.FileExists(layoutPath) {
+		return nil, nil
+	}
+
+	contents, err := p.fs.ReadFile(layoutPath)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Reading ephemeral disk layout")
+	}
+
+	var layout ephemeralDiskLayoutRecord
+
+	err = json.Unmarshal(contents, &layout)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Unmarshalling ephemeral disk layout")
+	}
+
+	return layout, nil
+}
+
+func (p linux) saveEphemeralDiskLayout(layout []ephemeralPartition) error {
+	contents, err := json.Marshal(newEphemeralDiskLayoutRecord(layout))
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling ephemeral disk layout")
+	}
+
+	err = p.fs.WriteFile(p.ephemeralDiskLayoutPath(), contents)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing ephemeral disk layout")
+	}
+
+	return nil
+}
+
+// Layout is kept on the root disk since it has to be read before ephemeral disk is mounted
+func (p linux) ephemeralDiskLayoutPath() string {
+	return filepath.Join(p.dirProvider.BoshDir(), "ephemeral_disk_layout.json")
+}
+
+func (p linux) calculateEphemeralDiskLayout(devicePath string, previousLayout ephemeralDiskLayoutRecord) ([]ephemeralPartition, error) {
 	memStats, err := p.collector.GetMemStats()
 	if err != nil {
-		err = bosherr.WrapError(err, "Getting mem stats")
-		return
+		return nil, bosherr.WrapError(err, "Getting mem stats")
 	}
 
 	totalMemInMb := memStats.Total / uint64(1024*1024)
 
 	diskSizeInMb, err := p.diskManager.GetPartitioner().GetDeviceSizeInMb(devicePath)
 	if err != nil {
-		err = bosherr.WrapError(err, "Getting device size")
-		return
+		return nil, bosherr.WrapError(err, "Getting device size")
 	}
 
-	if totalMemInMb > diskSizeInMb/2 {
-		swapSize = diskSizeInMb / 2
-	} else {
-		swapSize = totalMemInMb
-	}
-
-	linuxSize = diskSizeInMb - swapSize
-	return
+	return p.options.EphemeralDisk.layout(diskSizeInMb, totalMemInMb, p.dirProvider.DataDir(), previousLayout)
 }
@@ -16,6 +16,10 @@ import (
 	boshcmd "bosh/platform/commands"
 	boshdisk "bosh/platform/disk"
 	fakedisk "bosh/platform/disk/fakes"
+	boshdiskcopier "bosh/platform/diskcopier"
+	fakediskcopier "bosh/platform/diskcopier/fakes"
+	boshdns "bosh/platform/net/dns"
+	fakedns "bosh/platform/net/dns/fakes"
 	fakenet "bosh/platform/net/fakes"
 	fakestats "bosh/platform/stats/fakes"
 	boshvitals "bosh/platform/vitals"
@@ -36,8 +40,11 @@ var _ = Describe("LinuxPlatform", func() {
 		cdutil             *fakecd.FakeCdUtil
 		compressor         boshcmd.Compressor
 		copier             boshcmd.Copier
+		diskCopier         *fakediskcopier.FakeCopier
 		vitalsService      boshvitals.Service
 		netManager         *fakenet.FakeNetManager
+		netVerifier        *fakenet.FakeNetworkVerifier
+		dnsManager         *fakedns.FakeManager
 		options            LinuxOptions
 	)
 
@@ -52,8 +59,11 @@ var _ = Describe("LinuxPlatform", func() {
 		cdutil = fakecd.NewFakeCdUtil()
 		compressor = boshcmd.NewTarballCompressor(cmdRunner, fs)
 		copier = boshcmd.NewCpCopier(cmdRunner, fs, logger)
-		vitalsService = boshvitals.NewService(collector, dirProvider)
+		diskCopier = &fakediskcopier.FakeCopier{}
+		vitalsService = boshvitals.NewService(collector, dirProvider, nil)
 		netManager = &fakenet.FakeNetManager{}
+		netVerifier = &fakenet.FakeNetworkVerifier{}
+		dnsManager = &fakedns.FakeManager{}
 		devicePathResolver = fakedpresolv.NewFakeDevicePathResolver()
 		options = LinuxOptions{}
 
@@ -77,11 +87,14 @@ var _ = Describe("LinuxPlatform", func() {
 			collector,
 			compressor,
 			copier,
+			diskCopier,
 			dirProvider,
 			vitalsService,
 			cdutil,
 			diskManager,
 			netManager,
+			netVerifier,
+			dnsManager,
 			5*time.Millisecond,
 			options,
 			logger,
@@ -385,6 +398,312 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/*/*.log fake-base-
 					{SizeInMb: diskSizeInMb - expectedSwap, Type: boshdisk.PartitionTypeLinux},
 				}))
 			})
+
+			Context("when ephemeral disk layout is configured", func() {
+				BeforeEach(func() {
+					collector.MemStats.Total = 4096 * uint64(1024*1024)
+
+					diskManager.FakePartitioner.GetDeviceSizeInMbSizes = map[string]uint64{
+						"/dev/xvda": 10000,
+					}
+				})
+
+				Context("when swap size is fixed", func() {
+					BeforeEach(func() { options.EphemeralDisk.SwapSize = "1024" })
+
+					It("uses fixed swap size", func() {
+						err := act()
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(diskManager.FakePartitioner.PartitionPartitions).To(Equal([]boshdisk.Partition{
+							{SizeInMb: 1024, Type: boshdisk.PartitionTypeSwap},
+							{SizeInMb: 8976, Type: boshdisk.PartitionTypeLinux},
+						}))
+					})
+				})
+
+				Context("when swap size is percent of the disk", func() {
+					BeforeEach(func() { options.EphemeralDisk.SwapSize = "10%" })
+
+					It("uses swap size as percent of the disk", func() {
+						err := act()
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(diskManager.FakePartitioner.PartitionPartitions).To(Equal([]boshdisk.Partition{
+							{SizeInMb: 1000, Type: boshdisk.PartitionTypeSwap},
+							{SizeInMb: 9000, Type: boshdisk.PartitionTypeLinux},
+						}))
+					})
+				})
+
+				Context("when swap is disabled", func() {
+					BeforeEach(func() { options.EphemeralDisk.SwapSize = "none" })
+
+					It("uses whole disk for data partition", func() {
+						err := act()
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(diskManager.FakePartitioner.PartitionPartitions).To(Equal([]boshdisk.Partition{
+							{SizeInMb: 10000, Type: boshdisk.PartitionTypeLinux},
+						}))
+
+						Expect(diskManager.FakeFormatter.FormatPartitionPaths).To(Equal([]string{"/dev/xvda1"}))
+						Expect(diskManager.FakeMounter.SwapOnPartitionPaths).To(BeEmpty())
+						Expect(diskManager.FakeMounter.MountPartitionPaths).To(Equal([]string{"/dev/xvda1"}))
+						Expect(diskManager.FakeMounter.MountMountPoints).To(Equal([]string{"/fake-dir/data"}))
+					})
+				})
+
+				Context("when swap size cannot be parsed", func() {
+					BeforeEach(func() { options.EphemeralDisk.SwapSize = "fake-size" })
+
+					It("returns error", func() {
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("Parsing swap size"))
+					})
+				})
+
+				Context("when disk is too small for requested partitions", func() {
+					BeforeEach(func() { options.EphemeralDisk.SwapSize = "100%" })
+
+					It("returns error without partitioning disk", func() {
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("too small"))
+						Expect(diskManager.FakePartitioner.PartitionPartitions).To(BeEmpty())
+					})
+				})
+
+				Context("when file system is xfs", func() {
+					BeforeEach(func() { options.EphemeralDisk.FileSystem = boshdisk.FileSystemXFS })
+
+					It("formats data partition with xfs", func() {
+						err := act()
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(diskManager.FakeFormatter.FormatFsTypes).To(Equal([]boshdisk.FileSystemType{
+							boshdisk.FileSystemSwap,
+							boshdisk.FileSystemXFS,
+						}))
+					})
+				})
+
+				Context("when file system is not supported", func() {
+					BeforeEach(func() { options.EphemeralDisk.FileSystem = boshdisk.FileSystemSwap })
+
+					It("returns error without partitioning disk", func() {
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("Unsupported ephemeral disk file system swap"))
+						Expect(diskManager.FakePartitioner.PartitionPartitions).To(BeEmpty())
+					})
+				})
+
+				Context("when disk was not set up before", func() {
+					BeforeEach(func() { options.EphemeralDisk.SwapSize = "1024" })
+
+					It("records layout disk was set up with", func() {
+						err := act()
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(fs.ReadFileString("/fake-dir/bosh/ephemeral_disk_layout.json")).To(Equal(
+							`[{"SizeInMb":1024,"Type":"swap","FileSystem":"swap","MountPoint":""},` +
+								`{"SizeInMb":8976,"Type":"linux","FileSystem":"ext4","MountPoint":"/fake-dir/data"}]`,
+						))
+					})
+
+					It("does not record layout if formatting fails", func() {
+						diskManager.FakeFormatter.FormatErr = errors.New("fake-format-err")
+
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(fs.FileExists("/fake-dir/bosh/ephemeral_disk_layout.json")).To(BeFalse())
+					})
+				})
+
+				Context("when disk was set up before", func() {
+					BeforeEach(func() {
+						options.EphemeralDisk.SwapSize = "1024"
+
+						fs.WriteFileString("/fake-dir/bosh/ephemeral_disk_layout.json", `[
+							{"SizeInMb": 1024, "Type": "swap", "FileSystem": "swap", "MountPoint": ""},
+							{"SizeInMb": 8976, "Type": "linux", "FileSystem": "ext4", "MountPoint": "/fake-dir/data"}
+						]`)
+
+						diskManager.FakePartitioner.HasPartitionsResult = true
+					})
+
+					It("sets up disk when layout did not change", func() {
+						err := act()
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(diskManager.FakePartitioner.PartitionPartitions).To(HaveLen(2))
+						Expect(diskManager.FakeMounter.MountMountPoints).To(Equal([]string{"/fake-dir/data"}))
+					})
+
+					Context("when swap size changed", func() {
+						BeforeEach(func() { options.EphemeralDisk.SwapSize = "2048" })
+
+						It("returns error without re-partitioning or re-formatting disk", func() {
+							err := act()
+							Expect(err).To(HaveOccurred())
+							Expect(err.Error()).To(ContainSubstring("Refusing to re-partition ephemeral disk"))
+
+							Expect(diskManager.FakePartitioner.PartitionPartitions).To(BeEmpty())
+							Expect(diskManager.FakeFormatter.FormatPartitionPaths).To(BeEmpty())
+							Expect(diskManager.FakeMounter.MountPartitionPaths).To(BeEmpty())
+						})
+
+						Context("when re-partitioning is allowed", func() {
+							BeforeEach(func() { options.EphemeralDisk.AllowRepartition = true })
+
+							It("re-partitions disk and records new layout", func() {
+								err := act()
+								Expect(err).NotTo(HaveOccurred())
+
+								Expect(diskManager.FakePartitioner.PartitionPartitions).To(Equal([]boshdisk.Partition{
+									{SizeInMb: 2048, Type: boshdisk.PartitionTypeSwap},
+									{SizeInMb: 7952, Type: boshdisk.PartitionTypeLinux},
+								}))
+
+								Expect(fs.ReadFileString("/fake-dir/bosh/ephemeral_disk_layout.json")).To(ContainSubstring(`"SizeInMb":2048`))
+							})
+						})
+
+						Context("when disk is no longer partitioned as recorded", func() {
+							BeforeEach(func() { diskManager.FakePartitioner.HasPartitionsResult = false })
+
+							It("partitions disk since there is no data to lose", func() {
+								err := act()
+								Expect(err).NotTo(HaveOccurred())
+
+								Expect(diskManager.FakePartitioner.HasPartitionsDevicePath).To(Equal("/dev/xvda"))
+								Expect(diskManager.FakePartitioner.HasPartitionsPartitions).To(Equal([]boshdisk.Partition{
+									{SizeInMb: 1024, Type: boshdisk.PartitionTypeSwap},
+									{SizeInMb: 8976, Type: boshdisk.PartitionTypeLinux},
+								}))
+
+								Expect(diskManager.FakePartitioner.PartitionPartitions).To(Equal([]boshdisk.Partition{
+									{SizeInMb: 2048, Type: boshdisk.PartitionTypeSwap},
+									{SizeInMb: 7952, Type: boshdisk.PartitionTypeLinux},
+								}))
+							})
+						})
+					})
+
+					Context("when swap is sized to RAM and RAM changed", func() {
+						BeforeEach(func() { options.EphemeralDisk.SwapSize = "" })
+
+						It("keeps swap size disk was set up with so that layout does not change", func() {
+							err := act()
+							Expect(err).NotTo(HaveOccurred())
+
+							Expect(diskManager.FakePartitioner.PartitionPartitions).To(Equal([]boshdisk.Partition{
+								{SizeInMb: 1024, Type: boshdisk.PartitionTypeSwap},
+								{SizeInMb: 8976, Type: boshdisk.PartitionTypeLinux},
+							}))
+						})
+					})
+
+					Context("when file system changed", func() {
+						BeforeEach(func() { options.EphemeralDisk.FileSystem = boshdisk.FileSystemXFS })
+
+						It("returns error without re-formatting data partition", func() {
+							err := act()
+							Expect(err).To(HaveOccurred())
+							Expect(err.Error()).To(ContainSubstring("Refusing to re-partition ephemeral disk"))
+
+							Expect(diskManager.FakePartitioner.PartitionPartitions).To(BeEmpty())
+							Expect(diskManager.FakeFormatter.FormatPartitionPaths).To(BeEmpty())
+						})
+					})
+
+					It("returns error when recorded layout cannot be read", func() {
+						fs.ReadFileError = errors.New("fake-read-err")
+
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("fake-read-err"))
+						Expect(diskManager.FakePartitioner.PartitionPartitions).To(BeEmpty())
+					})
+				})
+
+				Context("when extra partitions are configured", func() {
+					BeforeEach(func() {
+						options.EphemeralDisk.SwapSize = "1000"
+						options.EphemeralDisk.Partitions = []EphemeralPartitionOptions{
+							{MountPoint: "sys/log", Size: "2000"},
+							{MountPoint: "fake-scratch", Size: "10%"},
+						}
+					})
+
+					It("places extra partitions between swap and data partitions", func() {
+						err := act()
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(diskManager.FakePartitioner.PartitionPartitions).To(Equal([]boshdisk.Partition{
+							{SizeInMb: 1000, Type: boshdisk.PartitionTypeSwap},
+							{SizeInMb: 2000, Type: boshdisk.PartitionTypeLinux},
+							{SizeInMb: 1000, Type: boshdisk.PartitionTypeLinux},
+							{SizeInMb: 6000, Type: boshdisk.PartitionTypeLinux},
+						}))
+
+						Expect(diskManager.FakeFormatter.FormatPartitionPaths).To(Equal([]string{
+							"/dev/xvda1", "/dev/xvda2", "/dev/xvda3", "/dev/xvda4",
+						}))
+					})
+
+					It("mounts extra partitions under data dir after mounting data partition", func() {
+						err := act()
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(diskManager.FakeMounter.MountPartitionPaths).To(Equal([]string{
+							"/dev/xvda4", "/dev/xvda2", "/dev/xvda3",
+						}))
+						Expect(diskManager.FakeMounter.MountMountPoints).To(Equal([]string{
+							"/fake-dir/data", "/fake-dir/data/sys/log", "/fake-dir/data/fake-scratch",
+						}))
+
+						logDir := fs.GetFileTestStat("/fake-dir/data/sys/log")
+						Expect(logDir.FileType).To(Equal(fakesys.FakeFileTypeDir))
+					})
+
+					It("returns error if mounting partition fails", func() {
+						diskManager.FakeMounter.MountErr = errors.New("fake-mount-err")
+
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("fake-mount-err"))
+					})
+
+					Context("when mount point is absolute", func() {
+						BeforeEach(func() {
+							options.EphemeralDisk.Partitions[0].MountPoint = "/var/vcap/data/sys/log"
+						})
+
+						It("returns error", func() {
+							err := act()
+							Expect(err).To(HaveOccurred())
+							Expect(err.Error()).To(ContainSubstring("Expected mount point relative to data dir"))
+						})
+					})
+
+					Context("when there are more partitions than partition table allows", func() {
+						BeforeEach(func() {
+							options.EphemeralDisk.Partitions = append(options.EphemeralDisk.Partitions, EphemeralPartitionOptions{
+								MountPoint: "fake-other", Size: "100",
+							})
+						})
+
+						It("returns error", func() {
+							err := act()
+							Expect(err).To(HaveOccurred())
+							Expect(err.Error()).To(ContainSubstring("Expected at most 4 partitions, got 5"))
+						})
+					})
+				})
+			})
 		})
 
 		Context("when ephemeral disk path is not provided", func() {
@@ -592,11 +911,14 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/*/*.log fake-base-
 	})
 
 	Describe("MountPersistentDisk", func() {
-		act := func() error { return platform.MountPersistentDisk("fake-volume-id", "/mnt/point") }
+		act := func() error {
+			_, err := platform.MountPersistentDisk("fake-disk-cid", "fake-device-path", "/mnt/point")
+			return err
+		}
 
 		Context("when device path is successfully resolved", func() {
 			BeforeEach(func() {
-				devicePathResolver.RegisterRealDevicePath("fake-volume-id", "fake-real-device-path")
+				devicePathResolver.RegisterRealDevicePath("fake-device-path", "fake-real-device-path")
 			})
 
 			Context("when UsePreformattedPersistentDisk set to false", func() {
@@ -640,6 +962,319 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/*/*.log fake-base-
 					Expect(diskManager.FakeMounter.MountMountPoints).To(Equal([]string{"/mnt/point"}))
 					Expect(diskManager.FakeMounter.MountMountOptions).To(Equal([][]string{nil}))
 				})
+
+				It("returns file system of the disk", func() {
+					result, err := platform.MountPersistentDisk("fake-disk-cid", "fake-device-path", "/mnt/point")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result).To(Equal(PersistentDiskMountResult{FileSystem: boshdisk.FileSystemExt4}))
+				})
+
+				It("tries to grow existing partition before partitioning the disk", func() {
+					err := act()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(diskManager.FakePartitioner.GrowPartitionDevicePath).To(Equal("fake-real-device-path"))
+					Expect(diskManager.FakePartitioner.GrowPartitionPartitionNumber).To(Equal(1))
+				})
+
+				It("returns error when growing partition fails", func() {
+					diskManager.FakePartitioner.GrowPartitionErr = errors.New("fake-grow-partition-err")
+
+					err := act()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("fake-grow-partition-err"))
+					Expect(diskManager.FakePartitioner.PartitionCalled).To(BeFalse())
+				})
+
+				It("does not check file system", func() {
+					err := act()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(diskManager.FakeChecker.CheckPartitionPaths).To(BeEmpty())
+				})
+
+				Context("when existing file system is smaller than its partition", func() {
+					BeforeEach(func() {
+						diskManager.FakeFormatter.DetectFileSystemTypeTypes = map[string]boshdisk.FileSystemType{
+							"fake-real-device-path1": boshdisk.FileSystemExt4,
+						}
+						diskManager.FakeResizer.NeedsToGrowFileSystemNeeds = true
+					})
+
+					It("grows file system after mounting it even if partition was not grown during this mount", func() {
+						diskManager.FakePartitioner.GrowPartitionGrown = false
+
+						result, err := platform.MountPersistentDisk("fake-disk-cid", "fake-device-path", "/mnt/point")
+						Expect(err).ToNot(HaveOccurred())
+						Expect(result.Resized).To(BeTrue())
+
+						resizer := diskManager.FakeResizer
+						Expect(resizer.NeedsToGrowFileSystemPartitionPath).To(Equal("fake-real-device-path1"))
+						Expect(resizer.GrowFileSystemPartitionPath).To(Equal("fake-real-device-path1"))
+						Expect(resizer.GrowFileSystemMountPoint).To(Equal("/mnt/point"))
+						Expect(resizer.GrowFileSystemFsType).To(Equal(boshdisk.FileSystemExt4))
+					})
+
+					It("returns error when file system and partition sizes cannot be compared", func() {
+						diskManager.FakeResizer.NeedsToGrowFileSystemErr = errors.New("fake-needs-to-grow-err")
+
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("fake-needs-to-grow-err"))
+						Expect(diskManager.FakeResizer.GrowFileSystemCalled).To(BeFalse())
+					})
+
+					It("returns error when growing file system fails", func() {
+						diskManager.FakeResizer.GrowFileSystemErr = errors.New("fake-grow-fs-err")
+
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("fake-grow-fs-err"))
+					})
+
+					It("does not grow file system when mounting fails", func() {
+						diskManager.FakeMounter.MountErr = errors.New("fake-mount-err")
+
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(diskManager.FakeResizer.GrowFileSystemCalled).To(BeFalse())
+					})
+				})
+
+				It("does not grow existing file system that already fills its partition", func() {
+					diskManager.FakePartitioner.GrowPartitionGrown = true
+					diskManager.FakeFormatter.DetectFileSystemTypeTypes = map[string]boshdisk.FileSystemType{
+						"fake-real-device-path1": boshdisk.FileSystemExt4,
+					}
+					diskManager.FakeResizer.NeedsToGrowFileSystemNeeds = false
+
+					result, err := platform.MountPersistentDisk("fake-disk-cid", "fake-device-path", "/mnt/point")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Resized).To(BeFalse())
+					Expect(diskManager.FakeResizer.GrowFileSystemCalled).To(BeFalse())
+				})
+
+				Context("when partition contains LVM physical volume while LVM is not used", func() {
+					BeforeEach(func() {
+						diskManager.FakeFormatter.DetectFileSystemTypeTypes = map[string]boshdisk.FileSystemType{
+							"fake-real-device-path1": boshdisk.FileSystemType("LVM2_member"),
+						}
+					})
+
+					It("refuses to format and mount the partition", func() {
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("Refusing to format fake-real-device-path1 since it already contains LVM2_member"))
+
+						Expect(diskManager.FakeFormatter.FormatCalled).To(BeFalse())
+						Expect(diskManager.FakeMounter.MountPartitionPaths).To(BeEmpty())
+					})
+				})
+
+				Context("when partition is already formatted", func() {
+					BeforeEach(func() {
+						diskManager.FakeFormatter.DetectFileSystemTypeTypes = map[string]boshdisk.FileSystemType{
+							"fake-real-device-path1": boshdisk.FileSystemExt4,
+						}
+					})
+
+					It("does not format the disk", func() {
+						err := act()
+						Expect(err).ToNot(HaveOccurred())
+						Expect(diskManager.FakeFormatter.FormatCalled).To(BeFalse())
+					})
+
+					Context("when configured file system is different", func() {
+						BeforeEach(func() {
+							options.PersistentDiskFileSystem = boshdisk.FileSystemXFS
+						})
+
+						It("keeps existing file system", func() {
+							result, err := platform.MountPersistentDisk("fake-disk-cid", "fake-device-path", "/mnt/point")
+							Expect(err).ToNot(HaveOccurred())
+							Expect(result.FileSystem).To(Equal(boshdisk.FileSystemExt4))
+							Expect(diskManager.FakeFormatter.FormatCalled).To(BeFalse())
+						})
+					})
+
+					Context("when CheckPersistentDiskOnMount set to true", func() {
+						BeforeEach(func() {
+							options.CheckPersistentDiskOnMount = true
+						})
+
+						It("checks file system before mounting it and returns check result", func() {
+							checkResult := boshdisk.CheckResult{FileSystem: boshdisk.FileSystemExt4, ExitStatus: 1, Repaired: true}
+							diskManager.FakeChecker.CheckResult = checkResult
+
+							result, err := platform.MountPersistentDisk("fake-disk-cid", "fake-device-path", "/mnt/point")
+							Expect(err).ToNot(HaveOccurred())
+							Expect(result.Check).To(Equal(&checkResult))
+
+							Expect(diskManager.FakeChecker.CheckPartitionPaths).To(Equal([]string{"fake-real-device-path1"}))
+							Expect(diskManager.FakeChecker.CheckFsTypes).To(Equal([]boshdisk.FileSystemType{boshdisk.FileSystemExt4}))
+						})
+
+						It("returns error without mounting disk when check fails", func() {
+							diskManager.FakeChecker.CheckErr = errors.New("fake-check-err")
+
+							err := act()
+							Expect(err).To(HaveOccurred())
+							Expect(err.Error()).To(ContainSubstring("fake-check-err"))
+							Expect(diskManager.FakeMounter.MountPartitionPaths).To(BeEmpty())
+						})
+					})
+				})
+
+				Context("when UseLVMForPersistentDisk set to true", func() {
+					var volumeManager *fakedisk.FakeVolumeManager
+
+					BeforeEach(func() {
+						options.UseLVMForPersistentDisk = true
+
+						volumeManager = diskManager.FakeVolumeManager
+						volumeManager.CreateVolumeVolume = boshdisk.LogicalVolume{
+							Name:        "store",
+							VolumeGroup: "fake-vg",
+							Path:        "/dev/mapper/fake-vg-store",
+						}
+					})
+
+					It("creates logical volume on the partition, formats and mounts it", func() {
+						err := act()
+						Expect(err).ToNot(HaveOccurred())
+
+						Expect(volumeManager.FindVolumePhysicalVolumePath).To(Equal("fake-real-device-path1"))
+						Expect(volumeManager.CreateVolumePhysicalVolumePath).To(Equal("fake-real-device-path1"))
+						Expect(volumeManager.CreateVolumeName).To(Equal("store"))
+						Expect(volumeManager.CreateVolumePercentOfGroup).To(Equal(80))
+
+						Expect(diskManager.FakeFormatter.FormatPartitionPaths).To(Equal([]string{"/dev/mapper/fake-vg-store"}))
+						Expect(diskManager.FakeMounter.MountPartitionPaths).To(Equal([]string{"/dev/mapper/fake-vg-store"}))
+						Expect(diskManager.FakeMounter.MountMountPoints).To(Equal([]string{"/mnt/point"}))
+					})
+
+					It("returns error when creating logical volume fails", func() {
+						volumeManager.CreateVolumeErr = errors.New("fake-create-volume-err")
+
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("fake-create-volume-err"))
+						Expect(diskManager.FakeFormatter.FormatCalled).To(BeFalse())
+					})
+
+					It("returns error when finding logical volume fails", func() {
+						volumeManager.FindVolumeErr = errors.New("fake-find-volume-err")
+
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("fake-find-volume-err"))
+						Expect(volumeManager.CreateVolumeCalled).To(BeFalse())
+					})
+
+					Context("when partition already has a file system", func() {
+						BeforeEach(func() {
+							diskManager.FakeFormatter.DetectFileSystemTypeTypes = map[string]boshdisk.FileSystemType{
+								"fake-real-device-path1": boshdisk.FileSystemExt4,
+							}
+						})
+
+						It("mounts the partition without creating logical volume", func() {
+							err := act()
+							Expect(err).ToNot(HaveOccurred())
+							Expect(volumeManager.CreateVolumeCalled).To(BeFalse())
+							Expect(diskManager.FakeFormatter.FormatCalled).To(BeFalse())
+							Expect(diskManager.FakeMounter.MountPartitionPaths).To(Equal([]string{"fake-real-device-path1"}))
+						})
+					})
+
+					Context("when logical volume already exists", func() {
+						BeforeEach(func() {
+							volumeManager.FindVolumeFound = true
+							volumeManager.FindVolumeVolume = volumeManager.CreateVolumeVolume
+
+							diskManager.FakeFormatter.DetectFileSystemTypeTypes = map[string]boshdisk.FileSystemType{
+								"/dev/mapper/fake-vg-store": boshdisk.FileSystemExt4,
+							}
+						})
+
+						It("activates volume group and mounts logical volume without formatting it", func() {
+							err := act()
+							Expect(err).ToNot(HaveOccurred())
+							Expect(volumeManager.CreateVolumeCalled).To(BeFalse())
+							Expect(volumeManager.ActivateVolumeGroupName).To(Equal("fake-vg"))
+							Expect(diskManager.FakeFormatter.FormatCalled).To(BeFalse())
+							Expect(diskManager.FakeMounter.MountPartitionPaths).To(Equal([]string{"/dev/mapper/fake-vg-store"}))
+						})
+
+						It("grows logical volume even if partition was not grown during this mount", func() {
+							diskManager.FakePartitioner.GrowPartitionGrown = false
+
+							err := act()
+							Expect(err).ToNot(HaveOccurred())
+							Expect(volumeManager.GrowVolumeCalled).To(BeTrue())
+							Expect(volumeManager.GrowVolumePhysicalVolumePath).To(Equal("fake-real-device-path1"))
+						})
+
+						It("returns error when activating volume group fails", func() {
+							volumeManager.ActivateVolumeGroupErr = errors.New("fake-activate-err")
+
+							err := act()
+							Expect(err).To(HaveOccurred())
+							Expect(err.Error()).To(ContainSubstring("fake-activate-err"))
+							Expect(diskManager.FakeMounter.MountPartitionPaths).To(BeEmpty())
+						})
+
+						Context("when file system is smaller than logical volume", func() {
+							BeforeEach(func() {
+								diskManager.FakeResizer.NeedsToGrowFileSystemNeeds = true
+							})
+
+							It("grows logical volume and then its file system", func() {
+								result, err := platform.MountPersistentDisk("fake-disk-cid", "fake-device-path", "/mnt/point")
+								Expect(err).ToNot(HaveOccurred())
+								Expect(result.Resized).To(BeTrue())
+
+								Expect(volumeManager.GrowVolumePhysicalVolumePath).To(Equal("fake-real-device-path1"))
+								Expect(volumeManager.GrowVolumeVolume).To(Equal(volumeManager.FindVolumeVolume))
+								Expect(volumeManager.GrowVolumePercentOfGroup).To(Equal(80))
+
+								Expect(diskManager.FakeResizer.GrowFileSystemPartitionPath).To(Equal("/dev/mapper/fake-vg-store"))
+							})
+
+							It("returns error when growing logical volume fails", func() {
+								volumeManager.GrowVolumeErr = errors.New("fake-grow-volume-err")
+
+								err := act()
+								Expect(err).To(HaveOccurred())
+								Expect(err.Error()).To(ContainSubstring("fake-grow-volume-err"))
+								Expect(diskManager.FakeResizer.GrowFileSystemCalled).To(BeFalse())
+							})
+						})
+					})
+				})
+
+				Context("when PersistentDiskFileSystem set to xfs", func() {
+					BeforeEach(func() {
+						options.PersistentDiskFileSystem = boshdisk.FileSystemXFS
+					})
+
+					It("formats the disk with xfs", func() {
+						err := act()
+						Expect(err).ToNot(HaveOccurred())
+						Expect(diskManager.FakeFormatter.FormatFsTypes).To(Equal([]boshdisk.FileSystemType{boshdisk.FileSystemXFS}))
+					})
+				})
+
+				Context("when PersistentDiskFileSystem is not supported", func() {
+					BeforeEach(func() {
+						options.PersistentDiskFileSystem = boshdisk.FileSystemSwap
+					})
+
+					It("returns error without formatting the disk", func() {
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("Unsupported persistent disk file system swap"))
+						Expect(diskManager.FakeFormatter.FormatCalled).To(BeFalse())
+					})
+				})
 			})
 
 			Context("when UsePreformattedPersistentDisk set to true", func() {
@@ -695,6 +1330,39 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/*/*.log fake-base-
 					Expect(diskManager.FakeFormatter.FormatCalled).To(BeFalse())
 				})
 			})
+
+			Context("when BindMountPersistentDisk set to true", func() {
+				BeforeEach(func() {
+					options.BindMountPersistentDisk = true
+				})
+
+				It("mounts directory at mount point without partitioning or formatting it", func() {
+					err := act()
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(diskManager.FakePartitioner.PartitionCalled).To(BeFalse())
+					Expect(diskManager.FakeFormatter.FormatCalled).To(BeFalse())
+
+					mounter := diskManager.FakeMounter
+					Expect(mounter.MountPartitionPaths).To(Equal([]string{"fake-real-device-path"}))
+					Expect(mounter.MountMountPoints).To(Equal([]string{"/mnt/point"}))
+				})
+			})
+		})
+
+		Context("when disk is found by volume ID", func() {
+			BeforeEach(func() {
+				devicePathResolver.RegisterRealDevicePathForVolumeID("fake-disk-cid", "fake-volume-id-real-device-path")
+			})
+
+			It("partitions, formats and mounts disk found by volume ID instead of device path", func() {
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(diskManager.FakePartitioner.PartitionDevicePath).To(Equal("fake-volume-id-real-device-path"))
+				Expect(diskManager.FakeFormatter.FormatPartitionPaths).To(Equal([]string{"fake-volume-id-real-device-path1"}))
+				Expect(diskManager.FakeMounter.MountPartitionPaths).To(Equal([]string{"fake-volume-id-real-device-path1"}))
+			})
 		})
 
 		Context("when device path is not successfully resolved", func() {
@@ -709,7 +1377,7 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/*/*.log fake-base-
 	})
 
 	Describe("UnmountPersistentDisk", func() {
-		act := func() (bool, error) { return platform.UnmountPersistentDisk("fake-device-path") }
+		act := func() (bool, error) { return platform.UnmountPersistentDisk("fake-disk-cid", "fake-device-path") }
 
 		Context("when device path can be resolved", func() {
 			BeforeEach(func() {
@@ -758,6 +1426,64 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/*/*.log fake-base-
 
 				ItUnmountsPersistentDisk("fake-real-device-path") // note no '1'; no partitions
 			})
+
+			Context("UseLVMForPersistentDisk is set to true", func() {
+				var volumeManager *fakedisk.FakeVolumeManager
+
+				BeforeEach(func() {
+					options.UseLVMForPersistentDisk = true
+					volumeManager = diskManager.FakeVolumeManager
+				})
+
+				Context("when partition is a logical volume", func() {
+					BeforeEach(func() {
+						volumeManager.FindVolumeFound = true
+						volumeManager.FindVolumeVolume = boshdisk.LogicalVolume{
+							Name:        "store",
+							VolumeGroup: "fake-vg",
+							Path:        "/dev/mapper/fake-vg-store",
+						}
+					})
+
+					ItUnmountsPersistentDisk("/dev/mapper/fake-vg-store")
+
+					It("unmounts snapshots and logical volume and deactivates volume group", func() {
+						volumeManager.ListSnapshotsSnapshots = []boshdisk.LogicalVolume{
+							{Name: "snap-1", Path: "/dev/mapper/fake-vg-snap--1"},
+						}
+
+						_, err := act()
+						Expect(err).ToNot(HaveOccurred())
+
+						Expect(volumeManager.FindVolumePhysicalVolumePath).To(Equal("fake-real-device-path1"))
+						Expect(diskManager.FakeMounter.UnmountPartitionPathsOrMountPoints).To(Equal([]string{
+							"/dev/mapper/fake-vg-snap--1",
+							"/dev/mapper/fake-vg-store",
+						}))
+						Expect(volumeManager.DeactivateVolumeGroupName).To(Equal("fake-vg"))
+					})
+
+					It("returns error when deactivating volume group fails", func() {
+						volumeManager.DeactivateVolumeGroupErr = errors.New("fake-deactivate-err")
+
+						_, err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("fake-deactivate-err"))
+					})
+				})
+
+				Context("when partition is not a logical volume", func() {
+					ItUnmountsPersistentDisk("fake-real-device-path1")
+				})
+			})
+
+			Context("BindMountPersistentDisk is set to true", func() {
+				BeforeEach(func() {
+					options.BindMountPersistentDisk = true
+				})
+
+				ItUnmountsPersistentDisk("fake-real-device-path") // note no '1'; directory is mounted
+			})
 		})
 
 		Context("when device path can be resolved", func() {
@@ -772,6 +1498,21 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/*/*.log fake-base-
 				Expect(didUnmount).To(BeFalse())
 			})
 		})
+
+		Context("when disk is found by volume ID", func() {
+			BeforeEach(func() {
+				devicePathResolver.RegisterRealDevicePathForVolumeID("fake-disk-cid", "fake-volume-id-real-device-path")
+			})
+
+			It("unmounts partition of disk found by volume ID", func() {
+				diskManager.FakeMounter.UnmountDidUnmount = true
+
+				didUnmount, err := act()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(didUnmount).To(BeTrue())
+				Expect(diskManager.FakeMounter.UnmountPartitionPathOrMountPoint).To(Equal("fake-volume-id-real-device-path1"))
+			})
+		})
 	})
 
 	Describe("GetFileContentsFromCDROM", func() {
@@ -785,6 +1526,23 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/*/*.log fake-base-
 		})
 	})
 
+	Describe("GetFilesContentsFromDisk", func() {
+		BeforeEach(func() {
+			fs.TempDirDir = "/fake-tmp-dir"
+			fs.WriteFileString("/fake-tmp-dir/fake-file-path-1", "fake-contents-1")
+		})
+
+		It("mounts disk read-only using disk manager mounter and reads files", func() {
+			contents, err := platform.GetFilesContentsFromDisk("/dev/fake-disk", []string{"fake-file-path-1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(contents).To(Equal([][]byte{[]byte("fake-contents-1")}))
+
+			Expect(diskManager.FakeMounter.MountPartitionPaths).To(Equal([]string{"/dev/fake-disk"}))
+			Expect(diskManager.FakeMounter.MountMountOptions).To(Equal([][]string{{"-o", "ro"}}))
+			Expect(diskManager.FakeMounter.UnmountPartitionPathOrMountPoint).To(Equal("/fake-tmp-dir"))
+		})
+	})
+
 	Describe("NormalizeDiskPath", func() {
 		Context("when real device path was resolved without an error", func() {
 			It("returns real device path and true", func() {
@@ -807,24 +1565,108 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/*/*.log fake-base-
 	})
 
 	Describe("MigratePersistentDisk", func() {
+		var (
+			progress *boshdiskcopier.Progress
+			cancelCh chan struct{}
+		)
+
+		BeforeEach(func() {
+			progress = boshdiskcopier.NewProgress()
+			cancelCh = make(chan struct{})
+		})
+
+		act := func() error {
+			return platform.MigratePersistentDisk("/from/path", "/to/path", progress, cancelCh)
+		}
+
 		It("migrate persistent disk", func() {
-			err := platform.MigratePersistentDisk("/from/path", "/to/path")
+			err := act()
 			Expect(err).ToNot(HaveOccurred())
 
 			fakeMounter := diskManager.FakeMounter
 			Expect(fakeMounter.RemountAsReadonlyPath).To(Equal("/from/path"))
 
-			Expect(len(cmdRunner.RunCommands)).To(Equal(1))
-			Expect(cmdRunner.RunCommands[0]).To(Equal([]string{"sh", "-c", "(tar -C /from/path -cf - .) | (tar -C /to/path -xpf -)"}))
+			Expect(diskCopier.CopyFromDir).To(Equal("/from/path"))
+			Expect(diskCopier.CopyToDir).To(Equal("/to/path"))
+			Expect(diskCopier.CopyProgress).To(Equal(progress))
+			Expect(diskCopier.CopyCancelCh).To(Equal((<-chan struct{})(cancelCh)))
 
 			Expect(fakeMounter.UnmountPartitionPathOrMountPoint).To(Equal("/from/path"))
 			Expect(fakeMounter.RemountFromMountPoint).To(Equal("/to/path"))
 			Expect(fakeMounter.RemountToMountPoint).To(Equal("/from/path"))
 		})
+
+		It("does not copy files when remounting as readonly fails", func() {
+			diskManager.FakeMounter.RemountAsReadonlyErr = errors.New("fake-remount-err")
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-remount-err"))
+			Expect(diskCopier.CopyCalled).To(BeFalse())
+		})
+
+		It("keeps old disk mounted when copying fails", func() {
+			diskCopier.CopyErr = errors.New("fake-copy-err")
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-copy-err"))
+
+			Expect(diskManager.FakeMounter.UnmountPartitionPathOrMountPoint).To(Equal(""))
+			Expect(diskManager.FakeMounter.RemountFromMountPoint).To(Equal(""))
+		})
+
+		It("does not remount old disk as read-write when migration succeeds", func() {
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskManager.FakeMounter.RemountAsReadWriteCalled).To(BeFalse())
+		})
+
+		It("does not remount old disk as read-write when remounting as readonly fails", func() {
+			diskManager.FakeMounter.RemountAsReadonlyErr = errors.New("fake-remount-err")
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(diskManager.FakeMounter.RemountAsReadWriteCalled).To(BeFalse())
+		})
+
+		It("remounts old disk as read-write when copying fails", func() {
+			diskCopier.CopyErr = errors.New("fake-copy-err")
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(diskManager.FakeMounter.RemountAsReadWritePath).To(Equal("/from/path"))
+		})
+
+		It("remounts old disk as read-write when migration is cancelled", func() {
+			diskCopier.CopyErr = boshdiskcopier.ErrCancelled
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(diskManager.FakeMounter.RemountAsReadWritePath).To(Equal("/from/path"))
+		})
+
+		It("remounts old disk as read-write when unmounting it fails", func() {
+			diskManager.FakeMounter.UnmountErr = errors.New("fake-unmount-err")
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-unmount-err"))
+			Expect(diskManager.FakeMounter.RemountAsReadWritePath).To(Equal("/from/path"))
+		})
+
+		It("returns original error when remounting old disk as read-write also fails", func() {
+			diskCopier.CopyErr = errors.New("fake-copy-err")
+			diskManager.FakeMounter.RemountAsReadWriteErr = errors.New("fake-remount-rw-err")
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-copy-err"))
+		})
 	})
 
 	Describe("IsPersistentDiskMounted", func() {
-		act := func() (bool, error) { return platform.IsPersistentDiskMounted("fake-device-path") }
+		act := func() (bool, error) { return platform.IsPersistentDiskMounted("fake-disk-cid", "fake-device-path") }
 
 		Context("when device path can be resolved", func() {
 			BeforeEach(func() {
@@ -877,6 +1719,26 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/*/*.log fake-base-
 
 				ItChecksPersistentDiskMountPoint("fake-real-device-path") // note no '1'; no partitions
 			})
+
+			Context("UseLVMForPersistentDisk is set to true", func() {
+				BeforeEach(func() {
+					options.UseLVMForPersistentDisk = true
+					diskManager.FakeVolumeManager.FindVolumeFound = true
+					diskManager.FakeVolumeManager.FindVolumeVolume = boshdisk.LogicalVolume{
+						Path: "/dev/mapper/fake-vg-store",
+					}
+				})
+
+				ItChecksPersistentDiskMountPoint("/dev/mapper/fake-vg-store") // note logical volume on partition
+			})
+
+			Context("BindMountPersistentDisk is set to true", func() {
+				BeforeEach(func() {
+					options.BindMountPersistentDisk = true
+				})
+
+				ItChecksPersistentDiskMountPoint("fake-real-device-path") // note no '1'; directory is mounted
+			})
 		})
 
 		Context("when device path can be resolved", func() {
@@ -893,6 +1755,240 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/*/*.log fake-base-
 		})
 	})
 
+	Describe("persistent disk snapshots", func() {
+		var (
+			volumeManager *fakedisk.FakeVolumeManager
+			origin        boshdisk.LogicalVolume
+		)
+
+		BeforeEach(func() {
+			options.UseLVMForPersistentDisk = true
+			devicePathResolver.RegisterRealDevicePath("fake-device-path", "fake-real-device-path")
+
+			origin = boshdisk.LogicalVolume{
+				Name:        "store",
+				VolumeGroup: "fake-vg",
+				Path:        "/dev/mapper/fake-vg-store",
+			}
+
+			volumeManager = diskManager.FakeVolumeManager
+			volumeManager.FindVolumeFound = true
+			volumeManager.FindVolumeVolume = origin
+			volumeManager.CreateSnapshotSnapshot = boshdisk.LogicalVolume{
+				Name:        "snap-1",
+				VolumeGroup: "fake-vg",
+				Origin:      "store",
+				Path:        "/dev/mapper/fake-vg-snap--1",
+			}
+			volumeManager.ListSnapshotsSnapshots = []boshdisk.LogicalVolume{
+				{
+					Name:                 "snap-1",
+					VolumeGroup:          "fake-vg",
+					Origin:               "store",
+					Path:                 "/dev/mapper/fake-vg-snap--1",
+					SizeInBytes:          1024,
+					SnapshotUsagePercent: 2.5,
+				},
+			}
+		})
+
+		ItRequiresLVM := func(act func() error) {
+			It("returns error when persistent disk is not a logical volume", func() {
+				volumeManager.FindVolumeFound = false
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Persistent disk fake-device-path is not an LVM logical volume"))
+			})
+
+			Context("when UseLVMForPersistentDisk is not set", func() {
+				BeforeEach(func() {
+					options.UseLVMForPersistentDisk = false
+				})
+
+				It("returns error", func() {
+					err := act()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Persistent disk snapshots require LVM"))
+				})
+			})
+		}
+
+		Describe("CheckPersistentDiskSnapshot", func() {
+			act := func() error {
+				return platform.CheckPersistentDiskSnapshot("fake-disk-cid", "fake-device-path", "snap-1")
+			}
+
+			It("succeeds without creating snapshot when logical volume can be snapshotted", func() {
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(volumeManager.FindVolumePhysicalVolumePath).To(Equal("fake-real-device-path1"))
+				Expect(volumeManager.CreateSnapshotName).To(BeEmpty())
+			})
+
+			It("returns error when name is invalid", func() {
+				err := platform.CheckPersistentDiskSnapshot("fake-disk-cid", "fake-device-path", "../snap")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Invalid snapshot name '../snap'"))
+			})
+
+			ItRequiresLVM(act)
+		})
+
+		Describe("SnapshotPersistentDisk", func() {
+			act := func() error {
+				_, err := platform.SnapshotPersistentDisk("fake-disk-cid", "fake-device-path", "snap-1")
+				return err
+			}
+
+			It("creates snapshot of logical volume and mounts it read-only", func() {
+				snapshot, err := platform.SnapshotPersistentDisk("fake-disk-cid", "fake-device-path", "snap-1")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(snapshot).To(Equal(PersistentDiskSnapshot{
+					Name:         "snap-1",
+					SizeInBytes:  1024,
+					UsagePercent: 2.5,
+					MountPoint:   "/fake-dir/store_snapshots/snap-1",
+				}))
+
+				Expect(volumeManager.FindVolumePhysicalVolumePath).To(Equal("fake-real-device-path1"))
+				Expect(volumeManager.CreateSnapshotOrigin).To(Equal(origin))
+				Expect(volumeManager.CreateSnapshotName).To(Equal("snap-1"))
+				Expect(volumeManager.CreateSnapshotPercentOfOrigin).To(Equal(10))
+
+				mountPoint := fs.GetFileTestStat("/fake-dir/store_snapshots/snap-1")
+				Expect(mountPoint.FileType).To(Equal(fakesys.FakeFileTypeDir))
+
+				Expect(diskManager.FakeMounter.MountPartitionPaths).To(Equal([]string{"/dev/mapper/fake-vg-snap--1"}))
+				Expect(diskManager.FakeMounter.MountMountPoints).To(Equal([]string{"/fake-dir/store_snapshots/snap-1"}))
+				Expect(diskManager.FakeMounter.MountMountOptions).To(Equal([][]string{{"-o", "ro"}}))
+			})
+
+			It("mounts xfs snapshot without uuid check", func() {
+				diskManager.FakeFormatter.DetectFileSystemTypeTypes = map[string]boshdisk.FileSystemType{
+					"/dev/mapper/fake-vg-snap--1": boshdisk.FileSystemXFS,
+				}
+
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(diskManager.FakeMounter.MountMountOptions).To(Equal([][]string{{"-o", "ro,nouuid"}}))
+			})
+
+			It("returns error without creating snapshot when name is invalid", func() {
+				_, err := platform.SnapshotPersistentDisk("fake-disk-cid", "fake-device-path", "../snap")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Invalid snapshot name '../snap'"))
+				Expect(volumeManager.CreateSnapshotName).To(BeEmpty())
+			})
+
+			It("returns error without mounting snapshot when creating snapshot fails", func() {
+				volumeManager.CreateSnapshotErr = errors.New("fake-create-snapshot-err")
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-create-snapshot-err"))
+				Expect(diskManager.FakeMounter.MountPartitionPaths).To(BeEmpty())
+			})
+
+			It("returns error when mounting snapshot fails", func() {
+				diskManager.FakeMounter.MountErr = errors.New("fake-mount-err")
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-mount-err"))
+			})
+
+			ItRequiresLVM(act)
+		})
+
+		Describe("ListPersistentDiskSnapshots", func() {
+			act := func() error {
+				_, err := platform.ListPersistentDiskSnapshots("fake-disk-cid", "fake-device-path")
+				return err
+			}
+
+			It("returns snapshots of logical volume with mount points of mounted ones", func() {
+				diskManager.FakeMounter.IsMountedResult = true
+
+				snapshots, err := platform.ListPersistentDiskSnapshots("fake-disk-cid", "fake-device-path")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(snapshots).To(Equal([]PersistentDiskSnapshot{
+					{
+						Name:         "snap-1",
+						SizeInBytes:  1024,
+						UsagePercent: 2.5,
+						MountPoint:   "/fake-dir/store_snapshots/snap-1",
+					},
+				}))
+
+				Expect(volumeManager.ListSnapshotsOrigin).To(Equal(origin))
+				Expect(diskManager.FakeMounter.IsMountedDevicePathOrMountPoint).To(Equal("/dev/mapper/fake-vg-snap--1"))
+			})
+
+			It("does not return mount point of snapshot that is not mounted", func() {
+				snapshots, err := platform.ListPersistentDiskSnapshots("fake-disk-cid", "fake-device-path")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(snapshots[0].MountPoint).To(BeEmpty())
+			})
+
+			It("returns error when listing snapshots fails", func() {
+				volumeManager.ListSnapshotsErr = errors.New("fake-list-snapshots-err")
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-list-snapshots-err"))
+			})
+
+			ItRequiresLVM(act)
+		})
+
+		Describe("DeletePersistentDiskSnapshot", func() {
+			act := func() error {
+				return platform.DeletePersistentDiskSnapshot("fake-disk-cid", "fake-device-path", "snap-1")
+			}
+
+			It("unmounts snapshot, removes its mount point and deletes it", func() {
+				fs.MkdirAll("/fake-dir/store_snapshots/snap-1", os.FileMode(0700))
+				diskManager.FakeMounter.UnmountDidUnmount = true
+
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(diskManager.FakeMounter.UnmountPartitionPathOrMountPoint).To(Equal("/dev/mapper/fake-vg-snap--1"))
+				Expect(fs.FileExists("/fake-dir/store_snapshots/snap-1")).To(BeFalse())
+				Expect(volumeManager.DeleteSnapshotOrigin).To(Equal(origin))
+				Expect(volumeManager.DeleteSnapshotName).To(Equal("snap-1"))
+			})
+
+			It("returns error without deleting snapshot when unmounting fails", func() {
+				diskManager.FakeMounter.UnmountErr = errors.New("fake-unmount-err")
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-unmount-err"))
+				Expect(volumeManager.DeleteSnapshotName).To(BeEmpty())
+			})
+
+			It("returns error when snapshot is not found", func() {
+				err := platform.DeletePersistentDiskSnapshot("fake-disk-cid", "fake-device-path", "store")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Snapshot store not found"))
+				Expect(diskManager.FakeMounter.UnmountPartitionPathsOrMountPoints).To(BeEmpty())
+				Expect(volumeManager.DeleteSnapshotName).To(BeEmpty())
+			})
+
+			It("returns error when deleting snapshot fails", func() {
+				volumeManager.DeleteSnapshotErr = errors.New("fake-delete-snapshot-err")
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-delete-snapshot-err"))
+			})
+
+			ItRequiresLVM(act)
+		})
+	})
+
 	Describe("StartMonit", func() {
 		It("start monit", func() {
 			err := platform.StartMonit()
@@ -963,6 +2059,15 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/*/*.log fake-base-
 			Expect(fs.FileExists("/etc/udev/rules.d/70-persistent-net.rules")).To(BeFalse())
 		})
 
+		It("removes networking failure recorded for previous networking change", func() {
+			fs.WriteFileString("/fake-dir/bosh/networking_failure", "fake-failure")
+
+			err := platform.PrepareForNetworkingChange()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fs.FileExists("/fake-dir/bosh/networking_failure")).To(BeFalse())
+		})
+
 		It("returns error if removing persistent rules file fails", func() {
 			fs.RemoveAllError = errors.New("fake-remove-all-error")
 
@@ -990,4 +2095,135 @@ fake-base-path/data/sys/log/*.log fake-base-path/data/sys/log/*/*.log fake-base-
 			Expect(network).To(Equal(boshsettings.Network{}))
 		})
 	})
+
+	Describe("StartDNSCache", func() {
+		networks := boshsettings.Networks{
+			"fake-net": boshsettings.Network{
+				DNS:       []string{"8.8.8.8"},
+				DNSSearch: []string{"fake-domain"},
+			},
+		}
+
+		Context("when DNS cache is enabled", func() {
+			BeforeEach(func() {
+				options.UseDNSCache = true
+			})
+
+			It("configures DNS with servers from default dns network", func() {
+				err := platform.StartDNSCache(networks)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(dnsManager.ConfigureConfig).To(Equal(boshdns.Config{
+					Servers: []string{"8.8.8.8"},
+					Search:  []string{"fake-domain"},
+				}))
+			})
+
+			It("does not configure DNS when there are no DNS servers", func() {
+				err := platform.StartDNSCache(boshsettings.Networks{"fake-net": boshsettings.Network{}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(dnsManager.ConfigureConfig).To(Equal(boshdns.Config{}))
+			})
+
+			It("returns error if configuring DNS fails", func() {
+				dnsManager.ConfigureErr = errors.New("fake-configure-err")
+
+				err := platform.StartDNSCache(networks)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-configure-err"))
+			})
+		})
+
+		Context("when DNS cache is disabled", func() {
+			It("does not configure DNS", func() {
+				err := platform.StartDNSCache(networks)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(dnsManager.ConfigureConfig).To(Equal(boshdns.Config{}))
+			})
+		})
+	})
+
+	Describe("VerifyNetworking", func() {
+		networks := boshsettings.Networks{
+			"bosh": boshsettings.Network{IP: "fake-ip"},
+		}
+
+		It("verifies networking with given endpoints", func() {
+			err := platform.VerifyNetworking(networks, []string{"fake-endpoint"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(netVerifier.VerifyNetworks).To(Equal(networks))
+			Expect(netVerifier.VerifyEndpoints).To(Equal([]string{"fake-endpoint"}))
+		})
+
+		Context("when verification succeeds", func() {
+			It("does not roll back networking", func() {
+				err := platform.VerifyNetworking(networks, []string{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(netManager.RollbackManualNetworkingCalled).To(BeFalse())
+			})
+
+			It("commits networking so that it is not rolled back later", func() {
+				err := platform.VerifyNetworking(networks, []string{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(netManager.CommitManualNetworkingCalled).To(BeTrue())
+			})
+
+			It("returns error if committing networking fails", func() {
+				netManager.CommitManualNetworkingErr = errors.New("fake-commit-err")
+
+				err := platform.VerifyNetworking(networks, []string{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-commit-err"))
+			})
+
+			It("removes previously recorded networking failure", func() {
+				fs.WriteFileString("/fake-dir/bosh/networking_failure", "fake-failure")
+
+				err := platform.VerifyNetworking(networks, []string{})
+				Expect(err).ToNot(HaveOccurred())
+
+				failure, err := platform.GetNetworkingFailure()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(failure).To(Equal(""))
+			})
+		})
+
+		Context("when verification fails", func() {
+			BeforeEach(func() {
+				netVerifier.VerifyErr = errors.New("fake-verify-err")
+			})
+
+			It("rolls back networking and returns error", func() {
+				err := platform.VerifyNetworking(networks, []string{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-verify-err"))
+
+				Expect(netManager.RollbackManualNetworkingCalled).To(BeTrue())
+			})
+
+			It("records networking failure", func() {
+				err := platform.VerifyNetworking(networks, []string{})
+				Expect(err).To(HaveOccurred())
+
+				failure, err := platform.GetNetworkingFailure()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(failure).To(Equal("fake-verify-err"))
+			})
+
+			It("records networking failure even if roll back fails", func() {
+				netManager.RollbackManualNetworkingErr = errors.New("fake-rollback-err")
+
+				err := platform.VerifyNetworking(networks, []string{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-verify-err"))
+
+				failure, err := platform.GetNetworkingFailure()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(failure).To(Equal("fake-verify-err"))
+			})
+		})
+	})
 })
@@ -1,6 +1,7 @@
 package arp
 
 import (
+	gonet "net"
 	"path/filepath"
 	"sync"
 	"time"
@@ -84,6 +85,16 @@ func (a arping) broadcastMACAddress(address boship.InterfaceAddress) {
 
 	ifaceName := address.GetInterfaceName()
 
+	// ARP does not exist in IPv6; unsolicited neighbor advertisement is used instead
+	parsedIP := gonet.ParseIP(ip)
+	if parsedIP != nil && parsedIP.To4() == nil {
+		_, _, _, err = a.cmdRunner.RunCommand("ndsend", ip, ifaceName)
+		if err != nil {
+			a.logger.Info(arpingLogTag, "Ignoring ndsend failure: %s", err.Error())
+		}
+		return
+	}
+
 	_, _, _, err = a.cmdRunner.RunCommand("arping", "-c", "1", "-U", "-I", ifaceName, ip)
 	if err != nil {
 		a.logger.Info(arpingLogTag, "Ignoring arping failure: %s", err.Error())
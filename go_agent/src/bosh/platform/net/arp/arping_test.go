@@ -73,6 +73,19 @@ var _ = Describe("arping", func() {
 			Expect(countB).To(Equal(arpingIterations))
 		})
 
+		It("sends unsolicited neighbor advertisements for IPv6 addresses", func() {
+			addresses := []boship.InterfaceAddress{
+				boship.NewSimpleInterfaceAddress("eth0", "2001:db8::10"),
+			}
+
+			arping.BroadcastMACAddresses(addresses)
+
+			Expect(len(cmdRunner.RunCommands)).To(Equal(arpingIterations))
+			for _, cmd := range cmdRunner.RunCommands {
+				Expect(cmd).To(Equal([]string{"ndsend", "2001:db8::10", "eth0"}))
+			}
+		})
+
 		It("does not run arping command if failed to get interface IP address", func() {
 			addresses := []boship.InterfaceAddress{failingInterfaceAddress{}}
 
@@ -3,12 +3,12 @@ package net
 import (
 	"bytes"
 	"path/filepath"
-	"strings"
 	"text/template"
 
 	bosherr "bosh/errors"
 	boshlog "bosh/logger"
 	bosharp "bosh/platform/net/arp"
+	boshdns "bosh/platform/net/dns"
 	boship "bosh/platform/net/ip"
 	boshsettings "bosh/settings"
 	boshsys "bosh/system"
@@ -24,6 +24,8 @@ type centosNetManager struct {
 	routesSearcher     RoutesSearcher
 	ipResolver         boship.IPResolver
 	addressBroadcaster bosharp.AddressBroadcaster
+	dnsManager         boshdns.Manager
+	configBackup       networkConfigBackup
 	logger             boshlog.Logger
 }
 
@@ -33,6 +35,8 @@ func NewCentosNetManager(
 	defaultNetworkResolver DefaultNetworkResolver,
 	ipResolver boship.IPResolver,
 	addressBroadcaster bosharp.AddressBroadcaster,
+	dnsManager boshdns.Manager,
+	configBackupPath string,
 	logger boshlog.Logger,
 ) centosNetManager {
 	return centosNetManager{
@@ -41,17 +45,15 @@ func NewCentosNetManager(
 		cmdRunner:          cmdRunner,
 		ipResolver:         ipResolver,
 		addressBroadcaster: addressBroadcaster,
+		dnsManager:         dnsManager,
+		configBackup:       newNetworkConfigBackup(fs, configBackupPath),
 		logger:             logger,
 	}
 }
 
-func (net centosNetManager) getDNSServers(networks boshsettings.Networks) []string {
-	dnsNetwork, _ := networks.DefaultNetworkFor("dns")
-	return dnsNetwork.DNS
-}
-
 func (net centosNetManager) SetupDhcp(networks boshsettings.Networks, errCh chan error) error {
-	dnsNetwork, _ := networks.DefaultNetworkFor("dns")
+	// dhclient rewrites resolv.conf on every lease so it has to list caching resolver as well
+	dnsServers := net.dnsManager.Nameservers(boshdns.NewConfigFromNetworks(networks))
 
 	type dhcpConfigArg struct {
 		DNSServers []string
@@ -60,7 +62,7 @@ func (net centosNetManager) SetupDhcp(networks boshsettings.Networks, errCh chan
 	buffer := bytes.NewBuffer([]byte{})
 	t := template.Must(template.New("dhcp-config").Parse(centosDHCPConfigTemplate))
 
-	err := t.Execute(buffer, dhcpConfigArg{dnsNetwork.DNS})
+	err := t.Execute(buffer, dhcpConfigArg{dnsServers})
 	if err != nil {
 		return bosherr.WrapError(err, "Generating config from template")
 	}
@@ -106,16 +108,26 @@ request subnet-mask, broadcast-address, time-offset, routers,
 {{ end }}`
 
 func (net centosNetManager) SetupManualNetworking(networks boshsettings.Networks, errCh chan error) error {
-	modifiedNetworks, err := net.writeIfcfgs(networks)
+	modifiedNetworks, err := buildCustomNetworks(networks, net.fs)
+	if err != nil {
+		return bosherr.WrapError(err, "Building network interfaces")
+	}
+
+	err = net.configBackup.Save(net.configPaths(modifiedNetworks))
+	if err != nil {
+		return bosherr.WrapError(err, "Backing up network configuration")
+	}
+
+	changedNetworks, err := net.writeIfcfgs(modifiedNetworks)
 	if err != nil {
 		return bosherr.WrapError(err, "Writing network interfaces")
 	}
 
-	net.restartNetwork()
+	net.restartNetworkingInterfaces(changedNetworks)
 
-	err = net.writeResolvConf(networks)
+	err = net.dnsManager.Configure(boshdns.NewConfigFromNetworks(networks))
 	if err != nil {
-		return bosherr.WrapError(err, "Writing resolv.conf")
+		return bosherr.WrapError(err, "Configuring DNS")
 	}
 
 	addresses := toInterfaceAddresses(modifiedNetworks)
@@ -130,100 +142,195 @@ func (net centosNetManager) SetupManualNetworking(networks boshsettings.Networks
 	return nil
 }
 
-func (net centosNetManager) writeIfcfgs(networks boshsettings.Networks) ([]customNetwork, error) {
-	var modifiedNetworks []customNetwork
+func (net centosNetManager) CommitManualNetworking() error {
+	return net.configBackup.Discard()
+}
 
-	macAddresses, err := net.detectMacAddresses()
+func (net centosNetManager) HasUncommittedManualNetworking() (bool, error) {
+	return net.configBackup.Exists()
+}
+
+func (net centosNetManager) RollbackManualNetworking() error {
+	restored, err := net.configBackup.Restore()
 	if err != nil {
-		return modifiedNetworks, bosherr.WrapError(err, "Detecting mac addresses")
+		return bosherr.WrapError(err, "Restoring network configuration")
 	}
 
-	for _, aNet := range networks {
-		var network, broadcast string
-		network, broadcast, err = boshsys.CalculateNetworkAndBroadcast(aNet.IP, aNet.Netmask)
+	if restored {
+		net.restartNetwork()
+	}
+
+	return nil
+}
+
+// configPaths returns paths of all files that could be
+// modified when configuring given networks
+func (net centosNetManager) configPaths(networks []customNetwork) []string {
+	paths := []string{"/etc/resolv.conf"}
+
+	for _, network := range networks {
+		for _, interfaceName := range network.interfaceNames() {
+			paths = append(paths, net.ifcfgPath(interfaceName))
+		}
+
+		paths = append(paths, net.routePath(network.Interface))
+	}
+
+	return paths
+}
+
+// writeIfcfgs returns networks which ifcfg or route files were changed
+func (net centosNetManager) writeIfcfgs(networks []customNetwork) ([]customNetwork, error) {
+	var changedNetworks []customNetwork
+
+	for _, network := range networks {
+		changed, err := net.writeNetworkIfcfgs(network)
 		if err != nil {
-			return modifiedNetworks, bosherr.WrapError(err, "Calculating network and broadcast")
+			return changedNetworks, err
 		}
 
-		newNet := customNetwork{
-			aNet,
-			macAddresses[aNet.Mac],
-			network,
-			broadcast,
-			true,
+		if changed {
+			changedNetworks = append(changedNetworks, network)
 		}
-		modifiedNetworks = append(modifiedNetworks, newNet)
+	}
+
+	return changedNetworks, nil
+}
 
-		buffer := bytes.NewBuffer([]byte{})
-		t := template.Must(template.New("ifcfg").Parse(centosIfcgfTemplate))
+func (net centosNetManager) writeNetworkIfcfgs(network customNetwork) (bool, error) {
+	var changed bool
 
-		err = t.Execute(buffer, newNet)
+	for _, slaveInterface := range network.SlaveInterfaces {
+		slave := centosSlaveIfcfgArg{Interface: slaveInterface, Master: network.BondInterface}
+
+		written, err := net.convergeTemplate(net.ifcfgPath(slaveInterface), centosSlaveIfcfgTemplate, slave)
 		if err != nil {
-			return modifiedNetworks, bosherr.WrapError(err, "Generating config from template")
+			return changed, err
 		}
 
-		err = net.fs.WriteFile(filepath.Join("/etc/sysconfig/network-scripts", "ifcfg-"+newNet.Interface), buffer.Bytes())
+		changed = changed || written
+	}
+
+	if network.BondInterface != "" && network.VLANRawDevice != "" {
+		written, err := net.convergeTemplate(net.ifcfgPath(network.BondInterface), centosBondIfcfgTemplate, network)
 		if err != nil {
-			return modifiedNetworks, bosherr.WrapError(err, "Writing to /etc/sysconfig/network-scripts")
+			return changed, err
 		}
+
+		changed = changed || written
 	}
 
-	return modifiedNetworks, nil
-}
+	written, err := net.convergeTemplate(net.ifcfgPath(network.Interface), centosIfcgfTemplate, network)
+	if err != nil {
+		return changed, err
+	}
 
-const centosIfcgfTemplate = `DEVICE={{ .Interface }}
-BOOTPROTO=static
-IPADDR={{ .IP }}
-NETMASK={{ .Netmask }}
-BROADCAST={{ .Broadcast }}
-{{ if .HasDefaultGateway }}GATEWAY={{ .Gateway }}{{ end }}
-ONBOOT=yes`
+	changed = changed || written
+
+	routePath := net.routePath(network.Interface)
+
+	if len(network.Routes) > 0 {
+		written, err = net.convergeTemplate(routePath, centosRouteTemplate, network)
+		if err != nil {
+			return changed, err
+		}
+
+		changed = changed || written
+	} else if net.fs.FileExists(routePath) {
+		err = net.fs.RemoveAll(routePath)
+		if err != nil {
+			return changed, bosherr.WrapError(err, "Removing %s", routePath)
+		}
 
-func (net centosNetManager) writeResolvConf(networks boshsettings.Networks) error {
+		changed = true
+	}
+
+	return changed, nil
+}
+
+func (net centosNetManager) convergeTemplate(path, templateText string, data interface{}) (bool, error) {
 	buffer := bytes.NewBuffer([]byte{})
-	t := template.Must(template.New("resolv-conf").Parse(centosResolvConfTemplate))
+	t := template.Must(template.New(filepath.Base(path)).Parse(templateText))
 
-	dnsServers := net.getDNSServers(networks)
-	dnsServersArg := dnsConfigArg{dnsServers}
-	err := t.Execute(buffer, dnsServersArg)
+	err := t.Execute(buffer, data)
 	if err != nil {
-		return bosherr.WrapError(err, "Generating config from template")
+		return false, bosherr.WrapError(err, "Generating config from template")
 	}
 
-	err = net.fs.WriteFile("/etc/resolv.conf", buffer.Bytes())
+	written, err := net.fs.ConvergeFileContents(path, buffer.Bytes())
 	if err != nil {
-		return bosherr.WrapError(err, "Writing to /etc/resolv.conf")
+		return false, bosherr.WrapError(err, "Writing to %s", path)
 	}
 
-	return nil
+	return written, nil
 }
 
-const centosResolvConfTemplate = `# Generated by bosh-agent
-{{ range .DNSServers }}nameserver {{ . }}
-{{ end }}`
+func (net centosNetManager) ifcfgPath(interfaceName string) string {
+	return filepath.Join(centosNetworkScriptsDir, "ifcfg-"+interfaceName)
+}
 
-func (net centosNetManager) detectMacAddresses() (map[string]string, error) {
-	addresses := map[string]string{}
+func (net centosNetManager) routePath(interfaceName string) string {
+	return filepath.Join(centosNetworkScriptsDir, "route-"+interfaceName)
+}
 
-	filePaths, err := net.fs.Glob("/sys/class/net/*")
-	if err != nil {
-		return addresses, bosherr.WrapError(err, "Getting file list from /sys/class/net")
-	}
+const centosNetworkScriptsDir = "/etc/sysconfig/network-scripts"
 
-	var macAddress string
-	for _, filePath := range filePaths {
-		macAddress, err = net.fs.ReadFileString(filepath.Join(filePath, "address"))
-		if err != nil {
-			return addresses, bosherr.WrapError(err, "Reading mac address from file")
-		}
+const centosIfcgfTemplate = `DEVICE={{ .Interface }}
+{{ if .IP }}BOOTPROTO=static
+IPADDR={{ .IP }}
+NETMASK={{ .Netmask }}
+BROADCAST={{ .Broadcast }}
+{{ if .HasDefaultGateway }}GATEWAY={{ .Gateway }}{{ end }}{{ else }}BOOTPROTO=none{{ end }}{{ if .HasIPv6 }}
+IPV6INIT=yes{{ if .IsIPv6Manual }}
+IPV6_AUTOCONF=no
+IPV6ADDR={{ .IPv6 }}/{{ .IPv6Prefix }}{{ if .IPv6Gateway }}
+IPV6_DEFAULTGW={{ .IPv6Gateway }}{{ end }}{{ else if .IsIPv6SLAAC }}
+IPV6_AUTOCONF=yes{{ else if .IsIPv6DHCP }}
+IPV6_AUTOCONF=no
+DHCPV6C=yes{{ end }}{{ end }}{{ if .MTU }}
+MTU={{ .MTU }}{{ end }}{{ if .VLANRawDevice }}
+VLAN=yes{{ else if .BondInterface }}
+BONDING_MASTER=yes
+BONDING_OPTS="mode={{ .Bond.Mode }} miimon={{ .Bond.MIIMon }}"{{ end }}
+ONBOOT=yes`
 
-		macAddress = strings.Trim(macAddress, "\n")
+const centosBondIfcfgTemplate = `DEVICE={{ .BondInterface }}
+BOOTPROTO=none
+BONDING_MASTER=yes
+BONDING_OPTS="mode={{ .Bond.Mode }} miimon={{ .Bond.MIIMon }}"
+ONBOOT=yes`
 
-		interfaceName := filepath.Base(filePath)
-		addresses[macAddress] = interfaceName
-	}
+type centosSlaveIfcfgArg struct {
+	Interface string
+	Master    string
+}
+
+const centosSlaveIfcfgTemplate = `DEVICE={{ .Interface }}
+BOOTPROTO=none
+MASTER={{ .Master }}
+SLAVE=yes
+ONBOOT=yes`
 
-	return addresses, nil
+// Static routes file - /etc/sysconfig/network-scripts/route-<interface>
+const centosRouteTemplate = `{{ range $i, $route := .Routes }}ADDRESS{{ $i }}={{ $route.Destination }}
+NETMASK{{ $i }}={{ $route.Netmask }}
+GATEWAY{{ $i }}={{ $route.Gateway }}
+{{ end }}`
+
+func (net centosNetManager) restartNetworkingInterfaces(networks []customNetwork) {
+	for _, network := range networks {
+		for _, interfaceName := range network.interfaceNames() {
+			_, _, _, err := net.cmdRunner.RunCommand("ifdown", interfaceName)
+			if err != nil {
+				net.logger.Info(centosNetManagerLogTag, "Ignoring ifdown failure: %#v", err)
+			}
+
+			_, _, _, err = net.cmdRunner.RunCommand("ifup", interfaceName)
+			if err != nil {
+				net.logger.Info(centosNetManagerLogTag, "Ignoring ifup failure: %#v", err)
+			}
+		}
+	}
 }
 
 func (net centosNetManager) restartNetwork() {
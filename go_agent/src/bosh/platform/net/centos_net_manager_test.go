@@ -7,6 +7,8 @@ import (
 	boshlog "bosh/logger"
 	. "bosh/platform/net"
 	fakearp "bosh/platform/net/arp/fakes"
+	boshdns "bosh/platform/net/dns"
+	fakedns "bosh/platform/net/dns/fakes"
 	fakenet "bosh/platform/net/fakes"
 	boship "bosh/platform/net/ip"
 	fakeip "bosh/platform/net/ip/fakes"
@@ -42,6 +44,67 @@ IPADDR=192.168.195.6
 NETMASK=255.255.255.0
 BROADCAST=192.168.195.255
 GATEWAY=192.168.195.1
+ONBOOT=yes`
+
+	const expectedCentosDualStackIfcfg = `DEVICE=eth0
+BOOTPROTO=static
+IPADDR=192.168.195.6
+NETMASK=255.255.255.0
+BROADCAST=192.168.195.255
+GATEWAY=192.168.195.1
+IPV6INIT=yes
+IPV6_AUTOCONF=no
+IPV6ADDR=2001:db8::10/64
+IPV6_DEFAULTGW=2001:db8::1
+ONBOOT=yes`
+
+	const expectedCentosMTUIfcfg = `DEVICE=eth0
+BOOTPROTO=static
+IPADDR=192.168.195.6
+NETMASK=255.255.255.0
+BROADCAST=192.168.195.255
+GATEWAY=192.168.195.1
+MTU=9000
+ONBOOT=yes`
+
+	const expectedCentosRoutes = `ADDRESS0=10.0.0.0
+NETMASK0=255.0.0.0
+GATEWAY0=192.168.195.2
+ADDRESS1=172.16.0.0
+NETMASK1=255.240.0.0
+GATEWAY1=192.168.195.3
+`
+
+	const expectedCentosBondIfcfg = `DEVICE=bond0
+BOOTPROTO=static
+IPADDR=192.168.195.6
+NETMASK=255.255.255.0
+BROADCAST=192.168.195.255
+GATEWAY=192.168.195.1
+BONDING_MASTER=yes
+BONDING_OPTS="mode=active-backup miimon=100"
+ONBOOT=yes`
+
+	const expectedCentosSlaveIfcfg = `DEVICE=eth1
+BOOTPROTO=none
+MASTER=bond0
+SLAVE=yes
+ONBOOT=yes`
+
+	const expectedCentosVLANIfcfg = `DEVICE=eth0.100
+BOOTPROTO=static
+IPADDR=192.168.195.6
+NETMASK=255.255.255.0
+BROADCAST=192.168.195.255
+GATEWAY=192.168.195.1
+VLAN=yes
+ONBOOT=yes`
+
+	const expectedCentosDHCPv6Ifcfg = `DEVICE=eth0
+BOOTPROTO=none
+IPV6INIT=yes
+IPV6_AUTOCONF=no
+DHCPV6C=yes
 ONBOOT=yes`
 
 	Describe("centos", func() {
@@ -67,6 +130,8 @@ ONBOOT=yes`
 				defaultNetworkResolver,
 				ipResolver,
 				addressBroadcaster,
+				boshdns.NewResolvConfManager(fs, nil, logger),
+				"/fake-network-config-backup.json",
 				logger,
 			)
 		})
@@ -170,6 +235,33 @@ ONBOOT=yes`
 
 				ItBroadcastsMACAddresses()
 			})
+
+			Context("when caching resolver is enabled", func() {
+				BeforeEach(func() {
+					logger := boshlog.NewLogger(boshlog.LevelNone)
+					cachingResolver := &fakedns.FakeCachingResolver{ListenIPIP: "127.0.0.1"}
+
+					netManager = NewCentosNetManager(
+						fs,
+						cmdRunner,
+						defaultNetworkResolver,
+						ipResolver,
+						addressBroadcaster,
+						boshdns.NewResolvConfManager(fs, cachingResolver, logger),
+						"/fake-network-config-backup.json",
+						logger,
+					)
+				})
+
+				It("prepends caching resolver instead of configured servers so that dhclient keeps using it", func() {
+					err := netManager.SetupDhcp(networks, nil)
+					Expect(err).ToNot(HaveOccurred())
+
+					dhcpConfig := fs.GetFileTestStat("/etc/dhcp/dhclient.conf")
+					Expect(dhcpConfig.StringContents()).To(ContainSubstring("\nprepend domain-name-servers 127.0.0.1;\n"))
+					Expect(dhcpConfig.StringContents()).ToNot(ContainSubstring("xx.xx.xx.xx"))
+				})
+			})
 		})
 
 		Describe("SetupManualNetworking", func() {
@@ -222,8 +314,21 @@ ONBOOT=yes`
 
 				<-errCh // wait for all arpings
 
-				Expect(len(cmdRunner.RunCommands)).To(Equal(1))
-				Expect(cmdRunner.RunCommands[0]).To(Equal([]string{"service", "network", "restart"}))
+				Expect(cmdRunner.RunCommands).To(Equal([][]string{
+					{"ifdown", "eth0"},
+					{"ifup", "eth0"},
+				}))
+			})
+
+			It("does not restart networking when configuration did not change", func() {
+				fs.WriteFileString("/etc/sysconfig/network-scripts/ifcfg-eth0", expectedCentosIfcfg)
+
+				err := netManager.SetupManualNetworking(networks, errCh)
+				Expect(err).ToNot(HaveOccurred())
+
+				<-errCh // wait for all arpings
+
+				Expect(cmdRunner.RunCommands).To(BeEmpty())
 			})
 
 			It("starts broadcasting the MAC addresses", func() {
@@ -239,6 +344,236 @@ ONBOOT=yes`
 					boship.NewSimpleInterfaceAddress("eth0", "192.168.195.6"),
 				}))
 			})
+
+			It("sets up centos ifconfig with manual IPv6 configuration", func() {
+				dualStackNetworks := boshsettings.Networks{
+					"bosh": boshsettings.Network{
+						Default:     []string{"dns", "gateway"},
+						IP:          "192.168.195.6",
+						Netmask:     "255.255.255.0",
+						Gateway:     "192.168.195.1",
+						IPv6Mode:    boshsettings.IPv6ModeManual,
+						IPv6:        "2001:db8::10",
+						IPv6Prefix:  64,
+						IPv6Gateway: "2001:db8::1",
+						Mac:         "22:00:0a:1f:ac:2a",
+					},
+				}
+
+				err := netManager.SetupManualNetworking(dualStackNetworks, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				networkConfig := fs.GetFileTestStat("/etc/sysconfig/network-scripts/ifcfg-eth0")
+				Expect(networkConfig).ToNot(BeNil())
+				Expect(networkConfig.StringContents()).To(Equal(expectedCentosDualStackIfcfg))
+			})
+
+			Context("when network has static routes and mtu", func() {
+				routesNetworks := boshsettings.Networks{
+					"bosh": boshsettings.Network{
+						Default: []string{"dns", "gateway"},
+						IP:      "192.168.195.6",
+						Netmask: "255.255.255.0",
+						Gateway: "192.168.195.1",
+						Mac:     "22:00:0a:1f:ac:2a",
+						MTU:     9000,
+						Routes: []boshsettings.Route{
+							{Destination: "10.0.0.0", Netmask: "255.0.0.0", Gateway: "192.168.195.2"},
+							{Destination: "172.16.0.0", Netmask: "255.240.0.0", Gateway: "192.168.195.3"},
+						},
+					},
+				}
+
+				It("sets up centos ifconfig with mtu", func() {
+					err := netManager.SetupManualNetworking(routesNetworks, nil)
+					Expect(err).ToNot(HaveOccurred())
+
+					networkConfig := fs.GetFileTestStat("/etc/sysconfig/network-scripts/ifcfg-eth0")
+					Expect(networkConfig).ToNot(BeNil())
+					Expect(networkConfig.StringContents()).To(Equal(expectedCentosMTUIfcfg))
+				})
+
+				It("sets up centos route file", func() {
+					err := netManager.SetupManualNetworking(routesNetworks, nil)
+					Expect(err).ToNot(HaveOccurred())
+
+					routeConfig := fs.GetFileTestStat("/etc/sysconfig/network-scripts/route-eth0")
+					Expect(routeConfig).ToNot(BeNil())
+					Expect(routeConfig.StringContents()).To(Equal(expectedCentosRoutes))
+				})
+
+				It("removes route file and restarts interface when routes are removed", func() {
+					fs.WriteFileString("/etc/sysconfig/network-scripts/ifcfg-eth0", expectedCentosIfcfg)
+					fs.WriteFileString("/etc/sysconfig/network-scripts/route-eth0", expectedCentosRoutes)
+
+					err := netManager.SetupManualNetworking(networks, errCh)
+					Expect(err).ToNot(HaveOccurred())
+
+					<-errCh // wait for all arpings
+
+					Expect(fs.FileExists("/etc/sysconfig/network-scripts/route-eth0")).To(BeFalse())
+					Expect(cmdRunner.RunCommands).To(Equal([][]string{
+						{"ifdown", "eth0"},
+						{"ifup", "eth0"},
+					}))
+				})
+			})
+
+			Context("when network is configured on top of bonded interfaces", func() {
+				BeforeEach(func() {
+					fs.WriteFileString("/sys/class/net/eth1/address", "22:00:0a:1f:ac:2b\n")
+					fs.SetGlob("/sys/class/net/*", []string{"/sys/class/net/eth0", "/sys/class/net/eth1"})
+				})
+
+				bondedNetworks := boshsettings.Networks{
+					"bosh": boshsettings.Network{
+						Default: []string{"dns", "gateway"},
+						IP:      "192.168.195.6",
+						Netmask: "255.255.255.0",
+						Gateway: "192.168.195.1",
+						Bond: boshsettings.Bond{
+							Slaves: []string{"22:00:0a:1f:ac:2a", "22:00:0a:1f:ac:2b"},
+						},
+					},
+				}
+
+				It("sets up centos bond and slave ifconfigs", func() {
+					err := netManager.SetupManualNetworking(bondedNetworks, nil)
+					Expect(err).ToNot(HaveOccurred())
+
+					bondConfig := fs.GetFileTestStat("/etc/sysconfig/network-scripts/ifcfg-bond0")
+					Expect(bondConfig).ToNot(BeNil())
+					Expect(bondConfig.StringContents()).To(Equal(expectedCentosBondIfcfg))
+
+					slaveConfig := fs.GetFileTestStat("/etc/sysconfig/network-scripts/ifcfg-eth1")
+					Expect(slaveConfig).ToNot(BeNil())
+					Expect(slaveConfig.StringContents()).To(Equal(expectedCentosSlaveIfcfg))
+				})
+
+				It("restarts slave and bond interfaces", func() {
+					err := netManager.SetupManualNetworking(bondedNetworks, errCh)
+					Expect(err).ToNot(HaveOccurred())
+
+					<-errCh // wait for all arpings
+
+					Expect(cmdRunner.RunCommands).To(Equal([][]string{
+						{"ifdown", "eth0"},
+						{"ifup", "eth0"},
+						{"ifdown", "eth1"},
+						{"ifup", "eth1"},
+						{"ifdown", "bond0"},
+						{"ifup", "bond0"},
+					}))
+				})
+			})
+
+			It("sets up centos vlan ifconfig", func() {
+				vlanNetworks := boshsettings.Networks{
+					"bosh": boshsettings.Network{
+						Default: []string{"dns", "gateway"},
+						IP:      "192.168.195.6",
+						Netmask: "255.255.255.0",
+						Gateway: "192.168.195.1",
+						Mac:     "22:00:0a:1f:ac:2a",
+						VLAN:    100,
+					},
+				}
+
+				err := netManager.SetupManualNetworking(vlanNetworks, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				networkConfig := fs.GetFileTestStat("/etc/sysconfig/network-scripts/ifcfg-eth0.100")
+				Expect(networkConfig).ToNot(BeNil())
+				Expect(networkConfig.StringContents()).To(Equal(expectedCentosVLANIfcfg))
+			})
+
+			It("sets up centos ifconfig for IPv6 only network configured with DHCPv6", func() {
+				dhcpv6Networks := boshsettings.Networks{
+					"bosh": boshsettings.Network{
+						Default:  []string{"dns", "gateway"},
+						IPv6Mode: boshsettings.IPv6ModeDHCPv6,
+						Mac:      "22:00:0a:1f:ac:2a",
+					},
+				}
+
+				err := netManager.SetupManualNetworking(dhcpv6Networks, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				networkConfig := fs.GetFileTestStat("/etc/sysconfig/network-scripts/ifcfg-eth0")
+				Expect(networkConfig).ToNot(BeNil())
+				Expect(networkConfig.StringContents()).To(Equal(expectedCentosDHCPv6Ifcfg))
+			})
+		})
+
+		Describe("RollbackManualNetworking", func() {
+			BeforeEach(func() {
+				fs.WriteFileString("/proc/sys/kernel/random/boot_id", "fake-boot-id\n")
+				fs.WriteFileString("/sys/class/net/eth0/address", "22:00:0a:1f:ac:2a\n")
+				fs.SetGlob("/sys/class/net/*", []string{"/sys/class/net/eth0"})
+			})
+
+			routesNetworks := boshsettings.Networks{
+				"bosh": boshsettings.Network{
+					Default: []string{"dns", "gateway"},
+					IP:      "192.168.195.6",
+					Netmask: "255.255.255.0",
+					Gateway: "192.168.195.1",
+					Mac:     "22:00:0a:1f:ac:2a",
+					Routes: []boshsettings.Route{
+						{Destination: "10.0.0.0", Netmask: "255.0.0.0", Gateway: "192.168.195.2"},
+					},
+				},
+			}
+
+			It("restores ifcfg files and removes newly created route files", func() {
+				fs.WriteFileString("/etc/sysconfig/network-scripts/ifcfg-eth0", "fake-previous-ifcfg")
+
+				err := netManager.SetupManualNetworking(routesNetworks, nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fs.FileExists("/etc/sysconfig/network-scripts/route-eth0")).To(BeTrue())
+
+				err = netManager.RollbackManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+
+				networkConfig := fs.GetFileTestStat("/etc/sysconfig/network-scripts/ifcfg-eth0")
+				Expect(networkConfig.StringContents()).To(Equal("fake-previous-ifcfg"))
+				Expect(fs.FileExists("/etc/sysconfig/network-scripts/route-eth0")).To(BeFalse())
+			})
+
+			It("restarts networking after restoring configuration", func() {
+				err := netManager.SetupManualNetworking(routesNetworks, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				cmdRunner.RunCommands = [][]string{}
+
+				err = netManager.RollbackManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(cmdRunner.RunCommands).To(Equal([][]string{{"service", "network", "restart"}}))
+			})
+
+			It("does not restart networking when there is nothing to restore", func() {
+				err := netManager.RollbackManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(cmdRunner.RunCommands).To(BeEmpty())
+			})
+
+			It("does not restore configuration that was committed", func() {
+				err := netManager.SetupManualNetworking(routesNetworks, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = netManager.CommitManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+
+				cmdRunner.RunCommands = [][]string{}
+
+				err = netManager.RollbackManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fs.FileExists("/etc/sysconfig/network-scripts/route-eth0")).To(BeTrue())
+				Expect(cmdRunner.RunCommands).To(BeEmpty())
+			})
 		})
 	})
 }
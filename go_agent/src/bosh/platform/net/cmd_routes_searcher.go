@@ -7,7 +7,7 @@ import (
 	boshsys "bosh/system"
 )
 
-// cmdRoutesSearcher uses `route -n` command to list routes
+// cmdRoutesSearcher uses `route -n` (and `route -A inet6 -n`) command to list routes
 // which routes in a same format on Ubuntu and CentOS
 type cmdRoutesSearcher struct {
 	runner boshsys.CmdRunner
@@ -45,3 +45,32 @@ func (s cmdRoutesSearcher) SearchRoutes() ([]Route, error) {
 
 	return routes, nil
 }
+
+func (s cmdRoutesSearcher) SearchIPv6Routes() ([]Route, error) {
+	var routes []Route
+
+	stdout, _, _, err := s.runner.RunCommand("route", "-A", "inet6", "-n")
+	if err != nil {
+		return routes, bosherr.WrapError(err, "Running route for IPv6")
+	}
+
+	for i, routeEntry := range strings.Split(stdout, "\n") {
+		if i < 2 { // first two lines are informational
+			continue
+		}
+
+		if routeEntry == "" {
+			continue
+		}
+
+		routeFields := strings.Fields(routeEntry)
+
+		routes = append(routes, Route{
+			Destination:   routeFields[0],
+			Gateway:       routeFields[1],
+			InterfaceName: routeFields[6],
+		})
+	}
+
+	return routes, nil
+}
@@ -68,4 +68,60 @@ Destination     Gateway         Genmask         Flags Metric Ref    Use Iface
 			})
 		})
 	})
+
+	Describe("SearchIPv6Routes", func() {
+		Context("when running command succeeds", func() {
+			It("returns parsed routes information", func() {
+				runner.AddCmdResult("route -A inet6 -n", fakesys.FakeCmdResult{
+					Stdout: `Kernel IPv6 routing table
+Destination                    Next Hop                   Flag Met Ref Use If
+2001:db8::/64                  ::                         U    256 0     0 eth0
+fe80::/64                      ::                         U    256 0     0 eth0
+::/0                           2001:db8::1                UG   1024 0     0 eth0
+`,
+				})
+
+				routes, err := searcher.SearchIPv6Routes()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(routes).To(Equal([]Route{
+					Route{Destination: "2001:db8::/64", Gateway: "::", InterfaceName: "eth0"},
+					Route{Destination: "fe80::/64", Gateway: "::", InterfaceName: "eth0"},
+					Route{Destination: "::/0", Gateway: "2001:db8::1", InterfaceName: "eth0"},
+				}))
+			})
+
+			It("does not consider unreachable route on loopback interface to be default", func() {
+				runner.AddCmdResult("route -A inet6 -n", fakesys.FakeCmdResult{
+					Stdout: `Kernel IPv6 routing table
+Destination                    Next Hop                   Flag Met Ref Use If
+::/0                           ::                         !n   -1  1     1 lo
+::1/128                        ::                         Un   0   1     0 lo
+::/0                           2001:db8::1                UG   1024 0     0 eth0
+`,
+				})
+
+				routes, err := searcher.SearchIPv6Routes()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(routes).To(HaveLen(3))
+
+				Expect(routes[0]).To(Equal(Route{Destination: "::/0", Gateway: "::", InterfaceName: "lo"}))
+				Expect(routes[0].IsDefault()).To(BeFalse())
+				Expect(routes[1].IsDefault()).To(BeFalse())
+				Expect(routes[2].IsDefault()).To(BeTrue())
+			})
+		})
+
+		Context("when running route command fails", func() {
+			It("returns error", func() {
+				runner.AddCmdResult("route -A inet6 -n", fakesys.FakeCmdResult{
+					Error: errors.New("fake-run-err"),
+				})
+
+				routes, err := searcher.SearchIPv6Routes()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-run-err"))
+				Expect(routes).To(BeEmpty())
+			})
+		})
+	})
 })
@@ -0,0 +1,114 @@
+package net
+
+import (
+	gonet "net"
+	"net/url"
+	"time"
+
+	bosherr "bosh/errors"
+	boshlog "bosh/logger"
+	boshsettings "bosh/settings"
+	boshsys "bosh/system"
+)
+
+const connectivityVerifierLogTag = "connectivityVerifier"
+
+const connectivityVerifierDialTimeout = 5 * time.Second
+
+var defaultEndpointPorts = map[string]string{
+	"nats":  "4222",
+	"http":  "80",
+	"https": "443",
+}
+
+type connectivityVerifier struct {
+	cmdRunner boshsys.CmdRunner
+
+	timeout    time.Duration
+	retryDelay time.Duration
+
+	logger boshlog.Logger
+}
+
+func NewConnectivityVerifier(
+	cmdRunner boshsys.CmdRunner,
+	timeout time.Duration,
+	retryDelay time.Duration,
+	logger boshlog.Logger,
+) connectivityVerifier {
+	return connectivityVerifier{
+		cmdRunner:  cmdRunner,
+		timeout:    timeout,
+		retryDelay: retryDelay,
+		logger:     logger,
+	}
+}
+
+func (v connectivityVerifier) Verify(networks boshsettings.Networks, endpoints []string) error {
+	deadline := time.Now().Add(v.timeout)
+
+	for {
+		err := v.verify(networks, endpoints)
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return bosherr.WrapError(err, "Timed out verifying network connectivity")
+		}
+
+		v.logger.Debug(connectivityVerifierLogTag, "Retrying network verification: %s", err.Error())
+
+		time.Sleep(v.retryDelay)
+	}
+}
+
+func (v connectivityVerifier) verify(networks boshsettings.Networks, endpoints []string) error {
+	gatewayNetwork, found := networks.DefaultNetworkFor("gateway")
+	if found && gatewayNetwork.Gateway != "" {
+		_, _, _, err := v.cmdRunner.RunCommand("ping", "-c", "1", "-W", "1", gatewayNetwork.Gateway)
+		if err != nil {
+			return bosherr.WrapError(err, "Reaching gateway %s", gatewayNetwork.Gateway)
+		}
+	}
+
+	for _, endpoint := range endpoints {
+		err := v.verifyEndpoint(endpoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v connectivityVerifier) verifyEndpoint(endpoint string) error {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return bosherr.WrapError(err, "Parsing endpoint")
+	}
+
+	host, port, err := gonet.SplitHostPort(endpointURL.Host)
+	if err != nil {
+		host = endpointURL.Host
+		port = defaultEndpointPorts[endpointURL.Scheme]
+	}
+
+	if host == "" || port == "" {
+		return bosherr.New("Unknown host or port for endpoint %s://%s", endpointURL.Scheme, endpointURL.Host)
+	}
+
+	if gonet.ParseIP(host) == nil {
+		_, err = gonet.LookupHost(host)
+		if err != nil {
+			return bosherr.WrapError(err, "Resolving %s", host)
+		}
+	}
+
+	conn, err := gonet.DialTimeout("tcp", gonet.JoinHostPort(host, port), connectivityVerifierDialTimeout)
+	if err != nil {
+		return bosherr.WrapError(err, "Connecting to %s", gonet.JoinHostPort(host, port))
+	}
+
+	return conn.Close()
+}
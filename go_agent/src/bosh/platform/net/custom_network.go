@@ -1,13 +1,22 @@
 package net
 
 import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	bosherr "bosh/errors"
 	boship "bosh/platform/net/ip"
 	boshsettings "bosh/settings"
+	boshsys "bosh/system"
 )
 
-type dnsConfigArg struct {
-	DNSServers []string
-}
+const (
+	defaultBondInterface = "bond0"
+	defaultBondMode      = "active-backup"
+	defaultBondMIIMon    = 100
+)
 
 type customNetwork struct {
 	boshsettings.Network
@@ -15,16 +24,156 @@ type customNetwork struct {
 	NetworkIP         string
 	Broadcast         string
 	HasDefaultGateway bool
+
+	// BondInterface is set when network is configured on top of bonded interfaces
+	BondInterface   string
+	SlaveInterfaces []string
+
+	// VLANRawDevice is set when Interface is a VLAN interface
+	VLANRawDevice string
 }
 
 func (c customNetwork) ToInterfaceAddress() boship.InterfaceAddress {
 	return boship.NewSimpleInterfaceAddress(c.Interface, c.IP)
 }
 
-// toInterfaceAddresses bulk converts customNetworks to InterfaceAddresses
+func (c customNetwork) ToIPv6InterfaceAddress() boship.InterfaceAddress {
+	return boship.NewSimpleInterfaceAddress(c.Interface, c.IPv6)
+}
+
+// toInterfaceAddresses bulk converts customNetworks to InterfaceAddresses.
+// Addresses assigned via SLAAC or DHCPv6 are announced by the kernel and are not included.
 func toInterfaceAddresses(networks []customNetwork) (addresses []boship.InterfaceAddress) {
 	for _, network := range networks {
-		addresses = append(addresses, network.ToInterfaceAddress())
+		if network.IP != "" {
+			addresses = append(addresses, network.ToInterfaceAddress())
+		}
+		if network.IsIPv6Manual() {
+			addresses = append(addresses, network.ToIPv6InterfaceAddress())
+		}
 	}
 	return
 }
+
+// interfaceNames returns all interfaces that need to be brought up
+// for the network in the order they should be brought up
+func (c customNetwork) interfaceNames() []string {
+	names := append([]string{}, c.SlaveInterfaces...)
+
+	if c.BondInterface != "" && c.BondInterface != c.Interface {
+		names = append(names, c.BondInterface)
+	}
+
+	return append(names, c.Interface)
+}
+
+// newCustomNetwork resolves network interface names from mac addresses
+// and calculates IPv4 network and broadcast addresses
+// unless network only has IPv6 configuration
+func newCustomNetwork(network boshsettings.Network, macAddresses map[string]string) (customNetwork, error) {
+	newNet := customNetwork{
+		Network:           network,
+		Interface:         macAddresses[network.Mac],
+		HasDefaultGateway: true,
+	}
+
+	if network.IsBonded() {
+		newNet.BondInterface = network.Bond.Name
+		if newNet.BondInterface == "" {
+			newNet.BondInterface = defaultBondInterface
+		}
+
+		if newNet.Bond.Mode == "" {
+			newNet.Bond.Mode = defaultBondMode
+		}
+
+		if newNet.Bond.MIIMon == 0 {
+			newNet.Bond.MIIMon = defaultBondMIIMon
+		}
+
+		for _, slaveMac := range network.Bond.Slaves {
+			slaveInterface, found := macAddresses[slaveMac]
+			if !found {
+				return newNet, bosherr.New("Unknown bond slave mac address %s", slaveMac)
+			}
+
+			newNet.SlaveInterfaces = append(newNet.SlaveInterfaces, slaveInterface)
+		}
+
+		newNet.Interface = newNet.BondInterface
+	}
+
+	if network.VLAN > 0 {
+		newNet.VLANRawDevice = newNet.Interface
+		newNet.Interface = fmt.Sprintf("%s.%d", newNet.Interface, network.VLAN)
+	}
+
+	if network.IP == "" && network.HasIPv6() {
+		return newNet, nil
+	}
+
+	networkIP, broadcast, err := boshsys.CalculateNetworkAndBroadcast(network.IP, network.Netmask)
+	if err != nil {
+		return newNet, bosherr.WrapError(err, "Calculating network and broadcast")
+	}
+
+	newNet.NetworkIP = networkIP
+	newNet.Broadcast = broadcast
+
+	return newNet, nil
+}
+
+// buildCustomNetworks resolves network interfaces of all networks.
+// Networks are kept in stable order so that configuration is written and reloaded deterministically.
+func buildCustomNetworks(networks boshsettings.Networks, fs boshsys.FileSystem) ([]customNetwork, error) {
+	var modifiedNetworks []customNetwork
+
+	macAddresses, err := detectMacAddresses(fs)
+	if err != nil {
+		return modifiedNetworks, bosherr.WrapError(err, "Detecting mac addresses")
+	}
+
+	for _, aNet := range networks {
+		newNet, err := newCustomNetwork(aNet, macAddresses)
+		if err != nil {
+			return modifiedNetworks, err
+		}
+
+		modifiedNetworks = append(modifiedNetworks, newNet)
+	}
+
+	sort.Sort(customNetworksByInterface(modifiedNetworks))
+
+	return modifiedNetworks, nil
+}
+
+// detectMacAddresses returns interface names keyed by their mac addresses
+func detectMacAddresses(fs boshsys.FileSystem) (map[string]string, error) {
+	addresses := map[string]string{}
+
+	filePaths, err := fs.Glob("/sys/class/net/*")
+	if err != nil {
+		return addresses, bosherr.WrapError(err, "Getting file list from /sys/class/net")
+	}
+
+	var macAddress string
+	for _, filePath := range filePaths {
+		macAddress, err = fs.ReadFileString(filepath.Join(filePath, "address"))
+		if err != nil {
+			return addresses, bosherr.WrapError(err, "Reading mac address from file")
+		}
+
+		macAddress = strings.Trim(macAddress, "\n")
+
+		interfaceName := filepath.Base(filePath)
+		addresses[macAddress] = interfaceName
+	}
+
+	return addresses, nil
+}
+
+type customNetworksByInterface []customNetwork
+
+func (s customNetworksByInterface) Len() int           { return len(s) }
+func (s customNetworksByInterface) Less(i, j int) bool { return s[i].Interface < s[j].Interface }
+func (s customNetworksByInterface) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -31,10 +31,33 @@ func (r defaultNetworkResolver) GetDefaultNetwork() (boshsettings.Network, error
 		return network, bosherr.WrapError(err, "Searching routes")
 	}
 
-	if len(routes) == 0 {
+	// IPv6 might not be enabled on the system hence its failure is not fatal
+	ipv6Routes, ipv6Err := r.routesSearcher.SearchIPv6Routes()
+
+	if len(routes) == 0 && len(ipv6Routes) == 0 {
 		return network, bosherr.New("No routes found")
 	}
 
+	ipv4Network, ipv4Err := r.getDefaultIPv4Network(routes)
+	if ipv6Err == nil {
+		network, ipv6Err = r.getDefaultIPv6Network(ipv6Routes)
+	}
+
+	if ipv4Err != nil {
+		if ipv6Err != nil {
+			return boshsettings.Network{}, ipv4Err
+		}
+		return network, nil
+	}
+
+	ipv4Network.IPv6 = network.IPv6
+	ipv4Network.IPv6Prefix = network.IPv6Prefix
+	ipv4Network.IPv6Gateway = network.IPv6Gateway
+
+	return ipv4Network, nil
+}
+
+func (r defaultNetworkResolver) getDefaultIPv4Network(routes []Route) (boshsettings.Network, error) {
 	for _, route := range routes {
 		if !route.IsDefault() {
 			continue
@@ -42,7 +65,7 @@ func (r defaultNetworkResolver) GetDefaultNetwork() (boshsettings.Network, error
 
 		ip, err := r.ipResolver.GetPrimaryIPv4(route.InterfaceName)
 		if err != nil {
-			return network, bosherr.WrapError(
+			return boshsettings.Network{}, bosherr.WrapError(
 				err, "Getting primary IPv4 for interface '%s'", route.InterfaceName)
 		}
 
@@ -51,8 +74,31 @@ func (r defaultNetworkResolver) GetDefaultNetwork() (boshsettings.Network, error
 			Netmask: gonet.IP(ip.Mask).String(),
 			Gateway: route.Gateway,
 		}, nil
+	}
+
+	return boshsettings.Network{}, bosherr.New("Failed to find default route")
+}
 
+func (r defaultNetworkResolver) getDefaultIPv6Network(routes []Route) (boshsettings.Network, error) {
+	for _, route := range routes {
+		if !route.IsDefault() {
+			continue
+		}
+
+		ip, err := r.ipResolver.GetPrimaryIPv6(route.InterfaceName)
+		if err != nil {
+			return boshsettings.Network{}, bosherr.WrapError(
+				err, "Getting primary IPv6 for interface '%s'", route.InterfaceName)
+		}
+
+		prefix, _ := ip.Mask.Size()
+
+		return boshsettings.Network{
+			IPv6:        ip.IP.String(),
+			IPv6Prefix:  prefix,
+			IPv6Gateway: route.Gateway,
+		}, nil
 	}
 
-	return network, bosherr.New("Failed to find default route")
+	return boshsettings.Network{}, bosherr.New("Failed to find default IPv6 route")
 }
@@ -76,6 +76,119 @@ var _ = Describe("defaultNetworkResolver", func() {
 			})
 		})
 
+		Context("when default IPv6 route is found", func() {
+			BeforeEach(func() {
+				routesSearcher.SearchIPv6RoutesRoutes = []Route{
+					Route{ // unreachable route with default destination on loopback interface
+						Destination:   "::/0",
+						Gateway:       "::",
+						InterfaceName: "lo",
+					},
+					Route{ // non-default route
+						Destination:   "2001:db8::/64",
+						Gateway:       "::",
+						InterfaceName: "fake-interface-name",
+					},
+					Route{ // route with default destination
+						Destination:   "::/0",
+						Gateway:       "fake-ipv6-gateway",
+						InterfaceName: "fake-interface-name",
+					},
+				}
+
+				ipResolver.GetPrimaryIPv6IPNet = &gonet.IPNet{
+					IP:   gonet.ParseIP("2001:db8::10"),
+					Mask: gonet.CIDRMask(64, 128),
+				}
+			})
+
+			Context("when default IPv4 route is also found", func() {
+				BeforeEach(func() {
+					routesSearcher.SearchRoutesRoutes = []Route{
+						Route{
+							Destination:   "0.0.0.0",
+							Gateway:       "fake-gateway",
+							InterfaceName: "fake-interface-name",
+						},
+					}
+
+					ipResolver.GetPrimaryIPv4IPNet = &gonet.IPNet{
+						IP:   gonet.ParseIP("127.0.0.1"),
+						Mask: gonet.CIDRMask(16, 32),
+					}
+				})
+
+				It("returns network with primary IPv4 and IPv6 addresses", func() {
+					network, err := resolver.GetDefaultNetwork()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(network).To(Equal(boshsettings.Network{
+						IP:          "127.0.0.1",
+						Netmask:     "255.255.0.0",
+						Gateway:     "fake-gateway",
+						IPv6:        "2001:db8::10",
+						IPv6Prefix:  64,
+						IPv6Gateway: "fake-ipv6-gateway",
+					}))
+
+					Expect(ipResolver.GetPrimaryIPv6InterfaceName).To(Equal("fake-interface-name"))
+				})
+			})
+
+			Context("when default IPv4 route is not found", func() {
+				It("returns network with only primary IPv6 address", func() {
+					network, err := resolver.GetDefaultNetwork()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(network).To(Equal(boshsettings.Network{
+						IPv6:        "2001:db8::10",
+						IPv6Prefix:  64,
+						IPv6Gateway: "fake-ipv6-gateway",
+					}))
+				})
+			})
+
+			Context("when primary IPv6 does not exist for the found route", func() {
+				BeforeEach(func() {
+					ipResolver.GetPrimaryIPv6Err = errors.New("fake-get-primary-ipv6-err")
+				})
+
+				It("returns error for missing default IPv4 route", func() {
+					network, err := resolver.GetDefaultNetwork()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Failed to find default route"))
+					Expect(network).To(Equal(boshsettings.Network{}))
+				})
+			})
+		})
+
+		Context("when searching for IPv6 routes returns error", func() {
+			BeforeEach(func() {
+				routesSearcher.SearchRoutesRoutes = []Route{
+					Route{
+						Destination:   "0.0.0.0",
+						Gateway:       "fake-gateway",
+						InterfaceName: "fake-interface-name",
+					},
+				}
+
+				ipResolver.GetPrimaryIPv4IPNet = &gonet.IPNet{
+					IP:   gonet.ParseIP("127.0.0.1"),
+					Mask: gonet.CIDRMask(16, 32),
+				}
+
+				routesSearcher.SearchIPv6RoutesErr = errors.New("fake-search-ipv6-routes-err")
+			})
+
+			It("returns network with primary IPv4 address", func() {
+				network, err := resolver.GetDefaultNetwork()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(network).To(Equal(boshsettings.Network{
+					IP:      "127.0.0.1",
+					Netmask: "255.255.0.0",
+					Gateway: "fake-gateway",
+				}))
+			})
+		})
+
 		Context("when default route is not found", func() {
 			BeforeEach(func() {
 				routesSearcher.SearchRoutesRoutes = []Route{
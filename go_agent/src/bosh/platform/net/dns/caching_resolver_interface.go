@@ -0,0 +1,19 @@
+package dns
+
+type CachingResolver interface {
+	// Start begins answering queries; calling Start on started resolver is a no-op
+	Start() error
+	Stop() error
+
+	SetUpstreams(servers []string)
+
+	// ListenIP is an address written to resolv.conf
+	ListenIP() string
+
+	Stats() CacheStats
+}
+
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
@@ -0,0 +1,22 @@
+package dns
+
+import (
+	boshsettings "bosh/settings"
+)
+
+type Config struct {
+	Servers []string
+	Search  []string
+	Options []string
+}
+
+// NewConfigFromNetworks uses DNS configuration of the default dns network
+func NewConfigFromNetworks(networks boshsettings.Networks) Config {
+	dnsNetwork, _ := networks.DefaultNetworkFor("dns")
+
+	return Config{
+		Servers: dnsNetwork.DNS,
+		Search:  dnsNetwork.DNSSearch,
+		Options: dnsNetwork.DNSOptions,
+	}
+}
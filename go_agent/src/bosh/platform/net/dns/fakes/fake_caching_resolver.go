@@ -0,0 +1,41 @@
+package fakes
+
+import (
+	boshdns "bosh/platform/net/dns"
+)
+
+type FakeCachingResolver struct {
+	Started  bool
+	StartErr error
+
+	Stopped bool
+	StopErr error
+
+	SetUpstreamsServers []string
+
+	ListenIPIP string
+
+	StatsStats boshdns.CacheStats
+}
+
+func (r *FakeCachingResolver) Start() error {
+	r.Started = true
+	return r.StartErr
+}
+
+func (r *FakeCachingResolver) Stop() error {
+	r.Stopped = true
+	return r.StopErr
+}
+
+func (r *FakeCachingResolver) SetUpstreams(servers []string) {
+	r.SetUpstreamsServers = servers
+}
+
+func (r *FakeCachingResolver) ListenIP() string {
+	return r.ListenIPIP
+}
+
+func (r *FakeCachingResolver) Stats() boshdns.CacheStats {
+	return r.StatsStats
+}
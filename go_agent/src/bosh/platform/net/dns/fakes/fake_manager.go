@@ -0,0 +1,25 @@
+package fakes
+
+import (
+	boshdns "bosh/platform/net/dns"
+)
+
+type FakeManager struct {
+	ConfigureConfig boshdns.Config
+	ConfigureErr    error
+
+	// When nil servers of given configuration are returned
+	NameserversServers []string
+}
+
+func (m *FakeManager) Configure(config boshdns.Config) error {
+	m.ConfigureConfig = config
+	return m.ConfigureErr
+}
+
+func (m *FakeManager) Nameservers(config boshdns.Config) []string {
+	if m.NameserversServers != nil {
+		return m.NameserversServers
+	}
+	return config.Servers
+}
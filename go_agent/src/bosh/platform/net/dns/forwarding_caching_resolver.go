@@ -0,0 +1,515 @@
+package dns
+
+import (
+	"encoding/binary"
+	"io"
+	gonet "net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bosherr "bosh/errors"
+	boshlog "bosh/logger"
+)
+
+const forwardingCachingResolverLogTag = "forwardingCachingResolver"
+
+const (
+	maxUDPMessageLen = 4096
+
+	upstreamTimeout = 2 * time.Second
+
+	// Clients keeping TCP connections open are disconnected after being idle
+	tcpIdleTimeout = 10 * time.Second
+
+	// Upstream TTLs are capped so that changes in DNS records are picked up
+	maxCacheTTL = 5 * time.Minute
+
+	// Once cache is full expired entries are removed first,
+	// then entries that are about to expire
+	maxCacheEntries = 1000
+
+	cacheSweepInterval = 1 * time.Minute
+)
+
+type cacheEntry struct {
+	response  []byte
+	cachedAt  time.Time
+	expiresAt time.Time
+}
+
+type forwardingCachingResolver struct {
+	listenAddr string
+	logger     boshlog.Logger
+
+	hits   uint64
+	misses uint64
+
+	lock        sync.RWMutex
+	upstreams   []string
+	cache       map[string]cacheEntry
+	conn        *gonet.UDPConn
+	tcpListener gonet.Listener
+	stopCh      chan struct{}
+}
+
+// NewForwardingCachingResolver returns stub resolver that forwards
+// queries to upstream servers and caches positive answers.
+// Queries are answered over UDP and TCP on the same address;
+// truncated upstream UDP responses are retried over TCP.
+func NewForwardingCachingResolver(listenAddr string, logger boshlog.Logger) *forwardingCachingResolver {
+	return &forwardingCachingResolver{
+		listenAddr: listenAddr,
+		logger:     logger,
+		cache:      map[string]cacheEntry{},
+	}
+}
+
+func (r *forwardingCachingResolver) Start() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.conn != nil {
+		return nil
+	}
+
+	addr, err := gonet.ResolveUDPAddr("udp", r.listenAddr)
+	if err != nil {
+		return bosherr.WrapError(err, "Resolving listen address")
+	}
+
+	conn, err := gonet.ListenUDP("udp", addr)
+	if err != nil {
+		return bosherr.WrapError(err, "Listening on %s", r.listenAddr)
+	}
+
+	// UDP address is used so that both listen on the same port when port 0 is requested
+	tcpListener, err := gonet.Listen("tcp", conn.LocalAddr().String())
+	if err != nil {
+		conn.Close()
+		return bosherr.WrapError(err, "Listening on %s over TCP", r.listenAddr)
+	}
+
+	r.conn = conn
+	r.tcpListener = tcpListener
+	r.stopCh = make(chan struct{})
+
+	go r.serve(conn)
+	go r.serveTCP(tcpListener)
+	go r.sweepCache(r.stopCh)
+
+	return nil
+}
+
+func (r *forwardingCachingResolver) Stop() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.conn == nil {
+		return nil
+	}
+
+	close(r.stopCh)
+
+	err := r.conn.Close()
+	tcpErr := r.tcpListener.Close()
+
+	r.conn = nil
+	r.tcpListener = nil
+	r.stopCh = nil
+
+	if err != nil {
+		return err
+	}
+
+	return tcpErr
+}
+
+func (r *forwardingCachingResolver) SetUpstreams(servers []string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.upstreams = servers
+
+	// Answers from previous servers may not be valid anymore
+	r.cache = map[string]cacheEntry{}
+}
+
+func (r *forwardingCachingResolver) ListenIP() string {
+	host, _, err := gonet.SplitHostPort(r.listenAddr)
+	if err != nil {
+		return r.listenAddr
+	}
+
+	return host
+}
+
+// ListenAddr returns actual listening address which is useful when port 0 is requested
+func (r *forwardingCachingResolver) ListenAddr() string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.conn == nil {
+		return r.listenAddr
+	}
+
+	return r.conn.LocalAddr().String()
+}
+
+func (r *forwardingCachingResolver) Stats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&r.hits),
+		Misses: atomic.LoadUint64(&r.misses),
+	}
+}
+
+func (r *forwardingCachingResolver) serve(conn *gonet.UDPConn) {
+	for {
+		buf := make([]byte, maxUDPMessageLen)
+
+		n, clientAddr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			r.logger.Debug(forwardingCachingResolverLogTag, "Stopped serving: %s", err.Error())
+			return
+		}
+
+		go r.handle(conn, clientAddr, buf[:n])
+	}
+}
+
+func (r *forwardingCachingResolver) handle(conn *gonet.UDPConn, clientAddr *gonet.UDPAddr, query []byte) {
+	defer r.logger.HandlePanic("DNS Caching Resolver Handle UDP")
+
+	response, err := r.resolve(query, false)
+	if err != nil {
+		r.logger.Debug(forwardingCachingResolverLogTag, "Failed to resolve query from %s: %s", clientAddr, err.Error())
+		return
+	}
+
+	// Client retries over TCP when full response does not fit
+	if len(response) > udpPayloadSize(query) {
+		response, err = truncate(response)
+		if err != nil {
+			r.logger.Debug(forwardingCachingResolverLogTag, "Failed to truncate response to %s: %s", clientAddr, err.Error())
+			return
+		}
+	}
+
+	_, err = conn.WriteToUDP(response, clientAddr)
+	if err != nil {
+		r.logger.Debug(forwardingCachingResolverLogTag, "Failed to respond to %s: %s", clientAddr, err.Error())
+	}
+}
+
+func (r *forwardingCachingResolver) serveTCP(listener gonet.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			r.logger.Debug(forwardingCachingResolverLogTag, "Stopped serving over TCP: %s", err.Error())
+			return
+		}
+
+		go r.handleTCP(conn)
+	}
+}
+
+// handleTCP answers queries until client closes connection or becomes idle
+func (r *forwardingCachingResolver) handleTCP(conn gonet.Conn) {
+	defer r.logger.HandlePanic("DNS Caching Resolver Handle TCP")
+	defer conn.Close()
+
+	for {
+		err := conn.SetDeadline(time.Now().Add(tcpIdleTimeout))
+		if err != nil {
+			return
+		}
+
+		query, err := readTCPMessage(conn)
+		if err != nil {
+			return
+		}
+
+		response, err := r.resolve(query, true)
+		if err != nil {
+			r.logger.Debug(forwardingCachingResolverLogTag, "Failed to resolve query from %s: %s", conn.RemoteAddr(), err.Error())
+			return
+		}
+
+		err = writeTCPMessage(conn, response)
+		if err != nil {
+			r.logger.Debug(forwardingCachingResolverLogTag, "Failed to respond to %s: %s", conn.RemoteAddr(), err.Error())
+			return
+		}
+	}
+}
+
+// resolve returns response with ID of the query either from cache or from upstream servers
+func (r *forwardingCachingResolver) resolve(query []byte, overTCP bool) ([]byte, error) {
+	q, err := parseQuestion(query)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Parsing query")
+	}
+
+	response, found := r.cachedResponse(q)
+	if found {
+		atomic.AddUint64(&r.hits, 1)
+	} else {
+		atomic.AddUint64(&r.misses, 1)
+
+		response, err = r.forward(q, query, overTCP)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Forwarding query for %s", q.Name)
+		}
+
+		r.cacheResponse(q, response)
+	}
+
+	setMessageID(response, messageID(query))
+
+	return response, nil
+}
+
+// cachedResponse returns copy of cached response with TTLs decreased by time spent in cache
+func (r *forwardingCachingResolver) cachedResponse(q question) ([]byte, bool) {
+	r.lock.RLock()
+	entry, found := r.cache[q.key()]
+	r.lock.RUnlock()
+
+	now := time.Now()
+
+	if !found || now.After(entry.expiresAt) {
+		return nil, false
+	}
+
+	response := append([]byte{}, entry.response...)
+
+	elapsed := uint32(now.Sub(entry.cachedAt) / time.Second)
+
+	err := rewriteTTLs(response, func(ttl uint32) uint32 {
+		if ttl < elapsed {
+			return 0
+		}
+		return ttl - elapsed
+	})
+	if err != nil {
+		return nil, false
+	}
+
+	return response, true
+}
+
+func (r *forwardingCachingResolver) cacheResponse(q question, response []byte) {
+	ttl, cacheable := cacheableTTL(response)
+	if !cacheable {
+		return
+	}
+
+	expiresIn := time.Duration(ttl) * time.Second
+	if expiresIn > maxCacheTTL {
+		expiresIn = maxCacheTTL
+	}
+
+	// Clients should not keep records longer than they are kept in cache
+	maxTTL := uint32(expiresIn / time.Second)
+
+	cachedResponse := append([]byte{}, response...)
+
+	err := rewriteTTLs(cachedResponse, func(ttl uint32) uint32 {
+		if ttl > maxTTL {
+			return maxTTL
+		}
+		return ttl
+	})
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	key := q.key()
+
+	if _, found := r.cache[key]; !found && len(r.cache) >= maxCacheEntries {
+		r.removeExpiredEntries(now)
+
+		if len(r.cache) >= maxCacheEntries {
+			r.removeSoonestExpiringEntry()
+		}
+	}
+
+	r.cache[key] = cacheEntry{
+		response:  cachedResponse,
+		cachedAt:  now,
+		expiresAt: now.Add(expiresIn),
+	}
+}
+
+func (r *forwardingCachingResolver) sweepCache(stopCh chan struct{}) {
+	ticker := time.NewTicker(cacheSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+
+		case <-ticker.C:
+			r.lock.Lock()
+			r.removeExpiredEntries(time.Now())
+			r.lock.Unlock()
+		}
+	}
+}
+
+// removeExpiredEntries must be called with lock held
+func (r *forwardingCachingResolver) removeExpiredEntries(now time.Time) {
+	for key, entry := range r.cache {
+		if now.After(entry.expiresAt) {
+			delete(r.cache, key)
+		}
+	}
+}
+
+// removeSoonestExpiringEntry must be called with lock held
+func (r *forwardingCachingResolver) removeSoonestExpiringEntry() {
+	var soonestKey string
+	var soonestExpiresAt time.Time
+
+	for key, entry := range r.cache {
+		if soonestKey == "" || entry.expiresAt.Before(soonestExpiresAt) {
+			soonestKey = key
+			soonestExpiresAt = entry.expiresAt
+		}
+	}
+
+	delete(r.cache, soonestKey)
+}
+
+// forward returns first valid response so that malformed or spoofed
+// responses are neither cached nor returned to clients
+func (r *forwardingCachingResolver) forward(q question, query []byte, overTCP bool) ([]byte, error) {
+	r.lock.RLock()
+	upstreams := r.upstreams
+	r.lock.RUnlock()
+
+	var lastErr error = bosherr.New("No upstream servers")
+
+	for _, upstream := range upstreams {
+		response, err := r.exchange(upstream, query, overTCP)
+		if err == nil {
+			err = validateResponse(q, query, response)
+			if err == nil {
+				return response, nil
+			}
+
+			r.logger.Debug(forwardingCachingResolverLogTag, "Ignoring response from %s: %s", upstream, err.Error())
+		}
+
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+// exchange queries upstream over UDP unless client asked over TCP;
+// truncated UDP response is retried over TCP to get all records
+func (r *forwardingCachingResolver) exchange(upstream string, query []byte, overTCP bool) ([]byte, error) {
+	upstreamAddr := upstream
+	if _, _, err := gonet.SplitHostPort(upstream); err != nil {
+		upstreamAddr = gonet.JoinHostPort(upstream, "53")
+	}
+
+	if !overTCP {
+		response, err := r.exchangeUDP(upstreamAddr, query)
+		if err != nil || !isTruncated(response) {
+			return response, err
+		}
+
+		r.logger.Debug(forwardingCachingResolverLogTag, "Retrying truncated response from %s over TCP", upstreamAddr)
+	}
+
+	return r.exchangeTCP(upstreamAddr, query)
+}
+
+func (r *forwardingCachingResolver) exchangeUDP(upstreamAddr string, query []byte) ([]byte, error) {
+	conn, err := gonet.DialTimeout("udp", upstreamAddr, upstreamTimeout)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Dialing %s", upstreamAddr)
+	}
+
+	defer conn.Close()
+
+	err = conn.SetDeadline(time.Now().Add(upstreamTimeout))
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Setting deadline")
+	}
+
+	_, err = conn.Write(query)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Sending query to %s", upstreamAddr)
+	}
+
+	buf := make([]byte, maxUDPMessageLen)
+
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Reading response from %s", upstreamAddr)
+	}
+
+	return buf[:n], nil
+}
+
+func (r *forwardingCachingResolver) exchangeTCP(upstreamAddr string, query []byte) ([]byte, error) {
+	conn, err := gonet.DialTimeout("tcp", upstreamAddr, upstreamTimeout)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Dialing %s over TCP", upstreamAddr)
+	}
+
+	defer conn.Close()
+
+	err = conn.SetDeadline(time.Now().Add(upstreamTimeout))
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Setting deadline")
+	}
+
+	err = writeTCPMessage(conn, query)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Sending query to %s over TCP", upstreamAddr)
+	}
+
+	response, err := readTCPMessage(conn)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Reading response from %s over TCP", upstreamAddr)
+	}
+
+	return response, nil
+}
+
+// Messages sent over TCP are prefixed with two byte length
+func readTCPMessage(conn io.Reader) ([]byte, error) {
+	lenBuf := make([]byte, 2)
+
+	_, err := io.ReadFull(conn, lenBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := make([]byte, binary.BigEndian.Uint16(lenBuf))
+
+	_, err = io.ReadFull(conn, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+func writeTCPMessage(conn io.Writer, msg []byte) error {
+	buf := make([]byte, 2, 2+len(msg))
+	binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+
+	_, err := conn.Write(append(buf, msg...))
+	return err
+}
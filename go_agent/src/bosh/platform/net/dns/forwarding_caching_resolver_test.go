@@ -0,0 +1,359 @@
+package dns_test
+
+import (
+	"encoding/binary"
+	"io"
+	gonet "net"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshlog "bosh/logger"
+	. "bosh/platform/net/dns"
+)
+
+// buildQuery returns query for A record of example.com.
+func buildQuery(id uint16) []byte {
+	msg := make([]byte, 12)
+	binary.BigEndian.PutUint16(msg[0:2], id)
+	binary.BigEndian.PutUint16(msg[2:4], 0x0100) // recursion desired
+	binary.BigEndian.PutUint16(msg[4:6], 1)
+
+	msg = append(msg, 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0)
+	msg = append(msg, 0, 1, 0, 1) // type A, class IN
+
+	return msg
+}
+
+// buildResponse answers query with A records that point back to question name.
+func buildResponse(query []byte, ttl uint32, answers int) []byte {
+	msg := append([]byte{}, query...)
+	binary.BigEndian.PutUint16(msg[2:4], 0x8180)
+	binary.BigEndian.PutUint16(msg[6:8], uint16(answers))
+
+	for i := 0; i < answers; i++ {
+		answer := []byte{0xc0, 12, 0, 1, 0, 1, 0, 0, 0, 0, 0, 4, 10, 0, 0, byte(i)}
+		binary.BigEndian.PutUint32(answer[6:10], ttl)
+		msg = append(msg, answer...)
+	}
+
+	return msg
+}
+
+// firstAnswerTTL returns TTL of the first answer of response built by buildResponse
+func firstAnswerTTL(response []byte) uint32 {
+	return binary.BigEndian.Uint32(response[35:39])
+}
+
+func messageIDOf(msg []byte) uint16 {
+	return binary.BigEndian.Uint16(msg[0:2])
+}
+
+func isTruncatedResponse(response []byte) bool {
+	return binary.BigEndian.Uint16(response[2:4])&0x0200 != 0
+}
+
+type fakeUpstream struct {
+	ttl     uint32
+	answers int
+
+	// Responses over UDP only have header and question with truncated flag
+	truncateUDP bool
+
+	// Responses over UDP are replaced with returned message when set
+	respondUDP func(query []byte) []byte
+
+	udpQueries int32
+	tcpQueries int32
+
+	udpConn     *gonet.UDPConn
+	tcpListener gonet.Listener
+}
+
+func (u *fakeUpstream) Start() {
+	var err error
+
+	u.udpConn, err = gonet.ListenUDP("udp", &gonet.UDPAddr{IP: gonet.ParseIP("127.0.0.1")})
+	Expect(err).ToNot(HaveOccurred())
+
+	u.tcpListener, err = gonet.Listen("tcp", u.udpConn.LocalAddr().String())
+	Expect(err).ToNot(HaveOccurred())
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := u.udpConn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+
+			atomic.AddInt32(&u.udpQueries, 1)
+
+			response := buildResponse(buf[:n], u.ttl, u.answers)
+			if u.truncateUDP {
+				response = buildResponse(buf[:n], u.ttl, 0)
+				binary.BigEndian.PutUint16(response[2:4], 0x8380)
+			}
+			if u.respondUDP != nil {
+				response = u.respondUDP(buf[:n])
+			}
+
+			u.udpConn.WriteToUDP(response, addr)
+		}
+	}()
+
+	go func() {
+		for {
+			conn, err := u.tcpListener.Accept()
+			if err != nil {
+				return
+			}
+
+			query := readTCP(conn)
+			atomic.AddInt32(&u.tcpQueries, 1)
+			writeTCP(conn, buildResponse(query, u.ttl, u.answers))
+			conn.Close()
+		}
+	}()
+}
+
+func (u *fakeUpstream) Stop() {
+	u.udpConn.Close()
+	u.tcpListener.Close()
+}
+
+func (u *fakeUpstream) Addr() string {
+	return u.udpConn.LocalAddr().String()
+}
+
+func exchange(addr string, query []byte) []byte {
+	conn, err := gonet.Dial("udp", addr)
+	Expect(err).ToNot(HaveOccurred())
+
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	_, err = conn.Write(query)
+	Expect(err).ToNot(HaveOccurred())
+
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	Expect(err).ToNot(HaveOccurred())
+
+	return buf[:n]
+}
+
+func exchangeTCP(addr string, query []byte) []byte {
+	conn, err := gonet.Dial("tcp", addr)
+	Expect(err).ToNot(HaveOccurred())
+
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	writeTCP(conn, query)
+
+	return readTCP(conn)
+}
+
+func readTCP(conn gonet.Conn) []byte {
+	lenBuf := make([]byte, 2)
+
+	_, err := io.ReadFull(conn, lenBuf)
+	Expect(err).ToNot(HaveOccurred())
+
+	msg := make([]byte, binary.BigEndian.Uint16(lenBuf))
+
+	_, err = io.ReadFull(conn, msg)
+	Expect(err).ToNot(HaveOccurred())
+
+	return msg
+}
+
+func writeTCP(conn gonet.Conn, msg []byte) {
+	lenBuf := make([]byte, 2)
+	binary.BigEndian.PutUint16(lenBuf, uint16(len(msg)))
+
+	_, err := conn.Write(append(lenBuf, msg...))
+	Expect(err).ToNot(HaveOccurred())
+}
+
+var _ = Describe("forwardingCachingResolver", func() {
+	var (
+		upstream *fakeUpstream
+		resolver CachingResolver
+		logger   boshlog.Logger
+	)
+
+	BeforeEach(func() {
+		upstream = &fakeUpstream{ttl: 60, answers: 1}
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+	})
+
+	startResolver := func(otherUpstreams ...string) string {
+		upstream.Start()
+
+		forwardingResolver := NewForwardingCachingResolver("127.0.0.1:0", logger)
+		forwardingResolver.SetUpstreams(append(otherUpstreams, upstream.Addr()))
+
+		err := forwardingResolver.Start()
+		Expect(err).ToNot(HaveOccurred())
+
+		resolver = forwardingResolver
+
+		return forwardingResolver.ListenAddr()
+	}
+
+	AfterEach(func() {
+		upstream.Stop()
+		resolver.Stop()
+	})
+
+	It("forwards first query to upstream and answers repeated query from cache", func() {
+		addr := startResolver()
+
+		firstResponse := exchange(addr, buildQuery(1))
+		Expect(binary.BigEndian.Uint16(firstResponse[0:2])).To(Equal(uint16(1)))
+
+		secondResponse := exchange(addr, buildQuery(2))
+		Expect(binary.BigEndian.Uint16(secondResponse[0:2])).To(Equal(uint16(2)))
+		Expect(secondResponse[2:]).To(Equal(firstResponse[2:]))
+
+		Expect(atomic.LoadInt32(&upstream.udpQueries)).To(Equal(int32(1)))
+		Expect(resolver.Stats()).To(Equal(CacheStats{Hits: 1, Misses: 1}))
+	})
+
+	It("does not cache responses with zero ttl", func() {
+		upstream.ttl = 0
+		addr := startResolver()
+
+		exchange(addr, buildQuery(1))
+		exchange(addr, buildQuery(2))
+
+		Expect(atomic.LoadInt32(&upstream.udpQueries)).To(Equal(int32(2)))
+		Expect(resolver.Stats()).To(Equal(CacheStats{Hits: 0, Misses: 2}))
+	})
+
+	It("serves remaining ttl of cached responses", func() {
+		addr := startResolver()
+
+		firstResponse := exchange(addr, buildQuery(1))
+		Expect(firstAnswerTTL(firstResponse)).To(Equal(uint32(60)))
+
+		time.Sleep(1100 * time.Millisecond)
+
+		secondResponse := exchange(addr, buildQuery(2))
+		Expect(firstAnswerTTL(secondResponse)).To(Equal(uint32(59)))
+	})
+
+	It("caps ttl of cached responses so that clients do not keep records longer than cache", func() {
+		upstream.ttl = 3600
+		addr := startResolver()
+
+		exchange(addr, buildQuery(1))
+
+		response := exchange(addr, buildQuery(2))
+		Expect(firstAnswerTTL(response)).To(Equal(uint32(300)))
+	})
+
+	It("answers queries over TCP", func() {
+		addr := startResolver()
+
+		response := exchangeTCP(addr, buildQuery(1))
+		Expect(binary.BigEndian.Uint16(response[0:2])).To(Equal(uint16(1)))
+		Expect(binary.BigEndian.Uint16(response[6:8])).To(Equal(uint16(1)))
+
+		// Queries over TCP are forwarded over TCP and share cache with UDP
+		Expect(atomic.LoadInt32(&upstream.tcpQueries)).To(Equal(int32(1)))
+
+		exchange(addr, buildQuery(2))
+		Expect(atomic.LoadInt32(&upstream.udpQueries)).To(Equal(int32(0)))
+	})
+
+	It("retries truncated upstream responses over TCP", func() {
+		upstream.truncateUDP = true
+		addr := startResolver()
+
+		response := exchange(addr, buildQuery(1))
+		Expect(isTruncatedResponse(response)).To(BeFalse())
+		Expect(binary.BigEndian.Uint16(response[6:8])).To(Equal(uint16(1)))
+
+		Expect(atomic.LoadInt32(&upstream.udpQueries)).To(Equal(int32(1)))
+		Expect(atomic.LoadInt32(&upstream.tcpQueries)).To(Equal(int32(1)))
+	})
+
+	It("truncates responses that do not fit into UDP response so that client retries over TCP", func() {
+		upstream.truncateUDP = true
+		upstream.answers = 40
+		addr := startResolver()
+
+		response := exchange(addr, buildQuery(1))
+		Expect(isTruncatedResponse(response)).To(BeTrue())
+		Expect(binary.BigEndian.Uint16(response[6:8])).To(Equal(uint16(0)))
+		Expect(len(response)).To(BeNumerically("<=", 512))
+
+		response = exchangeTCP(addr, buildQuery(2))
+		Expect(isTruncatedResponse(response)).To(BeFalse())
+		Expect(binary.BigEndian.Uint16(response[6:8])).To(Equal(uint16(40)))
+
+		// Full response was cached after first retry over TCP
+		Expect(atomic.LoadInt32(&upstream.tcpQueries)).To(Equal(int32(1)))
+	})
+
+	Context("when upstream responds with message that does not answer query", func() {
+		var badUpstream *fakeUpstream
+
+		BeforeEach(func() {
+			badUpstream = &fakeUpstream{ttl: 60, answers: 1}
+		})
+
+		AfterEach(func() {
+			badUpstream.Stop()
+		})
+
+		expectResponseIgnored := func(respond func(query []byte) []byte) {
+			badUpstream.respondUDP = respond
+			badUpstream.Start()
+
+			addr := startResolver(badUpstream.Addr())
+
+			response := exchange(addr, buildQuery(1))
+			Expect(binary.BigEndian.Uint16(response[0:2])).To(Equal(uint16(1)))
+			Expect(binary.BigEndian.Uint16(response[6:8])).To(Equal(uint16(1)))
+
+			// Response from good upstream was cached instead
+			exchange(addr, buildQuery(2))
+			Expect(atomic.LoadInt32(&badUpstream.udpQueries)).To(Equal(int32(1)))
+			Expect(atomic.LoadInt32(&upstream.udpQueries)).To(Equal(int32(1)))
+		}
+
+		It("ignores response shorter than header and asks next upstream", func() {
+			expectResponseIgnored(func(query []byte) []byte { return []byte{0} })
+		})
+
+		It("ignores response with different ID and asks next upstream", func() {
+			expectResponseIgnored(func(query []byte) []byte {
+				response := buildResponse(query, 60, 1)
+				binary.BigEndian.PutUint16(response[0:2], messageIDOf(query)+1)
+				return response
+			})
+		})
+
+		It("ignores response with different question and asks next upstream", func() {
+			expectResponseIgnored(func(query []byte) []byte {
+				response := buildResponse(query, 60, 1)
+				response[13] = 'x' // example.com -> xxample.com
+				return response
+			})
+		})
+	})
+
+	It("returns listen ip that is written to resolv.conf", func() {
+		upstream.Start()
+		resolver = NewForwardingCachingResolver("127.0.0.1:53", logger)
+		Expect(resolver.ListenIP()).To(Equal("127.0.0.1"))
+	})
+})
@@ -0,0 +1,12 @@
+package dns
+
+type Manager interface {
+	// Configure makes system name resolution use given DNS configuration.
+	// When caching resolver is enabled it forwards queries to configured servers.
+	Configure(config Config) error
+
+	// Nameservers returns servers that system resolvers should query for given configuration.
+	// DHCP clients and systemd-networkd are configured with them
+	// so that they do not bypass caching resolver.
+	Nameservers(config Config) []string
+}
@@ -0,0 +1,300 @@
+package dns
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	bosherr "bosh/errors"
+)
+
+// Only small subset of DNS message format (RFC 1035) is parsed:
+// question is used as a cache key, record TTLs determine cache expiration
+// and are decreased when cached response is served.
+
+const (
+	messageHeaderLen = 12
+
+	// Truncated responses are retried over TCP and are not cached
+	flagTruncated = 0x0200
+	rcodeMask     = 0x000f
+
+	maxNamePointers = 16
+
+	// TTL field of OPT pseudo record holds extended flags instead of TTL (RFC 6891)
+	typeOPT = 41
+
+	// Responses to UDP queries without EDNS are limited to 512 bytes (RFC 1035)
+	minUDPPayloadSize = 512
+)
+
+type question struct {
+	Name  string
+	Type  uint16
+	Class uint16
+}
+
+func (q question) key() string {
+	return fmt.Sprintf("%s/%d/%d", strings.ToLower(q.Name), q.Type, q.Class)
+}
+
+func messageID(msg []byte) uint16 {
+	return binary.BigEndian.Uint16(msg[0:2])
+}
+
+func setMessageID(msg []byte, id uint16) {
+	binary.BigEndian.PutUint16(msg[0:2], id)
+}
+
+// parseQuestion returns the only question of a query
+func parseQuestion(msg []byte) (question, error) {
+	var q question
+
+	if len(msg) < messageHeaderLen {
+		return q, errors.New("Message is too short")
+	}
+
+	if binary.BigEndian.Uint16(msg[4:6]) != 1 {
+		return q, errors.New("Message must have exactly one question")
+	}
+
+	name, off, err := readName(msg, messageHeaderLen)
+	if err != nil {
+		return q, err
+	}
+
+	if len(msg) < off+4 {
+		return q, errors.New("Question is too short")
+	}
+
+	q.Name = name
+	q.Type = binary.BigEndian.Uint16(msg[off : off+2])
+	q.Class = binary.BigEndian.Uint16(msg[off+2 : off+4])
+
+	return q, nil
+}
+
+// validateResponse checks that response answers query with question q
+func validateResponse(q question, query, response []byte) error {
+	if len(response) < messageHeaderLen {
+		return errors.New("Response is too short")
+	}
+
+	if messageID(response) != messageID(query) {
+		return errors.New("Response ID does not match query ID")
+	}
+
+	responseQuestion, err := parseQuestion(response)
+	if err != nil {
+		return bosherr.WrapError(err, "Parsing response question")
+	}
+
+	if responseQuestion != q {
+		return errors.New("Response question does not match query question")
+	}
+
+	return nil
+}
+
+func isTruncated(msg []byte) bool {
+	return len(msg) >= messageHeaderLen && binary.BigEndian.Uint16(msg[2:4])&flagTruncated != 0
+}
+
+// cacheableTTL returns minimum TTL of answers
+// or false if response should not be cached
+func cacheableTTL(msg []byte) (uint32, bool) {
+	if len(msg) < messageHeaderLen {
+		return 0, false
+	}
+
+	flags := binary.BigEndian.Uint16(msg[2:4])
+	if flags&flagTruncated != 0 || flags&rcodeMask != 0 {
+		return 0, false
+	}
+
+	answers := int(binary.BigEndian.Uint16(msg[6:8]))
+	if answers == 0 {
+		return 0, false
+	}
+
+	var minTTL uint32
+	var seen int
+
+	err := forEachRecord(msg, func(rrType uint16, ttlOff int) {
+		if seen >= answers {
+			return
+		}
+
+		ttl := binary.BigEndian.Uint32(msg[ttlOff : ttlOff+4])
+		if seen == 0 || ttl < minTTL {
+			minTTL = ttl
+		}
+
+		seen++
+	})
+	if err != nil {
+		return 0, false
+	}
+
+	return minTTL, minTTL > 0
+}
+
+// rewriteTTLs replaces TTL of each resource record with value returned by fn
+func rewriteTTLs(msg []byte, fn func(ttl uint32) uint32) error {
+	return forEachRecord(msg, func(rrType uint16, ttlOff int) {
+		if rrType == typeOPT {
+			return
+		}
+
+		ttl := binary.BigEndian.Uint32(msg[ttlOff : ttlOff+4])
+		binary.BigEndian.PutUint32(msg[ttlOff:ttlOff+4], fn(ttl))
+	})
+}
+
+// udpPayloadSize returns maximum UDP response size that client advertised in its query
+func udpPayloadSize(query []byte) int {
+	size := minUDPPayloadSize
+
+	forEachRecord(query, func(rrType uint16, ttlOff int) {
+		if rrType != typeOPT {
+			return
+		}
+
+		// Class field right before TTL holds requestor's payload size
+		advertised := int(binary.BigEndian.Uint16(query[ttlOff-2 : ttlOff]))
+		if advertised > size {
+			size = advertised
+		}
+	})
+
+	return size
+}
+
+// truncate returns header and question of a response with truncated flag set
+// so that client retries its query over TCP
+func truncate(msg []byte) ([]byte, error) {
+	off, err := questionsEnd(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	truncated := append([]byte{}, msg[:off]...)
+
+	flags := binary.BigEndian.Uint16(truncated[2:4])
+	binary.BigEndian.PutUint16(truncated[2:4], flags|flagTruncated)
+
+	// No answer, authority and additional records
+	for i := 6; i < messageHeaderLen; i++ {
+		truncated[i] = 0
+	}
+
+	return truncated, nil
+}
+
+// forEachRecord calls fn with type and TTL offset of answer, authority and additional records
+func forEachRecord(msg []byte, fn func(rrType uint16, ttlOff int)) error {
+	off, err := questionsEnd(msg)
+	if err != nil {
+		return err
+	}
+
+	records := int(binary.BigEndian.Uint16(msg[6:8])) +
+		int(binary.BigEndian.Uint16(msg[8:10])) +
+		int(binary.BigEndian.Uint16(msg[10:12]))
+
+	for i := 0; i < records; i++ {
+		_, nextOff, err := readName(msg, off)
+		if err != nil {
+			return err
+		}
+
+		if len(msg) < nextOff+10 {
+			return errors.New("Resource record is too short")
+		}
+
+		rrType := binary.BigEndian.Uint16(msg[nextOff : nextOff+2])
+		rdLength := int(binary.BigEndian.Uint16(msg[nextOff+8 : nextOff+10]))
+
+		off = nextOff + 10 + rdLength
+		if len(msg) < off {
+			return errors.New("Resource record data is out of bounds")
+		}
+
+		fn(rrType, nextOff+4)
+	}
+
+	return nil
+}
+
+// questionsEnd returns offset right after question section
+func questionsEnd(msg []byte) (int, error) {
+	if len(msg) < messageHeaderLen {
+		return 0, errors.New("Message is too short")
+	}
+
+	questions := int(binary.BigEndian.Uint16(msg[4:6]))
+
+	off := messageHeaderLen
+
+	for i := 0; i < questions; i++ {
+		_, nextOff, err := readName(msg, off)
+		if err != nil {
+			return 0, err
+		}
+
+		off = nextOff + 4
+		if len(msg) < off {
+			return 0, errors.New("Question is too short")
+		}
+	}
+
+	return off, nil
+}
+
+// readName returns dotted name and offset right after the name
+func readName(msg []byte, off int) (string, int, error) {
+	var labels []string
+
+	endOff := -1
+
+	for pointers := 0; ; {
+		if off >= len(msg) {
+			return "", 0, errors.New("Name is out of bounds")
+		}
+
+		length := int(msg[off])
+
+		switch {
+		case length == 0:
+			if endOff < 0 {
+				endOff = off + 1
+			}
+			return strings.Join(labels, ".") + ".", endOff, nil
+
+		case length&0xc0 == 0xc0:
+			if off+1 >= len(msg) {
+				return "", 0, errors.New("Name pointer is out of bounds")
+			}
+
+			pointers++
+			if pointers > maxNamePointers {
+				return "", 0, errors.New("Too many name pointers")
+			}
+
+			if endOff < 0 {
+				endOff = off + 2
+			}
+
+			off = int(binary.BigEndian.Uint16(msg[off:off+2]) & 0x3fff)
+
+		default:
+			if off+1+length > len(msg) {
+				return "", 0, errors.New("Label is out of bounds")
+			}
+
+			labels = append(labels, string(msg[off+1:off+1+length]))
+			off += 1 + length
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package dns
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	bosherr "bosh/errors"
+	boshlog "bosh/logger"
+	boshsys "bosh/system"
+)
+
+const resolvConfManagerLogTag = "resolvConfManager"
+
+const resolvConfPath = "/etc/resolv.conf"
+
+type resolvConfManager struct {
+	fs              boshsys.FileSystem
+	cachingResolver CachingResolver
+	logger          boshlog.Logger
+}
+
+// NewResolvConfManager returns manager that owns /etc/resolv.conf.
+// cachingResolver is optional; when it is nil servers are written to resolv.conf directly.
+func NewResolvConfManager(
+	fs boshsys.FileSystem,
+	cachingResolver CachingResolver,
+	logger boshlog.Logger,
+) Manager {
+	return resolvConfManager{
+		fs:              fs,
+		cachingResolver: cachingResolver,
+		logger:          logger,
+	}
+}
+
+func (m resolvConfManager) Nameservers(config Config) []string {
+	return cachingResolverNameservers(m.cachingResolver, config)
+}
+
+func (m resolvConfManager) Configure(config Config) error {
+	resolvConf := config
+
+	if m.cachingResolver != nil && len(config.Servers) > 0 {
+		m.cachingResolver.SetUpstreams(config.Servers)
+
+		err := m.cachingResolver.Start()
+		if err != nil {
+			// Name resolution should keep working without the cache
+			m.logger.Error(resolvConfManagerLogTag, "Failed to start caching resolver: %s", err.Error())
+		} else {
+			resolvConf.Servers = []string{m.cachingResolver.ListenIP()}
+		}
+	}
+
+	buffer := bytes.NewBuffer([]byte{})
+	funcs := template.FuncMap{"join": strings.Join}
+	t := template.Must(template.New("resolv-conf").Funcs(funcs).Parse(resolvConfTemplate))
+
+	err := t.Execute(buffer, resolvConf)
+	if err != nil {
+		return bosherr.WrapError(err, "Generating config from template")
+	}
+
+	err = m.fs.WriteFile(resolvConfPath, buffer.Bytes())
+	if err != nil {
+		return bosherr.WrapError(err, "Writing to %s", resolvConfPath)
+	}
+
+	return nil
+}
+
+const resolvConfTemplate = `# Generated by bosh-agent
+{{ range .Servers }}nameserver {{ . }}
+{{ end }}{{ if .Search }}search {{ join .Search " " }}
+{{ end }}{{ if .Options }}options {{ join .Options " " }}
+{{ end }}`
@@ -0,0 +1,154 @@
+package dns_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshlog "bosh/logger"
+	. "bosh/platform/net/dns"
+	fakedns "bosh/platform/net/dns/fakes"
+	boshsettings "bosh/settings"
+	fakesys "bosh/system/fakes"
+)
+
+var _ = Describe("NewConfigFromNetworks", func() {
+	It("uses dns configuration of the default dns network", func() {
+		networks := boshsettings.Networks{
+			"net1": boshsettings.Network{
+				DNS: []string{"10.0.0.1"},
+			},
+			"net2": boshsettings.Network{
+				Default:    []string{"dns"},
+				DNS:        []string{"8.8.8.8", "9.9.9.9"},
+				DNSSearch:  []string{"example.com"},
+				DNSOptions: []string{"rotate"},
+			},
+		}
+
+		Expect(NewConfigFromNetworks(networks)).To(Equal(Config{
+			Servers: []string{"8.8.8.8", "9.9.9.9"},
+			Search:  []string{"example.com"},
+			Options: []string{"rotate"},
+		}))
+	})
+})
+
+var _ = Describe("resolvConfManager", func() {
+	var (
+		fs              *fakesys.FakeFileSystem
+		cachingResolver *fakedns.FakeCachingResolver
+		logger          boshlog.Logger
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		cachingResolver = &fakedns.FakeCachingResolver{ListenIPIP: "127.0.0.1"}
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+	})
+
+	Describe("Configure", func() {
+		config := Config{
+			Servers: []string{"8.8.8.8", "9.9.9.9"},
+			Search:  []string{"example.com", "corp.example.com"},
+			Options: []string{"rotate", "timeout:1"},
+		}
+
+		Context("when caching resolver is not used", func() {
+			It("writes servers, search domains and options to resolv.conf", func() {
+				manager := NewResolvConfManager(fs, nil, logger)
+
+				err := manager.Configure(config)
+				Expect(err).ToNot(HaveOccurred())
+
+				resolvConf := fs.GetFileTestStat("/etc/resolv.conf")
+				Expect(resolvConf).ToNot(BeNil())
+				Expect(resolvConf.StringContents()).To(Equal(`# Generated by bosh-agent
+nameserver 8.8.8.8
+nameserver 9.9.9.9
+search example.com corp.example.com
+options rotate timeout:1
+`))
+			})
+
+			It("omits search and options when they are not configured", func() {
+				manager := NewResolvConfManager(fs, nil, logger)
+
+				err := manager.Configure(Config{Servers: []string{"8.8.8.8"}})
+				Expect(err).ToNot(HaveOccurred())
+
+				resolvConf := fs.GetFileTestStat("/etc/resolv.conf")
+				Expect(resolvConf.StringContents()).To(Equal("# Generated by bosh-agent\nnameserver 8.8.8.8\n"))
+			})
+
+			It("returns error if writing resolv.conf fails", func() {
+				fs.WriteToFileError = errors.New("fake-write-err")
+				manager := NewResolvConfManager(fs, nil, logger)
+
+				err := manager.Configure(config)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-write-err"))
+			})
+		})
+
+		Context("when caching resolver is used", func() {
+			It("points resolv.conf to caching resolver that forwards to configured servers", func() {
+				manager := NewResolvConfManager(fs, cachingResolver, logger)
+
+				err := manager.Configure(config)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(cachingResolver.SetUpstreamsServers).To(Equal([]string{"8.8.8.8", "9.9.9.9"}))
+				Expect(cachingResolver.Started).To(BeTrue())
+
+				resolvConf := fs.GetFileTestStat("/etc/resolv.conf")
+				Expect(resolvConf.StringContents()).To(Equal(`# Generated by bosh-agent
+nameserver 127.0.0.1
+search example.com corp.example.com
+options rotate timeout:1
+`))
+			})
+
+			It("writes configured servers if caching resolver fails to start", func() {
+				cachingResolver.StartErr = errors.New("fake-start-err")
+				manager := NewResolvConfManager(fs, cachingResolver, logger)
+
+				err := manager.Configure(config)
+				Expect(err).ToNot(HaveOccurred())
+
+				resolvConf := fs.GetFileTestStat("/etc/resolv.conf")
+				Expect(resolvConf.StringContents()).To(ContainSubstring("nameserver 8.8.8.8\nnameserver 9.9.9.9\n"))
+				Expect(resolvConf.StringContents()).ToNot(ContainSubstring("127.0.0.1"))
+			})
+
+			It("does not start caching resolver when there are no servers", func() {
+				manager := NewResolvConfManager(fs, cachingResolver, logger)
+
+				err := manager.Configure(Config{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(cachingResolver.Started).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("Nameservers", func() {
+		config := Config{Servers: []string{"8.8.8.8", "9.9.9.9"}}
+
+		It("returns configured servers when caching resolver is not used", func() {
+			manager := NewResolvConfManager(fs, nil, logger)
+			Expect(manager.Nameservers(config)).To(Equal([]string{"8.8.8.8", "9.9.9.9"}))
+		})
+
+		It("returns caching resolver address when caching resolver is used", func() {
+			manager := NewResolvConfManager(fs, cachingResolver, logger)
+			Expect(manager.Nameservers(config)).To(Equal([]string{"127.0.0.1"}))
+		})
+
+		It("returns no servers when there are no servers to forward to", func() {
+			manager := NewResolvConfManager(fs, cachingResolver, logger)
+			Expect(manager.Nameservers(Config{})).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,54 @@
+package dns
+
+import (
+	bosherr "bosh/errors"
+	boshlog "bosh/logger"
+)
+
+const resolvedManagerLogTag = "resolvedManager"
+
+type resolvedManager struct {
+	cachingResolver CachingResolver
+	logger          boshlog.Logger
+}
+
+// NewResolvedManager returns manager for systems where /etc/resolv.conf is owned by systemd-resolved.
+// DNS servers are configured in systemd-networkd units using Nameservers
+// so Configure only points caching resolver at configured servers.
+func NewResolvedManager(cachingResolver CachingResolver, logger boshlog.Logger) Manager {
+	return resolvedManager{
+		cachingResolver: cachingResolver,
+		logger:          logger,
+	}
+}
+
+func (m resolvedManager) Configure(config Config) error {
+	if m.cachingResolver == nil || len(config.Servers) == 0 {
+		return nil
+	}
+
+	m.cachingResolver.SetUpstreams(config.Servers)
+
+	err := m.cachingResolver.Start()
+	if err != nil {
+		return bosherr.WrapError(err, "Starting caching resolver")
+	}
+
+	m.logger.Debug(resolvedManagerLogTag, "Caching resolver forwards to %v", config.Servers)
+
+	return nil
+}
+
+func (m resolvedManager) Nameservers(config Config) []string {
+	return cachingResolverNameservers(m.cachingResolver, config)
+}
+
+// cachingResolverNameservers returns caching resolver address
+// when it is enabled and there are servers to forward queries to
+func cachingResolverNameservers(cachingResolver CachingResolver, config Config) []string {
+	if cachingResolver == nil || len(config.Servers) == 0 {
+		return config.Servers
+	}
+
+	return []string{cachingResolver.ListenIP()}
+}
@@ -0,0 +1,75 @@
+package dns_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshlog "bosh/logger"
+	. "bosh/platform/net/dns"
+	fakedns "bosh/platform/net/dns/fakes"
+)
+
+var _ = Describe("resolvedManager", func() {
+	var (
+		cachingResolver *fakedns.FakeCachingResolver
+		logger          boshlog.Logger
+	)
+
+	BeforeEach(func() {
+		cachingResolver = &fakedns.FakeCachingResolver{ListenIPIP: "127.0.0.1"}
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+	})
+
+	config := Config{Servers: []string{"8.8.8.8", "9.9.9.9"}}
+
+	Describe("Configure", func() {
+		It("starts caching resolver that forwards queries to configured servers", func() {
+			manager := NewResolvedManager(cachingResolver, logger)
+
+			err := manager.Configure(config)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cachingResolver.SetUpstreamsServers).To(Equal([]string{"8.8.8.8", "9.9.9.9"}))
+			Expect(cachingResolver.Started).To(BeTrue())
+		})
+
+		It("returns error when caching resolver fails to start since network units point at it", func() {
+			cachingResolver.StartErr = errors.New("fake-start-err")
+			manager := NewResolvedManager(cachingResolver, logger)
+
+			err := manager.Configure(config)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-start-err"))
+		})
+
+		It("does nothing when caching resolver is not used", func() {
+			manager := NewResolvedManager(nil, logger)
+
+			err := manager.Configure(config)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("does not start caching resolver when there are no servers", func() {
+			manager := NewResolvedManager(cachingResolver, logger)
+
+			err := manager.Configure(Config{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cachingResolver.Started).To(BeFalse())
+		})
+	})
+
+	Describe("Nameservers", func() {
+		It("returns configured servers when caching resolver is not used", func() {
+			manager := NewResolvedManager(nil, logger)
+			Expect(manager.Nameservers(config)).To(Equal([]string{"8.8.8.8", "9.9.9.9"}))
+		})
+
+		It("returns caching resolver address when caching resolver is used", func() {
+			manager := NewResolvedManager(cachingResolver, logger)
+			Expect(manager.Nameservers(config)).To(Equal([]string{"127.0.0.1"}))
+		})
+	})
+})
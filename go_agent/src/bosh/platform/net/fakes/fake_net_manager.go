@@ -12,6 +12,15 @@ type FakeNetManager struct {
 
 	SetupDhcpNetworks boshsettings.Networks
 	SetupDhcpErr      error
+
+	RollbackManualNetworkingCalled bool
+	RollbackManualNetworkingErr    error
+
+	CommitManualNetworkingCalled bool
+	CommitManualNetworkingErr    error
+
+	HasUncommittedManualNetworkingResult bool
+	HasUncommittedManualNetworkingErr    error
 }
 
 func (net *FakeNetManager) SetupManualNetworking(networks boshsettings.Networks, errCh chan error) error {
@@ -23,3 +32,17 @@ func (net *FakeNetManager) SetupDhcp(networks boshsettings.Networks, errCh chan
 	net.SetupDhcpNetworks = networks
 	return net.SetupDhcpErr
 }
+
+func (net *FakeNetManager) RollbackManualNetworking() error {
+	net.RollbackManualNetworkingCalled = true
+	return net.RollbackManualNetworkingErr
+}
+
+func (net *FakeNetManager) HasUncommittedManualNetworking() (bool, error) {
+	return net.HasUncommittedManualNetworkingResult, net.HasUncommittedManualNetworkingErr
+}
+
+func (net *FakeNetManager) CommitManualNetworking() error {
+	net.CommitManualNetworkingCalled = true
+	return net.CommitManualNetworkingErr
+}
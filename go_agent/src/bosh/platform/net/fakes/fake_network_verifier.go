@@ -0,0 +1,17 @@
+package fakes
+
+import (
+	boshsettings "bosh/settings"
+)
+
+type FakeNetworkVerifier struct {
+	VerifyNetworks  boshsettings.Networks
+	VerifyEndpoints []string
+	VerifyErr       error
+}
+
+func (v *FakeNetworkVerifier) Verify(networks boshsettings.Networks, endpoints []string) error {
+	v.VerifyNetworks = networks
+	v.VerifyEndpoints = endpoints
+	return v.VerifyErr
+}
@@ -7,8 +7,15 @@ import (
 type FakeRoutesSearcher struct {
 	SearchRoutesRoutes []boshnet.Route
 	SearchRoutesErr    error
+
+	SearchIPv6RoutesRoutes []boshnet.Route
+	SearchIPv6RoutesErr    error
 }
 
 func (s *FakeRoutesSearcher) SearchRoutes() ([]boshnet.Route, error) {
 	return s.SearchRoutesRoutes, s.SearchRoutesErr
 }
+
+func (s *FakeRoutesSearcher) SearchIPv6Routes() ([]boshnet.Route, error) {
+	return s.SearchIPv6RoutesRoutes, s.SearchIPv6RoutesErr
+}
@@ -20,6 +20,9 @@ func NetworkInterfaceToAddrsFunc(interfaceName string) ([]gonet.Addr, error) {
 type IPResolver interface {
 	// GetPrimaryIPv4 always returns error unless IPNet is found for given interface
 	GetPrimaryIPv4(interfaceName string) (*gonet.IPNet, error)
+
+	// GetPrimaryIPv6 always returns error unless global unicast IPNet is found for given interface
+	GetPrimaryIPv6(interfaceName string) (*gonet.IPNet, error)
 }
 
 type ipResolver struct {
@@ -56,3 +59,35 @@ func (r ipResolver) GetPrimaryIPv4(interfaceName string) (*gonet.IPNet, error) {
 
 	return nil, bosherr.New("Failed to find primary IPv4 address for interface '%s'", interfaceName)
 }
+
+func (r ipResolver) GetPrimaryIPv6(interfaceName string) (*gonet.IPNet, error) {
+	addrs, err := r.ifaceToAddrsFunc(interfaceName)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Looking up addresses for interface '%s'", interfaceName)
+	}
+
+	if len(addrs) == 0 {
+		return nil, bosherr.New("No addresses found for interface '%s'", interfaceName)
+	}
+
+	for _, addr := range addrs {
+		ip, ok := addr.(*gonet.IPNet)
+		if !ok {
+			continue
+		}
+
+		// ignore ipv4
+		if ip.IP.To4() != nil {
+			continue
+		}
+
+		// ignore link-local addresses since they are not routable
+		if !ip.IP.IsGlobalUnicast() {
+			continue
+		}
+
+		return ip, nil
+	}
+
+	return nil, bosherr.New("Failed to find primary IPv6 address for interface '%s'", interfaceName)
+}
@@ -107,4 +107,50 @@ var _ = Describe("ipResolver", func() {
 			})
 		})
 	})
+
+	Describe("GetPrimaryIPv6", func() {
+		var (
+			addrs []gonet.Addr
+		)
+
+		BeforeEach(func() {
+			ifaceToAddrs := func(_ string) ([]gonet.Addr, error) { return addrs, nil }
+			ipResolver = NewIPResolver(ifaceToAddrs)
+		})
+
+		It("returns first global unicast ipv6 address from associated interface", func() {
+			addrs = []gonet.Addr{
+				NotIPNet{},
+				&gonet.IPNet{IP: gonet.ParseIP("127.0.0.1"), Mask: gonet.CIDRMask(16, 32)},
+				&gonet.IPNet{IP: gonet.ParseIP("fe80::1"), Mask: gonet.CIDRMask(64, 128)},
+				&gonet.IPNet{IP: gonet.ParseIP("2001:db8::10"), Mask: gonet.CIDRMask(64, 128)},
+				&gonet.IPNet{IP: gonet.ParseIP("2001:db8::20"), Mask: gonet.CIDRMask(64, 128)},
+			}
+
+			ip, err := ipResolver.GetPrimaryIPv6("fake-iface-name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ip.String()).To(Equal("2001:db8::10/64"))
+		})
+
+		It("returns error if associated interface does not have any addresses", func() {
+			addrs = []gonet.Addr{}
+
+			ip, err := ipResolver.GetPrimaryIPv6("fake-iface-name")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("No addresses found for interface"))
+			Expect(ip).To(BeNil())
+		})
+
+		It("returns error if associated interface only has ipv4 and link-local ipv6 addresses", func() {
+			addrs = []gonet.Addr{
+				&gonet.IPNet{IP: gonet.ParseIP("127.0.0.1"), Mask: gonet.CIDRMask(16, 32)},
+				&gonet.IPNet{IP: gonet.ParseIP("fe80::1"), Mask: gonet.CIDRMask(64, 128)},
+			}
+
+			ip, err := ipResolver.GetPrimaryIPv6("fake-iface-name")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Failed to find primary IPv6 address for interface"))
+			Expect(ip).To(BeNil())
+		})
+	})
 })
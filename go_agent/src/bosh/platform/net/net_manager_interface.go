@@ -21,5 +21,17 @@ type NetManager interface {
 	// upon completion of background network reconfiguration (e.g. arping).
 	SetupDhcp(networks boshsettings.Networks, errCh chan error) error
 
+	// RollbackManualNetworking restores network configuration files
+	// replaced by the last SetupManualNetworking and restarts networking.
+	RollbackManualNetworking() error
+
+	// CommitManualNetworking discards backup made by the last SetupManualNetworking
+	// so that working configuration is never rolled back later.
+	CommitManualNetworking() error
+
+	// HasUncommittedManualNetworking is true when configuration set up
+	// by SetupManualNetworking in the current boot was neither committed nor rolled back.
+	HasUncommittedManualNetworking() (bool, error)
+
 	DefaultNetworkResolver
 }
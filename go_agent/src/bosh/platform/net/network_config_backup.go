@@ -0,0 +1,158 @@
+package net
+
+import (
+	"encoding/json"
+	"strings"
+
+	bosherr "bosh/errors"
+	boshsys "bosh/system"
+)
+
+// networkConfigBackup keeps contents of network configuration files
+// before they are replaced so that they could be restored
+// if new network configuration is not functional.
+// Backup is kept on disk since agent may be restarted before restoring it.
+// Backup only applies to the boot in which it was made; configuration
+// that survived a reboot is not rolled back.
+type networkConfigBackup struct {
+	fs   boshsys.FileSystem
+	path string
+}
+
+const networkConfigBackupBootIDPath = "/proc/sys/kernel/random/boot_id"
+
+type backedUpConfig struct {
+	BootID string         `json:"boot_id"`
+	Files  []backedUpFile `json:"files"`
+}
+
+type backedUpFile struct {
+	Path     string `json:"path"`
+	Contents []byte `json:"contents"`
+
+	// Files that did not exist are removed on restore
+	Existed bool `json:"existed"`
+}
+
+func newNetworkConfigBackup(fs boshsys.FileSystem, path string) networkConfigBackup {
+	return networkConfigBackup{fs: fs, path: path}
+}
+
+func (b networkConfigBackup) Save(paths []string) error {
+	var files []backedUpFile
+
+	for _, path := range paths {
+		file := backedUpFile{Path: path}
+
+		if b.fs.FileExists(path) {
+			contents, err := b.fs.ReadFile(path)
+			if err != nil {
+				return bosherr.WrapError(err, "Reading %s", path)
+			}
+
+			file.Contents = contents
+			file.Existed = true
+		}
+
+		files = append(files, file)
+	}
+
+	configJSON, err := json.Marshal(backedUpConfig{BootID: b.bootID(), Files: files})
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling backed up files")
+	}
+
+	err = b.fs.WriteFile(b.path, configJSON)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing %s", b.path)
+	}
+
+	return nil
+}
+
+// Restore returns false when there was no backup to restore
+// or backup was made before the last reboot
+func (b networkConfigBackup) Restore() (bool, error) {
+	config, found, err := b.load()
+	if err != nil || !found {
+		return false, err
+	}
+
+	if !b.madeInCurrentBoot(config) {
+		return false, b.Discard()
+	}
+
+	for _, file := range config.Files {
+		if file.Existed {
+			err = b.fs.WriteFile(file.Path, file.Contents)
+		} else {
+			err = b.fs.RemoveAll(file.Path)
+		}
+
+		if err != nil {
+			return false, bosherr.WrapError(err, "Restoring %s", file.Path)
+		}
+	}
+
+	err = b.Discard()
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Exists is true when backup made in the current boot
+// was neither restored nor discarded yet
+func (b networkConfigBackup) Exists() (bool, error) {
+	config, found, err := b.load()
+	if err != nil || !found {
+		return false, err
+	}
+
+	return b.madeInCurrentBoot(config), nil
+}
+
+// Discard removes backup once new configuration is known to work
+func (b networkConfigBackup) Discard() error {
+	err := b.fs.RemoveAll(b.path)
+	if err != nil {
+		return bosherr.WrapError(err, "Removing %s", b.path)
+	}
+
+	return nil
+}
+
+func (b networkConfigBackup) load() (backedUpConfig, bool, error) {
+	var config backedUpConfig
+
+	if !b.fs.FileExists(b.path) {
+		return config, false, nil
+	}
+
+	configJSON, err := b.fs.ReadFile(b.path)
+	if err != nil {
+		return config, false, bosherr.WrapError(err, "Reading %s", b.path)
+	}
+
+	err = json.Unmarshal(configJSON, &config)
+	if err != nil {
+		return config, false, bosherr.WrapError(err, "Unmarshalling backed up files")
+	}
+
+	return config, true, nil
+}
+
+func (b networkConfigBackup) madeInCurrentBoot(config backedUpConfig) bool {
+	return config.BootID != "" && config.BootID == b.bootID()
+}
+
+// bootID returns empty string when boot id is not known
+func (b networkConfigBackup) bootID() string {
+	bootID, err := b.fs.ReadFileString(networkConfigBackupBootIDPath)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(bootID)
+}
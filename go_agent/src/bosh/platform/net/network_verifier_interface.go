@@ -0,0 +1,12 @@
+package net
+
+import (
+	boshsettings "bosh/settings"
+)
+
+type NetworkVerifier interface {
+	// Verify checks that default gateway is reachable,
+	// endpoint host names can be resolved and endpoints accept connections.
+	// Checks are retried until they pass or verification times out.
+	Verify(networks boshsettings.Networks, endpoints []string) error
+}
@@ -8,8 +8,25 @@ type Route struct {
 
 type RoutesSearcher interface {
 	SearchRoutes() ([]Route, error)
+	SearchIPv6Routes() ([]Route, error)
 }
 
+// IsDefault is true for IPv4 default routes and IPv6 default routes that lead
+// to a gateway through a real interface; e.g. IPv6 unreachable route
+// `::/0 :: !n -1 1 lo` added by kernel is not a default route
 func (r Route) IsDefault() bool {
-	return r.Destination == "0.0.0.0"
+	switch r.Destination {
+	case "0.0.0.0":
+		// Gateway is 0.0.0.0 for point-to-point and DHCP-less interfaces
+		return true
+
+	case "::/0":
+		if r.InterfaceName == "" || r.InterfaceName == "lo" {
+			return false
+		}
+
+		return r.Gateway != "" && r.Gateway != "::"
+	}
+
+	return false
 }
@@ -10,12 +10,29 @@ import (
 var _ = Describe("Route", func() {
 	Describe("IsDefault", func() {
 		It("returns true if destination is 0.0.0.0", func() {
-			Expect(Route{Destination: "0.0.0.0"}.IsDefault()).To(BeTrue())
+			Expect(Route{Destination: "0.0.0.0", Gateway: "10.0.0.1", InterfaceName: "eth0"}.IsDefault()).To(BeTrue())
+		})
+
+		It("returns true if destination is ::/0", func() {
+			Expect(Route{Destination: "::/0", Gateway: "2001:db8::1", InterfaceName: "eth0"}.IsDefault()).To(BeTrue())
 		})
 
 		It("returns false if destination is not 0.0.0.0", func() {
 			Expect(Route{}.IsDefault()).To(BeFalse())
-			Expect(Route{Destination: "1.1.1.1"}.IsDefault()).To(BeFalse())
+			Expect(Route{Destination: "1.1.1.1", Gateway: "10.0.0.1", InterfaceName: "eth0"}.IsDefault()).To(BeFalse())
+		})
+
+		It("returns true for IPv4 default route without a gateway", func() {
+			Expect(Route{Destination: "0.0.0.0", Gateway: "0.0.0.0", InterfaceName: "eth0"}.IsDefault()).To(BeTrue())
+		})
+
+		It("returns false if IPv6 route goes through loopback interface", func() {
+			Expect(Route{Destination: "::/0", Gateway: "2001:db8::1", InterfaceName: "lo"}.IsDefault()).To(BeFalse())
+		})
+
+		It("returns false if IPv6 route does not have a gateway", func() {
+			Expect(Route{Destination: "::/0", InterfaceName: "eth0"}.IsDefault()).To(BeFalse())
+			Expect(Route{Destination: "::/0", Gateway: "::", InterfaceName: "eth0"}.IsDefault()).To(BeFalse())
 		})
 	})
 })
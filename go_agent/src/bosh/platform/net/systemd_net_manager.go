@@ -0,0 +1,435 @@
+package net
+
+import (
+	"bytes"
+	gonet "net"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	bosherr "bosh/errors"
+	boshlog "bosh/logger"
+	bosharp "bosh/platform/net/arp"
+	boshdns "bosh/platform/net/dns"
+	boship "bosh/platform/net/ip"
+	boshsettings "bosh/settings"
+	boshsys "bosh/system"
+)
+
+const systemdNetManagerLogTag = "systemdNetManager"
+
+const (
+	systemdNetworkDir = "/etc/systemd/network"
+
+	// All units generated by the agent share the prefix
+	// so that units for removed networks could be found and deleted
+	systemdUnitPrefix = "10-bosh-"
+)
+
+type systemdNetManager struct {
+	DefaultNetworkResolver
+
+	fs                 boshsys.FileSystem
+	cmdRunner          boshsys.CmdRunner
+	ipResolver         boship.IPResolver
+	addressBroadcaster bosharp.AddressBroadcaster
+	dnsManager         boshdns.Manager
+	configBackup       networkConfigBackup
+	logger             boshlog.Logger
+}
+
+func NewSystemdNetManager(
+	fs boshsys.FileSystem,
+	cmdRunner boshsys.CmdRunner,
+	defaultNetworkResolver DefaultNetworkResolver,
+	ipResolver boship.IPResolver,
+	addressBroadcaster bosharp.AddressBroadcaster,
+	dnsManager boshdns.Manager,
+	configBackupPath string,
+	logger boshlog.Logger,
+) systemdNetManager {
+	return systemdNetManager{
+		DefaultNetworkResolver: defaultNetworkResolver,
+		fs:                     fs,
+		cmdRunner:              cmdRunner,
+		ipResolver:             ipResolver,
+		addressBroadcaster:     addressBroadcaster,
+		dnsManager:             dnsManager,
+		configBackup:           newNetworkConfigBackup(fs, configBackupPath),
+		logger:                 logger,
+	}
+}
+
+type systemdUnit struct {
+	Path     string
+	Contents []byte
+}
+
+type systemdNetworkArg struct {
+	customNetwork
+
+	DNS boshdns.Config
+}
+
+func (net systemdNetManager) SetupDhcp(networks boshsettings.Networks, errCh chan error) error {
+	// eth0 is hard coded in AWS and OpenStack stemcells.
+	// TODO: abstract hardcoded network interface name to the NetManager
+	dhcpNet := systemdNetworkArg{
+		customNetwork: customNetwork{Interface: "eth0"},
+		DNS:           net.dnsConfig(networks),
+	}
+
+	unit, err := net.renderUnit(dhcpNet.Interface+".network", systemdDHCPNetworkTemplate, dhcpNet)
+	if err != nil {
+		return err
+	}
+
+	changed, err := net.convergeUnits([]systemdUnit{unit})
+	if err != nil {
+		return bosherr.WrapError(err, "Writing network units")
+	}
+
+	if changed {
+		net.reloadNetworking([]string{dhcpNet.Interface})
+	}
+
+	addresses := []boship.InterfaceAddress{
+		boship.NewResolvingInterfaceAddress(dhcpNet.Interface, net.ipResolver),
+	}
+
+	go func() {
+		net.addressBroadcaster.BroadcastMACAddresses(addresses)
+		if errCh != nil {
+			errCh <- nil
+		}
+	}()
+
+	return nil
+}
+
+const systemdDHCPNetworkTemplate = `# Generated by bosh-agent
+[Match]
+Name={{ .Interface }}
+
+[Network]
+DHCP=ipv4{{ template "dns" .DNS }}
+`
+
+func (net systemdNetManager) SetupManualNetworking(networks boshsettings.Networks, errCh chan error) error {
+	modifiedNetworks, err := buildCustomNetworks(networks, net.fs)
+	if err != nil {
+		return bosherr.WrapError(err, "Building network interfaces")
+	}
+
+	dnsConfig := net.dnsConfig(networks)
+
+	var units []systemdUnit
+
+	networksUnits := make([][]systemdUnit, len(modifiedNetworks))
+
+	for i, network := range modifiedNetworks {
+		networksUnits[i], err = net.networkUnits(network, dnsConfig)
+		if err != nil {
+			return err
+		}
+
+		units = append(units, networksUnits[i]...)
+	}
+
+	existingPaths, err := net.existingUnitPaths()
+	if err != nil {
+		return err
+	}
+
+	backupPaths := existingPaths
+	for _, unit := range units {
+		backupPaths = append(backupPaths, unit.Path)
+	}
+
+	err = net.configBackup.Save(backupPaths)
+	if err != nil {
+		return bosherr.WrapError(err, "Backing up network configuration")
+	}
+
+	var interfacesToReload []string
+
+	for i, network := range modifiedNetworks {
+		changed, err := net.convergeUnits(networksUnits[i])
+		if err != nil {
+			return bosherr.WrapError(err, "Writing network units")
+		}
+
+		if changed {
+			interfacesToReload = append(interfacesToReload, network.interfaceNames()...)
+		}
+	}
+
+	removed, err := net.removeStaleUnits(existingPaths, units)
+	if err != nil {
+		return bosherr.WrapError(err, "Removing stale network units")
+	}
+
+	if removed || len(interfacesToReload) > 0 {
+		net.reloadNetworking(interfacesToReload)
+	}
+
+	err = net.dnsManager.Configure(boshdns.NewConfigFromNetworks(networks))
+	if err != nil {
+		return bosherr.WrapError(err, "Configuring DNS")
+	}
+
+	addresses := toInterfaceAddresses(modifiedNetworks)
+
+	go func() {
+		net.addressBroadcaster.BroadcastMACAddresses(addresses)
+		if errCh != nil {
+			errCh <- nil
+		}
+	}()
+
+	return nil
+}
+
+func (net systemdNetManager) CommitManualNetworking() error {
+	return net.configBackup.Discard()
+}
+
+func (net systemdNetManager) HasUncommittedManualNetworking() (bool, error) {
+	return net.configBackup.Exists()
+}
+
+func (net systemdNetManager) RollbackManualNetworking() error {
+	restored, err := net.configBackup.Restore()
+	if err != nil {
+		return bosherr.WrapError(err, "Restoring network configuration")
+	}
+
+	if restored {
+		_, _, _, err = net.cmdRunner.RunCommand("systemctl", "restart", "systemd-networkd")
+		if err != nil {
+			net.logger.Info(systemdNetManagerLogTag, "Ignoring systemd-networkd restart failure: %#v", err)
+		}
+	}
+
+	return nil
+}
+
+// dnsConfig returns DNS configuration written to network units;
+// systemd-resolved is pointed at caching resolver when it is enabled
+func (net systemdNetManager) dnsConfig(networks boshsettings.Networks) boshdns.Config {
+	dnsConfig := boshdns.NewConfigFromNetworks(networks)
+	dnsConfig.Servers = net.dnsManager.Nameservers(dnsConfig)
+	return dnsConfig
+}
+
+// networkUnits renders .netdev and .network units for bond slaves,
+// bond and VLAN virtual devices and the network interface itself
+func (net systemdNetManager) networkUnits(network customNetwork, dnsConfig boshdns.Config) ([]systemdUnit, error) {
+	var units []systemdUnit
+
+	render := func(name, templateText string, data interface{}) error {
+		unit, err := net.renderUnit(name, templateText, data)
+		if err != nil {
+			return err
+		}
+
+		units = append(units, unit)
+		return nil
+	}
+
+	for _, slaveInterface := range network.SlaveInterfaces {
+		slave := systemdSlaveArg{Interface: slaveInterface, Master: network.BondInterface}
+
+		err := render(slaveInterface+".network", systemdSlaveNetworkTemplate, slave)
+		if err != nil {
+			return units, err
+		}
+	}
+
+	if network.BondInterface != "" {
+		err := render(network.BondInterface+".netdev", systemdBondNetDevTemplate, network)
+		if err != nil {
+			return units, err
+		}
+	}
+
+	if network.VLANRawDevice != "" {
+		err := render(network.Interface+".netdev", systemdVLANNetDevTemplate, network)
+		if err != nil {
+			return units, err
+		}
+
+		err = render(network.VLANRawDevice+".network", systemdVLANRawDeviceNetworkTemplate, network)
+		if err != nil {
+			return units, err
+		}
+	}
+
+	err := render(network.Interface+".network", systemdNetworkTemplate, systemdNetworkArg{network, dnsConfig})
+	if err != nil {
+		return units, err
+	}
+
+	return units, nil
+}
+
+type systemdSlaveArg struct {
+	Interface string
+	Master    string
+}
+
+const systemdSlaveNetworkTemplate = `# Generated by bosh-agent
+[Match]
+Name={{ .Interface }}
+
+[Network]
+Bond={{ .Master }}
+`
+
+const systemdBondNetDevTemplate = `# Generated by bosh-agent
+[NetDev]
+Name={{ .BondInterface }}
+Kind=bond
+
+[Bond]
+Mode={{ .Bond.Mode }}
+MIIMonitorSec={{ .Bond.MIIMon }}ms
+`
+
+const systemdVLANNetDevTemplate = `# Generated by bosh-agent
+[NetDev]
+Name={{ .Interface }}
+Kind=vlan
+
+[VLAN]
+Id={{ .VLAN }}
+`
+
+const systemdVLANRawDeviceNetworkTemplate = `# Generated by bosh-agent
+[Match]
+Name={{ .VLANRawDevice }}
+
+[Network]
+VLAN={{ .Interface }}
+`
+
+const systemdNetworkTemplate = `# Generated by bosh-agent
+[Match]
+Name={{ .Interface }}
+{{ if .MTU }}
+[Link]
+MTUBytes={{ .MTU }}
+{{ end }}
+[Network]{{ if .IP }}
+Address={{ .IP }}/{{ prefix .Netmask }}{{ if .HasDefaultGateway }}
+Gateway={{ .Gateway }}{{ end }}{{ end }}{{ if .IsIPv6Manual }}
+Address={{ .IPv6 }}/{{ .IPv6Prefix }}{{ if .IPv6Gateway }}
+Gateway={{ .IPv6Gateway }}{{ end }}
+IPv6AcceptRA=no{{ else if .IsIPv6SLAAC }}
+IPv6AcceptRA=yes{{ else if .IsIPv6DHCP }}
+DHCP=ipv6{{ end }}{{ template "dns" .DNS }}
+{{ range .Routes }}
+[Route]
+Destination={{ .Destination }}/{{ prefix .Netmask }}
+Gateway={{ .Gateway }}
+{{ end }}`
+
+// Search domains are passed as Domains= since resolv.conf is managed by systemd-resolved
+const systemdDNSTemplate = `{{ define "dns" }}{{ range .Servers }}
+DNS={{ . }}{{ end }}{{ if .Search }}
+Domains={{ join .Search " " }}{{ end }}{{ end }}`
+
+func (net systemdNetManager) renderUnit(name, templateText string, data interface{}) (systemdUnit, error) {
+	buffer := bytes.NewBuffer([]byte{})
+
+	funcs := template.FuncMap{"prefix": netmaskPrefix, "join": strings.Join}
+	t := template.Must(template.New(name).Funcs(funcs).Parse(templateText))
+	t = template.Must(t.Parse(systemdDNSTemplate))
+
+	err := t.Execute(buffer, data)
+	if err != nil {
+		return systemdUnit{}, bosherr.WrapError(err, "Generating config from template")
+	}
+
+	unit := systemdUnit{
+		Path:     filepath.Join(systemdNetworkDir, systemdUnitPrefix+name),
+		Contents: buffer.Bytes(),
+	}
+
+	return unit, nil
+}
+
+func (net systemdNetManager) convergeUnits(units []systemdUnit) (bool, error) {
+	var changed bool
+
+	for _, unit := range units {
+		written, err := net.fs.ConvergeFileContents(unit.Path, unit.Contents)
+		if err != nil {
+			return changed, bosherr.WrapError(err, "Writing to %s", unit.Path)
+		}
+
+		changed = changed || written
+	}
+
+	return changed, nil
+}
+
+func (net systemdNetManager) existingUnitPaths() ([]string, error) {
+	paths, err := net.fs.Glob(filepath.Join(systemdNetworkDir, systemdUnitPrefix+"*"))
+	if err != nil {
+		return paths, bosherr.WrapError(err, "Globbing network units")
+	}
+
+	return paths, nil
+}
+
+func (net systemdNetManager) removeStaleUnits(existingPaths []string, units []systemdUnit) (bool, error) {
+	var removed bool
+
+	generatedPaths := map[string]bool{}
+	for _, unit := range units {
+		generatedPaths[unit.Path] = true
+	}
+
+	for _, path := range existingPaths {
+		if generatedPaths[path] {
+			continue
+		}
+
+		err := net.fs.RemoveAll(path)
+		if err != nil {
+			return removed, bosherr.WrapError(err, "Removing %s", path)
+		}
+
+		removed = true
+	}
+
+	return removed, nil
+}
+
+func (net systemdNetManager) reloadNetworking(interfaceNames []string) {
+	_, _, _, err := net.cmdRunner.RunCommand("networkctl", "reload")
+	if err != nil {
+		net.logger.Info(systemdNetManagerLogTag, "Ignoring networkctl reload failure: %#v", err)
+	}
+
+	if len(interfaceNames) == 0 {
+		return
+	}
+
+	_, _, _, err = net.cmdRunner.RunCommand("networkctl", append([]string{"reconfigure"}, interfaceNames...)...)
+	if err != nil {
+		net.logger.Info(systemdNetManagerLogTag, "Ignoring networkctl reconfigure failure: %#v", err)
+	}
+}
+
+// netmaskPrefix converts dotted IPv4 netmask to CIDR prefix length
+func netmaskPrefix(netmask string) int {
+	ip := gonet.ParseIP(netmask)
+	if ip == nil || ip.To4() == nil {
+		return 0
+	}
+
+	ones, _ := gonet.IPMask(ip.To4()).Size()
+	return ones
+}
@@ -0,0 +1,461 @@
+package net_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshlog "bosh/logger"
+	. "bosh/platform/net"
+	fakearp "bosh/platform/net/arp/fakes"
+	boshdns "bosh/platform/net/dns"
+	fakedns "bosh/platform/net/dns/fakes"
+	fakenet "bosh/platform/net/fakes"
+	boship "bosh/platform/net/ip"
+	fakeip "bosh/platform/net/ip/fakes"
+	boshsettings "bosh/settings"
+	fakesys "bosh/system/fakes"
+)
+
+const expectedSystemdDHCPNetwork = `# Generated by bosh-agent
+[Match]
+Name=eth0
+
+[Network]
+DHCP=ipv4
+DNS=xx.xx.xx.xx
+DNS=yy.yy.yy.yy
+`
+
+const expectedSystemdNetwork = `# Generated by bosh-agent
+[Match]
+Name=eth0
+
+[Network]
+Address=192.168.195.6/24
+Gateway=192.168.195.1
+DNS=10.80.130.1
+DNS=10.80.130.2
+`
+
+const expectedSystemdRoutesNetwork = `# Generated by bosh-agent
+[Match]
+Name=eth0
+
+[Link]
+MTUBytes=9000
+
+[Network]
+Address=192.168.195.6/24
+Gateway=192.168.195.1
+Address=2001:db8::10/64
+Gateway=2001:db8::1
+IPv6AcceptRA=no
+
+[Route]
+Destination=10.0.0.0/8
+Gateway=192.168.195.2
+`
+
+const expectedSystemdSlaveNetwork = `# Generated by bosh-agent
+[Match]
+Name=eth1
+
+[Network]
+Bond=bond0
+`
+
+const expectedSystemdBondNetDev = `# Generated by bosh-agent
+[NetDev]
+Name=bond0
+Kind=bond
+
+[Bond]
+Mode=active-backup
+MIIMonitorSec=100ms
+`
+
+const expectedSystemdBondNetwork = `# Generated by bosh-agent
+[Match]
+Name=bond0
+
+[Network]
+VLAN=bond0.100
+`
+
+const expectedSystemdVLANNetDev = `# Generated by bosh-agent
+[NetDev]
+Name=bond0.100
+Kind=vlan
+
+[VLAN]
+Id=100
+`
+
+const expectedSystemdVLANNetwork = `# Generated by bosh-agent
+[Match]
+Name=bond0.100
+
+[Network]
+Address=192.168.195.6/24
+Gateway=192.168.195.1
+`
+
+var _ = Describe("systemdNetManager", func() {
+	var (
+		fs                     *fakesys.FakeFileSystem
+		cmdRunner              *fakesys.FakeCmdRunner
+		defaultNetworkResolver *fakenet.FakeDefaultNetworkResolver
+		ipResolver             *fakeip.FakeIPResolver
+		addressBroadcaster     *fakearp.FakeAddressBroadcaster
+		dnsManager             *fakedns.FakeManager
+		netManager             NetManager
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		cmdRunner = fakesys.NewFakeCmdRunner()
+		defaultNetworkResolver = &fakenet.FakeDefaultNetworkResolver{}
+		ipResolver = &fakeip.FakeIPResolver{}
+		addressBroadcaster = &fakearp.FakeAddressBroadcaster{}
+		dnsManager = &fakedns.FakeManager{}
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		netManager = NewSystemdNetManager(
+			fs,
+			cmdRunner,
+			defaultNetworkResolver,
+			ipResolver,
+			addressBroadcaster,
+			dnsManager,
+			"/fake-network-config-backup.json",
+			logger,
+		)
+	})
+
+	Describe("SetupDhcp", func() {
+		networks := boshsettings.Networks{
+			"bosh": boshsettings.Network{
+				Default: []string{"dns"},
+				DNS:     []string{"xx.xx.xx.xx", "yy.yy.yy.yy"},
+			},
+			"vip": boshsettings.Network{
+				Default: []string{},
+				DNS:     []string{"aa.aa.aa.aa"},
+			},
+		}
+
+		It("writes network unit with dns servers from default dns network", func() {
+			err := netManager.SetupDhcp(networks, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			networkUnit := fs.GetFileTestStat("/etc/systemd/network/10-bosh-eth0.network")
+			Expect(networkUnit).ToNot(BeNil())
+			Expect(networkUnit.StringContents()).To(Equal(expectedSystemdDHCPNetwork))
+		})
+
+		It("writes network unit with caching resolver as dns server when it is enabled", func() {
+			dnsManager.NameserversServers = []string{"127.0.0.1"}
+
+			err := netManager.SetupDhcp(networks, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			networkUnit := fs.GetFileTestStat("/etc/systemd/network/10-bosh-eth0.network")
+			Expect(networkUnit.StringContents()).To(ContainSubstring("DHCP=ipv4\nDNS=127.0.0.1\n"))
+			Expect(networkUnit.StringContents()).ToNot(ContainSubstring("xx.xx.xx.xx"))
+		})
+
+		It("reloads networking when unit changed", func() {
+			err := netManager.SetupDhcp(networks, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cmdRunner.RunCommands).To(Equal([][]string{
+				{"networkctl", "reload"},
+				{"networkctl", "reconfigure", "eth0"},
+			}))
+		})
+
+		It("does not reload networking when unit did not change", func() {
+			fs.WriteFileString("/etc/systemd/network/10-bosh-eth0.network", expectedSystemdDHCPNetwork)
+
+			err := netManager.SetupDhcp(networks, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cmdRunner.RunCommands).To(BeEmpty())
+		})
+
+		It("starts broadcasting the MAC addresses", func() {
+			errCh := make(chan error)
+
+			err := netManager.SetupDhcp(networks, errCh)
+			Expect(err).ToNot(HaveOccurred())
+
+			<-errCh // wait for all arpings
+
+			Expect(addressBroadcaster.BroadcastMACAddressesAddresses).To(Equal([]boship.InterfaceAddress{
+				boship.NewResolvingInterfaceAddress("eth0", ipResolver),
+			}))
+		})
+	})
+
+	Describe("SetupManualNetworking", func() {
+		var errCh chan error
+
+		BeforeEach(func() {
+			errCh = make(chan error)
+
+			fs.WriteFileString("/sys/class/net/eth0/address", "22:00:0a:1f:ac:2a\n")
+			fs.WriteFileString("/sys/class/net/eth1/address", "22:00:0a:1f:ac:2b\n")
+			fs.SetGlob("/sys/class/net/*", []string{"/sys/class/net/eth0", "/sys/class/net/eth1"})
+		})
+
+		networks := boshsettings.Networks{
+			"bosh": boshsettings.Network{
+				Default: []string{"dns", "gateway"},
+				IP:      "192.168.195.6",
+				Netmask: "255.255.255.0",
+				Gateway: "192.168.195.1",
+				Mac:     "22:00:0a:1f:ac:2a",
+				DNS:     []string{"10.80.130.1", "10.80.130.2"},
+			},
+		}
+
+		It("writes network unit", func() {
+			err := netManager.SetupManualNetworking(networks, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			networkUnit := fs.GetFileTestStat("/etc/systemd/network/10-bosh-eth0.network")
+			Expect(networkUnit).ToNot(BeNil())
+			Expect(networkUnit.StringContents()).To(Equal(expectedSystemdNetwork))
+		})
+
+		It("configures dns with servers from default dns network", func() {
+			err := netManager.SetupManualNetworking(networks, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(dnsManager.ConfigureConfig).To(Equal(boshdns.Config{
+				Servers: []string{"10.80.130.1", "10.80.130.2"},
+			}))
+		})
+
+		It("writes network unit with caching resolver as dns server when it is enabled", func() {
+			dnsManager.NameserversServers = []string{"127.0.0.1"}
+
+			err := netManager.SetupManualNetworking(networks, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			networkUnit := fs.GetFileTestStat("/etc/systemd/network/10-bosh-eth0.network")
+			Expect(networkUnit.StringContents()).To(ContainSubstring("Gateway=192.168.195.1\nDNS=127.0.0.1\n"))
+			Expect(networkUnit.StringContents()).ToNot(ContainSubstring("10.80.130.1"))
+
+			// Caching resolver still forwards queries to configured servers
+			Expect(dnsManager.ConfigureConfig.Servers).To(Equal([]string{"10.80.130.1", "10.80.130.2"}))
+		})
+
+		It("returns error when configuring dns fails", func() {
+			dnsManager.ConfigureErr = errors.New("fake-configure-err")
+
+			err := netManager.SetupManualNetworking(networks, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-configure-err"))
+		})
+
+		It("writes dns search domains", func() {
+			searchNetwork := networks["bosh"]
+			searchNetwork.DNSSearch = []string{"example.com", "corp.example.com"}
+
+			err := netManager.SetupManualNetworking(boshsettings.Networks{"bosh": searchNetwork}, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			networkUnit := fs.GetFileTestStat("/etc/systemd/network/10-bosh-eth0.network")
+			Expect(networkUnit.StringContents()).To(ContainSubstring(
+				"DNS=10.80.130.2\nDomains=example.com corp.example.com\n",
+			))
+		})
+
+		It("reloads networking and reconfigures changed interfaces", func() {
+			err := netManager.SetupManualNetworking(networks, errCh)
+			Expect(err).ToNot(HaveOccurred())
+
+			<-errCh // wait for all arpings
+
+			Expect(cmdRunner.RunCommands).To(Equal([][]string{
+				{"networkctl", "reload"},
+				{"networkctl", "reconfigure", "eth0"},
+			}))
+		})
+
+		It("does not reload networking when units did not change", func() {
+			fs.WriteFileString("/etc/systemd/network/10-bosh-eth0.network", expectedSystemdNetwork)
+			fs.SetGlob("/etc/systemd/network/10-bosh-*", []string{"/etc/systemd/network/10-bosh-eth0.network"})
+
+			err := netManager.SetupManualNetworking(networks, errCh)
+			Expect(err).ToNot(HaveOccurred())
+
+			<-errCh // wait for all arpings
+
+			Expect(cmdRunner.RunCommands).To(BeEmpty())
+		})
+
+		It("removes units of networks that are no longer configured and reloads networking", func() {
+			fs.WriteFileString("/etc/systemd/network/10-bosh-eth0.network", expectedSystemdNetwork)
+			fs.WriteFileString("/etc/systemd/network/10-bosh-eth1.network", "fake-stale-unit")
+			fs.SetGlob("/etc/systemd/network/10-bosh-*", []string{
+				"/etc/systemd/network/10-bosh-eth0.network",
+				"/etc/systemd/network/10-bosh-eth1.network",
+			})
+
+			err := netManager.SetupManualNetworking(networks, errCh)
+			Expect(err).ToNot(HaveOccurred())
+
+			<-errCh // wait for all arpings
+
+			Expect(fs.FileExists("/etc/systemd/network/10-bosh-eth1.network")).To(BeFalse())
+			Expect(cmdRunner.RunCommands).To(Equal([][]string{{"networkctl", "reload"}}))
+		})
+
+		It("writes mtu, IPv6 configuration and static routes", func() {
+			routesNetworks := boshsettings.Networks{
+				"bosh": boshsettings.Network{
+					Default:     []string{"gateway"},
+					IP:          "192.168.195.6",
+					Netmask:     "255.255.255.0",
+					Gateway:     "192.168.195.1",
+					IPv6Mode:    boshsettings.IPv6ModeManual,
+					IPv6:        "2001:db8::10",
+					IPv6Prefix:  64,
+					IPv6Gateway: "2001:db8::1",
+					Mac:         "22:00:0a:1f:ac:2a",
+					MTU:         9000,
+					Routes: []boshsettings.Route{
+						{Destination: "10.0.0.0", Netmask: "255.0.0.0", Gateway: "192.168.195.2"},
+					},
+				},
+			}
+
+			err := netManager.SetupManualNetworking(routesNetworks, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			networkUnit := fs.GetFileTestStat("/etc/systemd/network/10-bosh-eth0.network")
+			Expect(networkUnit).ToNot(BeNil())
+			Expect(networkUnit.StringContents()).To(Equal(expectedSystemdRoutesNetwork))
+		})
+
+		Context("when network is configured on VLAN on top of bonded interfaces", func() {
+			bondedNetworks := boshsettings.Networks{
+				"bosh": boshsettings.Network{
+					Default: []string{"gateway"},
+					IP:      "192.168.195.6",
+					Netmask: "255.255.255.0",
+					Gateway: "192.168.195.1",
+					VLAN:    100,
+					Bond: boshsettings.Bond{
+						Slaves: []string{"22:00:0a:1f:ac:2a", "22:00:0a:1f:ac:2b"},
+					},
+				},
+			}
+
+			It("writes slave, bond and vlan units", func() {
+				err := netManager.SetupManualNetworking(bondedNetworks, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				expectedUnits := map[string]string{
+					"/etc/systemd/network/10-bosh-eth1.network":      expectedSystemdSlaveNetwork,
+					"/etc/systemd/network/10-bosh-bond0.netdev":      expectedSystemdBondNetDev,
+					"/etc/systemd/network/10-bosh-bond0.network":     expectedSystemdBondNetwork,
+					"/etc/systemd/network/10-bosh-bond0.100.netdev":  expectedSystemdVLANNetDev,
+					"/etc/systemd/network/10-bosh-bond0.100.network": expectedSystemdVLANNetwork,
+				}
+
+				for path, expectedContents := range expectedUnits {
+					unit := fs.GetFileTestStat(path)
+					Expect(unit).ToNot(BeNil(), path)
+					Expect(unit.StringContents()).To(Equal(expectedContents), path)
+				}
+			})
+
+			It("reconfigures slave, bond and vlan interfaces", func() {
+				err := netManager.SetupManualNetworking(bondedNetworks, errCh)
+				Expect(err).ToNot(HaveOccurred())
+
+				<-errCh // wait for all arpings
+
+				Expect(cmdRunner.RunCommands).To(Equal([][]string{
+					{"networkctl", "reload"},
+					{"networkctl", "reconfigure", "eth0", "eth1", "bond0", "bond0.100"},
+				}))
+			})
+		})
+
+		It("starts broadcasting the MAC addresses", func() {
+			err := netManager.SetupManualNetworking(networks, errCh)
+			Expect(err).ToNot(HaveOccurred())
+
+			<-errCh // wait for all arpings
+
+			Expect(addressBroadcaster.BroadcastMACAddressesAddresses).To(Equal([]boship.InterfaceAddress{
+				boship.NewSimpleInterfaceAddress("eth0", "192.168.195.6"),
+			}))
+		})
+	})
+
+	Describe("RollbackManualNetworking", func() {
+		BeforeEach(func() {
+			fs.WriteFileString("/proc/sys/kernel/random/boot_id", "fake-boot-id\n")
+			fs.WriteFileString("/sys/class/net/eth0/address", "22:00:0a:1f:ac:2a\n")
+			fs.SetGlob("/sys/class/net/*", []string{"/sys/class/net/eth0"})
+		})
+
+		networks := boshsettings.Networks{
+			"bosh": boshsettings.Network{
+				IP:      "192.168.195.6",
+				Netmask: "255.255.255.0",
+				Gateway: "192.168.195.1",
+				Mac:     "22:00:0a:1f:ac:2a",
+			},
+		}
+
+		It("restores previous units and restarts systemd-networkd", func() {
+			fs.WriteFileString("/etc/systemd/network/10-bosh-eth0.network", "fake-previous-unit")
+
+			err := netManager.SetupManualNetworking(networks, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			cmdRunner.RunCommands = [][]string{}
+
+			err = netManager.RollbackManualNetworking()
+			Expect(err).ToNot(HaveOccurred())
+
+			networkUnit := fs.GetFileTestStat("/etc/systemd/network/10-bosh-eth0.network")
+			Expect(networkUnit.StringContents()).To(Equal("fake-previous-unit"))
+
+			Expect(cmdRunner.RunCommands).To(Equal([][]string{{"systemctl", "restart", "systemd-networkd"}}))
+		})
+
+		It("does not restart systemd-networkd when there is nothing to restore", func() {
+			err := netManager.RollbackManualNetworking()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cmdRunner.RunCommands).To(BeEmpty())
+		})
+
+		It("does not restore units that were committed", func() {
+			fs.WriteFileString("/etc/systemd/network/10-bosh-eth0.network", "fake-previous-unit")
+
+			err := netManager.SetupManualNetworking(networks, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = netManager.CommitManualNetworking()
+			Expect(err).ToNot(HaveOccurred())
+
+			cmdRunner.RunCommands = [][]string{}
+
+			err = netManager.RollbackManualNetworking()
+			Expect(err).ToNot(HaveOccurred())
+
+			networkUnit := fs.GetFileTestStat("/etc/systemd/network/10-bosh-eth0.network")
+			Expect(networkUnit.StringContents()).ToNot(Equal("fake-previous-unit"))
+			Expect(cmdRunner.RunCommands).To(BeEmpty())
+		})
+	})
+})
@@ -2,7 +2,6 @@ package net
 
 import (
 	"bytes"
-	"path/filepath"
 	"regexp"
 	"strings"
 	"text/template"
@@ -10,6 +9,7 @@ import (
 	bosherr "bosh/errors"
 	boshlog "bosh/logger"
 	bosharp "bosh/platform/net/arp"
+	boshdns "bosh/platform/net/dns"
 	boship "bosh/platform/net/ip"
 	boshsettings "bosh/settings"
 	boshsys "bosh/system"
@@ -28,6 +28,8 @@ type ubuntuNetManager struct {
 	fs                 boshsys.FileSystem
 	ipResolver         boship.IPResolver
 	addressBroadcaster bosharp.AddressBroadcaster
+	dnsManager         boshdns.Manager
+	configBackup       networkConfigBackup
 	logger             boshlog.Logger
 }
 
@@ -37,6 +39,8 @@ func NewUbuntuNetManager(
 	defaultNetworkResolver DefaultNetworkResolver,
 	ipResolver boship.IPResolver,
 	addressBroadcaster bosharp.AddressBroadcaster,
+	dnsManager boshdns.Manager,
+	configBackupPath string,
 	logger boshlog.Logger,
 ) ubuntuNetManager {
 	return ubuntuNetManager{
@@ -45,17 +49,15 @@ func NewUbuntuNetManager(
 		fs:                     fs,
 		ipResolver:             ipResolver,
 		addressBroadcaster:     addressBroadcaster,
+		dnsManager:             dnsManager,
+		configBackup:           newNetworkConfigBackup(fs, configBackupPath),
 		logger:                 logger,
 	}
 }
 
-func (net ubuntuNetManager) getDNSServers(networks boshsettings.Networks) []string {
-	dnsNetwork, _ := networks.DefaultNetworkFor("dns")
-	return dnsNetwork.DNS
-}
-
 func (net ubuntuNetManager) SetupDhcp(networks boshsettings.Networks, errCh chan error) error {
-	dnsServers := net.getDNSServers(networks)
+	// dhclient rewrites resolv.conf on every lease so it has to list caching resolver as well
+	dnsServers := net.dnsManager.Nameservers(boshdns.NewConfigFromNetworks(networks))
 	dnsServersList := strings.Join(dnsServers, ", ")
 	buffer := bytes.NewBuffer([]byte{})
 	t := template.Must(template.New("dhcp-config").Parse(ubuntuDHCPConfigTemplate))
@@ -72,17 +74,7 @@ func (net ubuntuNetManager) SetupDhcp(networks boshsettings.Networks, errCh chan
 	}
 
 	if written {
-		args := net.restartNetworkArguments()
-
-		_, _, _, err := net.cmdRunner.RunCommand("ifdown", args...)
-		if err != nil {
-			net.logger.Info(ubuntuNetManagerLogTag, "Ignoring ifdown failure: %#v", err)
-		}
-
-		_, _, _, err = net.cmdRunner.RunCommand("ifup", args...)
-		if err != nil {
-			net.logger.Info(ubuntuNetManagerLogTag, "Ignoring ifup failure: %#v", err)
-		}
+		net.restartAllNetworkingInterfaces()
 	}
 
 	addresses := []boship.InterfaceAddress{
@@ -118,18 +110,21 @@ prepend domain-name-servers {{ . }};
 `
 
 func (net ubuntuNetManager) SetupManualNetworking(networks boshsettings.Networks, errCh chan error) error {
-	modifiedNetworks, written, err := net.writeNetworkInterfaces(networks)
+	err := net.configBackup.Save([]string{ubuntuNetworkInterfacesPath, "/etc/resolv.conf"})
 	if err != nil {
-		return bosherr.WrapError(err, "Writing network interfaces")
+		return bosherr.WrapError(err, "Backing up network configuration")
 	}
 
-	if written {
-		net.restartNetworkingInterfaces(modifiedNetworks)
+	modifiedNetworks, changedNetworks, err := net.writeNetworkInterfaces(networks)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing network interfaces")
 	}
 
-	err = net.writeResolvConf(networks)
+	net.restartNetworkingInterfaces(changedNetworks)
+
+	err = net.dnsManager.Configure(boshdns.NewConfigFromNetworks(networks))
 	if err != nil {
-		return bosherr.WrapError(err, "Writing resolv.conf")
+		return bosherr.WrapError(err, "Configuring DNS")
 	}
 
 	addresses := toInterfaceAddresses(modifiedNetworks)
@@ -144,28 +139,35 @@ func (net ubuntuNetManager) SetupManualNetworking(networks boshsettings.Networks
 	return nil
 }
 
-func (net ubuntuNetManager) writeNetworkInterfaces(networks boshsettings.Networks) ([]customNetwork, bool, error) {
-	var modifiedNetworks []customNetwork
+func (net ubuntuNetManager) CommitManualNetworking() error {
+	return net.configBackup.Discard()
+}
 
-	macAddresses, err := net.detectMacAddresses()
+func (net ubuntuNetManager) HasUncommittedManualNetworking() (bool, error) {
+	return net.configBackup.Exists()
+}
+
+func (net ubuntuNetManager) RollbackManualNetworking() error {
+	restored, err := net.configBackup.Restore()
 	if err != nil {
-		return modifiedNetworks, false, bosherr.WrapError(err, "Detecting mac addresses")
+		return bosherr.WrapError(err, "Restoring network configuration")
 	}
 
-	for _, aNet := range networks {
-		network, broadcast, err := boshsys.CalculateNetworkAndBroadcast(aNet.IP, aNet.Netmask)
-		if err != nil {
-			return modifiedNetworks, false, bosherr.WrapError(err, "Calculating network and broadcast")
-		}
+	if restored {
+		net.restartAllNetworkingInterfaces()
+	}
 
-		newNet := customNetwork{
-			aNet,
-			macAddresses[aNet.Mac],
-			network,
-			broadcast,
-			true,
-		}
-		modifiedNetworks = append(modifiedNetworks, newNet)
+	return nil
+}
+
+// writeNetworkInterfaces returns all configured networks
+// and networks which interface stanzas were changed
+func (net ubuntuNetManager) writeNetworkInterfaces(networks boshsettings.Networks) ([]customNetwork, []customNetwork, error) {
+	var changedNetworks []customNetwork
+
+	modifiedNetworks, err := buildCustomNetworks(networks, net.fs)
+	if err != nil {
+		return modifiedNetworks, changedNetworks, err
 	}
 
 	buffer := bytes.NewBuffer([]byte{})
@@ -173,87 +175,120 @@ func (net ubuntuNetManager) writeNetworkInterfaces(networks boshsettings.Network
 
 	err = t.Execute(buffer, modifiedNetworks)
 	if err != nil {
-		return modifiedNetworks, false, bosherr.WrapError(err, "Generating config from template")
+		return modifiedNetworks, changedNetworks, bosherr.WrapError(err, "Generating config from template")
 	}
 
-	written, err := net.fs.ConvergeFileContents("/etc/network/interfaces", buffer.Bytes())
-	if err != nil {
-		return modifiedNetworks, false, bosherr.WrapError(err, "Writing to /etc/network/interfaces")
+	var oldContents string
+	if net.fs.FileExists(ubuntuNetworkInterfacesPath) {
+		oldContents, err = net.fs.ReadFileString(ubuntuNetworkInterfacesPath)
+		if err != nil {
+			net.logger.Info(ubuntuNetManagerLogTag, "Ignoring network interfaces read failure: %#v", err)
+		}
 	}
 
-	return modifiedNetworks, written, nil
-}
-
-const ubuntuNetworkInterfacesTemplate = `# Generated by bosh-agent
-auto lo
-iface lo inet loopback
-{{ range . }}
-auto {{ .Interface }}
-iface {{ .Interface }} inet static
-    address {{ .IP }}
-    network {{ .NetworkIP }}
-    netmask {{ .Netmask }}
-    broadcast {{ .Broadcast }}
-{{ if .HasDefaultGateway }}    gateway {{ .Gateway }}{{ end }}{{ end }}`
-
-func (net ubuntuNetManager) writeResolvConf(networks boshsettings.Networks) error {
-	buffer := bytes.NewBuffer([]byte{})
-	t := template.Must(template.New("resolv-conf").Parse(ubuntuResolvConfTemplate))
-
-	dnsServers := net.getDNSServers(networks)
-	dnsServersArg := dnsConfigArg{dnsServers}
-	err := t.Execute(buffer, dnsServersArg)
+	written, err := net.fs.ConvergeFileContents(ubuntuNetworkInterfacesPath, buffer.Bytes())
 	if err != nil {
-		return bosherr.WrapError(err, "Generating config from template")
+		return modifiedNetworks, changedNetworks, bosherr.WrapError(err, "Writing to %s", ubuntuNetworkInterfacesPath)
 	}
 
-	err = net.fs.WriteFile("/etc/resolv.conf", buffer.Bytes())
-	if err != nil {
-		return bosherr.WrapError(err, "Writing to /etc/resolv.conf")
+	if !written {
+		return modifiedNetworks, changedNetworks, nil
 	}
 
-	return nil
-}
+	oldStanzas := ubuntuInterfaceStanzas(oldContents)
+	newStanzas := ubuntuInterfaceStanzas(buffer.String())
 
-const ubuntuResolvConfTemplate = `# Generated by bosh-agent
-{{ range .DNSServers }}nameserver {{ . }}
-{{ end }}`
+	for _, modifiedNet := range modifiedNetworks {
+		for _, interfaceName := range modifiedNet.interfaceNames() {
+			if oldStanzas[interfaceName] != newStanzas[interfaceName] {
+				changedNetworks = append(changedNetworks, modifiedNet)
+				break
+			}
+		}
+	}
 
-func (net ubuntuNetManager) detectMacAddresses() (map[string]string, error) {
-	addresses := map[string]string{}
+	return modifiedNetworks, changedNetworks, nil
+}
 
-	filePaths, err := net.fs.Glob("/sys/class/net/*")
-	if err != nil {
-		return addresses, bosherr.WrapError(err, "Getting file list from /sys/class/net")
-	}
+// ubuntuInterfaceStanzas groups lines of /etc/network/interfaces
+// by the interface name from the preceding 'auto' line
+func ubuntuInterfaceStanzas(contents string) map[string]string {
+	stanzas := map[string]string{}
 
-	var macAddress string
-	for _, filePath := range filePaths {
-		macAddress, err = net.fs.ReadFileString(filepath.Join(filePath, "address"))
-		if err != nil {
-			return addresses, bosherr.WrapError(err, "Reading mac address from file")
-		}
+	var interfaceName string
 
-		macAddress = strings.Trim(macAddress, "\n")
+	for _, line := range strings.Split(contents, "\n") {
+		if strings.HasPrefix(line, "auto ") {
+			interfaceName = strings.TrimSpace(strings.TrimPrefix(line, "auto "))
+		}
 
-		interfaceName := filepath.Base(filePath)
-		addresses[macAddress] = interfaceName
+		if interfaceName != "" && strings.TrimSpace(line) != "" {
+			stanzas[interfaceName] += strings.TrimSpace(line) + "\n"
+		}
 	}
 
-	return addresses, nil
+	return stanzas
 }
 
+const ubuntuNetworkInterfacesPath = "/etc/network/interfaces"
+
+const ubuntuNetworkInterfacesTemplate = `# Generated by bosh-agent
+auto lo
+iface lo inet loopback
+{{ range $net := . }}{{ range $net.SlaveInterfaces }}
+auto {{ . }}
+iface {{ . }} inet manual
+    bond-master {{ $net.BondInterface }}{{ end }}{{ if and .BondInterface .VLANRawDevice }}
+auto {{ .BondInterface }}
+iface {{ .BondInterface }} inet manual{{ template "bond" . }}{{ end }}
+auto {{ .Interface }}{{ if .IP }}
+iface {{ .Interface }} inet static
+    address {{ .IP }}
+    network {{ .NetworkIP }}
+    netmask {{ .Netmask }}
+    broadcast {{ .Broadcast }}
+{{ if .HasDefaultGateway }}    gateway {{ .Gateway }}{{ end }}{{ template "options" . }}{{ range .Routes }}
+    post-up route add -net {{ .Destination }} netmask {{ .Netmask }} gw {{ .Gateway }}
+    pre-down route del -net {{ .Destination }} netmask {{ .Netmask }} gw {{ .Gateway }}{{ end }}{{ end }}{{ if .IsIPv6Manual }}
+iface {{ .Interface }} inet6 static
+    address {{ .IPv6 }}
+    netmask {{ .IPv6Prefix }}{{ if .IPv6Gateway }}
+    gateway {{ .IPv6Gateway }}{{ end }}{{ if not .IP }}{{ template "options" . }}{{ end }}{{ else if .IsIPv6SLAAC }}
+iface {{ .Interface }} inet6 auto{{ if not .IP }}{{ template "options" . }}{{ end }}{{ else if .IsIPv6DHCP }}
+iface {{ .Interface }} inet6 dhcp{{ if not .IP }}{{ template "options" . }}{{ end }}{{ end }}{{ end }}{{ define "options" }}{{ if .MTU }}
+    mtu {{ .MTU }}{{ end }}{{ if .VLANRawDevice }}
+    vlan-raw-device {{ .VLANRawDevice }}{{ else if .BondInterface }}{{ template "bond" . }}{{ end }}{{ end }}{{ define "bond" }}
+    bond-slaves none
+    bond-mode {{ .Bond.Mode }}
+    bond-miimon {{ .Bond.MIIMon }}{{ end }}`
+
 func (net ubuntuNetManager) restartNetworkingInterfaces(networks []customNetwork) {
 	for _, network := range networks {
-		_, _, _, err := net.cmdRunner.RunCommand("service", "network-interface", "stop", "INTERFACE="+network.Interface)
-		if err != nil {
-			net.logger.Info(ubuntuNetManagerLogTag, "Ignoring network stop failure: %#v", err)
+		for _, interfaceName := range network.interfaceNames() {
+			_, _, _, err := net.cmdRunner.RunCommand("service", "network-interface", "stop", "INTERFACE="+interfaceName)
+			if err != nil {
+				net.logger.Info(ubuntuNetManagerLogTag, "Ignoring network stop failure: %#v", err)
+			}
+
+			_, _, _, err = net.cmdRunner.RunCommand("service", "network-interface", "start", "INTERFACE="+interfaceName)
+			if err != nil {
+				net.logger.Info(ubuntuNetManagerLogTag, "Ignoring network start failure: %#v", err)
+			}
 		}
+	}
+}
 
-		_, _, _, err = net.cmdRunner.RunCommand("service", "network-interface", "start", "INTERFACE="+network.Interface)
-		if err != nil {
-			net.logger.Info(ubuntuNetManagerLogTag, "Ignoring network start failure: %#v", err)
-		}
+func (net ubuntuNetManager) restartAllNetworkingInterfaces() {
+	args := net.restartNetworkArguments()
+
+	_, _, _, err := net.cmdRunner.RunCommand("ifdown", args...)
+	if err != nil {
+		net.logger.Info(ubuntuNetManagerLogTag, "Ignoring ifdown failure: %#v", err)
+	}
+
+	_, _, _, err = net.cmdRunner.RunCommand("ifup", args...)
+	if err != nil {
+		net.logger.Info(ubuntuNetManagerLogTag, "Ignoring ifup failure: %#v", err)
 	}
 }
 
@@ -7,6 +7,8 @@ import (
 	boshlog "bosh/logger"
 	. "bosh/platform/net"
 	fakearp "bosh/platform/net/arp/fakes"
+	boshdns "bosh/platform/net/dns"
+	fakedns "bosh/platform/net/dns/fakes"
 	fakenet "bosh/platform/net/fakes"
 	boship "bosh/platform/net/ip"
 	fakeip "bosh/platform/net/ip/fakes"
@@ -26,6 +28,68 @@ iface eth0 inet static
     broadcast 192.168.195.255
     gateway 192.168.195.1`
 
+const expectedUbuntuDualStackNetworkInterfaces = `# Generated by bosh-agent
+auto lo
+iface lo inet loopback
+
+auto eth0
+iface eth0 inet static
+    address 192.168.195.6
+    network 192.168.195.0
+    netmask 255.255.255.0
+    broadcast 192.168.195.255
+    gateway 192.168.195.1
+iface eth0 inet6 static
+    address 2001:db8::10
+    netmask 64
+    gateway 2001:db8::1`
+
+const expectedUbuntuSLAACNetworkInterfaces = `# Generated by bosh-agent
+auto lo
+iface lo inet loopback
+
+auto eth0
+iface eth0 inet6 auto`
+
+const expectedUbuntuRoutesNetworkInterfaces = `# Generated by bosh-agent
+auto lo
+iface lo inet loopback
+
+auto eth0
+iface eth0 inet static
+    address 192.168.195.6
+    network 192.168.195.0
+    netmask 255.255.255.0
+    broadcast 192.168.195.255
+    gateway 192.168.195.1
+    mtu 9000
+    post-up route add -net 10.0.0.0 netmask 255.0.0.0 gw 192.168.195.2
+    pre-down route del -net 10.0.0.0 netmask 255.0.0.0 gw 192.168.195.2`
+
+const expectedUbuntuBondedVLANNetworkInterfaces = `# Generated by bosh-agent
+auto lo
+iface lo inet loopback
+
+auto eth0
+iface eth0 inet manual
+    bond-master bond0
+auto eth1
+iface eth1 inet manual
+    bond-master bond0
+auto bond0
+iface bond0 inet manual
+    bond-slaves none
+    bond-mode 802.3ad
+    bond-miimon 100
+auto bond0.100
+iface bond0.100 inet static
+    address 192.168.195.6
+    network 192.168.195.0
+    netmask 255.255.255.0
+    broadcast 192.168.195.255
+    gateway 192.168.195.1
+    vlan-raw-device bond0`
+
 const expectedUbuntuResolvConf = `# Generated by bosh-agent
 nameserver 10.80.130.1
 nameserver 10.80.130.2
@@ -69,6 +133,8 @@ prepend domain-name-servers xx.xx.xx.xx, yy.yy.yy.yy, zz.zz.zz.zz;
 				defaultNetworkResolver,
 				ipResolver,
 				addressBroadcaster,
+				boshdns.NewResolvConfManager(fs, nil, logger),
+				"/fake-network-config-backup.json",
 				logger,
 			)
 		})
@@ -223,6 +289,35 @@ prepend domain-name-servers xx.xx.xx.xx, yy.yy.yy.yy, zz.zz.zz.zz;
 					ItDoesNotRestartDhcp()
 				})
 			})
+
+			Context("when caching resolver is enabled", func() {
+				BeforeEach(func() {
+					cmdRunner.CommandExistsValue = false
+
+					logger := boshlog.NewLogger(boshlog.LevelNone)
+					cachingResolver := &fakedns.FakeCachingResolver{ListenIPIP: "127.0.0.1"}
+
+					netManager = NewUbuntuNetManager(
+						fs,
+						cmdRunner,
+						defaultNetworkResolver,
+						ipResolver,
+						addressBroadcaster,
+						boshdns.NewResolvConfManager(fs, cachingResolver, logger),
+						"/fake-network-config-backup.json",
+						logger,
+					)
+				})
+
+				It("prepends caching resolver instead of configured servers so that dhclient keeps using it", func() {
+					err := netManager.SetupDhcp(networks, nil)
+					Expect(err).ToNot(HaveOccurred())
+
+					dhcpConfig := fs.GetFileTestStat("/etc/dhcp/dhclient.conf")
+					Expect(dhcpConfig.StringContents()).To(ContainSubstring("\nprepend domain-name-servers 127.0.0.1;\n"))
+					Expect(dhcpConfig.StringContents()).ToNot(ContainSubstring("xx.xx.xx.xx"))
+				})
+			})
 		})
 
 		Describe("SetupManualNetworking", func() {
@@ -383,6 +478,335 @@ prepend domain-name-servers xx.xx.xx.xx, yy.yy.yy.yy, zz.zz.zz.zz;
 					}))
 				})
 			})
+
+			Context("when network has static routes and mtu", func() {
+				routesNetworks := boshsettings.Networks{
+					"bosh": boshsettings.Network{
+						Default: []string{"dns", "gateway"},
+						IP:      "192.168.195.6",
+						Netmask: "255.255.255.0",
+						Gateway: "192.168.195.1",
+						Mac:     "22:00:0a:1f:ac:2a",
+						MTU:     9000,
+						Routes: []boshsettings.Route{
+							{Destination: "10.0.0.0", Netmask: "255.0.0.0", Gateway: "192.168.195.2"},
+						},
+					},
+				}
+
+				It("writes mtu and routes to /etc/network/interfaces", func() {
+					err := netManager.SetupManualNetworking(routesNetworks, nil)
+					Expect(err).ToNot(HaveOccurred())
+
+					networkConfig := fs.GetFileTestStat("/etc/network/interfaces")
+					Expect(networkConfig).ToNot(BeNil())
+					Expect(networkConfig.StringContents()).To(Equal(expectedUbuntuRoutesNetworkInterfaces))
+				})
+			})
+
+			Context("when network is configured on VLAN on top of bonded interfaces", func() {
+				BeforeEach(func() {
+					fs.WriteFile("/sys/class/net/eth1", []byte{})
+					fs.WriteFileString("/sys/class/net/eth1/address", "22:00:0a:1f:ac:2b\n")
+					fs.SetGlob("/sys/class/net/*", []string{"/sys/class/net/eth0", "/sys/class/net/eth1"})
+				})
+
+				bondedNetworks := boshsettings.Networks{
+					"bosh": boshsettings.Network{
+						Default: []string{"dns", "gateway"},
+						IP:      "192.168.195.6",
+						Netmask: "255.255.255.0",
+						Gateway: "192.168.195.1",
+						VLAN:    100,
+						Bond: boshsettings.Bond{
+							Slaves: []string{"22:00:0a:1f:ac:2a", "22:00:0a:1f:ac:2b"},
+							Mode:   "802.3ad",
+						},
+					},
+				}
+
+				It("writes slave, bond and vlan stanzas to /etc/network/interfaces", func() {
+					err := netManager.SetupManualNetworking(bondedNetworks, nil)
+					Expect(err).ToNot(HaveOccurred())
+
+					networkConfig := fs.GetFileTestStat("/etc/network/interfaces")
+					Expect(networkConfig).ToNot(BeNil())
+					Expect(networkConfig.StringContents()).To(Equal(expectedUbuntuBondedVLANNetworkInterfaces))
+				})
+
+				It("restarts slave, bond and vlan interfaces", func() {
+					err := netManager.SetupManualNetworking(bondedNetworks, errCh)
+					Expect(err).ToNot(HaveOccurred())
+
+					<-errCh // wait for all arpings
+
+					Expect(cmdRunner.RunCommands).To(Equal([][]string{
+						{"service", "network-interface", "stop", "INTERFACE=eth0"},
+						{"service", "network-interface", "start", "INTERFACE=eth0"},
+						{"service", "network-interface", "stop", "INTERFACE=eth1"},
+						{"service", "network-interface", "start", "INTERFACE=eth1"},
+						{"service", "network-interface", "stop", "INTERFACE=bond0"},
+						{"service", "network-interface", "start", "INTERFACE=bond0"},
+						{"service", "network-interface", "stop", "INTERFACE=bond0.100"},
+						{"service", "network-interface", "start", "INTERFACE=bond0.100"},
+					}))
+				})
+
+				It("broadcasts address of the vlan interface", func() {
+					err := netManager.SetupManualNetworking(bondedNetworks, errCh)
+					Expect(err).ToNot(HaveOccurred())
+
+					<-errCh // wait for all arpings
+
+					Expect(addressBroadcaster.BroadcastMACAddressesAddresses).To(Equal([]boship.InterfaceAddress{
+						boship.NewSimpleInterfaceAddress("bond0.100", "192.168.195.6"),
+					}))
+				})
+
+				It("returns error when bond slave mac address cannot be resolved", func() {
+					fs.SetGlob("/sys/class/net/*", []string{"/sys/class/net/eth0"})
+
+					err := netManager.SetupManualNetworking(bondedNetworks, nil)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Unknown bond slave mac address 22:00:0a:1f:ac:2b"))
+				})
+			})
+
+			Context("when only some of the networks changed", func() {
+				BeforeEach(func() {
+					fs.WriteFile("/sys/class/net/eth1", []byte{})
+					fs.WriteFileString("/sys/class/net/eth1/address", "22:00:0a:1f:ac:2b\n")
+					fs.SetGlob("/sys/class/net/*", []string{"/sys/class/net/eth0", "/sys/class/net/eth1"})
+
+					fs.WriteFileString("/etc/network/interfaces", expectedUbuntuNetworkInterfaces)
+				})
+
+				It("restarts only changed interfaces", func() {
+					twoNetworks := boshsettings.Networks{
+						"bosh": networks["bosh"],
+						"other": boshsettings.Network{
+							IP:      "10.10.0.6",
+							Netmask: "255.255.255.0",
+							Gateway: "10.10.0.1",
+							Mac:     "22:00:0a:1f:ac:2b",
+						},
+					}
+
+					err := netManager.SetupManualNetworking(twoNetworks, errCh)
+					Expect(err).ToNot(HaveOccurred())
+
+					<-errCh // wait for all arpings
+
+					Expect(cmdRunner.RunCommands).To(Equal([][]string{
+						{"service", "network-interface", "stop", "INTERFACE=eth1"},
+						{"service", "network-interface", "start", "INTERFACE=eth1"},
+					}))
+				})
+			})
+
+			Context("when network has manual IPv6 configuration", func() {
+				dualStackNetworks := boshsettings.Networks{
+					"bosh": boshsettings.Network{
+						Default:     []string{"dns", "gateway"},
+						IP:          "192.168.195.6",
+						Netmask:     "255.255.255.0",
+						Gateway:     "192.168.195.1",
+						IPv6Mode:    boshsettings.IPv6ModeManual,
+						IPv6:        "2001:db8::10",
+						IPv6Prefix:  64,
+						IPv6Gateway: "2001:db8::1",
+						Mac:         "22:00:0a:1f:ac:2a",
+						DNS:         []string{"10.80.130.1", "10.80.130.2"},
+					},
+				}
+
+				It("writes IPv4 and IPv6 stanzas to /etc/network/interfaces", func() {
+					err := netManager.SetupManualNetworking(dualStackNetworks, nil)
+					Expect(err).ToNot(HaveOccurred())
+
+					networkConfig := fs.GetFileTestStat("/etc/network/interfaces")
+					Expect(networkConfig).ToNot(BeNil())
+					Expect(networkConfig.StringContents()).To(Equal(expectedUbuntuDualStackNetworkInterfaces))
+				})
+
+				It("starts broadcasting IPv4 and IPv6 addresses", func() {
+					err := netManager.SetupManualNetworking(dualStackNetworks, errCh)
+					Expect(err).ToNot(HaveOccurred())
+
+					<-errCh // wait for all arpings
+
+					Expect(addressBroadcaster.BroadcastMACAddressesAddresses).To(Equal([]boship.InterfaceAddress{
+						boship.NewSimpleInterfaceAddress("eth0", "192.168.195.6"),
+						boship.NewSimpleInterfaceAddress("eth0", "2001:db8::10"),
+					}))
+				})
+			})
+
+			Context("when network only has SLAAC IPv6 configuration", func() {
+				slaacNetworks := boshsettings.Networks{
+					"bosh": boshsettings.Network{
+						Default:  []string{"dns", "gateway"},
+						IPv6Mode: boshsettings.IPv6ModeSLAAC,
+						Mac:      "22:00:0a:1f:ac:2a",
+						DNS:      []string{"10.80.130.1", "10.80.130.2"},
+					},
+				}
+
+				It("writes auto configured inet6 stanza to /etc/network/interfaces", func() {
+					err := netManager.SetupManualNetworking(slaacNetworks, nil)
+					Expect(err).ToNot(HaveOccurred())
+
+					networkConfig := fs.GetFileTestStat("/etc/network/interfaces")
+					Expect(networkConfig).ToNot(BeNil())
+					Expect(networkConfig.StringContents()).To(Equal(expectedUbuntuSLAACNetworkInterfaces))
+				})
+
+				It("does not broadcast any addresses since kernel announces them", func() {
+					err := netManager.SetupManualNetworking(slaacNetworks, errCh)
+					Expect(err).ToNot(HaveOccurred())
+
+					<-errCh // wait for all arpings
+
+					Expect(addressBroadcaster.BroadcastMACAddressesAddresses).To(BeEmpty())
+				})
+			})
+		})
+
+		Describe("RollbackManualNetworking", func() {
+			BeforeEach(func() {
+				fs.WriteFileString("/proc/sys/kernel/random/boot_id", "fake-boot-id\n")
+				fs.WriteFile("/sys/class/net/eth0", []byte{})
+				fs.WriteFileString("/sys/class/net/eth0/address", "22:00:0a:1f:ac:2a\n")
+				fs.SetGlob("/sys/class/net/*", []string{"/sys/class/net/eth0"})
+
+				cmdRunner.AddCmdResult("ifup --version", fakesys.FakeCmdResult{
+					Stdout: "ifup version 0.7.47",
+				})
+			})
+
+			newNetworks := boshsettings.Networks{
+				"bosh": boshsettings.Network{
+					Default: []string{"dns", "gateway"},
+					IP:      "192.168.195.6",
+					Netmask: "255.255.255.0",
+					Gateway: "192.168.195.1",
+					Mac:     "22:00:0a:1f:ac:2a",
+					DNS:     []string{"10.80.130.1", "10.80.130.2"},
+				},
+			}
+
+			It("restores network configuration files replaced by manual networking setup", func() {
+				fs.WriteFileString("/etc/network/interfaces", "fake-previous-interfaces")
+				fs.WriteFileString("/etc/resolv.conf", "fake-previous-resolv-conf")
+
+				err := netManager.SetupManualNetworking(newNetworks, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = netManager.RollbackManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+
+				networkConfig := fs.GetFileTestStat("/etc/network/interfaces")
+				Expect(networkConfig.StringContents()).To(Equal("fake-previous-interfaces"))
+
+				resolvConf := fs.GetFileTestStat("/etc/resolv.conf")
+				Expect(resolvConf.StringContents()).To(Equal("fake-previous-resolv-conf"))
+			})
+
+			It("removes network configuration files that did not exist before manual networking setup", func() {
+				err := netManager.SetupManualNetworking(newNetworks, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = netManager.RollbackManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fs.FileExists("/etc/network/interfaces")).To(BeFalse())
+				Expect(fs.FileExists("/etc/resolv.conf")).To(BeFalse())
+			})
+
+			It("restarts networking after restoring configuration", func() {
+				err := netManager.SetupManualNetworking(newNetworks, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				cmdRunner.RunCommands = [][]string{}
+
+				err = netManager.RollbackManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(cmdRunner.RunCommands).To(Equal([][]string{
+					{"ifup", "--version"},
+					{"ifdown", "-a", "--no-loopback"},
+					{"ifup", "-a", "--no-loopback"},
+				}))
+			})
+
+			It("does not restart networking when there is nothing to restore", func() {
+				err := netManager.RollbackManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(cmdRunner.RunCommands).To(BeEmpty())
+			})
+
+			It("does not restore configuration that was backed up before reboot", func() {
+				fs.WriteFileString("/etc/network/interfaces", "fake-previous-interfaces")
+
+				err := netManager.SetupManualNetworking(newNetworks, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				fs.WriteFileString("/proc/sys/kernel/random/boot_id", "fake-new-boot-id\n")
+				cmdRunner.RunCommands = [][]string{}
+
+				err = netManager.RollbackManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+
+				networkConfig := fs.GetFileTestStat("/etc/network/interfaces")
+				Expect(networkConfig.StringContents()).ToNot(Equal("fake-previous-interfaces"))
+				Expect(fs.FileExists("/fake-network-config-backup.json")).To(BeFalse())
+				Expect(cmdRunner.RunCommands).To(BeEmpty())
+			})
+
+			It("does not restore configuration that was committed", func() {
+				fs.WriteFileString("/etc/network/interfaces", "fake-previous-interfaces")
+
+				err := netManager.SetupManualNetworking(newNetworks, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = netManager.CommitManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fs.FileExists("/fake-network-config-backup.json")).To(BeFalse())
+
+				err = netManager.RollbackManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+
+				networkConfig := fs.GetFileTestStat("/etc/network/interfaces")
+				Expect(networkConfig.StringContents()).ToNot(Equal("fake-previous-interfaces"))
+			})
+
+			It("reports uncommitted manual networking until it is committed", func() {
+				err := netManager.SetupManualNetworking(newNetworks, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				uncommitted, err := netManager.HasUncommittedManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(uncommitted).To(BeTrue())
+
+				err = netManager.CommitManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+
+				uncommitted, err = netManager.HasUncommittedManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(uncommitted).To(BeFalse())
+			})
+
+			It("does not report uncommitted manual networking set up before reboot", func() {
+				err := netManager.SetupManualNetworking(newNetworks, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				fs.WriteFileString("/proc/sys/kernel/random/boot_id", "fake-new-boot-id\n")
+
+				uncommitted, err := netManager.HasUncommittedManualNetworking()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(uncommitted).To(BeFalse())
+			})
 		})
 	})
 }
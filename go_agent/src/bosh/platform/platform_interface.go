@@ -3,12 +3,31 @@ package platform
 import (
 	boshdpresolv "bosh/infrastructure/devicepathresolver"
 	boshcmd "bosh/platform/commands"
+	boshdisk "bosh/platform/disk"
+	boshdiskcopier "bosh/platform/diskcopier"
 	boshvitals "bosh/platform/vitals"
 	boshsettings "bosh/settings"
 	boshdir "bosh/settings/directories"
 	boshsys "bosh/system"
 )
 
+// PersistentDiskMountResult describes what was done to persistent disk before it was mounted
+type PersistentDiskMountResult struct {
+	FileSystem boshdisk.FileSystemType `json:"file_system,omitempty"`
+	Resized    bool                    `json:"resized,omitempty"`
+	Check      *boshdisk.CheckResult   `json:"fsck,omitempty"`
+}
+
+// PersistentDiskSnapshot is a point-in-time copy of LVM-backed persistent disk
+type PersistentDiskSnapshot struct {
+	Name         string  `json:"name"`
+	SizeInBytes  uint64  `json:"size_in_bytes"`
+	UsagePercent float64 `json:"usage_percent"`
+
+	// Read-only mount point; empty when snapshot is not mounted
+	MountPoint string `json:"mount_point,omitempty"`
+}
+
 type Platform interface {
 	GetFs() boshsys.FileSystem
 	GetRunner() boshsys.CmdRunner
@@ -41,19 +60,47 @@ type Platform interface {
 	SetupRuntimeConfiguration() (err error)
 
 	// Disk management
-	MountPersistentDisk(devicePath, mountPoint string) error
-	UnmountPersistentDisk(devicePath string) (didUnmount bool, err error)
-	MigratePersistentDisk(fromMountPoint, toMountPoint string) (err error)
+	// Persistent disks are found by volume ID (disk CID) when infrastructure supports it,
+	// otherwise by device path from settings
+	MountPersistentDisk(volumeID, devicePath, mountPoint string) (PersistentDiskMountResult, error)
+	UnmountPersistentDisk(volumeID, devicePath string) (didUnmount bool, err error)
+	// MigratePersistentDisk can be retried after failure or cancellation
+	// and continues copying files that were not copied yet
+	MigratePersistentDisk(fromMountPoint, toMountPoint string, progress *boshdiskcopier.Progress, cancelCh <-chan struct{}) (err error)
 	NormalizeDiskPath(devicePath string) (realPath string, found bool)
 	IsMountPoint(path string) (result bool, err error)
-	IsPersistentDiskMounted(path string) (result bool, err error)
+	IsPersistentDiskMounted(volumeID, devicePath string) (result bool, err error)
+
+	// Snapshots are only available when persistent disk is an LVM logical volume
+	// CheckPersistentDiskSnapshot returns error when snapshot with given name cannot be taken
+	CheckPersistentDiskSnapshot(volumeID, devicePath, name string) error
+	SnapshotPersistentDisk(volumeID, devicePath, name string) (PersistentDiskSnapshot, error)
+	ListPersistentDiskSnapshots(volumeID, devicePath string) ([]PersistentDiskSnapshot, error)
+	DeletePersistentDiskSnapshot(volumeID, devicePath, name string) error
 
 	GetFileContentsFromCDROM(filePath string) (contents []byte, err error)
 
+	// GetFilesContentsFromDisk mounts disk read-only to read files from it
+	GetFilesContentsFromDisk(diskPath string, fileNames []string) (contents [][]byte, err error)
+
 	// Network misc
 	PrepareForNetworkingChange() error
 	GetDefaultNetwork() (boshsettings.Network, error)
 
+	// StartDNSCache starts DNS cache (if enabled) for configured DNS servers
+	StartDNSCache(networks boshsettings.Networks) error
+
+	// VerifyNetworking rolls back manual networking configuration
+	// and records failure if network connectivity cannot be verified
+	VerifyNetworking(networks boshsettings.Networks, endpoints []string) (err error)
+
+	// HasUnverifiedNetworking is true when networking was set up in the current boot
+	// but was not verified, e.g. because agent restarted before verifying it
+	HasUnverifiedNetworking() (bool, error)
+
+	// GetNetworkingFailure returns recorded network verification failure if any
+	GetNetworkingFailure() (failure string, err error)
+
 	// Additional monit management
 	GetMonitCredentials() (username, password string, err error)
 }
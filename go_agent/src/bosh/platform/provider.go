@@ -1,6 +1,7 @@
 package platform
 
 import (
+	"path/filepath"
 	"time"
 
 	bosherror "bosh/errors"
@@ -10,8 +11,10 @@ import (
 	boshcd "bosh/platform/cdutil"
 	boshcmd "bosh/platform/commands"
 	boshdisk "bosh/platform/disk"
+	boshdiskcopier "bosh/platform/diskcopier"
 	boshnet "bosh/platform/net"
 	bosharp "bosh/platform/net/arp"
+	boshdns "bosh/platform/net/dns"
 	boship "bosh/platform/net/ip"
 	boshstats "bosh/platform/stats"
 	boshvitals "bosh/platform/vitals"
@@ -26,7 +29,16 @@ const (
 )
 
 const (
-	SigarStatsCollectionInterval = 10 * time.Second
+	StatsCollectionInterval = 10 * time.Second
+)
+
+const (
+	DNSCacheListenAddr = "127.0.0.1:53"
+)
+
+const (
+	NetworkVerificationTimeout    = 60 * time.Second
+	NetworkVerificationRetryDelay = 2 * time.Second
 )
 
 type provider struct {
@@ -49,13 +61,29 @@ func NewProvider(logger boshlog.Logger, dirProvider boshdirs.DirectoriesProvider
 
 	compressor := boshcmd.NewTarballCompressor(runner, fs)
 	copier := boshcmd.NewCpCopier(runner, fs, logger)
+	diskCopier := boshdiskcopier.NewNativeCopier(logger)
 
-	sigarCollector := boshstats.NewSigarStatsCollector()
+	var statsCollector boshstats.StatsCollector
+	if options.Linux.UseProcStatsCollector {
+		statsCollector = boshstats.NewProcStatsCollector(fs, "/")
+	} else {
+		statsCollector = boshstats.NewSigarStatsCollector(fs)
+	}
 
 	// Kick of stats collection as soon as possible
-	sigarCollector.StartCollecting(SigarStatsCollectionInterval)
+	statsCollector.StartCollecting(StatsCollectionInterval)
 
-	vitalsService := boshvitals.NewService(sigarCollector, dirProvider)
+	var dnsCache boshdns.CachingResolver
+	if options.Linux.UseDNSCache {
+		dnsCache = boshdns.NewForwardingCachingResolver(DNSCacheListenAddr, logger)
+	}
+
+	dnsManager := boshdns.NewResolvConfManager(fs, dnsCache, logger)
+
+	// resolv.conf is owned by systemd-resolved which is configured through network units
+	resolvedDNSManager := boshdns.NewResolvedManager(dnsCache, logger)
+
+	vitalsService := boshvitals.NewService(statsCollector, dirProvider, dnsCache)
 
 	routesSearcher := boshnet.NewCmdRoutesSearcher(runner)
 	ipResolver := boship.NewIPResolver(boship.NetworkInterfaceToAddrsFunc)
@@ -63,20 +91,28 @@ func NewProvider(logger boshlog.Logger, dirProvider boshdirs.DirectoriesProvider
 	defaultNetworkResolver := boshnet.NewDefaultNetworkResolver(routesSearcher, ipResolver)
 	arping := bosharp.NewArping(runner, fs, logger, ArpIterations, ArpIterationDelay, ArpInterfaceCheckDelay)
 
-	centosNetManager := boshnet.NewCentosNetManager(fs, runner, defaultNetworkResolver, ipResolver, arping, logger)
-	ubuntuNetManager := boshnet.NewUbuntuNetManager(fs, runner, defaultNetworkResolver, ipResolver, arping, logger)
+	networkConfigBackupPath := filepath.Join(dirProvider.BoshDir(), "network_config_backup.json")
+
+	centosNetManager := boshnet.NewCentosNetManager(fs, runner, defaultNetworkResolver, ipResolver, arping, dnsManager, networkConfigBackupPath, logger)
+	ubuntuNetManager := boshnet.NewUbuntuNetManager(fs, runner, defaultNetworkResolver, ipResolver, arping, dnsManager, networkConfigBackupPath, logger)
+	systemdNetManager := boshnet.NewSystemdNetManager(fs, runner, defaultNetworkResolver, ipResolver, arping, resolvedDNSManager, networkConfigBackupPath, logger)
+
+	netVerifier := boshnet.NewConnectivityVerifier(runner, NetworkVerificationTimeout, NetworkVerificationRetryDelay, logger)
 
 	centos := NewLinuxPlatform(
 		fs,
 		runner,
-		sigarCollector,
+		statsCollector,
 		compressor,
 		copier,
+		diskCopier,
 		dirProvider,
 		vitalsService,
 		linuxCdutil,
 		linuxDiskManager,
 		centosNetManager,
+		netVerifier,
+		dnsManager,
 		500*time.Millisecond,
 		options.Linux,
 		logger,
@@ -85,23 +121,46 @@ func NewProvider(logger boshlog.Logger, dirProvider boshdirs.DirectoriesProvider
 	ubuntu := NewLinuxPlatform(
 		fs,
 		runner,
-		sigarCollector,
+		statsCollector,
 		compressor,
 		copier,
+		diskCopier,
 		dirProvider,
 		vitalsService,
 		linuxCdutil,
 		linuxDiskManager,
 		ubuntuNetManager,
+		netVerifier,
+		dnsManager,
+		500*time.Millisecond,
+		options.Linux,
+		logger,
+	)
+
+	systemd := NewLinuxPlatform(
+		fs,
+		runner,
+		statsCollector,
+		compressor,
+		copier,
+		diskCopier,
+		dirProvider,
+		vitalsService,
+		linuxCdutil,
+		linuxDiskManager,
+		systemdNetManager,
+		netVerifier,
+		resolvedDNSManager,
 		500*time.Millisecond,
 		options.Linux,
 		logger,
 	)
 
 	p.platforms = map[string]Platform{
-		"ubuntu": ubuntu,
-		"centos": centos,
-		"dummy":  NewDummyPlatform(sigarCollector, fs, runner, dirProvider, logger),
+		"ubuntu":  ubuntu,
+		"centos":  centos,
+		"systemd": systemd,
+		"dummy":   NewDummyPlatform(statsCollector, fs, runner, dirProvider, logger),
 	}
 	return
 }
@@ -36,3 +36,17 @@ func (p dummyStatsCollector) GetDiskStats(devicePath string) (stats DiskStats, e
 	stats.InodeUsage.Total = 1
 	return
 }
+
+func (p dummyStatsCollector) GetDiskIOStats() (stats map[string]DiskIOStats, err error) {
+	stats = map[string]DiskIOStats{}
+	return
+}
+
+func (p dummyStatsCollector) GetNetworkStats() (stats map[string]NetworkStats, err error) {
+	stats = map[string]NetworkStats{}
+	return
+}
+
+func (p dummyStatsCollector) GetProcessStats(pid int) (stats ProcessStats, err error) {
+	return
+}
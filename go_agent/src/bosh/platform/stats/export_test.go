@@ -0,0 +1,15 @@
+package stats
+
+import (
+	boshsys "bosh/system"
+)
+
+// NewProcIOCollector and Collect let tests take collections synchronously
+// instead of racing with the collection goroutine
+func NewProcIOCollector(fs boshsys.FileSystem, procDir string) *procIOCollector {
+	return newProcIOCollector(fs, procDir)
+}
+
+func (c *procIOCollector) Collect() {
+	c.collect()
+}
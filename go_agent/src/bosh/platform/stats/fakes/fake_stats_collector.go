@@ -16,6 +16,14 @@ type FakeStatsCollector struct {
 	MemStats  boshstats.Usage
 	SwapStats boshstats.Usage
 	DiskStats map[string]boshstats.DiskStats
+
+	DiskIOStats    map[string]boshstats.DiskIOStats
+	DiskIOStatsErr error
+
+	NetworkStats    map[string]boshstats.NetworkStats
+	NetworkStatsErr error
+
+	ProcessStats map[int]boshstats.ProcessStats
 }
 
 func (c *FakeStatsCollector) StartCollecting(collectionInterval time.Duration) {
@@ -49,3 +57,19 @@ func (c *FakeStatsCollector) GetDiskStats(devicePath string) (stats boshstats.Di
 	}
 	return
 }
+
+func (c *FakeStatsCollector) GetDiskIOStats() (map[string]boshstats.DiskIOStats, error) {
+	return c.DiskIOStats, c.DiskIOStatsErr
+}
+
+func (c *FakeStatsCollector) GetNetworkStats() (map[string]boshstats.NetworkStats, error) {
+	return c.NetworkStats, c.NetworkStatsErr
+}
+
+func (c *FakeStatsCollector) GetProcessStats(pid int) (stats boshstats.ProcessStats, err error) {
+	stats, found := c.ProcessStats[pid]
+	if !found {
+		err = errors.New("Process not found")
+	}
+	return
+}
@@ -0,0 +1,303 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	bosherr "bosh/errors"
+	boshsys "bosh/system"
+)
+
+// Sizes in /proc/diskstats are always in 512 byte sectors regardless of the device
+const diskStatsSectorSize = 512
+
+type diskIOCounters struct {
+	reads        uint64
+	readSectors  uint64
+	readTimeMs   uint64
+	writes       uint64
+	writeSectors uint64
+	writeTimeMs  uint64
+}
+
+type networkCounters struct {
+	receivedBytes uint64
+	receiveErrors uint64
+	sentBytes     uint64
+	sendErrors    uint64
+}
+
+// procIOCollector reads disk, network and process stats from proc file system.
+// Disk and network counters are cumulative so that
+// stats are computed from deltas between two latest collections.
+type procIOCollector struct {
+	fs      boshsys.FileSystem
+	procDir string
+
+	lock sync.RWMutex
+
+	prevCollectedAt time.Time
+	prevDisks       map[string]diskIOCounters
+	prevNetworks    map[string]networkCounters
+
+	latestDiskIOStats  map[string]DiskIOStats
+	latestNetworkStats map[string]NetworkStats
+}
+
+func newProcIOCollector(fs boshsys.FileSystem, procDir string) *procIOCollector {
+	return &procIOCollector{
+		fs:                 fs,
+		procDir:            procDir,
+		latestDiskIOStats:  map[string]DiskIOStats{},
+		latestNetworkStats: map[string]NetworkStats{},
+	}
+}
+
+func (c *procIOCollector) StartCollecting(collectionInterval time.Duration) {
+	go func() {
+		c.collect()
+
+		for _ = range time.Tick(collectionInterval) {
+			c.collect()
+		}
+	}()
+}
+
+// collect takes one sample of disk and network counters;
+// it is called synchronously so that collections can be driven without a ticker
+func (c *procIOCollector) collect() {
+	collectedAt := time.Now()
+
+	// Failing to read one of the files only leaves its stats stale
+	disks, disksErr := c.readDiskIOCounters()
+	networks, networksErr := c.readNetworkCounters()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	interval := collectedAt.Sub(c.prevCollectedAt)
+
+	if disksErr == nil {
+		if c.prevDisks != nil {
+			c.latestDiskIOStats = diskIOStatsDelta(c.prevDisks, disks, interval)
+		}
+		c.prevDisks = disks
+	}
+
+	if networksErr == nil {
+		if c.prevNetworks != nil {
+			c.latestNetworkStats = networkStatsDelta(c.prevNetworks, networks, interval)
+		}
+		c.prevNetworks = networks
+	}
+
+	c.prevCollectedAt = collectedAt
+}
+
+func (c *procIOCollector) GetDiskIOStats() (map[string]DiskIOStats, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.latestDiskIOStats, nil
+}
+
+func (c *procIOCollector) GetNetworkStats() (map[string]NetworkStats, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.latestNetworkStats, nil
+}
+
+func (c *procIOCollector) GetProcessStats(pid int) (stats ProcessStats, err error) {
+	pidDir := filepath.Join(c.procDir, strconv.Itoa(pid))
+
+	contents, err := c.fs.ReadFileString(filepath.Join(pidDir, "stat"))
+	if err != nil {
+		err = bosherr.WrapError(err, "Reading stat of process %d", pid)
+		return
+	}
+
+	// Process name is in parentheses and may contain spaces
+	closingParenIdx := strings.LastIndex(contents, ")")
+	if closingParenIdx == -1 {
+		err = bosherr.New("Unexpected stat of process %d: '%s'", pid, contents)
+		return
+	}
+
+	// Fields after process name start with state (3rd field); rss is 24th field
+	fields := strings.Fields(contents[closingParenIdx+1:])
+	if len(fields) < 22 {
+		err = bosherr.New("Unexpected stat of process %d: '%s'", pid, contents)
+		return
+	}
+
+	rssPages, err := strconv.ParseUint(fields[21], 10, 64)
+	if err != nil {
+		err = bosherr.WrapError(err, "Parsing rss of process %d", pid)
+		return
+	}
+
+	fds, err := c.fs.Glob(filepath.Join(pidDir, "fd", "*"))
+	if err != nil {
+		err = bosherr.WrapError(err, "Listing open files of process %d", pid)
+		return
+	}
+
+	stats.RSSBytes = rssPages * uint64(os.Getpagesize())
+	stats.OpenFiles = len(fds)
+
+	return
+}
+
+func (c *procIOCollector) readDiskIOCounters() (map[string]diskIOCounters, error) {
+	contents, err := c.fs.ReadFileString(filepath.Join(c.procDir, "diskstats"))
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Reading diskstats")
+	}
+
+	disks := map[string]diskIOCounters{}
+
+	for _, line := range strings.Split(contents, "\n") {
+		// major minor name reads merged sectors ms writes merged sectors ms ...
+		fields := strings.Fields(line)
+		if len(fields) < 11 {
+			continue
+		}
+
+		name := fields[2]
+
+		// Loop and ram devices are not backed by real disks
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+			continue
+		}
+
+		values, err := parseCounters(fields, 3, 4, 5, 6, 7, 8, 9, 10)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Parsing diskstats of %s", name)
+		}
+
+		disks[name] = diskIOCounters{
+			reads:        values[0],
+			readSectors:  values[2],
+			readTimeMs:   values[3],
+			writes:       values[4],
+			writeSectors: values[6],
+			writeTimeMs:  values[7],
+		}
+	}
+
+	return disks, nil
+}
+
+func (c *procIOCollector) readNetworkCounters() (map[string]networkCounters, error) {
+	contents, err := c.fs.ReadFileString(filepath.Join(c.procDir, "net", "dev"))
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Reading net/dev")
+	}
+
+	networks := map[string]networkCounters{}
+
+	for _, line := range strings.Split(contents, "\n") {
+		// Header lines do not have interface name followed by colon
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		name := strings.TrimSpace(parts[0])
+		if name == "lo" {
+			continue
+		}
+
+		// Receive bytes packets errs drop fifo frame compressed multicast
+		// followed by transmit bytes packets errs ...
+		fields := strings.Fields(parts[1])
+		if len(fields) < 11 {
+			continue
+		}
+
+		values, err := parseCounters(fields, 0, 2, 8, 10)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Parsing net/dev of %s", name)
+		}
+
+		networks[name] = networkCounters{
+			receivedBytes: values[0],
+			receiveErrors: values[1],
+			sentBytes:     values[2],
+			sendErrors:    values[3],
+		}
+	}
+
+	return networks, nil
+}
+
+func parseCounters(fields []string, idxs ...int) ([]uint64, error) {
+	var values []uint64
+
+	for _, idx := range idxs {
+		value, err := strconv.ParseUint(fields[idx], 10, 64)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Parsing counter '%s'", fields[idx])
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func diskIOStatsDelta(prev, curr map[string]diskIOCounters, interval time.Duration) map[string]DiskIOStats {
+	stats := map[string]DiskIOStats{}
+
+	for name, c := range curr {
+		p, found := prev[name]
+		if !found {
+			continue
+		}
+
+		stats[name] = DiskIOStats{
+			Reads:      counterDelta(p.reads, c.reads),
+			Writes:     counterDelta(p.writes, c.writes),
+			ReadBytes:  counterDelta(p.readSectors, c.readSectors) * diskStatsSectorSize,
+			WriteBytes: counterDelta(p.writeSectors, c.writeSectors) * diskStatsSectorSize,
+			ReadTime:   time.Duration(counterDelta(p.readTimeMs, c.readTimeMs)) * time.Millisecond,
+			WriteTime:  time.Duration(counterDelta(p.writeTimeMs, c.writeTimeMs)) * time.Millisecond,
+			Interval:   interval,
+		}
+	}
+
+	return stats
+}
+
+func networkStatsDelta(prev, curr map[string]networkCounters, interval time.Duration) map[string]NetworkStats {
+	stats := map[string]NetworkStats{}
+
+	for name, c := range curr {
+		p, found := prev[name]
+		if !found {
+			continue
+		}
+
+		stats[name] = NetworkStats{
+			ReceivedBytes: counterDelta(p.receivedBytes, c.receivedBytes),
+			SentBytes:     counterDelta(p.sentBytes, c.sentBytes),
+			ReceiveErrors: counterDelta(p.receiveErrors, c.receiveErrors),
+			SendErrors:    counterDelta(p.sendErrors, c.sendErrors),
+			Interval:      interval,
+		}
+	}
+
+	return stats
+}
+
+// counterDelta treats decreased counter as reset (e.g. wrapped around or re-attached device)
+func counterDelta(prev, curr uint64) uint64 {
+	if curr < prev {
+		return curr
+	}
+	return curr - prev
+}
@@ -0,0 +1,120 @@
+package stats_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bosh/platform/stats"
+	fakesys "bosh/system/fakes"
+)
+
+var _ = Describe("procIOCollector", func() {
+	var (
+		fs        *fakesys.FakeFileSystem
+		collector interface {
+			Collect()
+			GetDiskIOStats() (map[string]DiskIOStats, error)
+			GetNetworkStats() (map[string]NetworkStats, error)
+		}
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		collector = NewProcIOCollector(fs, "/proc")
+	})
+
+	Describe("GetDiskIOStats", func() {
+		writeDiskStats := func(sdaReads, sdaReadTimeMs, sdaWrites, sdaWriteTimeMs string) {
+			fs.WriteFileString("/proc/diskstats", `
+   7       0 loop0 10 0 20 30 0 0 0 0 0 30 30
+   8       0 sda `+sdaReads+` 0 100 `+sdaReadTimeMs+` `+sdaWrites+` 0 200 `+sdaWriteTimeMs+` 0 40 40
+   8       1 sda1 5 0 10 20 5 0 10 20 0 40 40
+`)
+		}
+
+		It("returns empty stats before the second collection", func() {
+			writeDiskStats("10", "30", "20", "30")
+			collector.Collect()
+
+			stats, err := collector.GetDiskIOStats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats).To(BeEmpty())
+		})
+
+		It("returns deltas between two latest collections ignoring loop devices", func() {
+			writeDiskStats("10", "30", "20", "30")
+			collector.Collect()
+
+			writeDiskStats("40", "90", "50", "180")
+			collector.Collect()
+
+			stats, err := collector.GetDiskIOStats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats).To(HaveLen(2))
+
+			Expect(stats["sda"].Reads).To(Equal(uint64(30)))
+			Expect(stats["sda"].Writes).To(Equal(uint64(30)))
+			Expect(stats["sda"].ReadBytes).To(Equal(uint64(0)))
+			Expect(stats["sda"].Interval).To(BeNumerically(">", 0))
+
+			Expect(stats["sda1"].Reads).To(Equal(uint64(0)))
+		})
+
+		It("returns per request latency from read and write times", func() {
+			writeDiskStats("10", "30", "20", "30")
+			collector.Collect()
+
+			writeDiskStats("40", "90", "50", "180")
+			collector.Collect()
+
+			stats, err := collector.GetDiskIOStats()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(stats["sda"].ReadLatency()).To(Equal(2 * time.Millisecond))
+			Expect(stats["sda"].WriteLatency()).To(Equal(5 * time.Millisecond))
+			Expect(stats["sda"].Latency()).To(Equal(3500 * time.Microsecond))
+		})
+
+		It("keeps previous stats when diskstats cannot be read", func() {
+			writeDiskStats("10", "30", "20", "30")
+			collector.Collect()
+
+			writeDiskStats("40", "90", "50", "180")
+			collector.Collect()
+
+			fs.RemoveAll("/proc/diskstats")
+			collector.Collect()
+
+			stats, err := collector.GetDiskIOStats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats["sda"].Reads).To(Equal(uint64(30)))
+		})
+	})
+
+	Describe("GetNetworkStats", func() {
+		It("returns deltas between two latest collections ignoring loopback", func() {
+			netDev := `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo: %s 10 0 0 0 0 0 0 %s 10 0 0 0 0 0 0
+  eth0: %s 10 %s 0 0 0 0 0 %s 10 1 0 0 0 0 0
+`
+			fs.WriteFileString("/proc/net/dev", fmt.Sprintf(netDev, "100", "100", "1000", "0", "2000"))
+			collector.Collect()
+
+			fs.WriteFileString("/proc/net/dev", fmt.Sprintf(netDev, "900", "900", "1500", "2", "2100"))
+			collector.Collect()
+
+			stats, err := collector.GetNetworkStats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats).To(HaveLen(1))
+
+			Expect(stats["eth0"].ReceivedBytes).To(Equal(uint64(500)))
+			Expect(stats["eth0"].ReceiveErrors).To(Equal(uint64(2)))
+			Expect(stats["eth0"].SentBytes).To(Equal(uint64(100)))
+			Expect(stats["eth0"].SendErrors).To(Equal(uint64(0)))
+		})
+	})
+})
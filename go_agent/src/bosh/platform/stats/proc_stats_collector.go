@@ -0,0 +1,261 @@
+package stats
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	bosherr "bosh/errors"
+	boshsys "bosh/system"
+)
+
+type procStatsCollector struct {
+	fs       boshsys.FileSystem
+	rootPath string
+	procDir  string
+
+	// Until second collection happens latest CPU stats are totals since boot
+	latestCPUStats     CPUStats
+	prevCPUStats       CPUStats
+	latestCPUStatsLock sync.RWMutex
+
+	ioCollector *procIOCollector
+}
+
+// NewProcStatsCollector reads stats directly from proc file system;
+// rootPath is prepended to all paths ("/" on a real system)
+func NewProcStatsCollector(fs boshsys.FileSystem, rootPath string) *procStatsCollector {
+	procDir := filepath.Join(rootPath, "proc")
+
+	return &procStatsCollector{
+		fs:          fs,
+		rootPath:    rootPath,
+		procDir:     procDir,
+		ioCollector: newProcIOCollector(fs, procDir),
+	}
+}
+
+func (s *procStatsCollector) StartCollecting(collectionInterval time.Duration) {
+	go func() {
+		s.collectCPUStats()
+
+		for _ = range time.Tick(collectionInterval) {
+			s.collectCPUStats()
+		}
+	}()
+
+	s.ioCollector.StartCollecting(collectionInterval)
+}
+
+func (s *procStatsCollector) collectCPUStats() {
+	// Failing to read stats only leaves latest CPU stats stale
+	totals, err := s.readCPUTotals()
+	if err != nil {
+		return
+	}
+
+	s.latestCPUStatsLock.Lock()
+	defer s.latestCPUStatsLock.Unlock()
+
+	s.latestCPUStats = CPUStats{
+		User:  counterDelta(s.prevCPUStats.User, totals.User),
+		Nice:  counterDelta(s.prevCPUStats.Nice, totals.Nice),
+		Sys:   counterDelta(s.prevCPUStats.Sys, totals.Sys),
+		Wait:  counterDelta(s.prevCPUStats.Wait, totals.Wait),
+		Total: counterDelta(s.prevCPUStats.Total, totals.Total),
+	}
+
+	s.prevCPUStats = totals
+}
+
+func (s *procStatsCollector) readCPUTotals() (stats CPUStats, err error) {
+	contents, err := s.fs.ReadFileString(filepath.Join(s.procDir, "stat"))
+	if err != nil {
+		err = bosherr.WrapError(err, "Reading stat")
+		return
+	}
+
+	for _, line := range strings.Split(contents, "\n") {
+		// Aggregate line of all CPUs: cpu user nice system idle iowait irq softirq steal guest guest_nice
+		fields := strings.Fields(line)
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+
+		// Guest time is already included in user time
+		if len(fields) > 9 {
+			fields = fields[:9]
+		}
+
+		var values []uint64
+
+		for _, field := range fields[1:] {
+			var value uint64
+
+			value, err = strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				err = bosherr.WrapError(err, "Parsing cpu stat '%s'", line)
+				return
+			}
+
+			values = append(values, value)
+		}
+
+		stats.User = values[0]
+		stats.Nice = values[1]
+		stats.Sys = values[2]
+
+		// iowait is only available since Linux 2.5.41
+		if len(values) > 4 {
+			stats.Wait = values[4]
+		}
+
+		for _, value := range values {
+			stats.Total += value
+		}
+
+		return
+	}
+
+	err = bosherr.New("Missing cpu line in stat")
+	return
+}
+
+func (s *procStatsCollector) GetCPULoad() (load CPULoad, err error) {
+	contents, err := s.fs.ReadFileString(filepath.Join(s.procDir, "loadavg"))
+	if err != nil {
+		err = bosherr.WrapError(err, "Reading loadavg")
+		return
+	}
+
+	fields := strings.Fields(contents)
+	if len(fields) < 3 {
+		err = bosherr.New("Unexpected loadavg '%s'", contents)
+		return
+	}
+
+	values := make([]float64, 3)
+
+	for i := range values {
+		values[i], err = strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			err = bosherr.WrapError(err, "Parsing loadavg '%s'", contents)
+			return
+		}
+	}
+
+	load.One = values[0]
+	load.Five = values[1]
+	load.Fifteen = values[2]
+
+	return
+}
+
+func (s *procStatsCollector) GetCPUStats() (CPUStats, error) {
+	s.latestCPUStatsLock.RLock()
+	defer s.latestCPUStatsLock.RUnlock()
+
+	return s.latestCPUStats, nil
+}
+
+func (s *procStatsCollector) GetMemStats() (usage Usage, err error) {
+	memInfo, err := s.readMemInfo()
+	if err != nil {
+		return
+	}
+
+	usage.Total = memInfo["MemTotal"]
+
+	// Buffers and page cache can be reclaimed so they are not considered used
+	// (same as sigar's actual used memory)
+	free := memInfo["MemFree"] + memInfo["Buffers"] + memInfo["Cached"]
+	if usage.Total > free {
+		usage.Used = usage.Total - free
+	}
+
+	return
+}
+
+func (s *procStatsCollector) GetSwapStats() (usage Usage, err error) {
+	memInfo, err := s.readMemInfo()
+	if err != nil {
+		return
+	}
+
+	usage.Total = memInfo["SwapTotal"]
+	if usage.Total > memInfo["SwapFree"] {
+		usage.Used = usage.Total - memInfo["SwapFree"]
+	}
+
+	return
+}
+
+// readMemInfo returns sizes in bytes keyed by field name
+func (s *procStatsCollector) readMemInfo() (map[string]uint64, error) {
+	contents, err := s.fs.ReadFileString(filepath.Join(s.procDir, "meminfo"))
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Reading meminfo")
+	}
+
+	memInfo := map[string]uint64{}
+
+	for _, line := range strings.Split(contents, "\n") {
+		// e.g. "MemTotal:        2048000 kB"
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		fields := strings.Fields(parts[1])
+		if len(fields) == 0 {
+			continue
+		}
+
+		value, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Parsing meminfo %s", parts[0])
+		}
+
+		if len(fields) > 1 && fields[1] == "kB" {
+			value *= 1024
+		}
+
+		memInfo[parts[0]] = value
+	}
+
+	return memInfo, nil
+}
+
+func (s *procStatsCollector) GetDiskStats(mountedPath string) (stats DiskStats, err error) {
+	var fsStat syscall.Statfs_t
+
+	err = syscall.Statfs(filepath.Join(s.rootPath, mountedPath), &fsStat)
+	if err != nil {
+		err = bosherr.WrapError(err, "Getting file system stats of %s", mountedPath)
+		return
+	}
+
+	blockSize := uint64(fsStat.Bsize)
+
+	stats.DiskUsage.Total = fsStat.Blocks * blockSize
+	stats.DiskUsage.Used = (fsStat.Blocks - fsStat.Bfree) * blockSize
+	stats.InodeUsage.Total = fsStat.Files
+	stats.InodeUsage.Used = fsStat.Files - fsStat.Ffree
+
+	return
+}
+
+func (s *procStatsCollector) GetDiskIOStats() (map[string]DiskIOStats, error) {
+	return s.ioCollector.GetDiskIOStats()
+}
+
+func (s *procStatsCollector) GetNetworkStats() (map[string]NetworkStats, error) {
+	return s.ioCollector.GetNetworkStats()
+}
+
+func (s *procStatsCollector) GetProcessStats(pid int) (ProcessStats, error) {
+	return s.ioCollector.GetProcessStats(pid)
+}
@@ -0,0 +1,137 @@
+package stats_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshlog "bosh/logger"
+	. "bosh/platform/stats"
+	boshsys "bosh/system"
+)
+
+var _ = Describe("procStatsCollector", func() {
+	var (
+		rootPath  string
+		fs        boshsys.FileSystem
+		collector StatsCollector
+	)
+
+	writeProcFile := func(name, contents string) {
+		err := fs.WriteFileString(filepath.Join(rootPath, "proc", name), contents)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		rootPath, err = ioutil.TempDir("", "proc-stats-collector")
+		Expect(err).ToNot(HaveOccurred())
+
+		fs = boshsys.NewOsFileSystem(boshlog.NewLogger(boshlog.LevelNone))
+		collector = NewProcStatsCollector(fs, rootPath)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(rootPath)
+	})
+
+	Describe("GetCPULoad", func() {
+		It("returns cpu load", func() {
+			writeProcFile("loadavg", "0.20 4.55 1.12 1/234 5678\n")
+
+			load, err := collector.GetCPULoad()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(load).To(Equal(CPULoad{One: 0.20, Five: 4.55, Fifteen: 1.12}))
+		})
+
+		It("returns error when loadavg is malformed", func() {
+			writeProcFile("loadavg", "0.20\n")
+
+			_, err := collector.GetCPULoad()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unexpected loadavg"))
+		})
+	})
+
+	Describe("GetCPUStats", func() {
+		It("returns cpu stats since boot before the second collection", func() {
+			writeProcFile("stat", "cpu  100 20 30 800 50 0 0 0 40 0\ncpu0 100 20 30 800 50 0 0 0 40 0\n")
+
+			collector.StartCollecting(1 * time.Hour)
+			time.Sleep(10 * time.Millisecond)
+
+			stats, err := collector.GetCPUStats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats).To(Equal(CPUStats{User: 100, Nice: 20, Sys: 30, Wait: 50, Total: 1000}))
+		})
+
+		It("returns deltas between two latest collections", func() {
+			writeProcFile("stat", "cpu  100 20 30 800 50 0 0 0 40 0\n")
+
+			collector.StartCollecting(20 * time.Millisecond)
+			time.Sleep(10 * time.Millisecond)
+
+			writeProcFile("stat", "cpu  140 30 40 830 60 5 5 10 40 0\n")
+			time.Sleep(20 * time.Millisecond)
+
+			stats, err := collector.GetCPUStats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats).To(Equal(CPUStats{User: 40, Nice: 10, Sys: 10, Wait: 10, Total: 120}))
+			Expect(stats.UserPercent().FormatFractionOf100(1)).To(Equal("41.7"))
+		})
+	})
+
+	Describe("GetMemStats", func() {
+		It("returns mem stats without buffers and page cache", func() {
+			writeProcFile("meminfo", `MemTotal:        1000 kB
+MemFree:          200 kB
+Buffers:          100 kB
+Cached:           300 kB
+SwapTotal:        500 kB
+SwapFree:         400 kB
+HugePages_Total:    0
+`)
+
+			usage, err := collector.GetMemStats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(usage).To(Equal(Usage{Used: 400 * 1024, Total: 1000 * 1024}))
+		})
+
+		It("returns error when meminfo cannot be read", func() {
+			_, err := collector.GetMemStats()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Reading meminfo"))
+		})
+	})
+
+	Describe("GetSwapStats", func() {
+		It("returns swap stats", func() {
+			writeProcFile("meminfo", "MemTotal: 1000 kB\nSwapTotal: 500 kB\nSwapFree: 400 kB\n")
+
+			usage, err := collector.GetSwapStats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(usage).To(Equal(Usage{Used: 100 * 1024, Total: 500 * 1024}))
+		})
+	})
+
+	Describe("GetDiskStats", func() {
+		It("returns disk stats of path relative to root path", func() {
+			stats, err := collector.GetDiskStats("/")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(stats.DiskUsage.Total).ToNot(BeZero())
+			Expect(stats.InodeUsage.Total).ToNot(BeZero())
+		})
+
+		It("returns error when path does not exist", func() {
+			_, err := collector.GetDiskStats("/fake-missing-dir")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Getting file system stats of /fake-missing-dir"))
+		})
+	})
+})
@@ -7,15 +7,21 @@ import (
 	sigar "github.com/cloudfoundry/gosigar"
 
 	bosherr "bosh/errors"
+	boshsys "bosh/system"
 )
 
 type sigarStatsCollector struct {
 	latestCPUStats     CPUStats
 	latestCPUStatsLock sync.RWMutex
+
+	// Sigar does not provide disk I/O, network and process fd stats
+	ioCollector *procIOCollector
 }
 
-func NewSigarStatsCollector() *sigarStatsCollector {
-	return &sigarStatsCollector{}
+func NewSigarStatsCollector(fs boshsys.FileSystem) *sigarStatsCollector {
+	return &sigarStatsCollector{
+		ioCollector: newProcIOCollector(fs, "/proc"),
+	}
 }
 
 func (s *sigarStatsCollector) StartCollecting(collectionInterval time.Duration) {
@@ -32,6 +38,8 @@ func (s *sigarStatsCollector) StartCollecting(collectionInterval time.Duration)
 			s.latestCPUStatsLock.Unlock()
 		}
 	}()
+
+	s.ioCollector.StartCollecting(collectionInterval)
 }
 
 func (s *sigarStatsCollector) GetCPULoad() (load CPULoad, err error) {
@@ -102,3 +110,15 @@ func (s *sigarStatsCollector) GetDiskStats(mountedPath string) (stats DiskStats,
 
 	return
 }
+
+func (s *sigarStatsCollector) GetDiskIOStats() (map[string]DiskIOStats, error) {
+	return s.ioCollector.GetDiskIOStats()
+}
+
+func (s *sigarStatsCollector) GetNetworkStats() (map[string]NetworkStats, error) {
+	return s.ioCollector.GetNetworkStats()
+}
+
+func (s *sigarStatsCollector) GetProcessStats(pid int) (ProcessStats, error) {
+	return s.ioCollector.GetProcessStats(pid)
+}
@@ -1,21 +1,25 @@
 package stats_test
 
 import (
+	"os"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	. "bosh/platform/stats"
+	fakesys "bosh/system/fakes"
 )
 
 var _ = Describe("sigarStatsCollector", func() {
 	var (
+		fs        *fakesys.FakeFileSystem
 		collector StatsCollector
 	)
 
 	BeforeEach(func() {
-		collector = NewSigarStatsCollector()
+		fs = fakesys.NewFakeFileSystem()
+		collector = NewSigarStatsCollector(fs)
 	})
 
 	Describe("GetCPULoad", func() {
@@ -90,4 +94,26 @@ var _ = Describe("sigarStatsCollector", func() {
 			Expect(stats.InodeUsage.Used).ToNot(BeZero())
 		})
 	})
+
+	Describe("GetProcessStats", func() {
+		BeforeEach(func() {
+			fs.WriteFileString("/proc/123/stat", "123 (fake process) S 1 123 123 0 -1 4202752 300 0 0 0 5 3 0 0 20 0 4 0 1000 200000 25 18446744073709551615")
+			fs.SetGlob("/proc/123/fd/*", []string{"/proc/123/fd/0", "/proc/123/fd/1"})
+		})
+
+		It("returns rss and number of open files", func() {
+			stats, err := collector.GetProcessStats(123)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats).To(Equal(ProcessStats{
+				RSSBytes:  uint64(25 * os.Getpagesize()),
+				OpenFiles: 2,
+			}))
+		})
+
+		It("returns error when process does not exist", func() {
+			_, err := collector.GetProcessStats(124)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Reading stat of process 124"))
+		})
+	})
 })
@@ -26,6 +26,36 @@ type DiskStats struct {
 	InodeUsage Usage
 }
 
+// DiskIOStats holds counters accumulated during the latest collection interval
+type DiskIOStats struct {
+	Reads      uint64
+	Writes     uint64
+	ReadBytes  uint64
+	WriteBytes uint64
+
+	// Sums of times each completed read and write request took
+	// including time spent in queue; not the time device was busy
+	ReadTime  time.Duration
+	WriteTime time.Duration
+
+	Interval time.Duration
+}
+
+// NetworkStats holds counters accumulated during the latest collection interval
+type NetworkStats struct {
+	ReceivedBytes uint64
+	SentBytes     uint64
+	ReceiveErrors uint64
+	SendErrors    uint64
+
+	Interval time.Duration
+}
+
+type ProcessStats struct {
+	RSSBytes  uint64
+	OpenFiles int
+}
+
 type StatsCollector interface {
 	StartCollecting(time.Duration)
 
@@ -35,6 +65,13 @@ type StatsCollector interface {
 	GetMemStats() (usage Usage, err error)
 	GetSwapStats() (usage Usage, err error)
 	GetDiskStats(mountedPath string) (stats DiskStats, err error)
+
+	// Disk I/O and network stats are keyed by device and interface name;
+	// they are empty until two collections happened
+	GetDiskIOStats() (stats map[string]DiskIOStats, err error)
+	GetNetworkStats() (stats map[string]NetworkStats, err error)
+
+	GetProcessStats(pid int) (stats ProcessStats, err error)
 }
 
 func (cpuStats CPUStats) UserPercent() Percentage {
@@ -52,3 +89,50 @@ func (cpuStats CPUStats) WaitPercent() Percentage {
 func (usage Usage) Percent() Percentage {
 	return NewPercentage(usage.Used, usage.Total)
 }
+
+func (s DiskIOStats) IOPS() float64 {
+	return perSecond(s.Reads+s.Writes, s.Interval)
+}
+
+// Latency returns average time per completed request (same as iostat await)
+func (s DiskIOStats) Latency() time.Duration {
+	return averageRequestTime(s.ReadTime+s.WriteTime, s.Reads+s.Writes)
+}
+
+func (s DiskIOStats) ReadLatency() time.Duration {
+	return averageRequestTime(s.ReadTime, s.Reads)
+}
+
+func (s DiskIOStats) WriteLatency() time.Duration {
+	return averageRequestTime(s.WriteTime, s.Writes)
+}
+
+func (s DiskIOStats) ReadBytesPerSecond() float64 {
+	return perSecond(s.ReadBytes, s.Interval)
+}
+
+func (s DiskIOStats) WriteBytesPerSecond() float64 {
+	return perSecond(s.WriteBytes, s.Interval)
+}
+
+func (s NetworkStats) ReceivedBytesPerSecond() float64 {
+	return perSecond(s.ReceivedBytes, s.Interval)
+}
+
+func (s NetworkStats) SentBytesPerSecond() float64 {
+	return perSecond(s.SentBytes, s.Interval)
+}
+
+func perSecond(count uint64, interval time.Duration) float64 {
+	if interval <= 0 {
+		return 0
+	}
+	return float64(count) / interval.Seconds()
+}
+
+func averageRequestTime(total time.Duration, requests uint64) time.Duration {
+	if requests == 0 {
+		return 0
+	}
+	return total / time.Duration(requests)
+}
@@ -1,6 +1,8 @@
 package stats_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -22,3 +24,27 @@ var _ = Describe("CPUStats", func() {
 		})
 	})
 })
+
+var _ = Describe("DiskIOStats", func() {
+	It("returns IOPS and average latency per request", func() {
+		stats := DiskIOStats{
+			Reads:     30,
+			Writes:    10,
+			ReadTime:  60 * time.Millisecond,
+			WriteTime: 140 * time.Millisecond,
+			Interval:  2 * time.Second,
+		}
+
+		Expect(stats.IOPS()).To(Equal(float64(20)))
+		Expect(stats.Latency()).To(Equal(5 * time.Millisecond))
+		Expect(stats.ReadLatency()).To(Equal(2 * time.Millisecond))
+		Expect(stats.WriteLatency()).To(Equal(14 * time.Millisecond))
+	})
+
+	It("returns zero when there were no requests", func() {
+		Expect(DiskIOStats{}.IOPS()).To(Equal(float64(0)))
+		Expect(DiskIOStats{}.Latency()).To(Equal(time.Duration(0)))
+		Expect(DiskIOStats{}.ReadLatency()).To(Equal(time.Duration(0)))
+		Expect(DiskIOStats{}.WriteLatency()).To(Equal(time.Duration(0)))
+	})
+})
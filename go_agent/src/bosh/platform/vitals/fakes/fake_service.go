@@ -5,6 +5,10 @@ import boshvitals "bosh/platform/vitals"
 type FakeService struct {
 	GetVitals boshvitals.Vitals
 	GetErr    error
+
+	GetExtendedProcessPids map[string]int
+	GetExtendedVitals      boshvitals.Vitals
+	GetExtendedErr         error
 }
 
 func NewFakeService() (fakeService *FakeService) {
@@ -17,3 +21,10 @@ func (s *FakeService) Get() (vitals boshvitals.Vitals, err error) {
 	err = s.GetErr
 	return
 }
+
+func (s *FakeService) GetExtended(processPids map[string]int) (vitals boshvitals.Vitals, err error) {
+	s.GetExtendedProcessPids = processPids
+	vitals = s.GetExtendedVitals
+	err = s.GetExtendedErr
+	return
+}
@@ -2,6 +2,7 @@ package vitals
 
 import (
 	bosherr "bosh/errors"
+	boshdns "bosh/platform/net/dns"
 	boshstats "bosh/platform/stats"
 	boshdirs "bosh/settings/directories"
 	"fmt"
@@ -9,17 +10,27 @@ import (
 
 type Service interface {
 	Get() (vitals Vitals, err error)
+
+	// GetExtended also includes disk I/O, network and given processes (pids keyed by name)
+	GetExtended(processPids map[string]int) (vitals Vitals, err error)
 }
 
 type concreteService struct {
 	statsCollector boshstats.StatsCollector
 	dirProvider    boshdirs.DirectoriesProvider
+	dnsCache       boshdns.CachingResolver
 }
 
-func NewService(statsCollector boshstats.StatsCollector, dirProvider boshdirs.DirectoriesProvider) Service {
+// NewService returns vitals service; dnsCache is optional
+func NewService(
+	statsCollector boshstats.StatsCollector,
+	dirProvider boshdirs.DirectoriesProvider,
+	dnsCache boshdns.CachingResolver,
+) Service {
 	return concreteService{
 		statsCollector: statsCollector,
 		dirProvider:    dirProvider,
+		dnsCache:       dnsCache,
 	}
 }
 
@@ -77,9 +88,84 @@ func (s concreteService) Get() (vitals Vitals, err error) {
 		Swap: createMemVitals(swapStats),
 		Disk: diskStats,
 	}
+
+	if s.dnsCache != nil {
+		cacheStats := s.dnsCache.Stats()
+
+		vitals.DNS = &DNSVitals{
+			CacheHits:   fmt.Sprintf("%d", cacheStats.Hits),
+			CacheMisses: fmt.Sprintf("%d", cacheStats.Misses),
+		}
+	}
 	return
 }
 
+func (s concreteService) GetExtended(processPids map[string]int) (Vitals, error) {
+	vitals, err := s.Get()
+	if err != nil {
+		return vitals, err
+	}
+
+	diskIOStats, err := s.statsCollector.GetDiskIOStats()
+	if err != nil {
+		return vitals, bosherr.WrapError(err, "Getting Disk IO Stats")
+	}
+
+	vitals.DiskIO = make(map[string]DiskIOVitals, len(diskIOStats))
+
+	for name, stats := range diskIOStats {
+		vitals.DiskIO[name] = DiskIOVitals{
+			IOPS:          fmt.Sprintf("%.1f", stats.IOPS()),
+			LatencyMs:     fmt.Sprintf("%.1f", stats.Latency().Seconds()*1000),
+			ReadKbPerSec:  fmt.Sprintf("%.1f", stats.ReadBytesPerSecond()/1024),
+			WriteKbPerSec: fmt.Sprintf("%.1f", stats.WriteBytesPerSecond()/1024),
+		}
+	}
+
+	networkStats, err := s.statsCollector.GetNetworkStats()
+	if err != nil {
+		return vitals, bosherr.WrapError(err, "Getting Network Stats")
+	}
+
+	vitals.Network = make(map[string]NetworkVitals, len(networkStats))
+
+	for name, stats := range networkStats {
+		vitals.Network[name] = NetworkVitals{
+			RxBytesPerSec: fmt.Sprintf("%.0f", stats.ReceivedBytesPerSecond()),
+			TxBytesPerSec: fmt.Sprintf("%.0f", stats.SentBytesPerSecond()),
+			RxErrors:      fmt.Sprintf("%d", stats.ReceiveErrors),
+			TxErrors:      fmt.Sprintf("%d", stats.SendErrors),
+		}
+	}
+
+	memStats, err := s.statsCollector.GetMemStats()
+	if err != nil {
+		return vitals, bosherr.WrapError(err, "Getting Memory Stats")
+	}
+
+	vitals.Processes = make(map[string]ProcessVitals, len(processPids))
+
+	for name, pid := range processPids {
+		if pid <= 0 {
+			continue
+		}
+
+		// Process might have exited since its pid was retrieved
+		stats, err := s.statsCollector.GetProcessStats(pid)
+		if err != nil {
+			continue
+		}
+
+		vitals.Processes[name] = ProcessVitals{
+			Pid:       fmt.Sprintf("%d", pid),
+			Mem:       createMemVitals(boshstats.Usage{Used: stats.RSSBytes, Total: memStats.Total}),
+			OpenFiles: fmt.Sprintf("%d", stats.OpenFiles),
+		}
+	}
+
+	return vitals, nil
+}
+
 func (s concreteService) getDiskStats() (diskStats DiskVitals, err error) {
 	disks := map[string]string{
 		"/": "system",
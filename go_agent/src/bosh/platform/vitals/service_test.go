@@ -1,12 +1,15 @@
 package vitals_test
 
 import (
+	"errors"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	boshassert "bosh/assert"
+	boshdns "bosh/platform/net/dns"
+	fakedns "bosh/platform/net/dns/fakes"
 	boshstats "bosh/platform/stats"
 	fakestats "bosh/platform/stats/fakes"
 	. "bosh/platform/vitals"
@@ -14,6 +17,10 @@ import (
 )
 
 func buildVitalsService() (statsCollector *fakestats.FakeStatsCollector, service Service) {
+	return buildVitalsServiceWithDNSCache(nil)
+}
+
+func buildVitalsServiceWithDNSCache(dnsCache boshdns.CachingResolver) (statsCollector *fakestats.FakeStatsCollector, service Service) {
 	dirProvider := boshdirs.NewDirectoriesProvider("/fake/base/dir")
 	statsCollector = &fakestats.FakeStatsCollector{
 		CPULoad: boshstats.CPULoad{
@@ -51,7 +58,7 @@ func buildVitalsService() (statsCollector *fakestats.FakeStatsCollector, service
 		},
 	}
 
-	service = NewService(statsCollector, dirProvider)
+	service = NewService(statsCollector, dirProvider, dnsCache)
 	statsCollector.StartCollecting(1 * time.Millisecond)
 	return
 }
@@ -97,6 +104,26 @@ func init() {
 			boshassert.MatchesJSONMap(GinkgoT(), vitals, expectedVitals)
 		})
 
+		It("does not include dns vitals when dns cache is not used", func() {
+			_, service := buildVitalsService()
+
+			vitals, err := service.Get()
+			Expect(err).ToNot(HaveOccurred())
+
+			boshassert.LacksJSONKey(GinkgoT(), vitals, "dns")
+		})
+
+		It("includes dns cache stats when dns cache is used", func() {
+			dnsCache := &fakedns.FakeCachingResolver{
+				StatsStats: boshdns.CacheStats{Hits: 10, Misses: 3},
+			}
+			_, service := buildVitalsServiceWithDNSCache(dnsCache)
+
+			vitals, err := service.Get()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vitals.DNS).To(Equal(&DNSVitals{CacheHits: "10", CacheMisses: "3"}))
+		})
+
 		It("getting vitals when missing disks", func() {
 
 			statsCollector, service := buildVitalsService()
@@ -122,4 +149,94 @@ func init() {
 			Expect(err).To(HaveOccurred())
 		})
 	})
+
+	Describe("GetExtended", func() {
+		var (
+			statsCollector *fakestats.FakeStatsCollector
+			service        Service
+		)
+
+		BeforeEach(func() {
+			statsCollector, service = buildVitalsService()
+
+			statsCollector.DiskIOStats = map[string]boshstats.DiskIOStats{
+				"sda": boshstats.DiskIOStats{
+					Reads:      30,
+					Writes:     10,
+					ReadBytes:  4096,
+					WriteBytes: 2048,
+					ReadTime:   150 * time.Millisecond,
+					WriteTime:  50 * time.Millisecond,
+					Interval:   2 * time.Second,
+				},
+			}
+
+			statsCollector.NetworkStats = map[string]boshstats.NetworkStats{
+				"eth0": boshstats.NetworkStats{
+					ReceivedBytes: 2000,
+					SentBytes:     1000,
+					ReceiveErrors: 3,
+					SendErrors:    1,
+					Interval:      2 * time.Second,
+				},
+			}
+
+			statsCollector.ProcessStats = map[int]boshstats.ProcessStats{
+				123: boshstats.ProcessStats{RSSBytes: 100 * 1024, OpenFiles: 12},
+			}
+		})
+
+		It("includes disk io, network and vitals of running processes", func() {
+			vitals, err := service.GetExtended(map[string]int{
+				"fake-process":         123,
+				"fake-stopped-process": 0,
+				"fake-exited-process":  124,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(vitals.Load).To(Equal([]string{"0.20", "4.55", "1.12"}))
+
+			Expect(vitals.DiskIO).To(Equal(map[string]DiskIOVitals{
+				"sda": DiskIOVitals{
+					IOPS:          "20.0",
+					LatencyMs:     "5.0",
+					ReadKbPerSec:  "2.0",
+					WriteKbPerSec: "1.0",
+				},
+			}))
+
+			Expect(vitals.Network).To(Equal(map[string]NetworkVitals{
+				"eth0": NetworkVitals{
+					RxBytesPerSec: "1000",
+					TxBytesPerSec: "500",
+					RxErrors:      "3",
+					TxErrors:      "1",
+				},
+			}))
+
+			Expect(vitals.Processes).To(Equal(map[string]ProcessVitals{
+				"fake-process": ProcessVitals{
+					Pid:       "123",
+					Mem:       MemoryVitals{Kb: "100", Percent: "10"},
+					OpenFiles: "12",
+				},
+			}))
+		})
+
+		It("returns error when disk io stats cannot be retrieved", func() {
+			statsCollector.DiskIOStatsErr = errors.New("fake-disk-io-err")
+
+			_, err := service.GetExtended(map[string]int{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-disk-io-err"))
+		})
+
+		It("returns error when network stats cannot be retrieved", func() {
+			statsCollector.NetworkStatsErr = errors.New("fake-network-err")
+
+			_, err := service.GetExtended(map[string]int{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-network-err"))
+		})
+	})
 }
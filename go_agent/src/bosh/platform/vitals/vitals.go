@@ -6,6 +6,14 @@ type Vitals struct {
 	Load []string     `json:"load,omitempty"`
 	Mem  MemoryVitals `json:"mem"`
 	Swap MemoryVitals `json:"swap"`
+
+	// DNS is only included when caching resolver is enabled
+	DNS *DNSVitals `json:"dns,omitempty"`
+
+	// Disk I/O, network and processes are only included in extended vitals
+	DiskIO    map[string]DiskIOVitals  `json:"disk_io,omitempty"`
+	Network   map[string]NetworkVitals `json:"network,omitempty"`
+	Processes map[string]ProcessVitals `json:"processes,omitempty"`
 }
 
 type CPUVitals struct {
@@ -25,3 +33,28 @@ type MemoryVitals struct {
 	Kb      string `json:"kb,omitempty"`
 	Percent string `json:"percent,omitempty"`
 }
+
+type DNSVitals struct {
+	CacheHits   string `json:"cache_hits"`
+	CacheMisses string `json:"cache_misses"`
+}
+
+type DiskIOVitals struct {
+	IOPS          string `json:"iops"`
+	LatencyMs     string `json:"latency_ms"`
+	ReadKbPerSec  string `json:"read_kb_per_sec"`
+	WriteKbPerSec string `json:"write_kb_per_sec"`
+}
+
+type NetworkVitals struct {
+	RxBytesPerSec string `json:"rx_bytes_per_sec"`
+	TxBytesPerSec string `json:"tx_bytes_per_sec"`
+	RxErrors      string `json:"rx_errors"`
+	TxErrors      string `json:"tx_errors"`
+}
+
+type ProcessVitals struct {
+	Pid       string       `json:"pid"`
+	Mem       MemoryVitals `json:"mem"`
+	OpenFiles string       `json:"open_files"`
+}
@@ -132,9 +132,10 @@ func (s concreteService) checkAtMostOneDynamicNetwork(settings Settings) error {
 }
 
 // GetSettings returns setting even if it fails to resolve IPs for dynamic networks.
+// IPv6 addresses assigned via SLAAC or DHCPv6 are resolved the same way.
 func (s *concreteService) GetSettings() Settings {
 	for networkName, network := range s.settings.Networks {
-		if !network.IsDynamic() {
+		if !network.IsDynamic() && !network.IsIPv6Auto() {
 			continue
 		}
 
@@ -146,9 +147,15 @@ func (s *concreteService) GetSettings() Settings {
 		}
 
 		// resolvedNetwork does not have all information for a network
-		network.IP = resolvedNetwork.IP
-		network.Netmask = resolvedNetwork.Netmask
-		network.Gateway = resolvedNetwork.Gateway
+		if network.IsDynamic() {
+			network.IP = resolvedNetwork.IP
+			network.Netmask = resolvedNetwork.Netmask
+			network.Gateway = resolvedNetwork.Gateway
+		}
+
+		network.IPv6 = resolvedNetwork.IPv6
+		network.IPv6Prefix = resolvedNetwork.IPv6Prefix
+		network.IPv6Gateway = resolvedNetwork.IPv6Gateway
 
 		s.settings.Networks[networkName] = network
 	}
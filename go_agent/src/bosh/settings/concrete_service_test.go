@@ -299,6 +299,47 @@ func init() {
 					})
 				})
 			})
+
+			Context("when there is a network with IPv6 address assigned via SLAAC", func() {
+				BeforeEach(func() {
+					loadedSettings = Settings{
+						Networks: map[string]Network{
+							"fake-net1": Network{
+								IP:       "fake-net1-ip",
+								Netmask:  "fake-net1-netmask",
+								Gateway:  "fake-net1-gateway",
+								IPv6Mode: IPv6ModeSLAAC,
+							},
+						},
+					}
+
+					platform.GetDefaultNetworkNetwork = Network{
+						IP:          "fake-resolved-ip",
+						Netmask:     "fake-resolved-netmask",
+						Gateway:     "fake-resolved-gateway",
+						IPv6:        "fake-resolved-ipv6",
+						IPv6Prefix:  64,
+						IPv6Gateway: "fake-resolved-ipv6-gateway",
+					}
+				})
+
+				It("returns settings with resolved IPv6 address keeping IPv4 configuration the same", func() {
+					settings := service.GetSettings()
+					Expect(settings).To(Equal(Settings{
+						Networks: map[string]Network{
+							"fake-net1": Network{
+								IP:          "fake-net1-ip",
+								Netmask:     "fake-net1-netmask",
+								Gateway:     "fake-net1-gateway",
+								IPv6Mode:    IPv6ModeSLAAC,
+								IPv6:        "fake-resolved-ipv6",
+								IPv6Prefix:  64,
+								IPv6Gateway: "fake-resolved-ipv6-gateway",
+							},
+						},
+					}))
+				})
+			})
 		})
 	})
 }
@@ -36,6 +36,10 @@ func (p DirectoriesProvider) StoreMigrationDir() string {
 	return filepath.Join(p.BaseDir(), "store_migration_target")
 }
 
+func (p DirectoriesProvider) StoreSnapshotsDir() string {
+	return filepath.Join(p.BaseDir(), "store_snapshots")
+}
+
 func (p DirectoriesProvider) PkgDir() string {
 	return filepath.Join(p.DataDir(), "packages")
 }
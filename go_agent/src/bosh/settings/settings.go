@@ -1,5 +1,9 @@
 package settings
 
+import (
+	"sort"
+)
+
 const (
 	RootUsername        = "root"
 	VCAPUsername        = "vcap"
@@ -38,8 +42,9 @@ type VM struct {
 	Name string `json:"name"`
 }
 
-func (d Disks) PersistentDiskPath() (path string) {
-	for _, path = range d.Persistent {
+// PersistentDisk returns disk CID along with device path of the persistent disk
+func (d Disks) PersistentDisk() (volumeID, path string) {
+	for volumeID, path = range d.Persistent {
 		return
 	}
 	return
@@ -55,6 +60,19 @@ func (e Env) GetPassword() string {
 
 type BoshEnv struct {
 	Password string `json:"password"`
+
+	// Includes disk I/O, network and processes vitals in heartbeats
+	ExtendedVitalsInHeartbeats bool `json:"extended_vitals_in_heartbeats"`
+
+	// Includes status of each job process in heartbeats
+	ProcessesInHeartbeats bool `json:"processes_in_heartbeats"`
+
+	// Seconds between periodic heartbeats; agent default is used when not set
+	HeartbeatInterval int `json:"heartbeat_interval"`
+
+	// Maximum number of seconds randomly added to each heartbeat interval;
+	// a tenth of heartbeat interval is used when not set
+	HeartbeatJitter int `json:"heartbeat_jitter"`
 }
 
 type Networks map[string]Network
@@ -65,6 +83,14 @@ const (
 	NetworkTypeDynamic NetworkType = "dynamic"
 )
 
+type IPv6Mode string
+
+const (
+	IPv6ModeManual IPv6Mode = "manual"
+	IPv6ModeSLAAC  IPv6Mode = "slaac"
+	IPv6ModeDHCPv6 IPv6Mode = "dhcpv6"
+)
+
 type Network struct {
 	Type NetworkType `json:"type"`
 
@@ -72,10 +98,44 @@ type Network struct {
 	Netmask string `json:"netmask"`
 	Gateway string `json:"gateway"`
 
+	// IPv6Mode is empty for IPv4 only networks
+	IPv6Mode    IPv6Mode `json:"ipv6_mode"`
+	IPv6        string   `json:"ipv6"`
+	IPv6Prefix  int      `json:"ipv6_prefix"`
+	IPv6Gateway string   `json:"ipv6_gateway"`
+
 	Default []string `json:"default"`
 	DNS     []string `json:"dns"`
 
+	// DNSSearch and DNSOptions (e.g. ndots:2, timeout:1) are written to resolv.conf
+	DNSSearch  []string `json:"dns_search"`
+	DNSOptions []string `json:"dns_options"`
+
 	Mac string `json:"mac"`
+
+	MTU    int     `json:"mtu"`
+	Routes []Route `json:"routes"`
+
+	// VLAN is a tag of 802.1q VLAN interface created on top of the resolved interface
+	VLAN int  `json:"vlan"`
+	Bond Bond `json:"bond"`
+}
+
+type Route struct {
+	Destination string `json:"destination"`
+	Netmask     string `json:"netmask"`
+	Gateway     string `json:"gateway"`
+}
+
+type Bond struct {
+	// Name of the bonded interface (e.g. bond0)
+	Name string `json:"name"`
+
+	// Slaves are MAC addresses of interfaces aggregated into the bond
+	Slaves []string `json:"slaves"`
+
+	Mode   string `json:"mode"`
+	MIIMon int    `json:"miimon"`
 }
 
 func (n Networks) DefaultNetworkFor(category string) (network Network, found bool) {
@@ -118,11 +178,42 @@ func (n Networks) DefaultIP() (ip string, found bool) {
 	return
 }
 
+// DefaultIPv6 prefers IPv6 address of a default network; networks are
+// checked in name order so that result does not depend on map order
+func (n Networks) DefaultIPv6() (ip string, found bool) {
+	var names []string
+	for name := range n {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		networkSettings := n[name]
+		if networkSettings.IPv6 == "" {
+			continue
+		}
+		if len(networkSettings.Default) > 0 {
+			return networkSettings.IPv6, true
+		}
+		if ip == "" {
+			ip = networkSettings.IPv6
+		}
+	}
+
+	if ip != "" {
+		found = true
+	}
+	return
+}
+
 func (n Networks) IPs() (ips []string) {
 	for _, net := range n {
 		if net.IP != "" {
 			ips = append(ips, net.IP)
 		}
+		if net.IPv6 != "" {
+			ips = append(ips, net.IPv6)
+		}
 	}
 	return
 }
@@ -131,6 +222,34 @@ func (n Network) IsDynamic() bool {
 	return n.Type == NetworkTypeDynamic
 }
 
+func (n Network) IsBonded() bool {
+	return len(n.Bond.Slaves) > 0
+}
+
+func (n Network) HasIPv6() bool {
+	return n.IPv6Mode != "" || n.IPv6 != ""
+}
+
+// IsIPv6Manual returns true when IPv6 address is statically assigned.
+// Networks without explicit mode but with an IPv6 address are considered manual.
+func (n Network) IsIPv6Manual() bool {
+	return n.IPv6Mode == IPv6ModeManual || (n.IPv6Mode == "" && n.IPv6 != "")
+}
+
+func (n Network) IsIPv6SLAAC() bool {
+	return n.IPv6Mode == IPv6ModeSLAAC
+}
+
+func (n Network) IsIPv6DHCP() bool {
+	return n.IPv6Mode == IPv6ModeDHCPv6
+}
+
+// IsIPv6Auto returns true when IPv6 address is assigned by the network
+// (SLAAC or DHCPv6) and has to be resolved from the interface.
+func (n Network) IsIPv6Auto() bool {
+	return n.IsIPv6SLAAC() || n.IsIPv6DHCP()
+}
+
 //{
 //	"agent_id": "bm-xxxxxxxx",
 //	"blobstore": {
@@ -167,6 +286,14 @@ func (n Network) IsDynamic() bool {
 //			"gateway": null,
 //			"ip": "xx.xx.xx.xx",
 //			"netmask": null,
+//			"ipv6_mode": "manual",
+//			"ipv6": "2001:db8::10",
+//			"ipv6_prefix": 64,
+//			"ipv6_gateway": "2001:db8::1",
+//			"mtu": 9000,
+//			"routes": [
+//				{"destination": "10.0.0.0", "netmask": "255.0.0.0", "gateway": "xx.xx.xx.xx"}
+//			],
 //			"type": "manual"
 //		},
 //		"vip": {
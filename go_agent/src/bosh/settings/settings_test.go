@@ -108,6 +108,123 @@ func init() {
 				Expect(found).To(BeFalse())
 			})
 		})
+
+		Describe("DefaultIPv6", func() {
+			It("with two networks only with defaults", func() {
+				networks := Networks{
+					"bosh": Network{
+						IPv6: "2001:db8::10",
+					},
+					"vip": Network{
+						IPv6:    "2001:db8::20",
+						Default: []string{"dns"},
+					},
+				}
+
+				ip, found := networks.DefaultIPv6()
+				Expect(found).To(BeTrue())
+				Expect(ip).To(Equal("2001:db8::20"))
+			})
+
+			It("ignores default network without IPv6 address", func() {
+				networks := Networks{
+					"bosh": Network{
+						IPv6: "2001:db8::10",
+					},
+					"vip": Network{
+						IP:      "aa.aa.aa.aa",
+						Default: []string{"dns"},
+					},
+				}
+
+				ip, found := networks.DefaultIPv6()
+				Expect(found).To(BeTrue())
+				Expect(ip).To(Equal("2001:db8::10"))
+			})
+
+			It("prefers default network regardless of map order", func() {
+				networks := Networks{
+					"a": Network{IPv6: "2001:db8::10"},
+					"b": Network{IPv6: "2001:db8::20", Default: []string{"dns", "gateway"}},
+					"c": Network{IPv6: "2001:db8::30"},
+					"d": Network{IPv6: "2001:db8::40"},
+				}
+
+				for i := 0; i < 20; i++ {
+					ip, found := networks.DefaultIPv6()
+					Expect(found).To(BeTrue())
+					Expect(ip).To(Equal("2001:db8::20"))
+				}
+			})
+
+			It("returns the same address when there is no default network", func() {
+				networks := Networks{
+					"c": Network{IPv6: "2001:db8::30"},
+					"a": Network{IPv6: "2001:db8::10"},
+					"b": Network{IPv6: "2001:db8::20"},
+				}
+
+				for i := 0; i < 20; i++ {
+					ip, found := networks.DefaultIPv6()
+					Expect(found).To(BeTrue())
+					Expect(ip).To(Equal("2001:db8::10"))
+				}
+			})
+
+			It("when none specified", func() {
+				networks := Networks{
+					"bosh": Network{IP: "xx.xx.xx.xx"},
+				}
+
+				_, found := networks.DefaultIPv6()
+				Expect(found).To(BeFalse())
+			})
+		})
+
+		Describe("IPs", func() {
+			It("includes IPv4 and IPv6 addresses", func() {
+				networks := Networks{
+					"bosh": Network{
+						IP:   "xx.xx.xx.xx",
+						IPv6: "2001:db8::10",
+					},
+				}
+
+				Expect(networks.IPs()).To(Equal([]string{"xx.xx.xx.xx", "2001:db8::10"}))
+			})
+		})
+	})
+
+	Describe("Network", func() {
+		Describe("IPv6 modes", func() {
+			It("considers network with IPv6 address and no mode to be manual", func() {
+				network := Network{IPv6: "2001:db8::10"}
+				Expect(network.HasIPv6()).To(BeTrue())
+				Expect(network.IsIPv6Manual()).To(BeTrue())
+				Expect(network.IsIPv6Auto()).To(BeFalse())
+			})
+
+			It("considers SLAAC and DHCPv6 networks to be auto configured", func() {
+				Expect(Network{IPv6Mode: IPv6ModeSLAAC}.IsIPv6Auto()).To(BeTrue())
+				Expect(Network{IPv6Mode: IPv6ModeDHCPv6}.IsIPv6Auto()).To(BeTrue())
+				Expect(Network{IPv6Mode: IPv6ModeManual}.IsIPv6Auto()).To(BeFalse())
+			})
+
+			It("does not have IPv6 when neither mode nor address is set", func() {
+				Expect(Network{IP: "xx.xx.xx.xx"}.HasIPv6()).To(BeFalse())
+			})
+		})
+
+		Describe("IsBonded", func() {
+			It("returns true when bond has slaves", func() {
+				network := Network{Bond: Bond{Slaves: []string{"fake-mac-1", "fake-mac-2"}}}
+				Expect(network.IsBonded()).To(BeTrue())
+			})
+
+			It("returns false when bond has no slaves", func() {
+				Expect(Network{Bond: Bond{Name: "bond0"}}.IsBonded()).To(BeFalse())
+			})
+		})
 	})
 
 	Describe("Settings", func() {